@@ -40,7 +40,8 @@ spec:
       {{- range $path, $_ := .Files.Glob  $tenantConfigPath }}
       {{- $dir := dir $path }}
     - namespace: {{ base $dir }}
-      {{- ($.Files.Glob $path).AsConfig | nindent 6 }}
+      files:
+        {{- ($.Files.Glob $path).AsConfig | nindent 8 }}
       {{ end }}
     hadoopConfigName: {{ .Values.configuration.hadoopConfigName }}
     hadoopConfigMountPath: {{ .Values.configuration.hadoopConfigMountPath }}
@@ -53,7 +54,8 @@ spec:
       {{- range $path, $_ := .Files.Glob  $tenantConfigPath }}
       {{- $dir := dir $path }}
     - namespace: {{ base $dir }}
-      {{- ($.Files.Glob $path).AsConfig | nindent 6 }}
+      files:
+        {{- ($.Files.Glob $path).AsConfig | nindent 8 }}
       {{ end }}
   {{- if .Values.tenantNamespaces }}
   tenantNamespaces:
@@ -33,7 +33,8 @@ spec:
       {{- range $path, $_ := .Files.Glob  $tenantConfigPath }}
       {{- $dir := dir $path }}
     - namespace: {{ base $dir }}
-      {{- ($.Files.Glob $path).AsConfig | nindent 6 }}
+      files:
+        {{- ($.Files.Glob $path).AsConfig | nindent 8 }}
       {{ end }}
     hadoopConfigName: hadoop-config
     hadoopConfigMountPath: /etc/hadoop
@@ -46,7 +47,8 @@ spec:
       {{- range $path, $_ := .Files.Glob  $tenantConfigPath }}
       {{- $dir := dir $path }}
     - namespace: {{ base $dir }}
-      {{- ($.Files.Glob $path).AsConfig | nindent 6 }}
+      files:
+        {{- ($.Files.Glob $path).AsConfig | nindent 8 }}
       {{ end }}
   standalone:
     {{- $ports := list 16000 16010 16030 16020 2181}}
@@ -19,6 +19,13 @@ metadata:
   name: {{ .Values.service.name }}
   namespace: {{ .Values.namespace }}
 spec:
+  {{- if .Values.clusterRef }}
+  clusterRef:
+    name: {{ .Values.clusterRef.name }}
+    {{- if .Values.clusterRef.namespace }}
+    namespace: {{ .Values.clusterRef.namespace }}
+    {{- end }}
+  {{- end }}
   baseImage: {{ .Values.service.image }}
   fsgroup: {{ .Values.service.runAsGroup }}
   {{- if .Values.service.labels }}
@@ -27,6 +34,18 @@ spec:
     {{ $key }}: {{ $val | quote }}
   {{- end }}
   {{- end }}
+  {{- if .Values.rsGroup }}
+  rsGroup:
+    {{- toYaml .Values.rsGroup | nindent 4 }}
+  {{- end }}
+  {{- if .Values.hbaseNamespaces }}
+  hbaseNamespaces:
+    {{- toYaml .Values.hbaseNamespaces | nindent 4 }}
+  {{- end }}
+  {{- if .Values.quotas }}
+  quotas:
+    {{- toYaml .Values.quotas | nindent 4 }}
+  {{- end }}
   configuration:
     hbaseConfigName: {{ .Values.configuration.hbaseConfigName }}
     hbaseConfigMountPath: {{ .Values.configuration.hbaseConfigMountPath }}
@@ -0,0 +1,136 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseBackupSpec defines the snapshot exported and where to
+type HbaseBackupSpec struct {
+	// HbaseCluster or HbaseStandalone the snapshot is exported from
+	ClusterRef HbaseSchemaClusterReference `json:"clusterRef"`
+	// Snapshot exported, which is taken first through the admin endpoint when table is set and it does not exist
+	Snapshot string `json:"snapshot"`
+	// Table as namespace:name snapshotted when the snapshot does not exist
+	// +optional
+	Table string `json:"table,omitempty"`
+	// Object store or HDFS the snapshot is exported to
+	Target HbaseBackupLocation `json:"target"`
+	// +optional
+	Job HbaseSnapshotJobSpec `json:"job,omitempty"`
+}
+
+// HbaseBackupLocation is an object store or filesystem snapshots are exported to and imported from
+type HbaseBackupLocation struct {
+	// Root of the exported snapshots, such as s3a://bucket/hbase, hdfs://namenode:8020/backups or file:///backups
+	// +kubebuilder:validation:Pattern:=`^[a-z0-9]+://`
+	URL string `json:"url"`
+	// Secret whose keys are set as environment of the job, such as AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY
+	// +optional
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// Hadoop properties passed to the job as -Dkey=value, such as fs.s3a.endpoint of a MinIO server
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+	// Volumes of the job in addition to the configuration of the cluster, such as a HostPath for file:// URLs
+	// +optional
+	Volumes []HbaseClusterVolume `json:"volumes,omitempty"`
+	// +optional
+	VolumeMounts []HbaseClusterVolumeMount `json:"volumeMounts,omitempty"`
+}
+
+// HbaseSnapshotJobSpec tunes the job copying snapshot files
+type HbaseSnapshotJobSpec struct {
+	// Number of map tasks copying files
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	Mappers *int32 `json:"mappers,omitempty"`
+	// Bandwidth limit of each mapper in MB/s
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	Bandwidth *int32 `json:"bandwidth,omitempty"`
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Retries of the job before it is failed, 0 unless set
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// HbaseSnapshotJobPhase is the progress of a backup or restore
+// +kubebuilder:validation:Enum=Pending;Running;Restoring;Succeeded;Failed
+type HbaseSnapshotJobPhase string
+
+const (
+	HbaseSnapshotJobPending   HbaseSnapshotJobPhase = "Pending"
+	HbaseSnapshotJobRunning   HbaseSnapshotJobPhase = "Running"
+	HbaseSnapshotJobRestoring HbaseSnapshotJobPhase = "Restoring"
+	HbaseSnapshotJobSucceeded HbaseSnapshotJobPhase = "Succeeded"
+	HbaseSnapshotJobFailed    HbaseSnapshotJobPhase = "Failed"
+)
+
+// HbaseSnapshotJobStatus defines the observed state of HbaseBackup and HbaseRestore
+type HbaseSnapshotJobStatus struct {
+	// +optional
+	Phase HbaseSnapshotJobPhase `json:"phase,omitempty"`
+	// Job copying the snapshot files
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+	// Bytes copied by the job, once it succeeded
+	// +optional
+	BytesCopied int64 `json:"bytesCopied,omitempty"`
+	// Cause of the failure
+	// +optional
+	Message string `json:"message,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshot`
+//+kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target.url`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Bytes",type=integer,JSONPath=`.status.bytesCopied`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// HbaseBackup is the Schema for the hbasebackups API
+type HbaseBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseBackupSpec        `json:"spec,omitempty"`
+	Status HbaseSnapshotJobStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseBackupList contains a list of HbaseBackup
+type HbaseBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseBackup{}, &HbaseBackupList{})
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package v1
 
 import (
+	"encoding/json"
+	"fmt"
+
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -26,6 +29,44 @@ import (
 type HbaseClusterContainerPort struct {
 	Port int32  `json:"port"`
 	Name string `json:"name"`
+	// Port of the pods targeted by Services, the port itself when not set
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	// +optional
+	TargetPort int32 `json:"targetPort,omitempty"`
+	// +kubebuilder:validation:Enum=TCP;UDP;SCTP
+	// +kubebuilder:default:=TCP
+	// +optional
+	Protocol corev1.Protocol `json:"protocol,omitempty"`
+	// Application protocol of the port in Services, such as the ones service meshes select their proxying on
+	// +optional
+	AppProtocol *string `json:"appProtocol,omitempty"`
+}
+
+// HbaseClusterServiceSpec customizes a Service created by the operator. Headless Services governing the StatefulSets
+// stay ClusterIP, as they give pods their DNS names
+type HbaseClusterServiceSpec struct {
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations of the Service, such as the ones of service meshes and cloud load balancers
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// +optional
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+	// +kubebuilder:validation:Enum=None;ClientIP
+	// +optional
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// Publishes the addresses of pods before they are ready, true when not set
+	// +optional
+	PublishNotReadyAddresses *bool `json:"publishNotReadyAddresses,omitempty"`
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	InternalTrafficPolicy *corev1.ServiceInternalTrafficPolicy `json:"internalTrafficPolicy,omitempty"`
+	// Only applies to NodePort and LoadBalancer Services
+	// +kubebuilder:validation:Enum=Cluster;Local
+	// +optional
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicy `json:"externalTrafficPolicy,omitempty"`
 }
 
 type HbaseClusterVolumeMount struct {
@@ -111,6 +152,43 @@ type HbaseClusterInitContainer struct {
 	SecurityContext HbaseClusterSecurity      `json:"securityContext"`
 	//+optional
 	IsBootstrap bool `json:"isBootstrap"`
+	// Phase of the bootstrap the init container performs, which makes it a bootstrap init container. It is rendered
+	// until the phase is completed in one of the pods of the deployment, and never afterwards
+	// +optional
+	BootstrapPhase HbaseBootstrapPhase `json:"bootstrapPhase,omitempty"`
+}
+
+// HbaseBootstrapPhase is a phase of the bootstrap of a cluster
+// +kubebuilder:validation:Enum=ZKFormatted;JNReady;NNFormatted;StandbyBootstrapped;HBaseRootCreated;Ready
+type HbaseBootstrapPhase string
+
+const (
+	// BootstrapZKFormatted the HA state of the namenodes is initialized in zookeeper, by hdfs zkfc -formatZK
+	BootstrapZKFormatted HbaseBootstrapPhase = "ZKFormatted"
+	// BootstrapJNReady all journalnodes are ready
+	BootstrapJNReady HbaseBootstrapPhase = "JNReady"
+	// BootstrapNNFormatted the first namenode is formatted, by hdfs namenode -format
+	BootstrapNNFormatted HbaseBootstrapPhase = "NNFormatted"
+	// BootstrapStandbyBootstrapped the standby namenodes copied the namespace, by hdfs namenode -bootstrapStandby
+	BootstrapStandbyBootstrapped HbaseBootstrapPhase = "StandbyBootstrapped"
+	// BootstrapHBaseRootCreated the root directory of hbase is created in hdfs
+	BootstrapHBaseRootCreated HbaseBootstrapPhase = "HBaseRootCreated"
+	// BootstrapReady all phases are completed and the StatefulSets are ready
+	BootstrapReady HbaseBootstrapPhase = "Ready"
+)
+
+// HbaseClusterBootstrapStatus is the progress of the bootstrap of the cluster
+type HbaseClusterBootstrapStatus struct {
+	// Last phase such that it and all the phases before it are completed or skipped, Ready once the cluster is
+	// bootstrapped
+	// +optional
+	Phase HbaseBootstrapPhase `json:"phase,omitempty"`
+	// Phases completed so far
+	// +optional
+	CompletedPhases []HbaseBootstrapPhase `json:"completedPhases,omitempty"`
+	// Phases no init container performs, nor journalnodes for JNReady. They are skipped as soon as the bootstrap starts
+	// +optional
+	SkippedPhases []HbaseBootstrapPhase `json:"skippedPhases,omitempty"`
 }
 
 type HbaseClusterVolumeClaim struct {
@@ -173,6 +251,9 @@ type HbaseClusterDeployment struct {
 	ShareProcessNamespace bool `json:"shareProcessNamespace"`
 	// +kubebuilder:default:=false
 	IsPodServiceRequired bool `json:"isPodServiceRequired"`
+	// Customizes the per pod Services, when isPodServiceRequired is set
+	// +optional
+	Service *HbaseClusterServiceSpec `json:"service,omitempty"`
 	// +optional
 	// +kubebuilder:default:=Parallel
 	// +kubebuilder:validation:Enum:=Parallel;OrderedReady;
@@ -193,8 +274,36 @@ type HbaseClusterDeployment struct {
 	PodDisruptionBudget *HBasePodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 	// +optional
 	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+	// Configuration overlaid on the shared configuration for this component only
+	// +optional
+	Configuration *HbaseClusterConfigOverlay `json:"configuration,omitempty"`
+}
+
+// HbaseClusterConfigOverlay is merged with the shared configuration into ConfigMaps of a single component. Properties
+// of xml, properties and shell files are merged key by key, any other file replaces the shared one
+type HbaseClusterConfigOverlay struct {
+	// +optional
+	HbaseConfig map[string]string `json:"hbaseConfig,omitempty"`
+	// +optional
+	HadoopConfig map[string]string `json:"hadoopConfig,omitempty"`
 }
 
+// ConfigUpdatePolicy controls how changes to the configuration are rolled out
+// +kubebuilder:validation:Enum=Ignore;ConfigOnly;RollingRestart;HotReload
+type ConfigUpdatePolicy string
+
+const (
+	// ConfigUpdatePolicyIgnore leaves the ConfigMaps and pods untouched
+	ConfigUpdatePolicyIgnore ConfigUpdatePolicy = "Ignore"
+	// ConfigUpdatePolicyConfigOnly updates the ConfigMaps, changes are picked up on next pod restart
+	ConfigUpdatePolicyConfigOnly ConfigUpdatePolicy = "ConfigOnly"
+	// ConfigUpdatePolicyRollingRestart updates the ConfigMaps and rolls the StatefulSets on a hbase config change
+	ConfigUpdatePolicyRollingRestart ConfigUpdatePolicy = "RollingRestart"
+	// ConfigUpdatePolicyHotReload is same as RollingRestart, except changes which hbase can reload online are
+	// applied through the admin endpoint without restarting pods
+	ConfigUpdatePolicyHotReload ConfigUpdatePolicy = "HotReload"
+)
+
 type HbaseClusterConfiguration struct {
 	HbaseConfigName       string            `json:"hbaseConfigName"`
 	HbaseConfigMountPath  string            `json:"hbaseConfigMountPath"`
@@ -202,10 +311,78 @@ type HbaseClusterConfiguration struct {
 	HadoopConfigName      string            `json:"hadoopConfigName"`
 	HadoopConfigMountPath string            `json:"hadoopConfigMountPath"`
 	HadoopConfig          map[string]string `json:"hadoopConfig"`
+	// Overrides of hbaseConfig files in tenant namespaces
+	// +optional
+	HbaseTenantConfig []HbaseTenantConfigOverride `json:"hbaseTenantConfig"`
+	// Overrides of hadoopConfig files in tenant namespaces
+	// +optional
+	HadoopTenantConfig []HbaseTenantConfigOverride `json:"hadoopTenantConfig"`
+	// Base URL of the HBase admin endpoint used for online operations such as reloading configuration.
+	// Without it, HotReload update policy falls back to RollingRestart
+	// +optional
+	AdminEndpoint string `json:"adminEndpoint,omitempty"`
+	// Base URL of the HBase REST gateway used to manage HbaseNamespaces and HbaseTables
+	// +optional
+	RestEndpoint string `json:"restEndpoint,omitempty"`
+	// How configuration changes are rolled out. CRs using the deprecated hbase-operator.cfg-statefulset-update/enable
+	// service label are migrated to the equivalent policy once, and the label removed
+	// +optional
+	UpdatePolicy ConfigUpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+// HbaseTenantConfigOverride replaces files of the shared configuration in the ConfigMaps of the selected namespaces.
+// Exactly one of namespace and namespaceSelector must be set. When several overrides select a namespace, later ones win.
+// Entries of the legacy shape, with the files next to namespace, are kept as stored and read as files
+// +kubebuilder:pruning:PreserveUnknownFields
+type HbaseTenantConfigOverride struct {
+	// Namespace the override applies to
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Selects the namespaces the override applies to by their labels. Only tenant namespaces are selected
 	// +optional
-	HbaseTenantConfig []map[string]string `json:"hbaseTenantConfig"`
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Files replacing the ones of the shared configuration
 	// +optional
-	HadoopTenantConfig []map[string]string `json:"hadoopTenantConfig"`
+	Files map[string]string `json:"files,omitempty"`
+}
+
+// UnmarshalJSON reads the override along with the files of the legacy shape, a flat map of namespace and files. Files
+// set under files win over legacy ones of the same name
+func (o *HbaseTenantConfigOverride) UnmarshalJSON(data []byte) error {
+	type override HbaseTenantConfigOverride
+	out := override{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	for name, raw := range fields {
+		if name == "namespace" || name == "namespaceSelector" || name == "files" {
+			continue
+		}
+		content := ""
+		if err := json.Unmarshal(raw, &content); err != nil {
+			return fmt.Errorf("legacy tenant config file %s is not a string: %w", name, err)
+		}
+		if out.Files == nil {
+			out.Files = map[string]string{}
+		}
+		if _, ok := out.Files[name]; !ok {
+			out.Files[name] = content
+		}
+	}
+	*o = HbaseTenantConfigOverride(out)
+	return nil
+}
+
+// TenantConfigOverrideStatus lists the files of a ConfigMap replaced by tenant config overrides
+type TenantConfigOverrideStatus struct {
+	// ConfigMap the overrides were applied to, as namespace/name
+	ConfigMap string `json:"configMap"`
+	// Files replaced by overrides
+	Files []string `json:"files"`
 }
 
 type HbaseClusterSecurity struct {
@@ -215,13 +392,293 @@ type HbaseClusterSecurity struct {
 	AddSysPtrace bool `json:"addSysPtrace"`
 }
 
+// HbaseMonitoringMode is how the JMX exporter runs alongside the daemons
+// +kubebuilder:validation:Enum=Agent;Sidecar
+type HbaseMonitoringMode string
+
+const (
+	// HbaseMonitoringAgent loads the exporter as a java agent of the daemon
+	HbaseMonitoringAgent HbaseMonitoringMode = "Agent"
+	// HbaseMonitoringSidecar runs the standalone exporter in a sidecar, which scrapes the remote JMX port of the daemon
+	HbaseMonitoringSidecar HbaseMonitoringMode = "Sidecar"
+)
+
+// HbaseMonitorKind is the prometheus-operator resource scraping the exporters
+// +kubebuilder:validation:Enum=ServiceMonitor;PodMonitor
+type HbaseMonitorKind string
+
+const (
+	HbaseServiceMonitor HbaseMonitorKind = "ServiceMonitor"
+	HbasePodMonitor     HbaseMonitorKind = "PodMonitor"
+)
+
+// HbaseClusterMonitoring exposes metrics of the daemons through a JMX exporter. A ServiceMonitor or PodMonitor is
+// created when prometheus-operator is installed, pods are annotated for scraping otherwise
+type HbaseClusterMonitoring struct {
+	// +kubebuilder:default:=Agent
+	// +optional
+	Mode HbaseMonitoringMode `json:"mode,omitempty"`
+	// Image of the exporter. In Agent mode the agent jar is copied from it by an init container, and is expected in
+	// the base image when not set
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Path of the agent jar in the image
+	// +kubebuilder:default:="/opt/jmx_exporter/jmx_prometheus_javaagent.jar"
+	// +optional
+	AgentPath string `json:"agentPath,omitempty"`
+	// Port metrics are served on
+	// +kubebuilder:default:=7071
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// Config of the exporter, in jmx_exporter format, for components without rules of their own. All MBeans are
+	// exported when not set
+	// +optional
+	Rules string `json:"rules,omitempty"`
+	// Exporter settings of the components, keyed by the name of their deployment
+	// +optional
+	Components map[string]HbaseComponentMonitoring `json:"components,omitempty"`
+	// Resources of the exporter sidecar or of the init container copying the agent
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// +kubebuilder:default:=ServiceMonitor
+	// +optional
+	MonitorKind HbaseMonitorKind `json:"monitorKind,omitempty"`
+	// Scrape interval of the ServiceMonitor or PodMonitor, the one of Prometheus when not set
+	// +kubebuilder:validation:Pattern:=`^([0-9]+(ms|s|m|h))+$`
+	// +optional
+	Interval string `json:"interval,omitempty"`
+	// Labels of the ServiceMonitor or PodMonitor, as selected by Prometheus
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// HbaseComponentMonitoring overrides the exporter settings of a component
+type HbaseComponentMonitoring struct {
+	// Config of the exporter of the component, in jmx_exporter format
+	// +optional
+	Rules string `json:"rules,omitempty"`
+	// Container the agent is loaded in, the first container of the component when not set
+	// +optional
+	Container string `json:"container,omitempty"`
+	// Environment variable the agent is set in, which the start scripts of the daemon pass to the JVM. Defaults to
+	// the variable of the daemon found in the name of the container, such as HBASE_REGIONSERVER_OPTS for regionserver
+	// or HDFS_NAMENODE_OPTS for namenode
+	// +optional
+	JavaOptsEnv string `json:"javaOptsEnv,omitempty"`
+	// Disables the exporter of the component
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// HbaseExternalZookeeper is a ZooKeeper ensemble managed outside of the cluster, used instead of the zookeeper deployment
+type HbaseExternalZookeeper struct {
+	// Comma separated host:port of the ZooKeeper servers, port defaults to 2181
+	// +kubebuilder:validation:MinLength=1
+	Quorum string `json:"quorum"`
+	// Root znode of the cluster in the ensemble, set as zookeeper.znode.parent. Clusters sharing an ensemble need
+	// distinct roots
+	// +kubebuilder:validation:Pattern:=`^/`
+	// +optional
+	Chroot string `json:"chroot,omitempty"`
+}
+
+// HbaseExternalHDFS is an HDFS managed outside of the cluster, used instead of the journalnode and namenode deployments.
+// The datanode deployment is still used to run regionservers
+type HbaseExternalHDFS struct {
+	// Nameservice of the HDFS. Without a config source defining it, it is taken as the host[:port] of the namenode
+	// +kubebuilder:validation:MinLength=1
+	Nameservice string `json:"nameservice"`
+	// Directory of hbase in the HDFS, set as hbase.rootdir
+	// +kubebuilder:default:="/hbase"
+	// +optional
+	RootDir string `json:"rootDir,omitempty"`
+	// ConfigMap in the namespace of the cluster holding the core-site.xml and hdfs-site.xml of the HDFS, which are
+	// merged into hadoopConfig
+	// +optional
+	ConfigSource *corev1.LocalObjectReference `json:"configSource,omitempty"`
+}
+
+// HbaseClusterZookeeper has the operator manage the zookeeper deployment as an ensemble. The server list is generated
+// from the replicas and their DNS names, and resizes are applied one member at a time through dynamic reconfig
+type HbaseClusterZookeeper struct {
+	// +kubebuilder:default:=2181
+	// +optional
+	ClientPort int32 `json:"clientPort,omitempty"`
+	// Port followers connect to the leader on
+	// +kubebuilder:default:=2888
+	// +optional
+	PeerPort int32 `json:"peerPort,omitempty"`
+	// Port of the leader election
+	// +kubebuilder:default:=3888
+	// +optional
+	LeaderPort int32 `json:"leaderPort,omitempty"`
+	// DNS domain of the kubernetes cluster, used to build the names of the servers
+	// +kubebuilder:default:="cluster.local"
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+}
+
+// HbaseClusterNamenodeHA has the operator roll out the namenode deployment according to the HA state of the namenodes.
+// Namenode ids and their addresses are read from hdfs-site.xml
+type HbaseClusterNamenodeHA struct {
+	// Nameservice of the namenodes, the first one of dfs.nameservices when not set
+	// +optional
+	Nameservice string `json:"nameservice,omitempty"`
+	// Container haadmin is run in, the first container of the namenode deployment when not set
+	// +optional
+	Container string `json:"container,omitempty"`
+	// Scheme of dfs.namenode.http-address, through which the NameNodeStatus bean is read
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default:=http
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// HbaseClusterHmasterHA has the operator roll out the hmaster deployment backup masters first, and the active master
+// last, once a backup master is ready to take over
+type HbaseClusterHmasterHA struct {
+	// Port of the info server of the masters, hbase.master.info.port, through which their jmx servlet is read
+	// +kubebuilder:default:=16010
+	// +optional
+	InfoPort int32 `json:"infoPort,omitempty"`
+	// Scheme of the info server of the masters
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default:=http
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// HbaseClusterRackAwareness maps pods to racks after a label of the nodes they run on. The mapping is published in a
+// ConfigMap along with a topology script, mounted into namenode and hmaster pods, and set as net.topology.script.file.name
+type HbaseClusterRackAwareness struct {
+	// Label of the nodes with the rack of the pods running on them
+	// +kubebuilder:default:=topology.kubernetes.io/zone
+	// +optional
+	TopologyKey string `json:"topologyKey,omitempty"`
+	// Rack of the hosts not mapped, such as pods on nodes without the topology label
+	// +kubebuilder:default:=/default-rack
+	// +optional
+	DefaultRack string `json:"defaultRack,omitempty"`
+	// Directory the topology script and mapping are mounted in
+	// +kubebuilder:default:=/etc/hbase-rack-topology
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// HbaseClusterExternalAccess exposes each regionserver outside of the kubernetes cluster through a Service of its own.
+// The external address of each regionserver is rendered as an hbase-site.xml override of hbase.regionserver.hostname,
+// in a ConfigMap mounted into datanode pods, at $HBASE_EXTERNAL_ACCESS_SITE in their containers
+type HbaseClusterExternalAccess struct {
+	// Type of the per pod Services
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort
+	// +kubebuilder:default:=LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+	// Annotations of the per pod Services, such as the ones configuring cloud load balancers
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Port of the regionservers, hbase.regionserver.port, exposed by LoadBalancer Services
+	// +kubebuilder:default:=16020
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// First node port of NodePort Services, required with NodePort. The regionserver of ordinal i listens on and is
+	// exposed at nodePortBase + i, rendered as its hbase.regionserver.port override
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=65535
+	// +optional
+	NodePortBase int32 `json:"nodePortBase,omitempty"`
+	// Directory the overrides are mounted in
+	// +kubebuilder:default:=/etc/hbase-external-access
+	// +optional
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// HbaseClusterNetworkPolicy has the operator generate a NetworkPolicy per component, only allowing the flows between
+// components, and from clients to zookeeper, namenodes, datanodes and masters, on the ports of their containers. Other
+// ingress traffic to the pods of the cluster is denied
+type HbaseClusterNetworkPolicy struct {
+	// Namespaces of the clients, besides tenantNamespaces and the namespaces of the HbaseTenants referring to the
+	// cluster. The namespace of the operator has to be selected, as it queries zookeeper, namenodes and masters
+	// +optional
+	ClientNamespaceSelector *metav1.LabelSelector `json:"clientNamespaceSelector,omitempty"`
+	// Pods of the clients in the namespace of the cluster
+	// +optional
+	ClientPodSelector *metav1.LabelSelector `json:"clientPodSelector,omitempty"`
+	// Namespaces allowed to scrape the exporters, when monitoring is set. All namespaces when not set
+	// +optional
+	MetricsNamespaceSelector *metav1.LabelSelector `json:"metricsNamespaceSelector,omitempty"`
+}
+
+// ExternalEndpointStatus is the address a regionserver is reachable at from outside of the kubernetes cluster
+type ExternalEndpointStatus struct {
+	// Pod of the regionserver
+	Pod string `json:"pod"`
+	// Service exposing the pod
+	Service string `json:"service"`
+	// host:port advertised to clients, empty until the load balancer or node address is known
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// NamenodeStatus is the HA state of a namenode
+type NamenodeStatus struct {
+	// Pod of the namenode
+	Pod string `json:"pod"`
+	// Namenode id in the nameservice
+	ID string `json:"id"`
+	// active, standby or observer, unknown when the namenode could not be queried
+	State string `json:"state"`
+}
+
+// ZookeeperEnsembleStatus is the membership and health of the zookeeper ensemble managed by the operator
+type ZookeeperEnsembleStatus struct {
+	// Number of servers in the configuration of the ensemble, the first ones of the zookeeper StatefulSet
+	Members int32 `json:"members"`
+	// Pod of the leader
+	// +optional
+	Leader string `json:"leader,omitempty"`
+	// Pods of the followers
+	// +optional
+	Followers []string `json:"followers,omitempty"`
+	// Pods of the members not answering the stat command
+	// +optional
+	Unreachable []string `json:"unreachable,omitempty"`
+	// True while a majority of the members are following the leader
+	QuorumHealthy bool `json:"quorumHealthy"`
+}
+
 type HbaseClusterDeployments struct {
 	//+optional
-	Zookeeper   HbaseClusterDeployment `json:"zookeeper"`
+	Zookeeper HbaseClusterDeployment `json:"zookeeper"`
+	// Not required with externalHDFS
+	//+optional
 	Journalnode HbaseClusterDeployment `json:"journalnode"`
-	Namenode    HbaseClusterDeployment `json:"namenode"`
-	Datanode    HbaseClusterDeployment `json:"datanode"`
-	Hmaster     HbaseClusterDeployment `json:"hmaster"`
+	// Not required with externalHDFS
+	//+optional
+	Namenode HbaseClusterDeployment `json:"namenode"`
+	Datanode HbaseClusterDeployment `json:"datanode"`
+	Hmaster  HbaseClusterDeployment `json:"hmaster"`
+}
+
+// ConfigChangeStatus summarises the last configuration change detected by the operator
+type ConfigChangeStatus struct {
+	// Time at which the change was detected
+	ObservedTime metav1.Time `json:"observedTime"`
+	// True if the change was only reported and not applied
+	// +optional
+	DryRun bool `json:"dryRun,omitempty"`
+	// Number of properties or files added
+	Added int32 `json:"added"`
+	// Number of properties or files modified
+	Modified int32 `json:"modified"`
+	// Number of properties or files removed
+	Removed int32 `json:"removed"`
+	// ConfigMap files touched by the change, as namespace/configmap/file
+	// +optional
+	Files []string `json:"files,omitempty"`
+	// Steps the operator takes (or would take in dry run) to roll the change out
+	// +optional
+	RolloutPlan []string `json:"rolloutPlan,omitempty"`
 }
 
 // HbaseClusterSpec defines the desired state of HbaseCluster
@@ -229,14 +686,47 @@ type HbaseClusterSpec struct {
 	Deployments   HbaseClusterDeployments   `json:"deployments"`
 	Configuration HbaseClusterConfiguration `json:"configuration"`
 	FSGroup       int64                     `json:"fsgroup"`
-	IsBootstrap   bool                      `json:"isBootstrap"`
-	BaseImage     string                    `json:"baseImage"`
+	// Bootstraps a new cluster, running bootstrap init containers until status.bootstrap.phase is Ready. It has no
+	// effect afterwards
+	IsBootstrap bool   `json:"isBootstrap"`
+	BaseImage   string `json:"baseImage"`
+	// Namespaces ConfigMaps are rendered in, besides the namespaces of the HbaseTenants referring to the cluster.
+	// Deprecated: set clusterRef on the HbaseTenants instead
 	// +optional
 	TenantNamespaces []string `json:"tenantNamespaces"`
 	// +optional
 	ServiceLabels map[string]string `json:"serviceLabels"`
 	// +optional
 	ServiceSelectorLabels map[string]string `json:"serviceSelectorLabels"`
+	// Customizes the headless Service of the cluster
+	// +optional
+	Service *HbaseClusterServiceSpec `json:"service,omitempty"`
+	// +optional
+	Monitoring *HbaseClusterMonitoring `json:"monitoring,omitempty"`
+	// ZooKeeper ensemble used instead of the zookeeper deployment
+	// +optional
+	ExternalZookeeper *HbaseExternalZookeeper `json:"externalZookeeper,omitempty"`
+	// HDFS used instead of the journalnode and namenode deployments
+	// +optional
+	ExternalHDFS *HbaseExternalHDFS `json:"externalHDFS,omitempty"`
+	// Manages the zookeeper deployment as an ensemble, instead of leaving its configuration to the start scripts
+	// +optional
+	ZookeeperEnsemble *HbaseClusterZookeeper `json:"zookeeperEnsemble,omitempty"`
+	// Rolls out namenodes standby first, failing over before restarting the active one
+	// +optional
+	NamenodeHA *HbaseClusterNamenodeHA `json:"namenodeHA,omitempty"`
+	// Rolls out backup masters first, and the active master once a backup master can take over
+	// +optional
+	HmasterHA *HbaseClusterHmasterHA `json:"hmasterHA,omitempty"`
+	// Maps datanodes and regionservers to racks after the topology label of their nodes
+	// +optional
+	RackAwareness *HbaseClusterRackAwareness `json:"rackAwareness,omitempty"`
+	// Exposes regionservers to clients outside of the kubernetes cluster through per pod LoadBalancer or NodePort Services
+	// +optional
+	ExternalAccess *HbaseClusterExternalAccess `json:"externalAccess,omitempty"`
+	// Restricts ingress traffic to the pods of the cluster to the flows between its components and from its clients
+	// +optional
+	NetworkPolicy *HbaseClusterNetworkPolicy `json:"networkPolicy,omitempty"`
 }
 
 // HbaseClusterStatus defines the observed state of HbaseCluster
@@ -244,8 +734,42 @@ type HbaseClusterStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	//TODO
-	Nodes      []string           `json:"nodes"`
-	Conditions []metav1.Condition `json:"conditions"`
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	LastConfigChange *ConfigChangeStatus `json:"lastConfigChange,omitempty"`
+	// Tenant config overrides applied to the ConfigMaps
+	// +optional
+	TenantConfigOverrides []TenantConfigOverrideStatus `json:"tenantConfigOverrides,omitempty"`
+	// HbaseTenants referring to the cluster, as namespace/name
+	// +optional
+	Tenants []string `json:"tenants,omitempty"`
+	// Membership and health of the zookeeper ensemble, when managed by the operator
+	// +optional
+	Zookeeper *ZookeeperEnsembleStatus `json:"zookeeper,omitempty"`
+	// Pod of the active namenode, when namenodeHA is set
+	// +optional
+	ActiveNamenode string `json:"activeNamenode,omitempty"`
+	// HA state of the namenodes, when namenodeHA is set
+	// +optional
+	Namenodes []NamenodeStatus `json:"namenodes,omitempty"`
+	// Pod of the active master, when hmasterHA is set
+	// +optional
+	ActiveHmaster string `json:"activeHmaster,omitempty"`
+	// Pods of the backup masters, when hmasterHA is set
+	// +optional
+	BackupHmasters []string `json:"backupHmasters,omitempty"`
+	// Progress of the bootstrap, when isBootstrap is set
+	// +optional
+	Bootstrap *HbaseClusterBootstrapStatus `json:"bootstrap,omitempty"`
+	// Endpoints of the regionservers outside of the kubernetes cluster, when externalAccess is set
+	// +optional
+	ExternalEndpoints []ExternalEndpointStatus `json:"externalEndpoints,omitempty"`
+	// NetworkPolicies generated for the components, when networkPolicy is set
+	// +optional
+	NetworkPolicies []string `json:"networkPolicies,omitempty"`
 }
 
 //+kubebuilder:object:root=true
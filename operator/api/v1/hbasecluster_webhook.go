@@ -0,0 +1,270 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseCluster
+func (r *HbaseCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseClusterValidator{client: mgr.GetClient()}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasecluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=create;update,versions=v1,name=vhbasecluster.kb.io,admissionReviewVersions=v1
+
+type hbaseClusterValidator struct {
+	// used to look up HbaseTenants referring to the cluster, when set
+	client client.Reader
+}
+
+func (v *hbaseClusterValidator) ValidateCreate(ctx context.Context, r *HbaseCluster) (admission.Warnings, error) {
+	return v.validate(ctx, r)
+}
+
+func (v *hbaseClusterValidator) ValidateUpdate(ctx context.Context, old *HbaseCluster, r *HbaseCluster) (admission.Warnings, error) {
+	return v.validate(ctx, r)
+}
+
+func (v *hbaseClusterValidator) ValidateDelete(ctx context.Context, r *HbaseCluster) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *hbaseClusterValidator) validate(ctx context.Context, r *HbaseCluster) (admission.Warnings, error) {
+	// ConfigMaps are rendered in the tenant namespaces, the namespaces of the tenants referring to the cluster
+	// and the namespace of the cluster
+	namespaces := append(append([]string{}, r.Spec.TenantNamespaces...), r.Namespace)
+	if v.client != nil {
+		tenants := &HbaseTenantList{}
+		if err := v.client.List(ctx, tenants); err != nil {
+			return nil, apierrors.NewInternalError(err)
+		}
+		for _, t := range tenants.Items {
+			if t.Spec.ClusterRef != nil && t.Spec.ClusterRef.Name == r.Name &&
+				(t.Spec.ClusterRef.Namespace == r.Namespace || len(t.Spec.ClusterRef.Namespace) == 0 && t.Namespace == r.Namespace) {
+				namespaces = append(namespaces, t.Namespace)
+			}
+		}
+	}
+
+	errs := ValidateTenantConfig(field.NewPath("spec", "configuration"), r.Spec.Configuration, namespaces)
+	d := r.Spec.Deployments
+	errs = append(errs, validateMonitoring(field.NewPath("spec", "monitoring"), r.Spec.Monitoring,
+		[]string{d.Zookeeper.Name, d.Journalnode.Name, d.Namenode.Name, d.Datanode.Name, d.Hmaster.Name})...)
+	errs = append(errs, validateExternalServices(field.NewPath("spec"), r.Spec)...)
+	errs = append(errs, validateBootstrapPhases(field.NewPath("spec", "deployments"), d)...)
+	errs = append(errs, validateService(field.NewPath("spec", "service"), r.Spec.Service, true)...)
+	deployments := deploymentsByName(d)
+	for _, name := range deploymentNames {
+		errs = append(errs, validateService(field.NewPath("spec", "deployments", name, "service"), deployments[name].Service, false)...)
+	}
+	errs = append(errs, validateNetworkPolicy(field.NewPath("spec", "networkPolicy"), r.Spec.NetworkPolicy)...)
+	errs = append(errs, validateExternalAccess(field.NewPath("spec", "externalAccess"), r.Spec.ExternalAccess, d.Datanode.Size)...)
+	warnings := admission.Warnings{}
+	if r.Spec.ZookeeperEnsemble != nil {
+		if r.Spec.ExternalZookeeper != nil {
+			errs = append(errs, field.Forbidden(field.NewPath("spec", "zookeeperEnsemble"), "can not be set along with externalZookeeper"))
+		} else if d.Zookeeper.Size%2 == 0 {
+			warnings = append(warnings, "spec.deployments.zookeeper.size is even, an ensemble tolerates as many failures with one server less")
+		}
+	}
+	if len(r.Spec.TenantNamespaces) > 0 {
+		warnings = append(warnings, "spec.tenantNamespaces is deprecated, set spec.clusterRef on the HbaseTenants instead")
+	}
+	if len(warnings) > 0 {
+		return warnings, toInvalid("HbaseCluster", r.Name, errs)
+	}
+	return nil, toInvalid("HbaseCluster", r.Name, errs)
+}
+
+func toInvalid(kind string, name string, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind(kind).GroupKind(), name, errs)
+}
+
+// ValidateTenantConfig checks the tenant config overrides only target the given namespaces. Used on admission by the
+// webhooks, and by the operator to report the overrides it ignores
+func ValidateTenantConfig(path *field.Path, c HbaseClusterConfiguration, namespaces []string) field.ErrorList {
+	errs := validateTenantConfigOverrides(path.Child("hbaseTenantConfig"), c.HbaseTenantConfig, namespaces)
+	return append(errs, validateTenantConfigOverrides(path.Child("hadoopTenantConfig"), c.HadoopTenantConfig, namespaces)...)
+}
+
+// validateMonitoring checks the exporter image is set for sidecars, and settings are only given for known components
+func validateMonitoring(path *field.Path, m *HbaseClusterMonitoring, components []string) field.ErrorList {
+	errs := field.ErrorList{}
+	if m == nil {
+		return errs
+	}
+	if m.Mode == HbaseMonitoringSidecar && len(m.Image) == 0 {
+		errs = append(errs, field.Required(path.Child("image"), "required in Sidecar mode"))
+	}
+	known := map[string]bool{}
+	for _, c := range components {
+		known[c] = len(c) > 0
+	}
+	for name := range m.Components {
+		if !known[name] {
+			errs = append(errs, field.NotFound(path.Child("components").Key(name), name))
+		}
+	}
+	return errs
+}
+
+// deploymentNames names of the deployments of a cluster, in the order they are validated
+var deploymentNames = []string{"zookeeper", "journalnode", "namenode", "datanode", "hmaster"}
+
+// deploymentsByName returns the deployments of a cluster by their name in deploymentNames
+func deploymentsByName(d HbaseClusterDeployments) map[string]HbaseClusterDeployment {
+	return map[string]HbaseClusterDeployment{"zookeeper": d.Zookeeper, "journalnode": d.Journalnode,
+		"namenode": d.Namenode, "datanode": d.Datanode, "hmaster": d.Hmaster}
+}
+
+// validateService checks headless Services are not given another type, and the external traffic policy is only set on
+// Services exposed on nodes
+func validateService(path *field.Path, s *HbaseClusterServiceSpec, headless bool) field.ErrorList {
+	errs := field.ErrorList{}
+	if s == nil {
+		return errs
+	}
+	if headless && len(s.Type) > 0 && s.Type != corev1.ServiceTypeClusterIP {
+		errs = append(errs, field.Forbidden(path.Child("type"), "the headless Service gives pods their DNS names, it can only be ClusterIP"))
+	}
+	exposed := s.Type == corev1.ServiceTypeNodePort || s.Type == corev1.ServiceTypeLoadBalancer
+	if len(s.ExternalTrafficPolicy) > 0 && (headless || !exposed) {
+		errs = append(errs, field.Forbidden(path.Child("externalTrafficPolicy"), "only applies to NodePort and LoadBalancer Services"))
+	}
+	return errs
+}
+
+// validateBootstrapPhases checks init containers only perform the phases of the bootstrap run in pods
+func validateBootstrapPhases(path *field.Path, d HbaseClusterDeployments) field.ErrorList {
+	errs := field.ErrorList{}
+	allowed := []string{string(BootstrapZKFormatted), string(BootstrapNNFormatted), string(BootstrapStandbyBootstrapped), string(BootstrapHBaseRootCreated)}
+	deployments := deploymentsByName(d)
+	for _, name := range deploymentNames {
+		for i, ic := range deployments[name].InitContainers {
+			if ic.BootstrapPhase == BootstrapJNReady || ic.BootstrapPhase == BootstrapReady {
+				errs = append(errs, field.NotSupported(path.Child(name, "initContainers").Index(i).Child("bootstrapPhase"), ic.BootstrapPhase, allowed))
+			}
+		}
+	}
+	return errs
+}
+
+// validateNetworkPolicy checks the selectors of the clients and of the scrapers are valid
+func validateNetworkPolicy(path *field.Path, p *HbaseClusterNetworkPolicy) field.ErrorList {
+	errs := field.ErrorList{}
+	if p == nil {
+		return errs
+	}
+	selectors := map[string]*metav1.LabelSelector{"clientNamespaceSelector": p.ClientNamespaceSelector,
+		"clientPodSelector": p.ClientPodSelector, "metricsNamespaceSelector": p.MetricsNamespaceSelector}
+	for _, name := range []string{"clientNamespaceSelector", "clientPodSelector", "metricsNamespaceSelector"} {
+		if _, err := metav1.LabelSelectorAsSelector(selectors[name]); err != nil {
+			errs = append(errs, field.Invalid(path.Child(name), selectors[name], err.Error()))
+		}
+	}
+	return errs
+}
+
+// validateExternalAccess checks NodePort Services are given a node port per regionserver
+func validateExternalAccess(path *field.Path, e *HbaseClusterExternalAccess, regionservers int32) field.ErrorList {
+	errs := field.ErrorList{}
+	if e == nil || e.Type != corev1.ServiceTypeNodePort {
+		return errs
+	}
+	if e.NodePortBase == 0 {
+		errs = append(errs, field.Required(path.Child("nodePortBase"), "required with NodePort"))
+	} else if e.NodePortBase+regionservers-1 > 65535 {
+		errs = append(errs, field.Invalid(path.Child("nodePortBase"), e.NodePortBase, "not enough ports left for all the regionservers"))
+	}
+	return errs
+}
+
+// validateExternalServices checks the external ZooKeeper and HDFS are not set along with the deployments they replace
+func validateExternalServices(path *field.Path, spec HbaseClusterSpec) field.ErrorList {
+	errs := field.ErrorList{}
+	d := spec.Deployments
+	if zk := spec.ExternalZookeeper; zk != nil {
+		if d.Zookeeper.Size > 0 {
+			errs = append(errs, field.Forbidden(path.Child("deployments", "zookeeper"), "can not be deployed along with externalZookeeper"))
+		}
+		for _, server := range strings.Split(zk.Quorum, ",") {
+			host, port, found := strings.Cut(strings.TrimSpace(server), ":")
+			if len(host) == 0 {
+				errs = append(errs, field.Invalid(path.Child("externalZookeeper", "quorum"), zk.Quorum, "servers must be given as host[:port]"))
+				break
+			}
+			if n, err := strconv.Atoi(port); found && (err != nil || n <= 0 || n > 65535) {
+				errs = append(errs, field.Invalid(path.Child("externalZookeeper", "quorum"), zk.Quorum, "invalid port of "+host))
+				break
+			}
+		}
+	}
+	if spec.ExternalHDFS != nil {
+		if d.Journalnode.Size > 0 {
+			errs = append(errs, field.Forbidden(path.Child("deployments", "journalnode"), "can not be deployed along with externalHDFS"))
+		}
+		if d.Namenode.Size > 0 {
+			errs = append(errs, field.Forbidden(path.Child("deployments", "namenode"), "can not be deployed along with externalHDFS"))
+		}
+		if spec.NamenodeHA != nil {
+			errs = append(errs, field.Forbidden(path.Child("namenodeHA"), "can not be set along with externalHDFS"))
+		}
+	}
+	return errs
+}
+
+func validateTenantConfigOverrides(path *field.Path, overrides []HbaseTenantConfigOverride, namespaces []string) field.ErrorList {
+	allowed := map[string]bool{}
+	for _, ns := range namespaces {
+		allowed[ns] = true
+	}
+
+	errs := field.ErrorList{}
+	for i, o := range overrides {
+		p := path.Index(i)
+		switch {
+		case len(o.Namespace) > 0 && o.NamespaceSelector != nil:
+			errs = append(errs, field.Invalid(p, o.Namespace, "only one of namespace and namespaceSelector can be set"))
+		case len(o.Namespace) == 0 && o.NamespaceSelector == nil:
+			errs = append(errs, field.Required(p.Child("namespace"), "one of namespace and namespaceSelector must be set"))
+		case len(o.Namespace) > 0 && !allowed[o.Namespace]:
+			errs = append(errs, field.NotSupported(p.Child("namespace"), o.Namespace, namespaces))
+		case o.NamespaceSelector != nil:
+			if _, err := metav1.LabelSelectorAsSelector(o.NamespaceSelector); err != nil {
+				errs = append(errs, field.Invalid(p.Child("namespaceSelector"), o.NamespaceSelector, err.Error()))
+			}
+		}
+		if len(o.Files) == 0 {
+			errs = append(errs, field.Required(p.Child("files"), "at least one file must be overridden"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,297 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestHbaseCluster(overrides ...HbaseTenantConfigOverride) *HbaseCluster {
+	return &HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "hbase-ns"},
+		Spec: HbaseClusterSpec{
+			TenantNamespaces: []string{"tenant-ns"},
+			Configuration:    HbaseClusterConfiguration{HbaseTenantConfig: overrides},
+		},
+	}
+}
+
+// TestHbaseClusterValidator_TenantConfig verifies tenant config overrides may only target the tenant namespaces or the cluster namespace.
+func TestHbaseClusterValidator_TenantConfig(t *testing.T) {
+	files := map[string]string{"hbase-env.sh": "export A=1"}
+	tests := []struct {
+		name     string
+		override HbaseTenantConfigOverride
+		valid    bool
+	}{
+		{"tenant namespace", HbaseTenantConfigOverride{Namespace: "tenant-ns", Files: files}, true},
+		{"cluster namespace", HbaseTenantConfigOverride{Namespace: "hbase-ns", Files: files}, true},
+		{"selector", HbaseTenantConfigOverride{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}}, Files: files}, true},
+		{"unknown namespace", HbaseTenantConfigOverride{Namespace: "tenant-nss", Files: files}, false},
+		{"no target", HbaseTenantConfigOverride{Files: files}, false},
+		{"both targets", HbaseTenantConfigOverride{Namespace: "tenant-ns", NamespaceSelector: &metav1.LabelSelector{}, Files: files}, false},
+		{"invalid selector", HbaseTenantConfigOverride{NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}}}, Files: files}, false},
+		{"no files", HbaseTenantConfigOverride{Namespace: "tenant-ns"}, false},
+	}
+
+	v := &hbaseClusterValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := v.ValidateCreate(context.TODO(), newTestHbaseCluster(tt.override))
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestHbaseTenantValidator_TenantConfig verifies tenant config overrides of an HbaseTenant may only target its own namespace.
+func TestHbaseTenantValidator_TenantConfig(t *testing.T) {
+	tenant := &HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "tenant-ns"}}
+	tenant.Spec.ClusterRef = &HbaseClusterReference{Name: "cluster", Namespace: "hbase-ns"}
+	tenant.Spec.Configuration.HadoopTenantConfig = []HbaseTenantConfigOverride{{Namespace: "tenant-ns", Files: map[string]string{"hadoop-env.sh": "x"}}}
+
+	v := &hbaseTenantValidator{}
+	_, err := v.ValidateUpdate(context.TODO(), tenant, tenant)
+	assert.NoError(t, err)
+
+	tenant.Spec.Configuration.HadoopTenantConfig[0].Namespace = "other-ns"
+	_, err = v.ValidateUpdate(context.TODO(), tenant, tenant)
+	assert.ErrorContains(t, err, "spec.configuration.hadoopTenantConfig[0].namespace")
+}
+
+// TestHbaseTenantValidator_ClusterRef verifies fields which can not be inherited without a cluster reference are required.
+func TestHbaseTenantValidator_ClusterRef(t *testing.T) {
+	tenant := &HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "tenant-ns"}}
+	v := &hbaseTenantValidator{}
+
+	_, err := v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.baseImage")
+	assert.ErrorContains(t, err, "spec.configuration.hbaseConfigName")
+
+	tenant.Spec.ClusterRef = &HbaseClusterReference{}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.clusterRef.name")
+
+	tenant.Spec.ClusterRef.Name = "cluster"
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.NoError(t, err)
+}
+
+// TestHbaseClusterValidator_ReferringTenants verifies overrides may target namespaces of the tenants referring to the cluster.
+func TestHbaseClusterValidator_ReferringTenants(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	tenants := []client.Object{
+		&HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "referring-ns"}, Spec: HbaseTenantSpec{ClusterRef: &HbaseClusterReference{Name: "cluster", Namespace: "hbase-ns"}}},
+		&HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "other-ns"}, Spec: HbaseTenantSpec{ClusterRef: &HbaseClusterReference{Name: "other-cluster", Namespace: "hbase-ns"}}},
+	}
+	v := &hbaseClusterValidator{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(tenants...).Build()}
+	files := map[string]string{"hbase-env.sh": "export A=1"}
+
+	warnings, err := v.ValidateCreate(context.TODO(), newTestHbaseCluster(HbaseTenantConfigOverride{Namespace: "referring-ns", Files: files}))
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 1)
+
+	_, err = v.ValidateCreate(context.TODO(), newTestHbaseCluster(HbaseTenantConfigOverride{Namespace: "other-ns", Files: files}))
+	assert.True(t, apierrors.IsInvalid(err))
+}
+
+// TestHbaseTenantValidator_RSGroup verifies hbase namespaces need an RSGroup, which needs an admin endpoint.
+func TestHbaseTenantValidator_RSGroup(t *testing.T) {
+	tenant := &HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "tenant-ns"}}
+	tenant.Spec.BaseImage = "hbase:2.4"
+	tenant.Spec.Configuration = HbaseClusterConfiguration{HbaseConfigName: "hbase-config", HadoopConfigName: "hadoop-config"}
+	tenant.Spec.HbaseNamespaces = []string{"team"}
+	v := &hbaseTenantValidator{}
+
+	_, err := v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.hbaseNamespaces")
+
+	tenant.Spec.RSGroup = &HbaseTenantRSGroup{}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.configuration.adminEndpoint")
+
+	tenant.Spec.ClusterRef = &HbaseClusterReference{Name: "cluster"}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.NoError(t, err)
+}
+
+// TestHbaseTenantValidator_Quotas verifies throttles need a target and quotas are not defined twice.
+func TestHbaseTenantValidator_Quotas(t *testing.T) {
+	tenant := &HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: "tenant-ns"}}
+	tenant.Spec.ClusterRef = &HbaseClusterReference{Name: "cluster"}
+	tenant.Spec.Quotas = &HbaseTenantQuotas{
+		Throttles: []HbaseThrottleQuota{{Namespace: "team", Type: "READ_NUMBER", Limit: "10req/sec"}, {Type: "READ_NUMBER", Limit: "10req/sec"}},
+		Space:     []HbaseSpaceQuota{{Namespace: "team", Limit: resource.MustParse("1Ti")}, {Namespace: "team", Limit: resource.MustParse("1Ti")}},
+	}
+	v := &hbaseTenantValidator{}
+
+	_, err := v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.quotas.throttles[1]: Required value")
+	assert.ErrorContains(t, err, "spec.quotas.space[1].namespace: Duplicate value")
+
+	tenant.Spec.Quotas.Throttles = tenant.Spec.Quotas.Throttles[:1]
+	tenant.Spec.Quotas.Space = tenant.Spec.Quotas.Space[:1]
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.NoError(t, err)
+}
+
+// TestHbaseClusterValidator_Monitoring verifies sidecars require an exporter image and settings only target known components.
+func TestHbaseClusterValidator_Monitoring(t *testing.T) {
+	tests := []struct {
+		name       string
+		monitoring *HbaseClusterMonitoring
+		valid      bool
+	}{
+		{"not set", nil, true},
+		{"agent in base image", &HbaseClusterMonitoring{Mode: HbaseMonitoringAgent}, true},
+		{"sidecar", &HbaseClusterMonitoring{Mode: HbaseMonitoringSidecar, Image: "jmx-exporter:1.0"}, true},
+		{"sidecar without image", &HbaseClusterMonitoring{Mode: HbaseMonitoringSidecar}, false},
+		{"known component", &HbaseClusterMonitoring{Components: map[string]HbaseComponentMonitoring{"hmaster": {Rules: "rules: []"}}}, true},
+		{"unknown component", &HbaseClusterMonitoring{Components: map[string]HbaseComponentMonitoring{"zookeeper": {Disabled: true}}}, false},
+	}
+
+	v := &hbaseClusterValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestHbaseCluster()
+			cluster.Spec.Deployments.Hmaster.Name = "hmaster"
+			cluster.Spec.Deployments.Datanode.Name = "datanode"
+			cluster.Spec.Monitoring = tt.monitoring
+			_, err := v.ValidateCreate(context.TODO(), cluster)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestHbaseClusterValidator_ExternalServices verifies external services can not be set along with the deployments they replace.
+func TestHbaseClusterValidator_ExternalServices(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *HbaseCluster)
+		valid  bool
+	}{
+		{"zookeeper quorum", func(c *HbaseCluster) {
+			c.Spec.ExternalZookeeper = &HbaseExternalZookeeper{Quorum: "zk-0:2181,zk-1"}
+		}, true},
+		{"invalid zookeeper port", func(c *HbaseCluster) {
+			c.Spec.ExternalZookeeper = &HbaseExternalZookeeper{Quorum: "zk-0:zk"}
+		}, false},
+		{"empty zookeeper server", func(c *HbaseCluster) {
+			c.Spec.ExternalZookeeper = &HbaseExternalZookeeper{Quorum: "zk-0,,zk-1"}
+		}, false},
+		{"zookeeper deployed", func(c *HbaseCluster) {
+			c.Spec.ExternalZookeeper = &HbaseExternalZookeeper{Quorum: "zk-0"}
+			c.Spec.Deployments.Zookeeper.Size = 3
+		}, false},
+		{"zookeeper ensemble", func(c *HbaseCluster) {
+			c.Spec.ZookeeperEnsemble = &HbaseClusterZookeeper{}
+			c.Spec.Deployments.Zookeeper.Size = 3
+		}, true},
+		{"zookeeper ensemble with external zookeeper", func(c *HbaseCluster) {
+			c.Spec.ExternalZookeeper = &HbaseExternalZookeeper{Quorum: "zk-0"}
+			c.Spec.ZookeeperEnsemble = &HbaseClusterZookeeper{}
+		}, false},
+		{"hdfs", func(c *HbaseCluster) {
+			c.Spec.ExternalHDFS = &HbaseExternalHDFS{Nameservice: "shared"}
+		}, true},
+		{"namenode deployed", func(c *HbaseCluster) {
+			c.Spec.ExternalHDFS = &HbaseExternalHDFS{Nameservice: "shared"}
+			c.Spec.Deployments.Namenode.Size = 2
+		}, false},
+		{"journalnode deployed", func(c *HbaseCluster) {
+			c.Spec.ExternalHDFS = &HbaseExternalHDFS{Nameservice: "shared"}
+			c.Spec.Deployments.Journalnode.Size = 3
+		}, false},
+		{"namenode ha", func(c *HbaseCluster) {
+			c.Spec.NamenodeHA = &HbaseClusterNamenodeHA{Nameservice: "hbase"}
+		}, true},
+		{"namenode ha with external hdfs", func(c *HbaseCluster) {
+			c.Spec.ExternalHDFS = &HbaseExternalHDFS{Nameservice: "shared"}
+			c.Spec.NamenodeHA = &HbaseClusterNamenodeHA{}
+		}, false},
+		{"bootstrap phase", func(c *HbaseCluster) {
+			c.Spec.Deployments.Namenode.InitContainers = []HbaseClusterInitContainer{{Name: "init-namenode", BootstrapPhase: BootstrapNNFormatted}}
+		}, true},
+		{"bootstrap phase not run in pods", func(c *HbaseCluster) {
+			c.Spec.Deployments.Namenode.InitContainers = []HbaseClusterInitContainer{{Name: "init-namenode", BootstrapPhase: BootstrapReady}}
+		}, false},
+		{"external access", func(c *HbaseCluster) {
+			c.Spec.ExternalAccess = &HbaseClusterExternalAccess{Type: "LoadBalancer"}
+		}, true},
+		{"external access node ports", func(c *HbaseCluster) {
+			c.Spec.ExternalAccess = &HbaseClusterExternalAccess{Type: "NodePort", NodePortBase: 30020}
+		}, true},
+		{"external access node ports without base", func(c *HbaseCluster) {
+			c.Spec.ExternalAccess = &HbaseClusterExternalAccess{Type: "NodePort"}
+		}, false},
+		{"pod service load balancer", func(c *HbaseCluster) {
+			c.Spec.Deployments.Datanode.Service = &HbaseClusterServiceSpec{Type: "LoadBalancer", ExternalTrafficPolicy: "Local"}
+		}, true},
+		{"pod service external traffic policy without load balancer", func(c *HbaseCluster) {
+			c.Spec.Deployments.Datanode.Service = &HbaseClusterServiceSpec{ExternalTrafficPolicy: "Local"}
+		}, false},
+		{"headless service load balancer", func(c *HbaseCluster) {
+			c.Spec.Service = &HbaseClusterServiceSpec{Type: "LoadBalancer"}
+		}, false},
+		{"headless service annotations", func(c *HbaseCluster) {
+			c.Spec.Service = &HbaseClusterServiceSpec{Annotations: map[string]string{"mesh.example.com/inject": "true"}}
+		}, true},
+		{"network policy", func(c *HbaseCluster) {
+			c.Spec.NetworkPolicy = &HbaseClusterNetworkPolicy{ClientPodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}}}
+		}, true},
+		{"network policy invalid selector", func(c *HbaseCluster) {
+			c.Spec.NetworkPolicy = &HbaseClusterNetworkPolicy{ClientNamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Unknown"}}}}
+		}, false},
+	}
+
+	v := &hbaseClusterValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := newTestHbaseCluster()
+			tt.mutate(cluster)
+			_, err := v.ValidateCreate(context.TODO(), cluster)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, apierrors.IsInvalid(err), "expected invalid error, got %v", err)
+			}
+		})
+	}
+}
+
+// TestHbaseTenantConfigOverride_Legacy verifies entries of the legacy shape are read as files, files set under files
+// winning, and pass validation.
+func TestHbaseTenantConfigOverride_Legacy(t *testing.T) {
+	overrides := []HbaseTenantConfigOverride{}
+	err := json.Unmarshal([]byte(`[
+		{"namespace": "tenant-ns", "hbase-site.xml": "<configuration/>", "hbase-env.sh": "export A=1"},
+		{"namespace": "tenant-ns", "hbase-env.sh": "export A=1", "files": {"hbase-env.sh": "export A=2"}}
+	]`), &overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, []HbaseTenantConfigOverride{
+		{Namespace: "tenant-ns", Files: map[string]string{"hbase-site.xml": "<configuration/>", "hbase-env.sh": "export A=1"}},
+		{Namespace: "tenant-ns", Files: map[string]string{"hbase-env.sh": "export A=2"}},
+	}, overrides)
+
+	_, err = (&hbaseClusterValidator{}).ValidateCreate(context.TODO(), newTestHbaseCluster(overrides...))
+	assert.NoError(t, err)
+
+	assert.Error(t, json.Unmarshal([]byte(`[{"namespace": "tenant-ns", "hbase-site.xml": 1}]`), &overrides))
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseNamespaceSpec defines the desired state of HbaseNamespace
+type HbaseNamespaceSpec struct {
+	// HbaseCluster or HbaseStandalone the namespace is created in, through its REST gateway
+	ClusterRef HbaseSchemaClusterReference `json:"clusterRef"`
+	// Name of the namespace in HBase. Defaults to the name of the resource
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace properties, e.g. hbase.namespace.quota.maxtables
+	// +optional
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// HbaseSchemaClusterReference refers to the HbaseCluster or HbaseStandalone schema objects are managed in
+type HbaseSchemaClusterReference struct {
+	// +kubebuilder:validation:Enum:=HbaseCluster;HbaseStandalone
+	// +kubebuilder:default:=HbaseCluster
+	// +optional
+	Kind string `json:"kind,omitempty"`
+	Name string `json:"name"`
+	// Defaults to the namespace of the referring object
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// HbaseNamespaceStatus defines the observed state of HbaseNamespace
+type HbaseNamespaceStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// HbaseNamespace is the Schema for the hbasenamespaces API
+type HbaseNamespace struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseNamespaceSpec   `json:"spec,omitempty"`
+	Status HbaseNamespaceStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseNamespaceList contains a list of HbaseNamespace
+type HbaseNamespaceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseNamespace `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseNamespace{}, &HbaseNamespaceList{})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseReplicationPeerSpec defines the cluster replicated from, the one replicated to, and what is replicated
+type HbaseReplicationPeerSpec struct {
+	// Id of the peer in the source cluster, the name of the object with - replaced by _ unless set
+	// +kubebuilder:validation:Pattern:=`^[A-Za-z0-9_]+$`
+	// +optional
+	PeerID string `json:"peerId,omitempty"`
+	// HbaseCluster or HbaseStandalone replicated from, whose admin endpoint manages the peer
+	SourceClusterRef HbaseSchemaClusterReference `json:"sourceClusterRef"`
+	// HbaseCluster or HbaseStandalone replicated to, whose cluster key is resolved from its hbase-site.xml
+	// +optional
+	TargetClusterRef *HbaseSchemaClusterReference `json:"targetClusterRef,omitempty"`
+	// Cluster key of a target not managed by the operator, as quorum:clientPort:znodeParent
+	// +optional
+	ClusterKey string `json:"clusterKey,omitempty"`
+	// Tables replicated, along with their column families. All the tables with replication scope set are replicated
+	// unless set
+	// +listType=map
+	// +listMapKey=table
+	// +optional
+	TableCFs []HbaseReplicationTableCFs `json:"tableCFs,omitempty"`
+	// Edits are shipped in the order they were written in. Changing it re-creates the peer
+	// +optional
+	Serial bool `json:"serial,omitempty"`
+	// Shipping of edits, which are queued while the peer is disabled
+	// +kubebuilder:default:=true
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// HbaseReplicationTableCFs is a table replicated to the peer
+type HbaseReplicationTableCFs struct {
+	// Table as namespace:name
+	Table string `json:"table"`
+	// Column families replicated, all of them unless set
+	// +optional
+	ColumnFamilies []string `json:"columnFamilies,omitempty"`
+}
+
+// HbaseReplicationPeerStatus defines the observed state of HbaseReplicationPeer
+type HbaseReplicationPeerStatus struct {
+	// Cluster key of the peer in the source cluster
+	// +optional
+	ClusterKey string `json:"clusterKey,omitempty"`
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Largest replication lag of the peer across the regionservers of the source cluster
+	// +optional
+	ReplicationLag *metav1.Duration `json:"replicationLag,omitempty"`
+	// WAL files waiting to be shipped across the regionservers of the source cluster
+	// +optional
+	SizeOfLogQueue int64 `json:"sizeOfLogQueue,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Cluster Key",type=string,JSONPath=`.status.clusterKey`
+//+kubebuilder:printcolumn:name="Enabled",type=boolean,JSONPath=`.status.enabled`
+//+kubebuilder:printcolumn:name="Lag",type=string,JSONPath=`.status.replicationLag`
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// HbaseReplicationPeer is the Schema for the hbasereplicationpeers API
+type HbaseReplicationPeer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseReplicationPeerSpec   `json:"spec,omitempty"`
+	Status HbaseReplicationPeerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseReplicationPeerList contains a list of HbaseReplicationPeer
+type HbaseReplicationPeerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseReplicationPeer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseReplicationPeer{}, &HbaseReplicationPeerList{})
+}
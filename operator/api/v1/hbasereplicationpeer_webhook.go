@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// clusterKeyPattern matches cluster keys, quorum:clientPort:znodeParent
+var clusterKeyPattern = regexp.MustCompile(`^[^:\s]+(,[^:\s]+)*:[0-9]+:/\S*$`)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseReplicationPeer
+func (r *HbaseReplicationPeer) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseReplicationPeerValidator{}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasereplicationpeer,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbasereplicationpeers,verbs=create;update,versions=v1,name=vhbasereplicationpeer.kb.io,admissionReviewVersions=v1
+
+type hbaseReplicationPeerValidator struct{}
+
+func (v *hbaseReplicationPeerValidator) ValidateCreate(ctx context.Context, r *HbaseReplicationPeer) (admission.Warnings, error) {
+	return nil, toInvalid("HbaseReplicationPeer", r.Name, r.validate())
+}
+
+func (v *hbaseReplicationPeerValidator) ValidateUpdate(ctx context.Context, old *HbaseReplicationPeer, r *HbaseReplicationPeer) (admission.Warnings, error) {
+	errs := r.validate()
+	if r.PeerID() != old.PeerID() {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "peerId"), "peer id can not be changed"))
+	}
+	if schemaClusterRefKey(r.Spec.SourceClusterRef, r.Namespace) != schemaClusterRefKey(old.Spec.SourceClusterRef, old.Namespace) {
+		errs = append(errs, field.Forbidden(field.NewPath("spec", "sourceClusterRef"), "source cluster can not be changed"))
+	}
+	return nil, toInvalid("HbaseReplicationPeer", r.Name, errs)
+}
+
+func (v *hbaseReplicationPeerValidator) ValidateDelete(ctx context.Context, r *HbaseReplicationPeer) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// PeerID returns the id of the peer in the source cluster. HBase does not allow - in peer ids
+func (r *HbaseReplicationPeer) PeerID() string {
+	if len(r.Spec.PeerID) > 0 {
+		return r.Spec.PeerID
+	}
+	return strings.ReplaceAll(r.Name, "-", "_")
+}
+
+func (r *HbaseReplicationPeer) validate() field.ErrorList {
+	errs := field.ErrorList{}
+	specPath := field.NewPath("spec")
+	if (r.Spec.TargetClusterRef == nil) == (len(r.Spec.ClusterKey) == 0) {
+		errs = append(errs, field.Required(specPath, "exactly one of targetClusterRef or clusterKey is required"))
+	}
+	if len(r.Spec.ClusterKey) > 0 && !clusterKeyPattern.MatchString(r.Spec.ClusterKey) {
+		errs = append(errs, field.Invalid(specPath.Child("clusterKey"), r.Spec.ClusterKey, "must be quorum:clientPort:znodeParent"))
+	}
+	if r.Spec.TargetClusterRef != nil && schemaClusterRefKey(*r.Spec.TargetClusterRef, r.Namespace) == schemaClusterRefKey(r.Spec.SourceClusterRef, r.Namespace) {
+		errs = append(errs, field.Invalid(specPath.Child("targetClusterRef"), r.Spec.TargetClusterRef.Name, "must differ from sourceClusterRef"))
+	}
+	for i, t := range r.Spec.TableCFs {
+		if strings.Count(t.Table, ":") > 1 || strings.HasPrefix(t.Table, ":") || strings.HasSuffix(t.Table, ":") {
+			errs = append(errs, field.Invalid(specPath.Child("tableCFs").Index(i).Child("table"), t.Table, "must be namespace:name or name"))
+		}
+	}
+	return errs
+}
+
+// schemaClusterRefKey identifies the cluster a reference resolves to from the given namespace
+func schemaClusterRefKey(ref HbaseSchemaClusterReference, namespace string) string {
+	if len(ref.Namespace) > 0 {
+		namespace = ref.Namespace
+	}
+	kind := ref.Kind
+	if len(kind) == 0 {
+		kind = "HbaseCluster"
+	}
+	return kind + "/" + namespace + "/" + ref.Name
+}
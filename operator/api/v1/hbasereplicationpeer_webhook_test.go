@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestHbaseReplicationPeerValidator verifies the target, cluster key and tables are validated, and the peer id and
+// source cluster can not change.
+func TestHbaseReplicationPeerValidator(t *testing.T) {
+	v := &hbaseReplicationPeerValidator{}
+	p := &HbaseReplicationPeer{ObjectMeta: metav1.ObjectMeta{Name: "dr-site", Namespace: "hbase"}}
+	p.Spec.SourceClusterRef = HbaseSchemaClusterReference{Name: "primary"}
+	p.Spec.TableCFs = []HbaseReplicationTableCFs{{Table: "orders:items:d"}}
+
+	_, err := v.ValidateCreate(context.TODO(), p)
+	assert.ErrorContains(t, err, "exactly one of targetClusterRef or clusterKey is required")
+	assert.ErrorContains(t, err, "spec.tableCFs[0].table")
+
+	p.Spec.TableCFs = []HbaseReplicationTableCFs{{Table: "orders:items"}, {Table: "users"}}
+	p.Spec.ClusterKey = "zk-0,zk-1:2181"
+	_, err = v.ValidateCreate(context.TODO(), p)
+	assert.ErrorContains(t, err, "spec.clusterKey")
+
+	p.Spec.ClusterKey = "zk-0,zk-1:2181:/hbase"
+	_, err = v.ValidateCreate(context.TODO(), p)
+	assert.NoError(t, err)
+	assert.Equal(t, "dr_site", p.PeerID())
+
+	p.Spec.ClusterKey = ""
+	p.Spec.TargetClusterRef = &HbaseSchemaClusterReference{Name: "primary", Namespace: "hbase"}
+	_, err = v.ValidateCreate(context.TODO(), p)
+	assert.ErrorContains(t, err, "must differ from sourceClusterRef")
+
+	updated := p.DeepCopy()
+	updated.Spec.TargetClusterRef = &HbaseSchemaClusterReference{Name: "dr"}
+	updated.Spec.PeerID = "dr"
+	updated.Spec.SourceClusterRef = HbaseSchemaClusterReference{Name: "other"}
+	_, err = v.ValidateUpdate(context.TODO(), p, updated)
+	assert.ErrorContains(t, err, "spec.peerId")
+	assert.ErrorContains(t, err, "spec.sourceClusterRef")
+}
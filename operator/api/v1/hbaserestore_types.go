@@ -0,0 +1,68 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseRestoreSpec defines the snapshot restored and into which table
+type HbaseRestoreSpec struct {
+	// HbaseCluster or HbaseStandalone the snapshot is restored into
+	ClusterRef HbaseSchemaClusterReference `json:"clusterRef"`
+	// Snapshot restored
+	Snapshot string `json:"snapshot"`
+	// Exported snapshots the snapshot is imported from first, when it is not in the cluster already
+	// +optional
+	Source *HbaseBackupLocation `json:"source,omitempty"`
+	// Table as namespace:name the snapshot is cloned into, which must not exist. The table of the snapshot is
+	// restored in place unless set, and must be disabled beforehand
+	// +optional
+	Table string `json:"table,omitempty"`
+	// +optional
+	Job HbaseSnapshotJobSpec `json:"job,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Snapshot",type=string,JSONPath=`.spec.snapshot`
+//+kubebuilder:printcolumn:name="Table",type=string,JSONPath=`.spec.table`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Bytes",type=integer,JSONPath=`.status.bytesCopied`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// HbaseRestore is the Schema for the hbaserestores API
+type HbaseRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseRestoreSpec       `json:"spec,omitempty"`
+	Status HbaseSnapshotJobStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseRestoreList contains a list of HbaseRestore
+type HbaseRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseRestore{}, &HbaseRestoreList{})
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseSnapshotScheduleSpec defines the tables snapshotted, when, and how long snapshots are kept
+type HbaseSnapshotScheduleSpec struct {
+	// HbaseCluster or HbaseStandalone the tables are in, snapshotted through its admin endpoint
+	ClusterRef HbaseSchemaClusterReference `json:"clusterRef"`
+	// Cron expression in UTC, e.g. "0 2 * * *" or @daily
+	Schedule string `json:"schedule"`
+	// Tables snapshotted on every run
+	TableSelector HbaseSnapshotTableSelector `json:"tableSelector"`
+	// Snapshots taken by the schedule which are pruned after every run
+	Retention HbaseSnapshotRetention `json:"retention"`
+	// Stops taking and pruning snapshots. Once resumed, only the latest run missed meanwhile is taken
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// HbaseSnapshotTableSelector selects tables by name and through the HbaseTables managing them
+type HbaseSnapshotTableSelector struct {
+	// Tables as namespace:name, which may be glob patterns such as orders:*
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+	// Selects HbaseTables in the namespace of the schedule, in addition to the listed tables
+	// +optional
+	HbaseTableSelector *metav1.LabelSelector `json:"hbaseTableSelector,omitempty"`
+}
+
+// HbaseSnapshotRetention bounds the snapshots kept per table. Snapshots beyond either limit are deleted
+type HbaseSnapshotRetention struct {
+	// Snapshots kept per table, latest first
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	MaxCount *int32 `json:"maxCount,omitempty"`
+	// Age after which snapshots are deleted, e.g. 168h
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+}
+
+// HbaseSnapshotScheduleStatus defines the observed state of HbaseSnapshotSchedule
+type HbaseSnapshotScheduleStatus struct {
+	// Time of the last run, successful or not
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// Snapshots taken by the last successful run
+	// +optional
+	LastSnapshots []string `json:"lastSnapshots,omitempty"`
+	// +optional
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+	// +optional
+	LastFailureMessage string `json:"lastFailureMessage,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Schedule",type=string,JSONPath=`.spec.schedule`
+//+kubebuilder:printcolumn:name="Suspend",type=boolean,JSONPath=`.spec.suspend`
+//+kubebuilder:printcolumn:name="Last Success",type=date,JSONPath=`.status.lastSuccessfulTime`
+//+kubebuilder:printcolumn:name="Last Failure",type=date,JSONPath=`.status.lastFailureTime`
+
+// HbaseSnapshotSchedule is the Schema for the hbasesnapshotschedules API
+type HbaseSnapshotSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseSnapshotScheduleSpec   `json:"spec,omitempty"`
+	Status HbaseSnapshotScheduleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseSnapshotScheduleList contains a list of HbaseSnapshotSchedule
+type HbaseSnapshotScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseSnapshotSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseSnapshotSchedule{}, &HbaseSnapshotScheduleList{})
+}
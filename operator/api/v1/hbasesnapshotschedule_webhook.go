@@ -0,0 +1,80 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseSnapshotSchedule
+func (r *HbaseSnapshotSchedule) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseSnapshotScheduleValidator{}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasesnapshotschedule,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbasesnapshotschedules,verbs=create;update,versions=v1,name=vhbasesnapshotschedule.kb.io,admissionReviewVersions=v1
+
+type hbaseSnapshotScheduleValidator struct{}
+
+func (v *hbaseSnapshotScheduleValidator) ValidateCreate(ctx context.Context, r *HbaseSnapshotSchedule) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseSnapshotScheduleValidator) ValidateUpdate(ctx context.Context, old *HbaseSnapshotSchedule, r *HbaseSnapshotSchedule) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseSnapshotScheduleValidator) ValidateDelete(ctx context.Context, r *HbaseSnapshotSchedule) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *HbaseSnapshotSchedule) validate() error {
+	errs := field.ErrorList{}
+	if _, err := ParseCronSchedule(r.Spec.Schedule); err != nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "schedule"), r.Spec.Schedule, err.Error()))
+	}
+
+	selector := r.Spec.TableSelector
+	selectorPath := field.NewPath("spec", "tableSelector")
+	if len(selector.Tables) == 0 && selector.HbaseTableSelector == nil {
+		errs = append(errs, field.Required(selectorPath, "one of tables or hbaseTableSelector is required"))
+	}
+	for i, pattern := range selector.Tables {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(selectorPath.Child("tables").Index(i), pattern, err.Error()))
+		}
+	}
+	if selector.HbaseTableSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector.HbaseTableSelector); err != nil {
+			errs = append(errs, field.Invalid(selectorPath.Child("hbaseTableSelector"), selector.HbaseTableSelector, err.Error()))
+		}
+	}
+
+	retention := r.Spec.Retention
+	if retention.MaxCount == nil && retention.MaxAge == nil {
+		errs = append(errs, field.Required(field.NewPath("spec", "retention"), "one of maxCount or maxAge is required"))
+	}
+	if retention.MaxAge != nil && retention.MaxAge.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "retention", "maxAge"), retention.MaxAge.Duration.String(), "must be positive"))
+	}
+	return toInvalid("HbaseSnapshotSchedule", r.Name, errs)
+}
@@ -0,0 +1,72 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestParseCronSchedule verifies fields, aliases, ranges and steps are parsed, and invalid expressions rejected.
+func TestParseCronSchedule(t *testing.T) {
+	for _, spec := range []string{"* * * * *", "@daily", "*/15 2-4 * * 1,3", "0 0 1 1 7", "5-55/10 * * * *"} {
+		_, err := ParseCronSchedule(spec)
+		assert.NoError(t, err, spec)
+	}
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 5m"} {
+		_, err := ParseCronSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+// TestCronSchedule_Next verifies the next time matching the schedule is found after, and not at, the given time.
+func TestCronSchedule_Next(t *testing.T) {
+	// a wednesday
+	now := time.Date(2026, 10, 21, 10, 30, 20, 0, time.UTC)
+	for spec, next := range map[string]time.Time{
+		"* * * * *":     time.Date(2026, 10, 21, 10, 31, 0, 0, time.UTC),
+		"30 10 * * *":   time.Date(2026, 10, 22, 10, 30, 0, 0, time.UTC),
+		"*/20 * * * *":  time.Date(2026, 10, 21, 10, 40, 0, 0, time.UTC),
+		"@hourly":       time.Date(2026, 10, 21, 11, 0, 0, 0, time.UTC),
+		"0 2 * * 0":     time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC),
+		"0 2 * * 7":     time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC),
+		"0 0 1 * *":     time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":    time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 1,15 * 5":  time.Date(2026, 10, 23, 0, 0, 0, 0, time.UTC),
+		"0 0 30 2 *":    {},
+		"0 0 */10 12 *": time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		s, err := ParseCronSchedule(spec)
+		assert.NoError(t, err, spec)
+		assert.Equal(t, next, s.Next(now), spec)
+	}
+}
+
+// TestHbaseSnapshotScheduleValidator verifies the schedule, table selector and retention are validated.
+func TestHbaseSnapshotScheduleValidator(t *testing.T) {
+	v := &hbaseSnapshotScheduleValidator{}
+	s := &HbaseSnapshotSchedule{ObjectMeta: metav1.ObjectMeta{Name: "nightly"}}
+	s.Spec.Schedule = "0 25 * * *"
+	s.Spec.TableSelector.Tables = []string{"orders:[items"}
+	s.Spec.Retention.MaxAge = &metav1.Duration{Duration: -time.Hour}
+
+	_, err := v.ValidateCreate(context.TODO(), s)
+	assert.ErrorContains(t, err, "spec.schedule")
+	assert.ErrorContains(t, err, "spec.tableSelector.tables[0]")
+	assert.ErrorContains(t, err, "spec.retention.maxAge")
+
+	s.Spec.Schedule = "@daily"
+	s.Spec.TableSelector = HbaseSnapshotTableSelector{}
+	s.Spec.Retention = HbaseSnapshotRetention{}
+	_, err = v.ValidateCreate(context.TODO(), s)
+	assert.ErrorContains(t, err, "one of tables or hbaseTableSelector is required")
+	assert.ErrorContains(t, err, "one of maxCount or maxAge is required")
+
+	count := int32(7)
+	s.Spec.TableSelector.HbaseTableSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"backup": "nightly"}}
+	s.Spec.Retention.MaxCount = &count
+	_, err = v.ValidateUpdate(context.TODO(), s, s)
+	assert.NoError(t, err)
+}
@@ -38,6 +38,11 @@ type HbaseStandaloneSpec struct {
 	ServiceLabels map[string]string `json:"serviceLabels"`
 	// +optional
 	ServiceSelectorLabels map[string]string `json:"serviceSelectorLabels"`
+	// Customizes the headless Service of the standalone
+	// +optional
+	Service *HbaseClusterServiceSpec `json:"service,omitempty"`
+	// +optional
+	Monitoring *HbaseClusterMonitoring `json:"monitoring,omitempty"`
 }
 
 // HbaseStandaloneStatus defines the observed state of HbaseStandalone
@@ -45,8 +50,15 @@ type HbaseStandaloneStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	//TODO
-	Nodes      []string           `json:"nodes"`
-	Conditions []metav1.Condition `json:"conditions"`
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	LastConfigChange *ConfigChangeStatus `json:"lastConfigChange,omitempty"`
+	// Tenant config overrides applied to the ConfigMaps
+	// +optional
+	TenantConfigOverrides []TenantConfigOverrideStatus `json:"tenantConfigOverrides,omitempty"`
 }
 
 //+kubebuilder:object:root=true
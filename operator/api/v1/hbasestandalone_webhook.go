@@ -0,0 +1,54 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseStandalone
+func (r *HbaseStandalone) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseStandaloneValidator{}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasestandalone,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=create;update,versions=v1,name=vhbasestandalone.kb.io,admissionReviewVersions=v1
+
+type hbaseStandaloneValidator struct{}
+
+func (v *hbaseStandaloneValidator) ValidateCreate(ctx context.Context, r *HbaseStandalone) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseStandaloneValidator) ValidateUpdate(ctx context.Context, old *HbaseStandalone, r *HbaseStandalone) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseStandaloneValidator) ValidateDelete(ctx context.Context, r *HbaseStandalone) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *HbaseStandalone) validate() error {
+	// ConfigMaps are only rendered in the namespace of the standalone
+	errs := ValidateTenantConfig(field.NewPath("spec", "configuration"), r.Spec.Configuration, []string{r.Namespace})
+	errs = append(errs, validateService(field.NewPath("spec", "service"), r.Spec.Service, true)...)
+	errs = append(errs, validateMonitoring(field.NewPath("spec", "monitoring"), r.Spec.Monitoring, []string{r.Spec.Standalone.Name})...)
+	return toInvalid("HbaseStandalone", r.Name, errs)
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseTableSpec defines the desired state of HbaseTable
+type HbaseTableSpec struct {
+	// HbaseCluster or HbaseStandalone the table is created in, through its REST gateway
+	ClusterRef HbaseSchemaClusterReference `json:"clusterRef"`
+	// HBase namespace of the table
+	// +kubebuilder:default:=default
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Name of the table in HBase, without namespace. Defaults to the name of the resource
+	// +optional
+	Name string `json:"name,omitempty"`
+	// +kubebuilder:validation:MinItems:=1
+	// +listType=map
+	// +listMapKey=name
+	ColumnFamilies []HbaseColumnFamily `json:"columnFamilies"`
+	// Split keys of the regions the table is created with. Only applied when the table is created
+	// +optional
+	SplitKeys []string `json:"splitKeys,omitempty"`
+	// Table attributes, e.g. DURABILITY or MAX_FILESIZE
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+	// Allows altering the schema of the existing table when it differs from the spec. The REST gateway disables the
+	// table while altering it, so it is unavailable for the duration. Without it, differences are only reported in status
+	// +optional
+	AllowAlter bool `json:"allowAlter,omitempty"`
+	// Allows changes which lose data, such as dropping column families missing from the spec, along with allowAlter.
+	// Without it, such changes are only reported in status
+	// +optional
+	AllowDestructiveChanges bool `json:"allowDestructiveChanges,omitempty"`
+}
+
+// HbaseColumnFamily defines a column family of an HbaseTable. Settings which are not set are left to HBase
+type HbaseColumnFamily struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Enum:=NONE;SNAPPY;LZ4;LZO;GZ;ZSTD;BZIP2
+	// +optional
+	Compression string `json:"compression,omitempty"`
+	// Time to live of cells, in seconds
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	TTL *int32 `json:"ttl,omitempty"`
+	// Versions of cells kept
+	// +kubebuilder:validation:Minimum:=1
+	// +optional
+	MaxVersions *int32 `json:"maxVersions,omitempty"`
+	// +kubebuilder:validation:Enum:=NONE;ROW;ROWCOL;ROWPREFIX_FIXED_LENGTH
+	// +optional
+	BloomFilter string `json:"bloomFilter,omitempty"`
+	// Other column family attributes, e.g. BLOCKSIZE or IN_MEMORY
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// HbaseTableStatus defines the observed state of HbaseTable
+type HbaseTableStatus struct {
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Differences between the spec and the schema in HBase which are not applied, e.g. destructive changes
+	// +optional
+	Drift []string `json:"drift,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+
+// HbaseTable is the Schema for the hbasetables API
+type HbaseTable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseTableSpec   `json:"spec,omitempty"`
+	Status HbaseTableStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseTableList contains a list of HbaseTable
+type HbaseTableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseTable `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseTable{}, &HbaseTableList{})
+}
@@ -17,20 +17,107 @@ limitations under the License.
 package v1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // HbaseTenantSpec defines the desired state of HbaseTenant
 type HbaseTenantSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
-	Datanode      HbaseClusterDeployment    `json:"datanode"`
+	Datanode HbaseClusterDeployment `json:"datanode"`
+	// HbaseCluster the tenant belongs to. The tenant inherits baseImage, fsgroup and configuration of the cluster
+	// which are not set, and is only reconciled while the cluster is Available
+	// +optional
+	ClusterRef *HbaseClusterReference `json:"clusterRef,omitempty"`
+	// Required unless inherited through clusterRef
+	// +optional
 	Configuration HbaseClusterConfiguration `json:"configuration"`
-	FSGroup       int64                     `json:"fsgroup"`
-	BaseImage     string                    `json:"baseImage"`
+	// Required unless inherited through clusterRef
+	// +optional
+	FSGroup int64 `json:"fsgroup"`
+	// Required unless inherited through clusterRef
+	// +optional
+	BaseImage string `json:"baseImage"`
 	// +optional
 	ServiceLabels map[string]string `json:"serviceLabels"`
 	// +optional
 	ServiceSelectorLabels map[string]string `json:"serviceSelectorLabels"`
+	// Customizes the headless Service of the tenant
+	// +optional
+	Service *HbaseClusterServiceSpec `json:"service,omitempty"`
+	// Isolates the tenant in an RSGroup of its own through the admin endpoint. Regionservers of the tenant are moved
+	// into the group as they become ready
+	// +optional
+	RSGroup *HbaseTenantRSGroup `json:"rsGroup,omitempty"`
+	// HBase namespaces moved into the RSGroup of the tenant, so that their tables are served by its regionservers only
+	// +optional
+	HbaseNamespaces []string `json:"hbaseNamespaces,omitempty"`
+	// HBase quotas of the tenant, periodically applied through the admin endpoint
+	// +optional
+	Quotas *HbaseTenantQuotas `json:"quotas,omitempty"`
+	// +optional
+	Monitoring *HbaseClusterMonitoring `json:"monitoring,omitempty"`
+}
+
+// HbaseTenantQuotas defines the HBase quotas of a tenant
+type HbaseTenantQuotas struct {
+	// +optional
+	Throttles []HbaseThrottleQuota `json:"throttles,omitempty"`
+	// +optional
+	Space []HbaseSpaceQuota `json:"space,omitempty"`
+	// Interval quotas are checked for drift and space usage is reported at
+	// +kubebuilder:default:="5m"
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+}
+
+// HbaseThrottleQuota throttles requests of a user, namespace or table. A user throttle may be scoped to a namespace
+// or table of the user
+type HbaseThrottleQuota struct {
+	// +optional
+	User string `json:"user,omitempty"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// +optional
+	Table string `json:"table,omitempty"`
+	// +kubebuilder:validation:Enum:=REQUEST_NUMBER;REQUEST_SIZE;REQUEST_CAPACITY_UNIT;WRITE_NUMBER;WRITE_SIZE;WRITE_CAPACITY_UNIT;READ_NUMBER;READ_SIZE;READ_CAPACITY_UNIT
+	Type string `json:"type"`
+	// Limit as in the hbase shell, e.g. 1000req/sec, 10M/sec or 100CU/sec
+	// +kubebuilder:validation:Pattern:=`^[0-9]+(req|CU|B|K|M|G|T|P)?/(sec|min|hour|day)$`
+	Limit string `json:"limit"`
+}
+
+// HbaseSpaceQuota limits the space used by the tables of an HBase namespace
+type HbaseSpaceQuota struct {
+	Namespace string `json:"namespace"`
+	// Size of the namespace in the filesystem, e.g. 10Ti
+	Limit resource.Quantity `json:"limit"`
+	// Applied once the limit is exceeded
+	// +kubebuilder:validation:Enum:=NO_INSERTS;NO_WRITES;NO_WRITES_COMPACTIONS;DISABLE
+	Policy string `json:"policy"`
+}
+
+// HbaseTenantRSGroup defines the RSGroup of a tenant
+type HbaseTenantRSGroup struct {
+	// Defaults to the name of the tenant
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Port regionservers register with in HBase
+	// +kubebuilder:default:=16020
+	// +optional
+	Port int32 `json:"port,omitempty"`
+	// Cluster domain of the pod hostnames regionservers register with in HBase
+	// +kubebuilder:default:=cluster.local
+	// +optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+}
+
+// HbaseClusterReference refers to an HbaseCluster
+type HbaseClusterReference struct {
+	Name string `json:"name"`
+	// Defaults to the namespace of the referring object
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
 }
 
 // HbaseTenantStatus defines the observed state of HbaseTenant
@@ -38,8 +125,55 @@ type HbaseTenantStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 	//TODO
-	Nodes      []string           `json:"nodes"`
-	Conditions []metav1.Condition `json:"conditions"`
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// +optional
+	LastConfigChange *ConfigChangeStatus `json:"lastConfigChange,omitempty"`
+	// Tenant config overrides applied to the ConfigMaps
+	// +optional
+	TenantConfigOverrides []TenantConfigOverrideStatus `json:"tenantConfigOverrides,omitempty"`
+	// HbaseCluster the tenant belongs to, as namespace/name
+	// +optional
+	Cluster string `json:"cluster,omitempty"`
+	// RSGroup of the tenant as last seen through the admin endpoint
+	// +optional
+	RSGroup *RSGroupStatus `json:"rsGroup,omitempty"`
+	// Quotas of the tenant as last synced through the admin endpoint
+	// +optional
+	Quotas *QuotaStatus `json:"quotas,omitempty"`
+}
+
+// QuotaStatus defines the observed state of the quotas of a tenant
+type QuotaStatus struct {
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+	// Quotas applied by the operator, as target/type
+	// +optional
+	Applied []string `json:"applied,omitempty"`
+	// Usage of the namespaces with a space quota
+	// +optional
+	SpaceUsage []SpaceQuotaUsage `json:"spaceUsage,omitempty"`
+}
+
+// SpaceQuotaUsage defines the space used by an HBase namespace
+type SpaceQuotaUsage struct {
+	Namespace string            `json:"namespace"`
+	Usage     resource.Quantity `json:"usage"`
+	Limit     resource.Quantity `json:"limit"`
+	// True while the policy of the quota is enforced
+	InViolation bool `json:"inViolation"`
+}
+
+// RSGroupStatus defines the observed state of an RSGroup
+type RSGroupStatus struct {
+	Name string `json:"name"`
+	// Regionservers of the tenant in the group, as host:port
+	// +optional
+	Servers []string `json:"servers,omitempty"`
+	// HBase namespaces of the tenant in the group
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
 }
 
 //+kubebuilder:object:root=true
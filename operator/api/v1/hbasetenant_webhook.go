@@ -0,0 +1,206 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseTenant
+func (r *HbaseTenant) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseTenantValidator{client: mgr.GetClient()}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasetenant,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbasetenants,verbs=create;update,versions=v1,name=vhbasetenant.kb.io,admissionReviewVersions=v1
+
+type hbaseTenantValidator struct {
+	// used to enforce HbaseTenantPolicies, when set
+	client client.Reader
+}
+
+func (v *hbaseTenantValidator) ValidateCreate(ctx context.Context, r *HbaseTenant) (admission.Warnings, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return nil, v.validatePolicies(ctx, nil, r)
+}
+
+func (v *hbaseTenantValidator) ValidateUpdate(ctx context.Context, old *HbaseTenant, r *HbaseTenant) (admission.Warnings, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	return nil, v.validatePolicies(ctx, old, r)
+}
+
+func (v *hbaseTenantValidator) ValidateDelete(ctx context.Context, r *HbaseTenant) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *HbaseTenant) validate() error {
+	// ConfigMaps are only rendered in the namespace of the tenant
+	errs := ValidateTenantConfig(field.NewPath("spec", "configuration"), r.Spec.Configuration, []string{r.Namespace})
+	if r.Spec.ClusterRef == nil {
+		// without a cluster to inherit them from, these have to be set on the tenant
+		if len(r.Spec.BaseImage) == 0 {
+			errs = append(errs, field.Required(field.NewPath("spec", "baseImage"), "required unless inherited through spec.clusterRef"))
+		}
+		if len(r.Spec.Configuration.HbaseConfigName) == 0 {
+			errs = append(errs, field.Required(field.NewPath("spec", "configuration", "hbaseConfigName"), "required unless inherited through spec.clusterRef"))
+		}
+		if len(r.Spec.Configuration.HadoopConfigName) == 0 {
+			errs = append(errs, field.Required(field.NewPath("spec", "configuration", "hadoopConfigName"), "required unless inherited through spec.clusterRef"))
+		}
+	} else if len(r.Spec.ClusterRef.Name) == 0 {
+		errs = append(errs, field.Required(field.NewPath("spec", "clusterRef", "name"), ""))
+	}
+	// RSGroups are managed through the admin endpoint, which may be inherited from the cluster
+	if r.Spec.RSGroup != nil && r.Spec.ClusterRef == nil && len(r.Spec.Configuration.AdminEndpoint) == 0 {
+		errs = append(errs, field.Required(field.NewPath("spec", "configuration", "adminEndpoint"), "required to manage spec.rsGroup"))
+	}
+	if r.Spec.Quotas != nil && r.Spec.ClusterRef == nil && len(r.Spec.Configuration.AdminEndpoint) == 0 {
+		errs = append(errs, field.Required(field.NewPath("spec", "configuration", "adminEndpoint"), "required to manage spec.quotas"))
+	}
+	if r.Spec.Quotas != nil {
+		errs = append(errs, validateQuotas(field.NewPath("spec", "quotas"), r.Spec.Quotas)...)
+	}
+	if len(r.Spec.HbaseNamespaces) > 0 && r.Spec.RSGroup == nil {
+		errs = append(errs, field.Invalid(field.NewPath("spec", "hbaseNamespaces"), r.Spec.HbaseNamespaces, "only moved into spec.rsGroup, which is not set"))
+	}
+	errs = append(errs, validateService(field.NewPath("spec", "service"), r.Spec.Service, true)...)
+	errs = append(errs, validateMonitoring(field.NewPath("spec", "monitoring"), r.Spec.Monitoring, []string{r.Spec.Datanode.Name})...)
+	return toInvalid("HbaseTenant", r.Name, errs)
+}
+
+// validateQuotas checks every throttle has a target and quotas are not defined twice for the same target
+func validateQuotas(path *field.Path, q *HbaseTenantQuotas) field.ErrorList {
+	errs := field.ErrorList{}
+	seen := map[string]bool{}
+	for i, t := range q.Throttles {
+		p := path.Child("throttles").Index(i)
+		if len(t.User) == 0 && len(t.Namespace) == 0 && len(t.Table) == 0 {
+			errs = append(errs, field.Required(p, "one of user, namespace or table is required"))
+		} else if len(t.Namespace) > 0 && len(t.Table) > 0 {
+			errs = append(errs, field.Invalid(p, t.Table, "only one of namespace or table may be set"))
+		}
+		key := t.User + "/" + t.Namespace + "/" + t.Table + "/" + t.Type
+		if seen[key] {
+			errs = append(errs, field.Duplicate(p, t.Type))
+		}
+		seen[key] = true
+	}
+	for i, s := range q.Space {
+		p := path.Child("space").Index(i)
+		if len(s.Namespace) == 0 {
+			errs = append(errs, field.Required(p.Child("namespace"), ""))
+		}
+		if s.Limit.Sign() <= 0 {
+			errs = append(errs, field.Invalid(p.Child("limit"), s.Limit.String(), "must be positive"))
+		}
+		if seen["space/"+s.Namespace] {
+			errs = append(errs, field.Duplicate(p.Child("namespace"), s.Namespace))
+		}
+		seen["space/"+s.Namespace] = true
+	}
+	return errs
+}
+
+// validatePolicies checks the tenant against the HbaseTenantPolicies applying to its namespace
+func (v *hbaseTenantValidator) validatePolicies(ctx context.Context, old *HbaseTenant, r *HbaseTenant) error {
+	if v.client == nil {
+		return nil
+	}
+	policies := &HbaseTenantPolicyList{}
+	if err := v.client.List(ctx, policies); err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	if len(policies.Items) == 0 {
+		return nil
+	}
+
+	// the base image may be inherited from the cluster
+	baseImage := r.Spec.BaseImage
+	if len(baseImage) == 0 && r.Spec.ClusterRef != nil {
+		cluster := &HbaseCluster{}
+		ref := types.NamespacedName{Name: r.Spec.ClusterRef.Name, Namespace: r.Spec.ClusterRef.Namespace}
+		if len(ref.Namespace) == 0 {
+			ref.Namespace = r.Namespace
+		}
+		if err := v.client.Get(ctx, ref, cluster); err != nil && !apierrors.IsNotFound(err) {
+			return apierrors.NewInternalError(err)
+		}
+		baseImage = cluster.Spec.BaseImage
+	}
+	grows := old == nil || r.Spec.Datanode.Size > old.Spec.Datanode.Size
+
+	errs, err := ValidateTenantPolicies(ctx, v.client, policies.Items, r, baseImage, grows)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	return toInvalid("HbaseTenant", r.Name, errs)
+}
+
+// ValidateTenantPolicies checks the tenant against the policies applying to its namespace. The regionserver limit is
+// only checked when the tenant grows. Used on admission by the webhook, and by the operator before rendering a tenant
+func ValidateTenantPolicies(ctx context.Context, c client.Reader, policies []HbaseTenantPolicy, r *HbaseTenant,
+	baseImage string, grows bool) (field.ErrorList, error) {
+	namespaces := &corev1.NamespaceList{}
+	if err := c.List(ctx, namespaces); err != nil {
+		return nil, err
+	}
+	namespaceByName := map[string]*corev1.Namespace{}
+	for i := range namespaces.Items {
+		namespaceByName[namespaces.Items[i].Name] = &namespaces.Items[i]
+	}
+	namespaceOf := func(name string) *corev1.Namespace {
+		if ns, ok := namespaceByName[name]; ok {
+			return ns
+		}
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	tenants := &HbaseTenantList{}
+	if err := c.List(ctx, tenants); err != nil {
+		return nil, err
+	}
+
+	errs := field.ErrorList{}
+	for i := range policies {
+		p := &policies[i]
+		if selected, err := p.Selects(namespaceOf(r.Namespace)); err != nil || !selected {
+			continue
+		}
+		others := int32(0)
+		for _, t := range tenants.Items {
+			if t.Namespace == r.Namespace && t.Name == r.Name {
+				continue
+			}
+			if selected, _ := p.Selects(namespaceOf(t.Namespace)); selected {
+				others += t.Spec.Datanode.Size
+			}
+		}
+		errs = append(errs, p.validateTenant(r, baseImage, others, grows)...)
+	}
+	return errs, nil
+}
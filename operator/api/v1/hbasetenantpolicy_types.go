@@ -0,0 +1,107 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HbaseTenantPolicySpec defines the limits of HbaseTenants in the namespaces selected by the policy
+type HbaseTenantPolicySpec struct {
+	// Namespaces the policy applies to
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Namespaces the policy applies to, in addition to the listed ones
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+	// Maximum regionservers of all the HbaseTenants in the selected namespaces together
+	// +kubebuilder:validation:Minimum:=0
+	// +optional
+	MaxRegionServers *int32 `json:"maxRegionServers,omitempty"`
+	// Maximum cpu and memory of a regionserver pod, summed over its containers and sidecars. Limits of the containers
+	// are counted, or their requests when they have no limit
+	// +optional
+	MaxPodResources corev1.ResourceList `json:"maxPodResources,omitempty"`
+	// Glob patterns of the base and sidecar images allowed, any image when empty
+	// +optional
+	AllowedBaseImages []string `json:"allowedBaseImages,omitempty"`
+	// Volume sources allowed, any when empty
+	// +optional
+	AllowedVolumeSources []TenantVolumeSource `json:"allowedVolumeSources,omitempty"`
+}
+
+// TenantVolumeSource is a kind of volume of an HbaseTenant. PersistentVolumeClaim stands for volume claims
+// +kubebuilder:validation:Enum:=ConfigMap;EmptyDir;Secret;HostPath;PersistentVolumeClaim
+type TenantVolumeSource string
+
+// HbaseTenantPolicyStatus defines the observed usage of the namespaces selected by the policy
+type HbaseTenantPolicyStatus struct {
+	// Namespaces the policy applies to
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+	// HbaseTenants in the selected namespaces, as namespace/name
+	// +optional
+	Tenants []string `json:"tenants,omitempty"`
+	// Regionservers of all the HbaseTenants in the selected namespaces
+	// +optional
+	RegionServers int32 `json:"regionServers"`
+	// How the limits are enforced. With Reconcile, the operator refuses to render HbaseTenants beyond them. With
+	// Admission, the HbaseTenant webhook also rejects such changes, which needs the operator to run with webhooks
+	// +optional
+	Enforcement TenantPolicyEnforcement `json:"enforcement,omitempty"`
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// TenantPolicyEnforcement tells how the limits of an HbaseTenantPolicy are enforced
+// +kubebuilder:validation:Enum:=Reconcile;Admission
+type TenantPolicyEnforcement string
+
+const (
+	TenantPolicyEnforcementReconcile TenantPolicyEnforcement = "Reconcile"
+	TenantPolicyEnforcementAdmission TenantPolicyEnforcement = "Admission"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="RegionServers",type=integer,JSONPath=`.status.regionServers`
+//+kubebuilder:printcolumn:name="MaxRegionServers",type=integer,JSONPath=`.spec.maxRegionServers`
+//+kubebuilder:printcolumn:name="Enforcement",type=string,JSONPath=`.status.enforcement`
+
+// HbaseTenantPolicy is the Schema for the hbasetenantpolicies API
+type HbaseTenantPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HbaseTenantPolicySpec   `json:"spec,omitempty"`
+	Status HbaseTenantPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// HbaseTenantPolicyList contains a list of HbaseTenantPolicy
+type HbaseTenantPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HbaseTenantPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HbaseTenantPolicy{}, &HbaseTenantPolicyList{})
+}
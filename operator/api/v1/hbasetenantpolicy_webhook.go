@@ -0,0 +1,196 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupWebhookWithManager registers the validating webhook of HbaseTenantPolicy
+func (r *HbaseTenantPolicy) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr, r).WithValidator(&hbaseTenantPolicyValidator{}).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-kvstore-flipkart-com-v1-hbasetenantpolicy,mutating=false,failurePolicy=fail,sideEffects=None,groups=kvstore.flipkart.com,resources=hbasetenantpolicies,verbs=create;update,versions=v1,name=vhbasetenantpolicy.kb.io,admissionReviewVersions=v1
+
+type hbaseTenantPolicyValidator struct{}
+
+func (v *hbaseTenantPolicyValidator) ValidateCreate(ctx context.Context, r *HbaseTenantPolicy) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseTenantPolicyValidator) ValidateUpdate(ctx context.Context, old *HbaseTenantPolicy, r *HbaseTenantPolicy) (admission.Warnings, error) {
+	return nil, r.validate()
+}
+
+func (v *hbaseTenantPolicyValidator) ValidateDelete(ctx context.Context, r *HbaseTenantPolicy) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (r *HbaseTenantPolicy) validate() error {
+	errs := field.ErrorList{}
+	if len(r.Spec.Namespaces) == 0 && r.Spec.NamespaceSelector == nil {
+		errs = append(errs, field.Required(field.NewPath("spec"), "one of namespaces or namespaceSelector is required"))
+	}
+	if r.Spec.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Spec.NamespaceSelector); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "namespaceSelector"), r.Spec.NamespaceSelector, err.Error()))
+		}
+	}
+	for i, pattern := range r.Spec.AllowedBaseImages {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("spec", "allowedBaseImages").Index(i), pattern, err.Error()))
+		}
+	}
+	return toInvalid("HbaseTenantPolicy", r.Name, errs)
+}
+
+// Selects tells whether the policy applies to the namespace
+func (r *HbaseTenantPolicy) Selects(ns *corev1.Namespace) (bool, error) {
+	for _, name := range r.Spec.Namespaces {
+		if name == ns.Name {
+			return true, nil
+		}
+	}
+	if r.Spec.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(r.Spec.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
+
+// validateTenant checks the tenant against the limits of the policy. The regionserver limit is checked against the
+// regionservers of the other tenants the policy applies to, and only when the tenant grows
+func (r *HbaseTenantPolicy) validateTenant(t *HbaseTenant, baseImage string, otherRegionServers int32, grows bool) field.ErrorList {
+	errs := field.ErrorList{}
+	d := t.Spec.Datanode
+	spec := field.NewPath("spec")
+	forbidden := func(p *field.Path, detail string) {
+		errs = append(errs, field.Forbidden(p, detail+" by HbaseTenantPolicy "+r.Name))
+	}
+
+	if r.Spec.MaxRegionServers != nil && grows && otherRegionServers+d.Size > *r.Spec.MaxRegionServers {
+		forbidden(spec.Child("datanode", "size"), fmt.Sprintf("exceeds the limit of %d regionservers with %d used by other tenants,",
+			*r.Spec.MaxRegionServers, otherRegionServers))
+	}
+
+	if len(r.Spec.MaxPodResources) > 0 {
+		used, err := podResourcesOf(d)
+		if err != nil {
+			errs = append(errs, field.Invalid(spec.Child("datanode"), d.Name, err.Error()))
+		}
+		for name, max := range r.Spec.MaxPodResources {
+			if q, ok := used[name]; ok && q.Cmp(max) > 0 {
+				forbidden(spec.Child("datanode"), string(name)+" of "+q.String()+" per pod exceeds the limit of "+max.String())
+			}
+		}
+	}
+
+	if len(r.Spec.AllowedBaseImages) > 0 {
+		if !r.allowsImage(baseImage) {
+			forbidden(spec.Child("baseImage"), "image "+baseImage+" not allowed")
+		}
+		for i, c := range d.SideCarContainers {
+			if !r.allowsImage(c.Image) {
+				forbidden(spec.Child("datanode", "sidecarContainers").Index(i).Child("image"), "image "+c.Image+" not allowed")
+			}
+		}
+	}
+
+	if len(r.Spec.AllowedVolumeSources) > 0 {
+		for i, v := range d.Volumes {
+			if !r.allowsVolumeSource(TenantVolumeSource(v.VolumeSource)) {
+				forbidden(spec.Child("datanode", "volumes").Index(i).Child("volumeSource"), "volume source "+v.VolumeSource+" not allowed")
+			}
+		}
+		if len(d.VolumeClaims) > 0 && !r.allowsVolumeSource("PersistentVolumeClaim") {
+			forbidden(spec.Child("datanode", "volumeClaims"), "volume source PersistentVolumeClaim not allowed")
+		}
+	}
+	return errs
+}
+
+func (r *HbaseTenantPolicy) allowsImage(image string) bool {
+	for _, pattern := range r.Spec.AllowedBaseImages {
+		if ok, _ := path.Match(pattern, image); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *HbaseTenantPolicy) allowsVolumeSource(source TenantVolumeSource) bool {
+	for _, s := range r.Spec.AllowedVolumeSources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
+
+// podResourcesOf returns the cpu and memory of a pod of the deployment, summed over its containers and sidecars
+func podResourcesOf(d HbaseClusterDeployment) (corev1.ResourceList, error) {
+	used := corev1.ResourceList{corev1.ResourceCPU: resource.Quantity{}, corev1.ResourceMemory: resource.Quantity{}}
+	add := func(name corev1.ResourceName, limit string, request string) error {
+		value := limit
+		if len(value) == 0 {
+			value = request
+		}
+		if len(value) == 0 {
+			return nil
+		}
+		q, err := resource.ParseQuantity(value)
+		if err != nil {
+			return err
+		}
+		total := used[name]
+		total.Add(q)
+		used[name] = total
+		return nil
+	}
+
+	for _, c := range d.Containers {
+		if err := add(corev1.ResourceCPU, c.CpuLimit, c.CpuRequest); err != nil {
+			return used, err
+		}
+		if err := add(corev1.ResourceMemory, c.MemoryLimit, c.MemoryRequest); err != nil {
+			return used, err
+		}
+	}
+	for _, c := range d.SideCarContainers {
+		if err := add(corev1.ResourceCPU, c.CpuLimit, c.CpuRequest); err != nil {
+			return used, err
+		}
+		if err := add(corev1.ResourceMemory, c.MemoryLimit, c.MemoryRequest); err != nil {
+			return used, err
+		}
+	}
+	return used, nil
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestPolicyTenant(namespace string, name string, size int32) *HbaseTenant {
+	t := &HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	t.Spec.BaseImage = "registry/hbase:2.4"
+	t.Spec.Configuration = HbaseClusterConfiguration{HbaseConfigName: "hbase-config", HadoopConfigName: "hadoop-config"}
+	t.Spec.Datanode = HbaseClusterDeployment{Name: "datanode", Size: size,
+		Containers: []HbaseClusterContainer{{Name: "regionserver", CpuLimit: "4", MemoryRequest: "16Gi"}, {Name: "datanode", CpuRequest: "1", MemoryLimit: "8Gi"}}}
+	return t
+}
+
+func newTestTenantValidator(t *testing.T, objs ...client.Object) *hbaseTenantValidator {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	objs = append(objs,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "gold-a", Labels: map[string]string{"tier": "gold"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "gold-b", Labels: map[string]string{"tier": "gold"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other"}},
+	)
+	return &hbaseTenantValidator{client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+}
+
+// TestHbaseTenantPolicyValidator verifies a policy selects namespaces with a valid selector and image patterns.
+func TestHbaseTenantPolicyValidator(t *testing.T) {
+	v := &hbaseTenantPolicyValidator{}
+	policy := &HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "policy"}}
+
+	_, err := v.ValidateCreate(context.TODO(), policy)
+	assert.ErrorContains(t, err, "one of namespaces or namespaceSelector is required")
+
+	policy.Spec.NamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}}}
+	policy.Spec.AllowedBaseImages = []string{"registry/[hbase"}
+	_, err = v.ValidateCreate(context.TODO(), policy)
+	assert.ErrorContains(t, err, "spec.namespaceSelector")
+	assert.ErrorContains(t, err, "spec.allowedBaseImages[0]")
+
+	policy.Spec.NamespaceSelector = nil
+	policy.Spec.Namespaces = []string{"gold-a"}
+	policy.Spec.AllowedBaseImages = []string{"registry/hbase:*"}
+	_, err = v.ValidateCreate(context.TODO(), policy)
+	assert.NoError(t, err)
+}
+
+// TestHbaseTenantPolicy_Selects verifies namespaces are selected by name or by labels.
+func TestHbaseTenantPolicy_Selects(t *testing.T) {
+	policy := &HbaseTenantPolicy{Spec: HbaseTenantPolicySpec{Namespaces: []string{"listed"},
+		NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}}}}
+
+	for ns, expected := range map[*corev1.Namespace]bool{
+		{ObjectMeta: metav1.ObjectMeta{Name: "listed"}}:                                             true,
+		{ObjectMeta: metav1.ObjectMeta{Name: "gold", Labels: map[string]string{"tier": "gold"}}}:    true,
+		{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"tier": "bronze"}}}: false,
+	} {
+		selected, err := policy.Selects(ns)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, selected, ns.Name)
+	}
+}
+
+// TestHbaseTenantValidator_PolicyRegionServers verifies regionservers of all the selected namespaces count towards the limit.
+func TestHbaseTenantValidator_PolicyRegionServers(t *testing.T) {
+	max := int32(5)
+	policy := &HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+		Spec: HbaseTenantPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}}, MaxRegionServers: &max}}
+	existing := newTestPolicyTenant("gold-b", "existing", 3)
+	v := newTestTenantValidator(t, policy, existing, newTestPolicyTenant("other", "unselected", 10))
+
+	_, err := v.ValidateCreate(context.TODO(), newTestPolicyTenant("gold-a", "tenant", 2))
+	assert.NoError(t, err)
+
+	_, err = v.ValidateCreate(context.TODO(), newTestPolicyTenant("gold-a", "tenant", 3))
+	assert.ErrorContains(t, err, "spec.datanode.size: Forbidden: exceeds the limit of 5 regionservers with 3 used by other tenants, by HbaseTenantPolicy gold")
+
+	_, err = v.ValidateCreate(context.TODO(), newTestPolicyTenant("other", "tenant", 30))
+	assert.NoError(t, err)
+
+	// tenants already over the limit may still shrink
+	grown := newTestPolicyTenant("gold-b", "existing", 7)
+	_, err = v.ValidateUpdate(context.TODO(), grown, newTestPolicyTenant("gold-b", "existing", 6))
+	assert.NoError(t, err)
+	_, err = v.ValidateUpdate(context.TODO(), existing, newTestPolicyTenant("gold-b", "existing", 6))
+	assert.ErrorContains(t, err, "spec.datanode.size")
+}
+
+// TestHbaseTenantValidator_PolicyPodResources verifies limits, or requests without limits, are summed over the containers.
+func TestHbaseTenantValidator_PolicyPodResources(t *testing.T) {
+	policy := &HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "gold"}, Spec: HbaseTenantPolicySpec{Namespaces: []string{"gold-a"},
+		MaxPodResources: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5"), corev1.ResourceMemory: resource.MustParse("24Gi")}}}
+	v := newTestTenantValidator(t, policy)
+
+	tenant := newTestPolicyTenant("gold-a", "tenant", 1)
+	_, err := v.ValidateCreate(context.TODO(), tenant)
+	assert.NoError(t, err)
+
+	tenant.Spec.Datanode.SideCarContainers = []HbaseClusterSideCarContainer{{Name: "exporter", Image: "exporter", CpuLimit: "500m", MemoryLimit: "1Gi"}}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "cpu of 5500m per pod exceeds the limit of 5")
+	assert.ErrorContains(t, err, "memory of 25Gi per pod exceeds the limit of 24Gi")
+}
+
+// TestHbaseTenantValidator_PolicyImagesAndVolumes verifies base and sidecar images, and volume sources, must be allowed.
+func TestHbaseTenantValidator_PolicyImagesAndVolumes(t *testing.T) {
+	policy := &HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "gold"}, Spec: HbaseTenantPolicySpec{Namespaces: []string{"gold-a"},
+		AllowedBaseImages: []string{"registry/*"}, AllowedVolumeSources: []TenantVolumeSource{"ConfigMap"}}}
+	cluster := &HbaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster", Namespace: "gold-a"}, Spec: HbaseClusterSpec{BaseImage: "docker.io/hbase:2.4"}}
+	v := newTestTenantValidator(t, policy, cluster)
+
+	tenant := newTestPolicyTenant("gold-a", "tenant", 1)
+	tenant.Spec.Datanode.Volumes = []HbaseClusterVolume{{Name: "config", VolumeSource: "ConfigMap"}}
+	_, err := v.ValidateCreate(context.TODO(), tenant)
+	assert.NoError(t, err)
+
+	tenant.Spec.Datanode.SideCarContainers = []HbaseClusterSideCarContainer{{Name: "exporter", Image: "docker.io/exporter"}}
+	tenant.Spec.Datanode.Volumes = append(tenant.Spec.Datanode.Volumes, HbaseClusterVolume{Name: "logs", VolumeSource: "HostPath"})
+	tenant.Spec.Datanode.VolumeClaims = []HbaseClusterVolumeClaim{{Name: "data", StorageSize: "1Ti"}}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "spec.datanode.sidecarContainers[0].image")
+	assert.ErrorContains(t, err, "spec.datanode.volumes[1].volumeSource")
+	assert.ErrorContains(t, err, "spec.datanode.volumeClaims")
+
+	// the base image inherited from the cluster is checked as well
+	tenant = newTestPolicyTenant("gold-a", "tenant", 1)
+	tenant.Spec.BaseImage = ""
+	tenant.Spec.ClusterRef = &HbaseClusterReference{Name: "cluster"}
+	_, err = v.ValidateCreate(context.TODO(), tenant)
+	assert.ErrorContains(t, err, "image docker.io/hbase:2.4 not allowed")
+}
@@ -27,6 +27,47 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigChangeStatus) DeepCopyInto(out *ConfigChangeStatus) {
+	*out = *in
+	in.ObservedTime.DeepCopyInto(&out.ObservedTime)
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RolloutPlan != nil {
+		in, out := &in.RolloutPlan, &out.RolloutPlan
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigChangeStatus.
+func (in *ConfigChangeStatus) DeepCopy() *ConfigChangeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigChangeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalEndpointStatus) DeepCopyInto(out *ExternalEndpointStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalEndpointStatus.
+func (in *ExternalEndpointStatus) DeepCopy() *ExternalEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HBasePodDisruptionBudget) DeepCopyInto(out *HBasePodDisruptionBudget) {
 	*out = *in
@@ -52,6 +93,115 @@ func (in *HBasePodDisruptionBudget) DeepCopy() *HBasePodDisruptionBudget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseBackup) DeepCopyInto(out *HbaseBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseBackup.
+func (in *HbaseBackup) DeepCopy() *HbaseBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseBackupList) DeepCopyInto(out *HbaseBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseBackupList.
+func (in *HbaseBackupList) DeepCopy() *HbaseBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseBackupLocation) DeepCopyInto(out *HbaseBackupLocation) {
+	*out = *in
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]HbaseClusterVolume, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]HbaseClusterVolumeMount, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseBackupLocation.
+func (in *HbaseBackupLocation) DeepCopy() *HbaseBackupLocation {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseBackupLocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseBackupSpec) DeepCopyInto(out *HbaseBackupSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	in.Target.DeepCopyInto(&out.Target)
+	in.Job.DeepCopyInto(&out.Job)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseBackupSpec.
+func (in *HbaseBackupSpec) DeepCopy() *HbaseBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HbaseCluster) DeepCopyInto(out *HbaseCluster) {
 	*out = *in
@@ -79,6 +229,60 @@ func (in *HbaseCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterBootstrapStatus) DeepCopyInto(out *HbaseClusterBootstrapStatus) {
+	*out = *in
+	if in.CompletedPhases != nil {
+		in, out := &in.CompletedPhases, &out.CompletedPhases
+		*out = make([]HbaseBootstrapPhase, len(*in))
+		copy(*out, *in)
+	}
+	if in.SkippedPhases != nil {
+		in, out := &in.SkippedPhases, &out.SkippedPhases
+		*out = make([]HbaseBootstrapPhase, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterBootstrapStatus.
+func (in *HbaseClusterBootstrapStatus) DeepCopy() *HbaseClusterBootstrapStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterBootstrapStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterConfigOverlay) DeepCopyInto(out *HbaseClusterConfigOverlay) {
+	*out = *in
+	if in.HbaseConfig != nil {
+		in, out := &in.HbaseConfig, &out.HbaseConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HadoopConfig != nil {
+		in, out := &in.HadoopConfig, &out.HadoopConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterConfigOverlay.
+func (in *HbaseClusterConfigOverlay) DeepCopy() *HbaseClusterConfigOverlay {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterConfigOverlay)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HbaseClusterConfiguration) DeepCopyInto(out *HbaseClusterConfiguration) {
 	*out = *in
@@ -98,28 +302,16 @@ func (in *HbaseClusterConfiguration) DeepCopyInto(out *HbaseClusterConfiguration
 	}
 	if in.HbaseTenantConfig != nil {
 		in, out := &in.HbaseTenantConfig, &out.HbaseTenantConfig
-		*out = make([]map[string]string, len(*in))
+		*out = make([]HbaseTenantConfigOverride, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = make(map[string]string, len(*in))
-				for key, val := range *in {
-					(*out)[key] = val
-				}
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 	if in.HadoopTenantConfig != nil {
 		in, out := &in.HadoopTenantConfig, &out.HadoopTenantConfig
-		*out = make([]map[string]string, len(*in))
+		*out = make([]HbaseTenantConfigOverride, len(*in))
 		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = make(map[string]string, len(*in))
-				for key, val := range *in {
-					(*out)[key] = val
-				}
-			}
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
@@ -150,7 +342,9 @@ func (in *HbaseClusterContainer) DeepCopyInto(out *HbaseClusterContainer) {
 	if in.Ports != nil {
 		in, out := &in.Ports, &out.Ports
 		*out = make([]HbaseClusterContainerPort, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.VolumeMounts != nil {
 		in, out := &in.VolumeMounts, &out.VolumeMounts
@@ -177,6 +371,11 @@ func (in *HbaseClusterContainer) DeepCopy() *HbaseClusterContainer {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HbaseClusterContainerPort) DeepCopyInto(out *HbaseClusterContainerPort) {
 	*out = *in
+	if in.AppProtocol != nil {
+		in, out := &in.AppProtocol, &out.AppProtocol
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterContainerPort.
@@ -239,6 +438,11 @@ func (in *HbaseClusterDeployment) DeepCopyInto(out *HbaseClusterDeployment) {
 		*out = make([]HbaseClusterVolume, len(*in))
 		copy(*out, *in)
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(HbaseClusterServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DNSConfig != nil {
 		in, out := &in.DNSConfig, &out.DNSConfig
 		*out = new(corev1.PodDNSConfig)
@@ -263,6 +467,11 @@ func (in *HbaseClusterDeployment) DeepCopyInto(out *HbaseClusterDeployment) {
 		*out = new(HBasePodDisruptionBudget)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = new(HbaseClusterConfigOverlay)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterDeployment.
@@ -295,6 +504,43 @@ func (in *HbaseClusterDeployments) DeepCopy() *HbaseClusterDeployments {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterExternalAccess) DeepCopyInto(out *HbaseClusterExternalAccess) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterExternalAccess.
+func (in *HbaseClusterExternalAccess) DeepCopy() *HbaseClusterExternalAccess {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterExternalAccess)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterHmasterHA) DeepCopyInto(out *HbaseClusterHmasterHA) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterHmasterHA.
+func (in *HbaseClusterHmasterHA) DeepCopy() *HbaseClusterHmasterHA {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterHmasterHA)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HbaseClusterInitContainer) DeepCopyInto(out *HbaseClusterInitContainer) {
 	*out = *in
@@ -384,87 +630,229 @@ func (in *HbaseClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseClusterProbe) DeepCopyInto(out *HbaseClusterProbe) {
+func (in *HbaseClusterMonitoring) DeepCopyInto(out *HbaseClusterMonitoring) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make(map[string]HbaseComponentMonitoring, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterProbe.
-func (in *HbaseClusterProbe) DeepCopy() *HbaseClusterProbe {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterMonitoring.
+func (in *HbaseClusterMonitoring) DeepCopy() *HbaseClusterMonitoring {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseClusterProbe)
+	out := new(HbaseClusterMonitoring)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseClusterSecurity) DeepCopyInto(out *HbaseClusterSecurity) {
+func (in *HbaseClusterNamenodeHA) DeepCopyInto(out *HbaseClusterNamenodeHA) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterSecurity.
-func (in *HbaseClusterSecurity) DeepCopy() *HbaseClusterSecurity {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterNamenodeHA.
+func (in *HbaseClusterNamenodeHA) DeepCopy() *HbaseClusterNamenodeHA {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseClusterSecurity)
+	out := new(HbaseClusterNamenodeHA)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseClusterSideCarContainer) DeepCopyInto(out *HbaseClusterSideCarContainer) {
+func (in *HbaseClusterNetworkPolicy) DeepCopyInto(out *HbaseClusterNetworkPolicy) {
 	*out = *in
-	if in.Command != nil {
-		in, out := &in.Command, &out.Command
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.ClientNamespaceSelector != nil {
+		in, out := &in.ClientNamespaceSelector, &out.ClientNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.Args != nil {
-		in, out := &in.Args, &out.Args
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.ClientPodSelector != nil {
+		in, out := &in.ClientPodSelector, &out.ClientPodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
-	out.SecurityContext = in.SecurityContext
-	if in.VolumeMounts != nil {
-		in, out := &in.VolumeMounts, &out.VolumeMounts
-		*out = make([]HbaseClusterVolumeMount, len(*in))
-		copy(*out, *in)
+	if in.MetricsNamespaceSelector != nil {
+		in, out := &in.MetricsNamespaceSelector, &out.MetricsNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterSideCarContainer.
-func (in *HbaseClusterSideCarContainer) DeepCopy() *HbaseClusterSideCarContainer {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterNetworkPolicy.
+func (in *HbaseClusterNetworkPolicy) DeepCopy() *HbaseClusterNetworkPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseClusterSideCarContainer)
+	out := new(HbaseClusterNetworkPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseClusterSpec) DeepCopyInto(out *HbaseClusterSpec) {
+func (in *HbaseClusterProbe) DeepCopyInto(out *HbaseClusterProbe) {
 	*out = *in
-	in.Deployments.DeepCopyInto(&out.Deployments)
-	in.Configuration.DeepCopyInto(&out.Configuration)
-	if in.TenantNamespaces != nil {
-		in, out := &in.TenantNamespaces, &out.TenantNamespaces
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.ServiceLabels != nil {
-		in, out := &in.ServiceLabels, &out.ServiceLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterProbe.
+func (in *HbaseClusterProbe) DeepCopy() *HbaseClusterProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterRackAwareness) DeepCopyInto(out *HbaseClusterRackAwareness) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterRackAwareness.
+func (in *HbaseClusterRackAwareness) DeepCopy() *HbaseClusterRackAwareness {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterRackAwareness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterReference) DeepCopyInto(out *HbaseClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterReference.
+func (in *HbaseClusterReference) DeepCopy() *HbaseClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterSecurity) DeepCopyInto(out *HbaseClusterSecurity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterSecurity.
+func (in *HbaseClusterSecurity) DeepCopy() *HbaseClusterSecurity {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterSecurity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterServiceSpec) DeepCopyInto(out *HbaseClusterServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.PublishNotReadyAddresses != nil {
+		in, out := &in.PublishNotReadyAddresses, &out.PublishNotReadyAddresses
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InternalTrafficPolicy != nil {
+		in, out := &in.InternalTrafficPolicy, &out.InternalTrafficPolicy
+		*out = new(corev1.ServiceInternalTrafficPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterServiceSpec.
+func (in *HbaseClusterServiceSpec) DeepCopy() *HbaseClusterServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterSideCarContainer) DeepCopyInto(out *HbaseClusterSideCarContainer) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.SecurityContext = in.SecurityContext
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]HbaseClusterVolumeMount, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterSideCarContainer.
+func (in *HbaseClusterSideCarContainer) DeepCopy() *HbaseClusterSideCarContainer {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseClusterSideCarContainer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseClusterSpec) DeepCopyInto(out *HbaseClusterSpec) {
+	*out = *in
+	in.Deployments.DeepCopyInto(&out.Deployments)
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.TenantNamespaces != nil {
+		in, out := &in.TenantNamespaces, &out.TenantNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 	if in.ServiceSelectorLabels != nil {
 		in, out := &in.ServiceSelectorLabels, &out.ServiceSelectorLabels
@@ -473,6 +861,56 @@ func (in *HbaseClusterSpec) DeepCopyInto(out *HbaseClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(HbaseClusterServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(HbaseClusterMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalZookeeper != nil {
+		in, out := &in.ExternalZookeeper, &out.ExternalZookeeper
+		*out = new(HbaseExternalZookeeper)
+		**out = **in
+	}
+	if in.ExternalHDFS != nil {
+		in, out := &in.ExternalHDFS, &out.ExternalHDFS
+		*out = new(HbaseExternalHDFS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ZookeeperEnsemble != nil {
+		in, out := &in.ZookeeperEnsemble, &out.ZookeeperEnsemble
+		*out = new(HbaseClusterZookeeper)
+		**out = **in
+	}
+	if in.NamenodeHA != nil {
+		in, out := &in.NamenodeHA, &out.NamenodeHA
+		*out = new(HbaseClusterNamenodeHA)
+		**out = **in
+	}
+	if in.HmasterHA != nil {
+		in, out := &in.HmasterHA, &out.HmasterHA
+		*out = new(HbaseClusterHmasterHA)
+		**out = **in
+	}
+	if in.RackAwareness != nil {
+		in, out := &in.RackAwareness, &out.RackAwareness
+		*out = new(HbaseClusterRackAwareness)
+		**out = **in
+	}
+	if in.ExternalAccess != nil {
+		in, out := &in.ExternalAccess, &out.ExternalAccess
+		*out = new(HbaseClusterExternalAccess)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		*out = new(HbaseClusterNetworkPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterSpec.
@@ -500,6 +938,53 @@ func (in *HbaseClusterStatus) DeepCopyInto(out *HbaseClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastConfigChange != nil {
+		in, out := &in.LastConfigChange, &out.LastConfigChange
+		*out = new(ConfigChangeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantConfigOverrides != nil {
+		in, out := &in.TenantConfigOverrides, &out.TenantConfigOverrides
+		*out = make([]TenantConfigOverrideStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Zookeeper != nil {
+		in, out := &in.Zookeeper, &out.Zookeeper
+		*out = new(ZookeeperEnsembleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Namenodes != nil {
+		in, out := &in.Namenodes, &out.Namenodes
+		*out = make([]NamenodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.BackupHmasters != nil {
+		in, out := &in.BackupHmasters, &out.BackupHmasters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(HbaseClusterBootstrapStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalEndpoints != nil {
+		in, out := &in.ExternalEndpoints, &out.ExternalEndpoints
+		*out = make([]ExternalEndpointStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkPolicies != nil {
+		in, out := &in.NetworkPolicies, &out.NetworkPolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterStatus.
@@ -572,37 +1057,35 @@ func (in *HbaseClusterVolumeMount) DeepCopy() *HbaseClusterVolumeMount {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseStandalone) DeepCopyInto(out *HbaseStandalone) {
+func (in *HbaseClusterZookeeper) DeepCopyInto(out *HbaseClusterZookeeper) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandalone.
-func (in *HbaseStandalone) DeepCopy() *HbaseStandalone {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseClusterZookeeper.
+func (in *HbaseClusterZookeeper) DeepCopy() *HbaseClusterZookeeper {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseStandalone)
+	out := new(HbaseClusterZookeeper)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HbaseStandalone) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseStandaloneConfiguration) DeepCopyInto(out *HbaseStandaloneConfiguration) {
+func (in *HbaseColumnFamily) DeepCopyInto(out *HbaseColumnFamily) {
 	*out = *in
-	if in.HbaseConfig != nil {
-		in, out := &in.HbaseConfig, &out.HbaseConfig
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxVersions != nil {
+		in, out := &in.MaxVersions, &out.MaxVersions
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -610,108 +1093,68 @@ func (in *HbaseStandaloneConfiguration) DeepCopyInto(out *HbaseStandaloneConfigu
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneConfiguration.
-func (in *HbaseStandaloneConfiguration) DeepCopy() *HbaseStandaloneConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseColumnFamily.
+func (in *HbaseColumnFamily) DeepCopy() *HbaseColumnFamily {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseStandaloneConfiguration)
+	out := new(HbaseColumnFamily)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseStandaloneList) DeepCopyInto(out *HbaseStandaloneList) {
+func (in *HbaseComponentMonitoring) DeepCopyInto(out *HbaseComponentMonitoring) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]HbaseStandalone, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneList.
-func (in *HbaseStandaloneList) DeepCopy() *HbaseStandaloneList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseComponentMonitoring.
+func (in *HbaseComponentMonitoring) DeepCopy() *HbaseComponentMonitoring {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseStandaloneList)
+	out := new(HbaseComponentMonitoring)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HbaseStandaloneList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseStandaloneSpec) DeepCopyInto(out *HbaseStandaloneSpec) {
+func (in *HbaseExternalHDFS) DeepCopyInto(out *HbaseExternalHDFS) {
 	*out = *in
-	in.Standalone.DeepCopyInto(&out.Standalone)
-	in.Configuration.DeepCopyInto(&out.Configuration)
-	if in.ServiceLabels != nil {
-		in, out := &in.ServiceLabels, &out.ServiceLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.ServiceSelectorLabels != nil {
-		in, out := &in.ServiceSelectorLabels, &out.ServiceSelectorLabels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.ConfigSource != nil {
+		in, out := &in.ConfigSource, &out.ConfigSource
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneSpec.
-func (in *HbaseStandaloneSpec) DeepCopy() *HbaseStandaloneSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseExternalHDFS.
+func (in *HbaseExternalHDFS) DeepCopy() *HbaseExternalHDFS {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseStandaloneSpec)
+	out := new(HbaseExternalHDFS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseStandaloneStatus) DeepCopyInto(out *HbaseStandaloneStatus) {
+func (in *HbaseExternalZookeeper) DeepCopyInto(out *HbaseExternalZookeeper) {
 	*out = *in
-	if in.Nodes != nil {
-		in, out := &in.Nodes, &out.Nodes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneStatus.
-func (in *HbaseStandaloneStatus) DeepCopy() *HbaseStandaloneStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseExternalZookeeper.
+func (in *HbaseExternalZookeeper) DeepCopy() *HbaseExternalZookeeper {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseStandaloneStatus)
+	out := new(HbaseExternalZookeeper)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseTenant) DeepCopyInto(out *HbaseTenant) {
+func (in *HbaseNamespace) DeepCopyInto(out *HbaseNamespace) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -719,18 +1162,18 @@ func (in *HbaseTenant) DeepCopyInto(out *HbaseTenant) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenant.
-func (in *HbaseTenant) DeepCopy() *HbaseTenant {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseNamespace.
+func (in *HbaseNamespace) DeepCopy() *HbaseNamespace {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseTenant)
+	out := new(HbaseNamespace)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HbaseTenant) DeepCopyObject() runtime.Object {
+func (in *HbaseNamespace) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -738,31 +1181,31 @@ func (in *HbaseTenant) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseTenantList) DeepCopyInto(out *HbaseTenantList) {
+func (in *HbaseNamespaceList) DeepCopyInto(out *HbaseNamespaceList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]HbaseTenant, len(*in))
+		*out = make([]HbaseNamespace, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantList.
-func (in *HbaseTenantList) DeepCopy() *HbaseTenantList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseNamespaceList.
+func (in *HbaseNamespaceList) DeepCopy() *HbaseNamespaceList {
 	if in == nil {
 		return nil
 	}
-	out := new(HbaseTenantList)
+	out := new(HbaseNamespaceList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HbaseTenantList) DeepCopyObject() runtime.Object {
+func (in *HbaseNamespaceList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -770,12 +1213,1072 @@ func (in *HbaseTenantList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HbaseTenantSpec) DeepCopyInto(out *HbaseTenantSpec) {
+func (in *HbaseNamespaceSpec) DeepCopyInto(out *HbaseNamespaceSpec) {
 	*out = *in
-	in.Datanode.DeepCopyInto(&out.Datanode)
-	in.Configuration.DeepCopyInto(&out.Configuration)
-	if in.ServiceLabels != nil {
-		in, out := &in.ServiceLabels, &out.ServiceLabels
+	out.ClusterRef = in.ClusterRef
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseNamespaceSpec.
+func (in *HbaseNamespaceSpec) DeepCopy() *HbaseNamespaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseNamespaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseNamespaceStatus) DeepCopyInto(out *HbaseNamespaceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseNamespaceStatus.
+func (in *HbaseNamespaceStatus) DeepCopy() *HbaseNamespaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseNamespaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseReplicationPeer) DeepCopyInto(out *HbaseReplicationPeer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseReplicationPeer.
+func (in *HbaseReplicationPeer) DeepCopy() *HbaseReplicationPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseReplicationPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseReplicationPeer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseReplicationPeerList) DeepCopyInto(out *HbaseReplicationPeerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseReplicationPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseReplicationPeerList.
+func (in *HbaseReplicationPeerList) DeepCopy() *HbaseReplicationPeerList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseReplicationPeerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseReplicationPeerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseReplicationPeerSpec) DeepCopyInto(out *HbaseReplicationPeerSpec) {
+	*out = *in
+	out.SourceClusterRef = in.SourceClusterRef
+	if in.TargetClusterRef != nil {
+		in, out := &in.TargetClusterRef, &out.TargetClusterRef
+		*out = new(HbaseSchemaClusterReference)
+		**out = **in
+	}
+	if in.TableCFs != nil {
+		in, out := &in.TableCFs, &out.TableCFs
+		*out = make([]HbaseReplicationTableCFs, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseReplicationPeerSpec.
+func (in *HbaseReplicationPeerSpec) DeepCopy() *HbaseReplicationPeerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseReplicationPeerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseReplicationPeerStatus) DeepCopyInto(out *HbaseReplicationPeerStatus) {
+	*out = *in
+	if in.ReplicationLag != nil {
+		in, out := &in.ReplicationLag, &out.ReplicationLag
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseReplicationPeerStatus.
+func (in *HbaseReplicationPeerStatus) DeepCopy() *HbaseReplicationPeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseReplicationPeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseReplicationTableCFs) DeepCopyInto(out *HbaseReplicationTableCFs) {
+	*out = *in
+	if in.ColumnFamilies != nil {
+		in, out := &in.ColumnFamilies, &out.ColumnFamilies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseReplicationTableCFs.
+func (in *HbaseReplicationTableCFs) DeepCopy() *HbaseReplicationTableCFs {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseReplicationTableCFs)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseRestore) DeepCopyInto(out *HbaseRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseRestore.
+func (in *HbaseRestore) DeepCopy() *HbaseRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseRestoreList) DeepCopyInto(out *HbaseRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseRestoreList.
+func (in *HbaseRestoreList) DeepCopy() *HbaseRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseRestoreSpec) DeepCopyInto(out *HbaseRestoreSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(HbaseBackupLocation)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Job.DeepCopyInto(&out.Job)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseRestoreSpec.
+func (in *HbaseRestoreSpec) DeepCopy() *HbaseRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSchemaClusterReference) DeepCopyInto(out *HbaseSchemaClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSchemaClusterReference.
+func (in *HbaseSchemaClusterReference) DeepCopy() *HbaseSchemaClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSchemaClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotJobSpec) DeepCopyInto(out *HbaseSnapshotJobSpec) {
+	*out = *in
+	if in.Mappers != nil {
+		in, out := &in.Mappers, &out.Mappers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Bandwidth != nil {
+		in, out := &in.Bandwidth, &out.Bandwidth
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotJobSpec.
+func (in *HbaseSnapshotJobSpec) DeepCopy() *HbaseSnapshotJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotJobStatus) DeepCopyInto(out *HbaseSnapshotJobStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotJobStatus.
+func (in *HbaseSnapshotJobStatus) DeepCopy() *HbaseSnapshotJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotRetention) DeepCopyInto(out *HbaseSnapshotRetention) {
+	*out = *in
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotRetention.
+func (in *HbaseSnapshotRetention) DeepCopy() *HbaseSnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotSchedule) DeepCopyInto(out *HbaseSnapshotSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotSchedule.
+func (in *HbaseSnapshotSchedule) DeepCopy() *HbaseSnapshotSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseSnapshotSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotScheduleList) DeepCopyInto(out *HbaseSnapshotScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseSnapshotSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotScheduleList.
+func (in *HbaseSnapshotScheduleList) DeepCopy() *HbaseSnapshotScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseSnapshotScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotScheduleSpec) DeepCopyInto(out *HbaseSnapshotScheduleSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	in.TableSelector.DeepCopyInto(&out.TableSelector)
+	in.Retention.DeepCopyInto(&out.Retention)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotScheduleSpec.
+func (in *HbaseSnapshotScheduleSpec) DeepCopy() *HbaseSnapshotScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotScheduleStatus) DeepCopyInto(out *HbaseSnapshotScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSnapshots != nil {
+		in, out := &in.LastSnapshots, &out.LastSnapshots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotScheduleStatus.
+func (in *HbaseSnapshotScheduleStatus) DeepCopy() *HbaseSnapshotScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSnapshotTableSelector) DeepCopyInto(out *HbaseSnapshotTableSelector) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HbaseTableSelector != nil {
+		in, out := &in.HbaseTableSelector, &out.HbaseTableSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSnapshotTableSelector.
+func (in *HbaseSnapshotTableSelector) DeepCopy() *HbaseSnapshotTableSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSnapshotTableSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseSpaceQuota) DeepCopyInto(out *HbaseSpaceQuota) {
+	*out = *in
+	out.Limit = in.Limit.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseSpaceQuota.
+func (in *HbaseSpaceQuota) DeepCopy() *HbaseSpaceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseSpaceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseStandalone) DeepCopyInto(out *HbaseStandalone) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandalone.
+func (in *HbaseStandalone) DeepCopy() *HbaseStandalone {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseStandalone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseStandalone) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseStandaloneConfiguration) DeepCopyInto(out *HbaseStandaloneConfiguration) {
+	*out = *in
+	if in.HbaseConfig != nil {
+		in, out := &in.HbaseConfig, &out.HbaseConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneConfiguration.
+func (in *HbaseStandaloneConfiguration) DeepCopy() *HbaseStandaloneConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseStandaloneConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseStandaloneList) DeepCopyInto(out *HbaseStandaloneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseStandalone, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneList.
+func (in *HbaseStandaloneList) DeepCopy() *HbaseStandaloneList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseStandaloneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseStandaloneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseStandaloneSpec) DeepCopyInto(out *HbaseStandaloneSpec) {
+	*out = *in
+	in.Standalone.DeepCopyInto(&out.Standalone)
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceSelectorLabels != nil {
+		in, out := &in.ServiceSelectorLabels, &out.ServiceSelectorLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(HbaseClusterServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(HbaseClusterMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneSpec.
+func (in *HbaseStandaloneSpec) DeepCopy() *HbaseStandaloneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseStandaloneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseStandaloneStatus) DeepCopyInto(out *HbaseStandaloneStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastConfigChange != nil {
+		in, out := &in.LastConfigChange, &out.LastConfigChange
+		*out = new(ConfigChangeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantConfigOverrides != nil {
+		in, out := &in.TenantConfigOverrides, &out.TenantConfigOverrides
+		*out = make([]TenantConfigOverrideStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseStandaloneStatus.
+func (in *HbaseStandaloneStatus) DeepCopy() *HbaseStandaloneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseStandaloneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTable) DeepCopyInto(out *HbaseTable) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTable.
+func (in *HbaseTable) DeepCopy() *HbaseTable {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTable) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTableList) DeepCopyInto(out *HbaseTableList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseTable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTableList.
+func (in *HbaseTableList) DeepCopy() *HbaseTableList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTableList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTableList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTableSpec) DeepCopyInto(out *HbaseTableSpec) {
+	*out = *in
+	out.ClusterRef = in.ClusterRef
+	if in.ColumnFamilies != nil {
+		in, out := &in.ColumnFamilies, &out.ColumnFamilies
+		*out = make([]HbaseColumnFamily, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SplitKeys != nil {
+		in, out := &in.SplitKeys, &out.SplitKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTableSpec.
+func (in *HbaseTableSpec) DeepCopy() *HbaseTableSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTableSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTableStatus) DeepCopyInto(out *HbaseTableStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTableStatus.
+func (in *HbaseTableStatus) DeepCopy() *HbaseTableStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTableStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenant) DeepCopyInto(out *HbaseTenant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenant.
+func (in *HbaseTenant) DeepCopy() *HbaseTenant {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTenant) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantConfigOverride) DeepCopyInto(out *HbaseTenantConfigOverride) {
+	*out = *in
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantConfigOverride.
+func (in *HbaseTenantConfigOverride) DeepCopy() *HbaseTenantConfigOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantConfigOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantList) DeepCopyInto(out *HbaseTenantList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseTenant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantList.
+func (in *HbaseTenantList) DeepCopy() *HbaseTenantList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTenantList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantPolicy) DeepCopyInto(out *HbaseTenantPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantPolicy.
+func (in *HbaseTenantPolicy) DeepCopy() *HbaseTenantPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTenantPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantPolicyList) DeepCopyInto(out *HbaseTenantPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HbaseTenantPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantPolicyList.
+func (in *HbaseTenantPolicyList) DeepCopy() *HbaseTenantPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HbaseTenantPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantPolicySpec) DeepCopyInto(out *HbaseTenantPolicySpec) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxRegionServers != nil {
+		in, out := &in.MaxRegionServers, &out.MaxRegionServers
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxPodResources != nil {
+		in, out := &in.MaxPodResources, &out.MaxPodResources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.AllowedBaseImages != nil {
+		in, out := &in.AllowedBaseImages, &out.AllowedBaseImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedVolumeSources != nil {
+		in, out := &in.AllowedVolumeSources, &out.AllowedVolumeSources
+		*out = make([]TenantVolumeSource, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantPolicySpec.
+func (in *HbaseTenantPolicySpec) DeepCopy() *HbaseTenantPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantPolicyStatus) DeepCopyInto(out *HbaseTenantPolicyStatus) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tenants != nil {
+		in, out := &in.Tenants, &out.Tenants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantPolicyStatus.
+func (in *HbaseTenantPolicyStatus) DeepCopy() *HbaseTenantPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantQuotas) DeepCopyInto(out *HbaseTenantQuotas) {
+	*out = *in
+	if in.Throttles != nil {
+		in, out := &in.Throttles, &out.Throttles
+		*out = make([]HbaseThrottleQuota, len(*in))
+		copy(*out, *in)
+	}
+	if in.Space != nil {
+		in, out := &in.Space, &out.Space
+		*out = make([]HbaseSpaceQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SyncInterval != nil {
+		in, out := &in.SyncInterval, &out.SyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantQuotas.
+func (in *HbaseTenantQuotas) DeepCopy() *HbaseTenantQuotas {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantQuotas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantRSGroup) DeepCopyInto(out *HbaseTenantRSGroup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantRSGroup.
+func (in *HbaseTenantRSGroup) DeepCopy() *HbaseTenantRSGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseTenantRSGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseTenantSpec) DeepCopyInto(out *HbaseTenantSpec) {
+	*out = *in
+	in.Datanode.DeepCopyInto(&out.Datanode)
+	if in.ClusterRef != nil {
+		in, out := &in.ClusterRef, &out.ClusterRef
+		*out = new(HbaseClusterReference)
+		**out = **in
+	}
+	in.Configuration.DeepCopyInto(&out.Configuration)
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
@@ -788,6 +2291,31 @@ func (in *HbaseTenantSpec) DeepCopyInto(out *HbaseTenantSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(HbaseClusterServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RSGroup != nil {
+		in, out := &in.RSGroup, &out.RSGroup
+		*out = new(HbaseTenantRSGroup)
+		**out = **in
+	}
+	if in.HbaseNamespaces != nil {
+		in, out := &in.HbaseNamespaces, &out.HbaseNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(HbaseTenantQuotas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(HbaseClusterMonitoring)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantSpec.
@@ -815,6 +2343,28 @@ func (in *HbaseTenantStatus) DeepCopyInto(out *HbaseTenantStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastConfigChange != nil {
+		in, out := &in.LastConfigChange, &out.LastConfigChange
+		*out = new(ConfigChangeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TenantConfigOverrides != nil {
+		in, out := &in.TenantConfigOverrides, &out.TenantConfigOverrides
+		*out = make([]TenantConfigOverrideStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RSGroup != nil {
+		in, out := &in.RSGroup, &out.RSGroup
+		*out = new(RSGroupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quotas != nil {
+		in, out := &in.Quotas, &out.Quotas
+		*out = new(QuotaStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseTenantStatus.
@@ -826,3 +2376,148 @@ func (in *HbaseTenantStatus) DeepCopy() *HbaseTenantStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HbaseThrottleQuota) DeepCopyInto(out *HbaseThrottleQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HbaseThrottleQuota.
+func (in *HbaseThrottleQuota) DeepCopy() *HbaseThrottleQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(HbaseThrottleQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamenodeStatus) DeepCopyInto(out *NamenodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamenodeStatus.
+func (in *NamenodeStatus) DeepCopy() *NamenodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NamenodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaStatus) DeepCopyInto(out *QuotaStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SpaceUsage != nil {
+		in, out := &in.SpaceUsage, &out.SpaceUsage
+		*out = make([]SpaceQuotaUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaStatus.
+func (in *QuotaStatus) DeepCopy() *QuotaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RSGroupStatus) DeepCopyInto(out *RSGroupStatus) {
+	*out = *in
+	if in.Servers != nil {
+		in, out := &in.Servers, &out.Servers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RSGroupStatus.
+func (in *RSGroupStatus) DeepCopy() *RSGroupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RSGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpaceQuotaUsage) DeepCopyInto(out *SpaceQuotaUsage) {
+	*out = *in
+	out.Usage = in.Usage.DeepCopy()
+	out.Limit = in.Limit.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpaceQuotaUsage.
+func (in *SpaceQuotaUsage) DeepCopy() *SpaceQuotaUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(SpaceQuotaUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TenantConfigOverrideStatus) DeepCopyInto(out *TenantConfigOverrideStatus) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TenantConfigOverrideStatus.
+func (in *TenantConfigOverrideStatus) DeepCopy() *TenantConfigOverrideStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TenantConfigOverrideStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZookeeperEnsembleStatus) DeepCopyInto(out *ZookeeperEnsembleStatus) {
+	*out = *in
+	if in.Followers != nil {
+		in, out := &in.Followers, &out.Followers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Unreachable != nil {
+		in, out := &in.Unreachable, &out.Unreachable
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZookeeperEnsembleStatus.
+func (in *ZookeeperEnsembleStatus) DeepCopy() *ZookeeperEnsembleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ZookeeperEnsembleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
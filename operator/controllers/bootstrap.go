@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	time "time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// bootstrapPhases phases of the bootstrap of a cluster, in order
+var bootstrapPhases = []kvstorev1.HbaseBootstrapPhase{
+	kvstorev1.BootstrapZKFormatted,
+	kvstorev1.BootstrapJNReady,
+	kvstorev1.BootstrapNNFormatted,
+	kvstorev1.BootstrapStandbyBootstrapped,
+	kvstorev1.BootstrapHBaseRootCreated,
+	kvstorev1.BootstrapReady,
+}
+
+// isBootstrapping tells whether bootstrap init containers are rendered. They never are once the bootstrap is Ready
+func isBootstrapping(c *kvstorev1.HbaseCluster) bool {
+	return c.Spec.IsBootstrap && (c.Status.Bootstrap == nil || c.Status.Bootstrap.Phase != kvstorev1.BootstrapReady)
+}
+
+// isBootstrapPhaseCompleted tells whether the phase is in the completed phases of the status
+func isBootstrapPhaseCompleted(c *kvstorev1.HbaseCluster, phase kvstorev1.HbaseBootstrapPhase) bool {
+	return c.Status.Bootstrap != nil && containsBootstrapPhase(c.Status.Bootstrap.CompletedPhases, phase)
+}
+
+// isBootstrapPhaseSkipped tells whether the phase is in the skipped phases of the status
+func isBootstrapPhaseSkipped(c *kvstorev1.HbaseCluster, phase kvstorev1.HbaseBootstrapPhase) bool {
+	return c.Status.Bootstrap != nil && containsBootstrapPhase(c.Status.Bootstrap.SkippedPhases, phase)
+}
+
+func containsBootstrapPhase(phases []kvstorev1.HbaseBootstrapPhase, phase kvstorev1.HbaseBootstrapPhase) bool {
+	for _, p := range phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// bootstrapInitContainers returns the init containers of the deployment, without the ones of completed phases
+func bootstrapInitContainers(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) []kvstorev1.HbaseClusterInitContainer {
+	containers := []kvstorev1.HbaseClusterInitContainer{}
+	for _, ic := range d.InitContainers {
+		if len(ic.BootstrapPhase) == 0 || !isBootstrapPhaseCompleted(c, ic.BootstrapPhase) {
+			containers = append(containers, ic)
+		}
+	}
+	return containers
+}
+
+// bootstrapPhaseContainers returns the names of the init containers of the deployment performing the phase
+func bootstrapPhaseContainers(d kvstorev1.HbaseClusterDeployment, phase kvstorev1.HbaseBootstrapPhase) []string {
+	names := []string{}
+	for _, ic := range d.InitContainers {
+		if ic.BootstrapPhase == phase {
+			names = append(names, ic.Name)
+		}
+	}
+	return names
+}
+
+// isInitContainerCompleted tells whether the init container ran to completion in the pod
+func isInitContainerCompleted(pod *corev1.Pod, name string) bool {
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.Name == name && status.State.Terminated != nil && status.State.Terminated.ExitCode == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isBootstrapPhasePerformed tells whether an init container of the deployments performs the phase, or journalnodes
+// are deployed for JNReady
+func isBootstrapPhasePerformed(c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment, phase kvstorev1.HbaseBootstrapPhase) bool {
+	if phase == kvstorev1.BootstrapJNReady {
+		return c.Spec.ExternalHDFS == nil && c.Spec.Deployments.Journalnode.Size > 0
+	}
+	for _, d := range deployments {
+		if len(bootstrapPhaseContainers(d, phase)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isBootstrapPhaseDone tells whether the init containers of the phase completed in one of the pods of their deployment,
+// or all journalnodes are ready for JNReady. The phase is expected to be performed
+func isBootstrapPhaseDone(ctx context.Context, c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment,
+	phase kvstorev1.HbaseBootstrapPhase, cl client.Client) (bool, error) {
+	if phase == kvstorev1.BootstrapJNReady {
+		jn := c.Spec.Deployments.Journalnode
+		ss := &appsv1.StatefulSet{}
+		if err := cl.Get(ctx, types.NamespacedName{Name: jn.Name, Namespace: c.Namespace}, ss); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		return ss.Status.ReadyReplicas >= jn.Size, nil
+	}
+
+	for _, d := range deployments {
+		names := bootstrapPhaseContainers(d, phase)
+		if len(names) == 0 {
+			continue
+		}
+		done := false
+		for i := int32(0); i < d.Size && !done; i++ {
+			pod := &corev1.Pod{}
+			if err := cl.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("%s-%d", d.Name, i), Namespace: c.Namespace}, pod); err != nil {
+				if errors.IsNotFound(err) {
+					continue
+				}
+				return false, err
+			}
+			done = true
+			for _, name := range names {
+				done = done && isInitContainerCompleted(pod, name)
+			}
+		}
+		if !done {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reconcileBootstrap records the phases of the bootstrap completed since the last reconciliation, and the ones nothing
+// performs as skipped. Ready is completed separately, once the StatefulSets are ready
+func reconcileBootstrap(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment,
+	recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	if !isBootstrapping(c) {
+		return ctrl.Result{}, nil
+	}
+
+	completed := []kvstorev1.HbaseBootstrapPhase{}
+	skipped := []kvstorev1.HbaseBootstrapPhase{}
+	for _, phase := range bootstrapPhases[:len(bootstrapPhases)-1] {
+		if isBootstrapPhaseCompleted(c, phase) || isBootstrapPhaseSkipped(c, phase) {
+			continue
+		}
+		if !isBootstrapPhasePerformed(c, deployments, phase) {
+			skipped = append(skipped, phase)
+			continue
+		}
+		done, err := isBootstrapPhaseDone(ctx, c, deployments, phase, cl)
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		if done {
+			completed = append(completed, phase)
+		}
+	}
+	if len(completed) == 0 && len(skipped) == 0 && c.Status.Bootstrap != nil {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, completeBootstrapPhases(ctx, log, c, completed, skipped, recorder, cl)
+}
+
+// completeBootstrapPhases adds the phases to the completed and skipped phases of the status, and moves the bootstrap
+// to the last phase such that all the phases up to it are completed or skipped
+func completeBootstrapPhases(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, phases []kvstorev1.HbaseBootstrapPhase,
+	skipped []kvstorev1.HbaseBootstrapPhase, recorder record.EventRecorder, cl client.Client) error {
+	if c.Status.Bootstrap == nil {
+		c.Status.Bootstrap = &kvstorev1.HbaseClusterBootstrapStatus{}
+	}
+	bootstrap := c.Status.Bootstrap
+	bootstrap.CompletedPhases = append(bootstrap.CompletedPhases, phases...)
+	bootstrap.SkippedPhases = append(bootstrap.SkippedPhases, skipped...)
+	bootstrap.Phase = ""
+	for _, phase := range bootstrapPhases {
+		if !isBootstrapPhaseCompleted(c, phase) && !isBootstrapPhaseSkipped(c, phase) {
+			break
+		}
+		bootstrap.Phase = phase
+	}
+	if err := cl.Status().Update(ctx, c); err != nil {
+		log.Error(err, "Failed to update HbaseCluster status with the bootstrap phases", "Phases", phases, "Skipped", skipped)
+		return err
+	}
+	for _, phase := range skipped {
+		log.Info("Bootstrap phase skipped", "Phase", phase)
+		recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_BOOTSTRAP_PHASE_SKIPPED, "Bootstrap phase "+string(phase)+" skipped, nothing performs it")
+	}
+	for _, phase := range phases {
+		log.Info("Bootstrap phase completed", "Phase", phase)
+		recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_BOOTSTRAP_PHASE_COMPLETED, "Bootstrap phase "+string(phase)+" completed")
+	}
+	return nil
+}
+
+// completeBootstrap moves the bootstrap to Ready once all its other phases are completed or skipped and the StatefulSets
+// are ready
+func completeBootstrap(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, recorder record.EventRecorder, cl client.Client) error {
+	if !isBootstrapping(c) || c.Status.Bootstrap == nil || c.Status.Bootstrap.Phase != bootstrapPhases[len(bootstrapPhases)-2] {
+		return nil
+	}
+	return completeBootstrapPhases(ctx, log, c, []kvstorev1.HbaseBootstrapPhase{kvstorev1.BootstrapReady}, nil, recorder, cl)
+}
@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newBootstrapCluster() *kvstorev1.HbaseCluster {
+	initContainer := func(name string, phase kvstorev1.HbaseBootstrapPhase) kvstorev1.HbaseClusterInitContainer {
+		return kvstorev1.HbaseClusterInitContainer{Name: name, BootstrapPhase: phase, CpuLimit: "0.1", CpuRequest: "0.1", MemoryLimit: "128Mi", MemoryRequest: "128Mi"}
+	}
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			IsBootstrap: true,
+			Deployments: kvstorev1.HbaseClusterDeployments{
+				Journalnode: kvstorev1.HbaseClusterDeployment{Name: "jn", Size: 3},
+				Namenode: kvstorev1.HbaseClusterDeployment{Name: "nn", Size: 2, InitContainers: []kvstorev1.HbaseClusterInitContainer{
+					initContainer("init-dnslookup", ""),
+					initContainer("init-namenode", kvstorev1.BootstrapNNFormatted),
+					initContainer("init-zkfc", kvstorev1.BootstrapZKFormatted),
+					initContainer("init-standby", kvstorev1.BootstrapStandbyBootstrapped),
+				}},
+			},
+		},
+	}
+}
+
+// TestBootstrapInitContainers verifies init containers of completed phases are dropped, and bootstrap ones are not
+// rendered once the bootstrap is Ready.
+func TestBootstrapInitContainers(t *testing.T) {
+	c := newBootstrapCluster()
+	c.Status.Bootstrap = &kvstorev1.HbaseClusterBootstrapStatus{CompletedPhases: []kvstorev1.HbaseBootstrapPhase{kvstorev1.BootstrapNNFormatted}}
+	config := kvstorev1.HbaseClusterConfiguration{}
+
+	names := func(containers []corev1.Container) []string {
+		result := []string{}
+		for _, c := range containers {
+			result = append(result, c.Name)
+		}
+		return result
+	}
+	initContainers := bootstrapInitContainers(c, c.Spec.Deployments.Namenode)
+	assert.True(t, isBootstrapping(c))
+	assert.Equal(t, []string{"init-dnslookup", "init-zkfc", "init-standby"}, names(buildInitContainers("base", config, initContainers, isBootstrapping(c))))
+
+	c.Status.Bootstrap.Phase = kvstorev1.BootstrapReady
+	assert.False(t, isBootstrapping(c))
+	assert.Equal(t, []string{"init-dnslookup"}, names(buildInitContainers("base", config, initContainers, isBootstrapping(c))))
+}
+
+// TestReconcileBootstrap verifies phases are completed once their init containers completed in a pod, or the
+// journalnodes are ready, phases nothing performs skipped, and the bootstrap moved to the last phase completed or
+// skipped along with all the ones before it.
+func TestReconcileBootstrap(t *testing.T) {
+	ctx := context.TODO()
+	c := newBootstrapCluster()
+	deployments := []kvstorev1.HbaseClusterDeployment{c.Spec.Deployments.Journalnode, c.Spec.Deployments.Namenode}
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "jn", Namespace: testNamespace}, &appsv1.StatefulSet{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*appsv1.StatefulSet).Status.ReadyReplicas = 3
+		}).
+		Return(nil)
+	completed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 0}}
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "nn-0", Namespace: testNamespace}, &corev1.Pod{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*corev1.Pod).Status.InitContainerStatuses = []corev1.ContainerStatus{
+				{Name: "init-namenode", State: completed},
+				{Name: "init-zkfc", State: completed},
+				{Name: "init-standby", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1}}},
+			}
+		}).
+		Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "nn-1", Namespace: testNamespace}, &corev1.Pod{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, "nn-1"))
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := reconcileBootstrap(ctx, ctrl.Log.WithName("test"), c, deployments, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []kvstorev1.HbaseBootstrapPhase{kvstorev1.BootstrapZKFormatted, kvstorev1.BootstrapJNReady,
+		kvstorev1.BootstrapNNFormatted}, c.Status.Bootstrap.CompletedPhases)
+	assert.Equal(t, []kvstorev1.HbaseBootstrapPhase{kvstorev1.BootstrapHBaseRootCreated}, c.Status.Bootstrap.SkippedPhases)
+	assert.Equal(t, kvstorev1.BootstrapNNFormatted, c.Status.Bootstrap.Phase)
+	assert.Equal(t, []string{REASON_BOOTSTRAP_PHASE_SKIPPED, REASON_BOOTSTRAP_PHASE_COMPLETED, REASON_BOOTSTRAP_PHASE_COMPLETED,
+		REASON_BOOTSTRAP_PHASE_COMPLETED}, recordedReasons(recorder))
+
+	// Ready waits for the standby to be bootstrapped
+	assert.NoError(t, completeBootstrap(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient))
+	assert.True(t, isBootstrapping(c))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// TestReconcileBootstrap_NothingPerformed verifies phases are skipped rather than completed when no init container
+// performs them and journalnodes are not deployed.
+func TestReconcileBootstrap_NothingPerformed(t *testing.T) {
+	ctx := context.TODO()
+	c := newBootstrapCluster()
+	c.Spec.Deployments.Journalnode.Size = 0
+	c.Spec.Deployments.Namenode.InitContainers = c.Spec.Deployments.Namenode.InitContainers[:1]
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := reconcileBootstrap(ctx, ctrl.Log.WithName("test"), c, []kvstorev1.HbaseClusterDeployment{c.Spec.Deployments.Namenode}, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Status.Bootstrap.CompletedPhases)
+	assert.Equal(t, bootstrapPhases[:len(bootstrapPhases)-1], c.Status.Bootstrap.SkippedPhases)
+	assert.Equal(t, kvstorev1.BootstrapHBaseRootCreated, c.Status.Bootstrap.Phase)
+	reasons := recordedReasons(recorder)
+	assert.Len(t, reasons, 5)
+	for _, reason := range reasons {
+		assert.Equal(t, REASON_BOOTSTRAP_PHASE_SKIPPED, reason)
+	}
+
+	assert.NoError(t, completeBootstrap(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient))
+	assert.Equal(t, kvstorev1.BootstrapReady, c.Status.Bootstrap.Phase)
+	assert.Equal(t, []kvstorev1.HbaseBootstrapPhase{kvstorev1.BootstrapReady}, c.Status.Bootstrap.CompletedPhases)
+	k8sMockClient.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+}
+
+// TestCompleteBootstrap verifies the bootstrap is Ready once all other phases are completed, after which it is over.
+func TestCompleteBootstrap(t *testing.T) {
+	ctx := context.TODO()
+	c := newBootstrapCluster()
+	c.Status.Bootstrap = &kvstorev1.HbaseClusterBootstrapStatus{Phase: kvstorev1.BootstrapHBaseRootCreated,
+		CompletedPhases: append([]kvstorev1.HbaseBootstrapPhase{}, bootstrapPhases[:len(bootstrapPhases)-1]...)}
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	assert.NoError(t, completeBootstrap(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient))
+	assert.Equal(t, kvstorev1.BootstrapReady, c.Status.Bootstrap.Phase)
+	assert.False(t, isBootstrapping(c))
+	assert.Equal(t, []string{REASON_BOOTSTRAP_PHASE_COMPLETED}, recordedReasons(recorder))
+
+	// nothing is left to do afterwards
+	_, err := reconcileBootstrap(ctx, ctrl.Log.WithName("test"), c, nil, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+}
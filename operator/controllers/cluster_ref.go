@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	context "context"
+	sort "sort"
+	time "time"
+
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+	reconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// CONDITION_AVAILABLE HbaseCluster condition, true while all of its StatefulSets are ready
+const CONDITION_AVAILABLE = "Available"
+
+// CONDITION_CLUSTER_AVAILABLE HbaseTenant condition, true while the HbaseCluster it refers to is available
+const CONDITION_CLUSTER_AVAILABLE = "ClusterAvailable"
+
+// TENANT_CLUSTER_REF_INDEX field index of HbaseTenants by the HbaseCluster they refer to, as namespace/name
+const TENANT_CLUSTER_REF_INDEX = "spec.clusterRef"
+
+// clusterRefOf returns the HbaseCluster the tenant refers to, in the namespace of the tenant unless set otherwise
+func clusterRefOf(t *kvstorev1.HbaseTenant) types.NamespacedName {
+	ref := types.NamespacedName{Name: t.Spec.ClusterRef.Name, Namespace: t.Spec.ClusterRef.Namespace}
+	if len(ref.Namespace) == 0 {
+		ref.Namespace = t.Namespace
+	}
+	return ref
+}
+
+func indexTenantClusterRef(o client.Object) []string {
+	t := o.(*kvstorev1.HbaseTenant)
+	if t.Spec.ClusterRef == nil {
+		return nil
+	}
+	return []string{clusterRefOf(t).String()}
+}
+
+// clusterOfTenant maps an HbaseTenant to a reconcile request of the HbaseCluster it refers to
+func clusterOfTenant(ctx context.Context, o client.Object) []reconcile.Request {
+	t, ok := o.(*kvstorev1.HbaseTenant)
+	if !ok || t.Spec.ClusterRef == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: clusterRefOf(t)}}
+}
+
+// inheritClusterDefaults fills the fields of the tenant spec which are not set with the ones of the cluster
+func inheritClusterDefaults(t *kvstorev1.HbaseTenantSpec, c *kvstorev1.HbaseClusterSpec) {
+	if len(t.BaseImage) == 0 {
+		t.BaseImage = c.BaseImage
+	}
+	if t.FSGroup == 0 {
+		t.FSGroup = c.FSGroup
+	}
+
+	tc, cc := &t.Configuration, c.Configuration
+	if len(tc.HbaseConfigName) == 0 {
+		tc.HbaseConfigName = cc.HbaseConfigName
+	}
+	if len(tc.HbaseConfigMountPath) == 0 {
+		tc.HbaseConfigMountPath = cc.HbaseConfigMountPath
+	}
+	if len(tc.HadoopConfigName) == 0 {
+		tc.HadoopConfigName = cc.HadoopConfigName
+	}
+	if len(tc.HadoopConfigMountPath) == 0 {
+		tc.HadoopConfigMountPath = cc.HadoopConfigMountPath
+	}
+	if len(tc.AdminEndpoint) == 0 {
+		tc.AdminEndpoint = cc.AdminEndpoint
+	}
+	if len(tc.RestEndpoint) == 0 {
+		tc.RestEndpoint = cc.RestEndpoint
+	}
+	if tc.HbaseConfig == nil {
+		tc.HbaseConfig = cc.HbaseConfig
+	}
+	if tc.HadoopConfig == nil {
+		tc.HadoopConfig = cc.HadoopConfig
+	}
+	if tc.HbaseTenantConfig == nil {
+		tc.HbaseTenantConfig = cc.HbaseTenantConfig
+	}
+	if tc.HadoopTenantConfig == nil {
+		tc.HadoopTenantConfig = cc.HadoopTenantConfig
+	}
+}
+
+// reconcileClusterRef records the HbaseCluster the tenant refers to in its status, and inherits defaults of the
+// cluster once it is available. Until then, the tenant is requeued without reconciling anything else
+func reconcileClusterRef(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTenant, cl client.Client) (ctrl.Result, error) {
+	ref := clusterRefOf(t)
+	cluster := &kvstorev1.HbaseCluster{}
+	err := cl.Get(ctx, ref, cluster)
+
+	condition := metav1.Condition{Type: CONDITION_CLUSTER_AVAILABLE, ObservedGeneration: t.Generation}
+	switch {
+	case errors.IsNotFound(err):
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "ClusterNotFound", "HbaseCluster "+ref.String()+" not found"
+	case err != nil:
+		log.Error(err, "Failed to get HbaseCluster", "HbaseCluster", ref.String())
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	case !meta.IsStatusConditionTrue(cluster.Status.Conditions, CONDITION_AVAILABLE):
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, "ClusterNotAvailable", "Waiting for HbaseCluster "+ref.String()+" to be available"
+	default:
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionTrue, "ClusterAvailable", "HbaseCluster "+ref.String()+" is available"
+	}
+
+	changed := t.Status.Cluster != ref.String()
+	t.Status.Cluster = ref.String()
+	if meta.SetStatusCondition(&t.Status.Conditions, condition) || changed {
+		if err = cl.Status().Update(ctx, t); err != nil {
+			log.Error(err, "Failed to update HbaseTenant status")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+
+	if condition.Status != metav1.ConditionTrue {
+		log.Info("Waiting for parent HbaseCluster", "HbaseCluster", ref.String(), "Reason", condition.Reason)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	inheritClusterDefaults(&t.Spec, &cluster.Spec)
+	return ctrl.Result{}, nil
+}
+
+// listClusterTenants returns the HbaseTenants referring to the cluster
+func listClusterTenants(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, cl client.Client) ([]kvstorev1.HbaseTenant, error) {
+	tenants := &kvstorev1.HbaseTenantList{}
+	ref := types.NamespacedName{Name: c.Name, Namespace: c.Namespace}
+	if err := cl.List(ctx, tenants, client.MatchingFields{TENANT_CLUSTER_REF_INDEX: ref.String()}); err != nil {
+		log.Error(err, "Failed to list HbaseTenants referring to the cluster")
+		return nil, err
+	}
+	return tenants.Items, nil
+}
+
+// tenantNamespacesOf returns the namespaces ConfigMaps of the cluster are rendered in: the listed tenant namespaces,
+// the ones of the tenants referring to the cluster and finally the namespace of the cluster itself
+func tenantNamespacesOf(c *kvstorev1.HbaseCluster, tenants []kvstorev1.HbaseTenant) []string {
+	seen := map[string]bool{c.Namespace: true}
+	namespaces := []string{}
+	for _, ns := range c.Spec.TenantNamespaces {
+		if !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	for _, t := range tenants {
+		if !seen[t.Namespace] {
+			seen[t.Namespace] = true
+			namespaces = append(namespaces, t.Namespace)
+		}
+	}
+	return append(namespaces, c.Namespace)
+}
+
+// reportClusterTenants updates the status with the tenants referring to the cluster, when they changed
+func reportClusterTenants(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, tenants []kvstorev1.HbaseTenant, cl client.Client) error {
+	names := []string{}
+	for _, t := range tenants {
+		names = append(names, t.Namespace+"/"+t.Name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 && len(c.Status.Tenants) == 0 || equality.Semantic.DeepEqual(names, c.Status.Tenants) {
+		return nil
+	}
+
+	c.Status.Tenants = names
+	if err := cl.Status().Update(ctx, c); err != nil {
+		log.Error(err, "Failed to update status with HbaseTenants of the cluster")
+		return err
+	}
+	return nil
+}
+
+// updateAvailableCondition sets the Available condition of the cluster. The condition is only added once the cluster
+// becomes available for the first time, so it is not reported as unavailable while being created
+func updateAvailableCondition(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, available bool, reason string, message string, cl client.Client) error {
+	if !available && meta.FindStatusCondition(c.Status.Conditions, CONDITION_AVAILABLE) == nil {
+		return nil
+	}
+	status := metav1.ConditionFalse
+	if available {
+		status = metav1.ConditionTrue
+	}
+	if !meta.SetStatusCondition(&c.Status.Conditions, metav1.Condition{Type: CONDITION_AVAILABLE, Status: status,
+		Reason: reason, Message: message, ObservedGeneration: c.Generation}) {
+		return nil
+	}
+	if err := cl.Status().Update(ctx, c); err != nil {
+		log.Error(err, "Failed to update HbaseCluster status", "Condition", CONDITION_AVAILABLE)
+		return err
+	}
+	return nil
+}
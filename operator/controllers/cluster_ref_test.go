@@ -0,0 +1,199 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func getMockClusterRefTenant() *kvstorev1.HbaseTenant {
+	hbasetenant := getMockHbaseTenant()
+	hbasetenant.Spec.ClusterRef = &kvstorev1.HbaseClusterReference{Name: testCluster, Namespace: testNamespace}
+	return hbasetenant
+}
+
+// mockParentCluster mocks the Get of the HbaseCluster referred to by tenants, available or not
+func mockParentCluster(k8sMockClient *K8sMockClient, ctx context.Context, available bool) *kvstorev1.HbaseCluster {
+	hbasecluster := getMockHbaseCluster()
+	if available {
+		meta.SetStatusCondition(&hbasecluster.Status.Conditions, metav1.Condition{Type: CONDITION_AVAILABLE, Status: metav1.ConditionTrue, Reason: "StatefulSetsReady"})
+	}
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: testCluster, Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseCluster)
+			*arg = *hbasecluster
+		}).
+		Return(nil)
+	return hbasecluster
+}
+
+// TestClusterRefOf verifies the cluster reference defaults to the namespace of the tenant, and is used as index and watch mapping.
+func TestClusterRefOf(t *testing.T) {
+	hbasetenant := getMockHbaseTenant()
+	assert.Nil(t, indexTenantClusterRef(hbasetenant))
+	assert.Nil(t, clusterOfTenant(context.TODO(), hbasetenant))
+
+	hbasetenant.Spec.ClusterRef = &kvstorev1.HbaseClusterReference{Name: testCluster}
+	assert.Equal(t, types.NamespacedName{Name: testCluster, Namespace: hbasetenant.Namespace}, clusterRefOf(hbasetenant))
+	assert.Equal(t, []string{hbasetenant.Namespace + "/" + testCluster}, indexTenantClusterRef(hbasetenant))
+
+	hbasetenant.Spec.ClusterRef.Namespace = testNamespace
+	requests := clusterOfTenant(context.TODO(), hbasetenant)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, types.NamespacedName{Name: testCluster, Namespace: testNamespace}, requests[0].NamespacedName)
+}
+
+// TestInheritClusterDefaults verifies only the fields not set on the tenant are inherited from the cluster.
+func TestInheritClusterDefaults(t *testing.T) {
+	hbasecluster := getMockHbaseCluster()
+	spec := kvstorev1.HbaseTenantSpec{Configuration: kvstorev1.HbaseClusterConfiguration{HbaseConfigName: "tenant-hbase-config"}}
+
+	inheritClusterDefaults(&spec, &hbasecluster.Spec)
+	assert.Equal(t, hbasecluster.Spec.BaseImage, spec.BaseImage)
+	assert.Equal(t, hbasecluster.Spec.FSGroup, spec.FSGroup)
+	assert.Equal(t, "tenant-hbase-config", spec.Configuration.HbaseConfigName)
+	assert.Equal(t, hbasecluster.Spec.Configuration.HbaseConfigMountPath, spec.Configuration.HbaseConfigMountPath)
+	assert.Equal(t, hbasecluster.Spec.Configuration.HadoopConfigName, spec.Configuration.HadoopConfigName)
+	assert.Equal(t, hbasecluster.Spec.Configuration.HadoopConfig, spec.Configuration.HadoopConfig)
+	assert.Equal(t, hbasecluster.Spec.Configuration.HbaseTenantConfig, spec.Configuration.HbaseTenantConfig)
+	assert.Empty(t, spec.Configuration.UpdatePolicy)
+}
+
+// TestTenantNamespacesOf verifies namespaces of referring tenants are added to the listed ones, without duplicates.
+func TestTenantNamespacesOf(t *testing.T) {
+	hbasecluster := getMockHbaseCluster()
+	tenants := []kvstorev1.HbaseTenant{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: tenantNamespace2}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "new-tenant-ns"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: testNamespace}},
+	}
+	assert.Equal(t, []string{tenantNamespace1, tenantNamespace2, "new-tenant-ns", testNamespace}, tenantNamespacesOf(hbasecluster, tenants))
+
+	hbasecluster.Spec.TenantNamespaces = nil
+	assert.Equal(t, []string{testNamespace}, tenantNamespacesOf(hbasecluster, nil))
+}
+
+// TestReportClusterTenants verifies the tenants referring to the cluster are listed in its status, updated only on change.
+func TestReportClusterTenants(t *testing.T) {
+	hbasecluster := getMockHbaseCluster()
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	k8sMockClient.On("List", ctx, &kvstorev1.HbaseTenantList{}, []client.ListOption{client.MatchingFields{TENANT_CLUSTER_REF_INDEX: testNamespace + "/" + testCluster}}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(1).(*kvstorev1.HbaseTenantList)
+			arg.Items = []kvstorev1.HbaseTenant{
+				{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: tenantNamespace2}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: tenantNamespace1}},
+			}
+		}).
+		Return(nil)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, hbasecluster).Return(nil)
+
+	log := ctrl.Log.WithName("test")
+	tenants, err := listClusterTenants(ctx, log, hbasecluster, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NoError(t, reportClusterTenants(ctx, log, hbasecluster, tenants, k8sMockClient))
+	assert.NoError(t, reportClusterTenants(ctx, log, hbasecluster, tenants, k8sMockClient))
+	assert.Equal(t, []string{tenantNamespace1 + "/a", tenantNamespace2 + "/b"}, hbasecluster.Status.Tenants)
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// TestUpdateAvailableCondition verifies the condition is added once the cluster is available, and flips afterwards.
+func TestUpdateAvailableCondition(t *testing.T) {
+	hbasecluster := getMockHbaseCluster()
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, hbasecluster).Return(nil)
+
+	log := ctrl.Log.WithName("test")
+	assert.NoError(t, updateAvailableCondition(ctx, log, hbasecluster, false, "StatefulSetNotReady", "", k8sMockClient))
+	assert.Empty(t, hbasecluster.Status.Conditions)
+
+	assert.NoError(t, updateAvailableCondition(ctx, log, hbasecluster, true, "StatefulSetsReady", "", k8sMockClient))
+	assert.NoError(t, updateAvailableCondition(ctx, log, hbasecluster, true, "StatefulSetsReady", "", k8sMockClient))
+	assert.True(t, meta.IsStatusConditionTrue(hbasecluster.Status.Conditions, CONDITION_AVAILABLE))
+
+	assert.NoError(t, updateAvailableCondition(ctx, log, hbasecluster, false, "StatefulSetNotReady", "", k8sMockClient))
+	assert.True(t, meta.IsStatusConditionFalse(hbasecluster.Status.Conditions, CONDITION_AVAILABLE))
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+}
+
+// TestHbaseTenantReconciler_ClusterNotAvailable verifies the tenant waits for its cluster without reconciling anything else.
+func TestHbaseTenantReconciler_ClusterNotAvailable(t *testing.T) {
+	resetHashStore()
+	hbasetenant := getMockClusterRefTenant()
+	k8sMockClient, reconciler, ctx, req := doTenantTestSetup()
+
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseTenant{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseTenant)
+			*arg = *hbasetenant
+		}).
+		Return(nil)
+	mockParentCluster(k8sMockClient, ctx, false)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(o *kvstorev1.HbaseTenant) bool {
+		c := meta.FindStatusCondition(o.Status.Conditions, CONDITION_CLUSTER_AVAILABLE)
+		return o.Status.Cluster == testNamespace+"/"+testCluster && c != nil && c.Reason == "ClusterNotAvailable"
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 30}, result)
+
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+	k8sMockClient.AssertNumberOfCalls(t, "Get", 2)
+}
+
+// TestReconcileClusterRef_NotFound verifies a missing cluster is reported in the tenant status.
+func TestReconcileClusterRef_NotFound(t *testing.T) {
+	hbasetenant := getMockClusterRefTenant()
+	hbasetenant.Status.Cluster = testNamespace + "/" + testCluster
+	meta.SetStatusCondition(&hbasetenant.Status.Conditions, metav1.Condition{Type: CONDITION_CLUSTER_AVAILABLE, Status: metav1.ConditionFalse, Reason: "ClusterNotFound",
+		Message: "HbaseCluster " + testNamespace + "/" + testCluster + " not found"})
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: testCluster, Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, testCluster))
+
+	// status is unchanged, so it is not updated again
+	result, err := reconcileClusterRef(ctx, ctrl.Log.WithName("test"), hbasetenant, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 30}, result)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
+
+// TestReconcileClusterRef_Available verifies defaults are inherited once the cluster is available.
+func TestReconcileClusterRef_Available(t *testing.T) {
+	hbasetenant := getMockClusterRefTenant()
+	hbasetenant.Spec.BaseImage = ""
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	hbasecluster := mockParentCluster(k8sMockClient, ctx, true)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, hbasetenant).Return(nil)
+
+	result, err := reconcileClusterRef(ctx, ctrl.Log.WithName("test"), hbasetenant, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, hbasecluster.Spec.BaseImage, hbasetenant.Spec.BaseImage)
+	assert.True(t, meta.IsStatusConditionTrue(hbasetenant.Status.Conditions, CONDITION_CLUSTER_AVAILABLE))
+}
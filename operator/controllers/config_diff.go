@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	context "context"
+	xml "encoding/xml"
+	fmt "fmt"
+	reflect "reflect"
+	sort "sort"
+	strconv "strconv"
+	strings "strings"
+
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// DRY_RUN_ANNOTATION when set to "true" on a CR, the operator only reports the config diff and rollout plan
+const DRY_RUN_ANNOTATION = "hbase-operator/dry-run"
+
+// MASKED_CONFIG_VALUE replaces values of sensitive properties in diffs, events and status
+const MASKED_CONFIG_VALUE = "******"
+
+// maximum number of changed properties listed in a single event message
+const maxConfigChangesInEvent = 20
+
+// property names containing any of these are treated as secrets
+var sensitiveConfigKeys = []string{"password", "passwd", "secret", "token", "credential"}
+
+type configChangeAction string
+
+const (
+	configAdded    configChangeAction = "Added"
+	configModified configChangeAction = "Modified"
+	configRemoved  configChangeAction = "Removed"
+)
+
+// configChange is a single property (or whole file when Key is empty) level change within a ConfigMap
+type configChange struct {
+	ConfigMap string
+	File      string
+	Key       string
+	Action    configChangeAction
+	OldValue  string
+	NewValue  string
+}
+
+func (c configChange) String() string {
+	name := c.ConfigMap + "/" + c.File
+	if len(c.Key) == 0 {
+		switch c.Action {
+		case configAdded:
+			return "+ " + name
+		case configRemoved:
+			return "- " + name
+		default:
+			return "~ " + name + " (content changed)"
+		}
+	}
+	switch c.Action {
+	case configAdded:
+		return "+ " + name + ": " + c.Key + "=" + c.NewValue
+	case configRemoved:
+		return "- " + name + ": " + c.Key
+	default:
+		return "~ " + name + ": " + c.Key + ": " + c.OldValue + " -> " + c.NewValue
+	}
+}
+
+type hadoopConfigProperty struct {
+	Name  string `xml:"name"`
+	Value string `xml:"value"`
+}
+
+type hadoopConfiguration struct {
+	Properties []hadoopConfigProperty `xml:"property"`
+}
+
+func isDryRun(obj metav1.Object) bool {
+	value, exists := obj.GetAnnotations()[DRY_RUN_ANNOTATION]
+	return exists && value == "true"
+}
+
+func isSensitiveConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveConfigKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskConfigValue(key string, value string) string {
+	if isSensitiveConfigKey(key) {
+		return MASKED_CONFIG_VALUE
+	}
+	return value
+}
+
+// parseConfigProperties returns the properties defined in a config file. Second return value is false when
+// the file type cannot be broken down into properties, in which case only file level changes are reported.
+func parseConfigProperties(file string, content string) (map[string]string, bool) {
+	configType, ok := allowedConfigs[file]
+	if !ok {
+		return nil, false
+	}
+
+	properties := map[string]string{}
+	switch configType {
+	case XML:
+		conf := hadoopConfiguration{}
+		if err := xml.Unmarshal([]byte(content), &conf); err != nil || len(conf.Properties) == 0 {
+			return nil, false
+		}
+		for _, p := range conf.Properties {
+			properties[strings.TrimSpace(p.Name)] = strings.TrimSpace(p.Value)
+		}
+	case PROPS:
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+				continue
+			}
+			if idx := strings.IndexAny(line, "=:"); idx > 0 {
+				properties[strings.TrimSpace(line[:idx])] = strings.TrimSpace(line[idx+1:])
+			}
+		}
+	case SHELL:
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+			if idx := strings.Index(line, "="); idx > 0 && !strings.ContainsAny(line[:idx], " \t") {
+				properties[line[:idx]] = line[idx+1:]
+			}
+		}
+	default:
+		return nil, false
+	}
+	return properties, true
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// diffConfigData computes property level changes between the old and new data of a ConfigMap. Files which can
+// not be parsed into properties, or differ only in content that is not a property, are reported as a whole.
+func diffConfigData(cfgName string, oldData map[string]string, newData map[string]string) []configChange {
+	changes := []configChange{}
+
+	files := map[string]string{}
+	for k := range oldData {
+		files[k] = ""
+	}
+	for k := range newData {
+		files[k] = ""
+	}
+
+	for _, file := range sortedKeys(files) {
+		oldContent, inOld := oldData[file]
+		newContent, inNew := newData[file]
+		if !inOld {
+			changes = append(changes, configChange{ConfigMap: cfgName, File: file, Action: configAdded})
+			continue
+		}
+		if !inNew {
+			changes = append(changes, configChange{ConfigMap: cfgName, File: file, Action: configRemoved})
+			continue
+		}
+		if oldContent == newContent {
+			continue
+		}
+
+		oldProps, oldOk := parseConfigProperties(file, oldContent)
+		newProps, newOk := parseConfigProperties(file, newContent)
+		if !oldOk || !newOk {
+			changes = append(changes, configChange{ConfigMap: cfgName, File: file, Action: configModified})
+			continue
+		}
+
+		fileChanges := []configChange{}
+		keys := map[string]string{}
+		for k := range oldProps {
+			keys[k] = ""
+		}
+		for k := range newProps {
+			keys[k] = ""
+		}
+		for _, key := range sortedKeys(keys) {
+			oldValue, inOld := oldProps[key]
+			newValue, inNew := newProps[key]
+			change := configChange{ConfigMap: cfgName, File: file, Key: key,
+				OldValue: maskConfigValue(key, oldValue), NewValue: maskConfigValue(key, newValue)}
+			if !inOld {
+				change.Action = configAdded
+				change.OldValue = ""
+			} else if !inNew {
+				change.Action = configRemoved
+				change.NewValue = ""
+			} else if oldValue != newValue {
+				change.Action = configModified
+			} else {
+				continue
+			}
+			fileChanges = append(fileChanges, change)
+		}
+
+		// content changed, but not in any of the properties (comments, formatting, non-property lines)
+		if len(fileChanges) == 0 {
+			fileChanges = append(fileChanges, configChange{ConfigMap: cfgName, File: file, Action: configModified})
+		}
+		changes = append(changes, fileChanges...)
+	}
+
+	return changes
+}
+
+// computeConfigMapDiff compares the desired ConfigMap against the one present in the cluster, which is returned along
+// with the diff. A ConfigMap which does not exist yet is not a change and results in no diff.
+func computeConfigMapDiff(ctx context.Context, log logr.Logger, cfg *corev1.ConfigMap, cl client.Client) ([]configChange, *corev1.ConfigMap, error) {
+	existing := &corev1.ConfigMap{}
+	err := cl.Get(ctx, types.NamespacedName{Name: cfg.Name, Namespace: cfg.Namespace}, existing)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		log.Error(err, "Failed to get ConfigMap for diff", "ConfigMap.Namespace", cfg.Namespace, "ConfigMap.Name", cfg.Name)
+		return nil, nil, err
+	}
+	return diffConfigData(cfg.Namespace+"/"+cfg.Name, existing.Data, cfg.Data), existing, nil
+}
+
+// buildRolloutPlan lists the steps taken to roll out the given changes. restartTargets maps ConfigMaps (namespace/name)
+// to the StatefulSets bound to them, which are restarted on a change only if restart is enabled for the CR, unless all
+// changes to their ConfigMap can be reloaded online and hot reload is enabled.
+func buildRolloutPlan(changes []configChange, restartTargets map[string][]string, restartEnabled bool, hotReloadEnabled bool) []string {
+	plan := []string{}
+	changesByConfigMap := map[string][]configChange{}
+	updated := map[string]string{}
+	for _, c := range changes {
+		changesByConfigMap[c.ConfigMap] = append(changesByConfigMap[c.ConfigMap], c)
+		updated[c.ConfigMap] = ""
+	}
+	cfgNames := sortedKeys(updated)
+	for _, cfgName := range cfgNames {
+		plan = append(plan, "update ConfigMap "+cfgName)
+	}
+
+	reloaded := []string{}
+	restarts := []string{}
+	for _, cfgName := range cfgNames {
+		statefulSets, ok := restartTargets[cfgName]
+		if !ok || !restartEnabled {
+			continue
+		}
+		if hotReloadEnabled && canHotReload(changesByConfigMap[cfgName]) {
+			reloaded = append(reloaded, cfgName)
+			continue
+		}
+		for _, name := range statefulSets {
+			restarts = append(restarts, "rolling restart of StatefulSet "+name)
+		}
+	}
+
+	if len(reloaded) > 0 {
+		plan = append(plan, "wait for kubelet to sync ConfigMap "+strings.Join(reloaded, ", "), "reload config online with update_all_config, no pod restart")
+	}
+	plan = append(plan, restarts...)
+	if len(cfgNames) > 0 && len(reloaded) == 0 && len(restarts) == 0 {
+		plan = append(plan, "no pod restart, changes are picked up on next pod restart")
+	}
+	return plan
+}
+
+func summarizeConfigChanges(changes []configChange, plan []string, dryRun bool) *kvstorev1.ConfigChangeStatus {
+	summary := &kvstorev1.ConfigChangeStatus{
+		ObservedTime: metav1.Now(),
+		DryRun:       dryRun,
+		RolloutPlan:  plan,
+	}
+	files := map[string]string{}
+	for _, c := range changes {
+		files[c.ConfigMap+"/"+c.File] = ""
+		switch c.Action {
+		case configAdded:
+			summary.Added += 1
+		case configRemoved:
+			summary.Removed += 1
+		default:
+			summary.Modified += 1
+		}
+	}
+	summary.Files = sortedKeys(files)
+	return summary
+}
+
+func isSameConfigChange(a *kvstorev1.ConfigChangeStatus, b *kvstorev1.ConfigChangeStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	x.ObservedTime, y.ObservedTime = metav1.Time{}, metav1.Time{}
+	return reflect.DeepEqual(x, y)
+}
+
+func formatConfigChangeMessage(changes []configChange, plan []string, dryRun bool) string {
+	var sb strings.Builder
+	if dryRun {
+		sb.WriteString("Dry run, nothing applied. ")
+	}
+	sb.WriteString(strconv.Itoa(len(changes)) + " config change(s):")
+	for i, c := range changes {
+		if i == maxConfigChangesInEvent {
+			sb.WriteString(fmt.Sprintf("\n... and %d more", len(changes)-maxConfigChangesInEvent))
+			break
+		}
+		sb.WriteString("\n" + c.String())
+	}
+	if len(plan) > 0 {
+		sb.WriteString("\nRollout plan:")
+		for i, step := range plan {
+			sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, step))
+		}
+	}
+	return sb.String()
+}
+
+// reportConfigChanges publishes the diff as an event for the CR and records its summary in the CR status
+func reportConfigChanges(ctx context.Context, log logr.Logger, obj client.Object, lastChange **kvstorev1.ConfigChangeStatus,
+	changes []configChange, plan []string, dryRun bool, recorder record.EventRecorder, cl client.Client) {
+	if len(changes) == 0 && !dryRun {
+		return
+	}
+
+	summary := summarizeConfigChanges(changes, plan, dryRun)
+	// status update triggers another reconcile, dry run reports the same diff until the spec changes
+	if dryRun && isSameConfigChange(*lastChange, summary) {
+		return
+	}
+
+	reason := REASON_CONFIG_CHANGED
+	if dryRun {
+		reason = REASON_CONFIG_DRY_RUN
+	}
+	message := formatConfigChangeMessage(changes, plan, dryRun)
+	log.Info("Config changes detected", "DryRun", dryRun, "Changes", len(changes), "RolloutPlan", plan)
+	recorder.Event(obj, corev1.EventTypeNormal, reason, message)
+
+	*lastChange = summary
+	if err := cl.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "Failed to update status with config changes", "Resource", kindOf(obj))
+	}
+}
@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const testHbaseSite = `<?xml version="1.0"?>
+<configuration>
+<property><name>hbase.regionserver.handler.count</name><value>30</value></property>
+<property><name>hbase.rootdir</name><value>hdfs://ns/hbase</value></property>
+<property><name>hbase.ssl.keystore.password</name><value>old-secret</value></property>
+</configuration>`
+
+// ---- diffConfigData ----
+
+// TestDiffConfigData_XML verifies property level changes are reported for hadoop style xml files, with secrets masked.
+func TestDiffConfigData_XML(t *testing.T) {
+	newSite := `<?xml version="1.0"?>
+<configuration>
+<property><name>hbase.regionserver.handler.count</name><value>60</value></property>
+<property><name>hbase.ssl.keystore.password</name><value>new-secret</value></property>
+<property><name>hbase.zookeeper.quorum</name><value>zk-0</value></property>
+</configuration>`
+
+	changes := diffConfigData("ns/hbase-config", map[string]string{"hbase-site.xml": testHbaseSite}, map[string]string{"hbase-site.xml": newSite})
+
+	assert.Equal(t, []configChange{
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.regionserver.handler.count", Action: configModified, OldValue: "30", NewValue: "60"},
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.rootdir", Action: configRemoved, OldValue: "hdfs://ns/hbase"},
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.ssl.keystore.password", Action: configModified, OldValue: MASKED_CONFIG_VALUE, NewValue: MASKED_CONFIG_VALUE},
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.zookeeper.quorum", Action: configAdded, NewValue: "zk-0"},
+	}, changes)
+}
+
+// TestDiffConfigData_PropsAndShell verifies property level changes for properties and shell env files.
+func TestDiffConfigData_PropsAndShell(t *testing.T) {
+	oldData := map[string]string{
+		"log4j.properties": "# comment\nhbase.root.logger=INFO,console\nlog4j.threshold=ALL\n",
+		"hbase-env.sh":     "export HBASE_HEAPSIZE=4G\nexport HBASE_OPTS=\"-XX:+UseG1GC\"\n",
+	}
+	newData := map[string]string{
+		"log4j.properties": "# comment changed\nhbase.root.logger=DEBUG,console\nlog4j.threshold=ALL\n",
+		"hbase-env.sh":     "export HBASE_HEAPSIZE=8G\nexport HBASE_OPTS=\"-XX:+UseG1GC\"\n",
+	}
+
+	changes := diffConfigData("ns/hbase-config", oldData, newData)
+
+	assert.Equal(t, []configChange{
+		{ConfigMap: "ns/hbase-config", File: "hbase-env.sh", Key: "HBASE_HEAPSIZE", Action: configModified, OldValue: "4G", NewValue: "8G"},
+		{ConfigMap: "ns/hbase-config", File: "log4j.properties", Key: "hbase.root.logger", Action: configModified, OldValue: "INFO,console", NewValue: "DEBUG,console"},
+	}, changes)
+}
+
+// TestDiffConfigData_FileLevel verifies added, removed and unparseable files are reported as whole file changes.
+func TestDiffConfigData_FileLevel(t *testing.T) {
+	oldData := map[string]string{
+		"hbase-site.xml": testHbaseSite,
+		"hdfs-site.xml":  "<configuration></configuration>",
+		"unknown.cfg":    "a",
+	}
+	newData := map[string]string{
+		"hbase-site.xml":  testHbaseSite + "\n<!-- trailing comment -->",
+		"core-site.xml":   "<configuration></configuration>",
+		"unknown.cfg":     "b",
+		"hadoop-env.sh":   "export HADOOP_CONF_DIR=/etc/hadoop",
+		"hdfs-site.xml-x": "",
+	}
+
+	changes := diffConfigData("ns/cfg", oldData, newData)
+
+	assert.Equal(t, []configChange{
+		{ConfigMap: "ns/cfg", File: "core-site.xml", Action: configAdded},
+		{ConfigMap: "ns/cfg", File: "hadoop-env.sh", Action: configAdded},
+		{ConfigMap: "ns/cfg", File: "hbase-site.xml", Action: configModified},
+		{ConfigMap: "ns/cfg", File: "hdfs-site.xml", Action: configRemoved},
+		{ConfigMap: "ns/cfg", File: "hdfs-site.xml-x", Action: configAdded},
+		{ConfigMap: "ns/cfg", File: "unknown.cfg", Action: configModified},
+	}, changes)
+}
+
+// TestDiffConfigData_NoChange verifies identical data results in an empty diff.
+func TestDiffConfigData_NoChange(t *testing.T) {
+	data := map[string]string{"hbase-site.xml": testHbaseSite}
+	assert.Empty(t, diffConfigData("ns/cfg", data, data))
+}
+
+// ---- isDryRun ----
+
+// TestIsDryRun verifies only the "true" value of the dry-run annotation enables dry run.
+func TestIsDryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expected    bool
+	}{
+		{"no annotations", nil, false},
+		{"true", map[string]string{DRY_RUN_ANNOTATION: "true"}, true},
+		{"false", map[string]string{DRY_RUN_ANNOTATION: "false"}, false},
+		{"other annotation", map[string]string{"foo": "true"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &metav1.ObjectMeta{Annotations: tt.annotations}
+			assert.Equal(t, tt.expected, isDryRun(obj))
+		})
+	}
+}
+
+// ---- buildRolloutPlan ----
+
+// TestBuildRolloutPlan verifies restart steps are planned only when a bound ConfigMap changes and restart is enabled.
+func TestBuildRolloutPlan(t *testing.T) {
+	hbaseChange := []configChange{{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Action: configModified}}
+	hadoopChange := []configChange{{ConfigMap: "ns/hadoop-config", File: "core-site.xml", Action: configModified}}
+	targets := map[string][]string{"ns/hbase-config": {"zk", "rs"}}
+
+	assert.Empty(t, buildRolloutPlan(nil, targets, true, false))
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config", "rolling restart of StatefulSet zk", "rolling restart of StatefulSet rs"},
+		buildRolloutPlan(hbaseChange, targets, true, false))
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config", "no pod restart, changes are picked up on next pod restart"},
+		buildRolloutPlan(hbaseChange, targets, false, false))
+	assert.Equal(t, []string{"update ConfigMap ns/hadoop-config", "no pod restart, changes are picked up on next pod restart"},
+		buildRolloutPlan(hadoopChange, targets, true, false))
+
+	reloadable := []configChange{{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.regions.slop", Action: configModified}}
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config", "wait for kubelet to sync ConfigMap ns/hbase-config", "reload config online with update_all_config, no pod restart"},
+		buildRolloutPlan(reloadable, targets, true, true))
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config", "rolling restart of StatefulSet zk", "rolling restart of StatefulSet rs"},
+		buildRolloutPlan(reloadable, targets, true, false))
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config", "rolling restart of StatefulSet zk", "rolling restart of StatefulSet rs"},
+		buildRolloutPlan(append(reloadable, hbaseChange...), targets, true, true))
+}
+
+// TestBuildRolloutPlan_ComponentConfig verifies only the component bound to a changed component ConfigMap is restarted.
+func TestBuildRolloutPlan_ComponentConfig(t *testing.T) {
+	targets := map[string][]string{"ns/hbase-config": {"zk", "rs"}, "ns/hbase-config-hmaster": {"hmaster"}}
+	changes := []configChange{{ConfigMap: "ns/hbase-config-hmaster", File: "hbase-env.sh", Key: "HBASE_HEAPSIZE", Action: configModified}}
+
+	assert.Equal(t, []string{"update ConfigMap ns/hbase-config-hmaster", "rolling restart of StatefulSet hmaster"},
+		buildRolloutPlan(changes, targets, true, false))
+}
+
+// ---- formatConfigChangeMessage ----
+
+// TestFormatConfigChangeMessage verifies the event message lists changes and plan, and truncates long diffs.
+func TestFormatConfigChangeMessage(t *testing.T) {
+	changes := []configChange{
+		{ConfigMap: "ns/cfg", File: "hbase-site.xml", Key: "a", Action: configModified, OldValue: "1", NewValue: "2"},
+		{ConfigMap: "ns/cfg", File: "hbase-site.xml", Key: "b", Action: configAdded, NewValue: "3"},
+		{ConfigMap: "ns/cfg", File: "core-site.xml", Action: configRemoved},
+	}
+	message := formatConfigChangeMessage(changes, []string{"update ConfigMap ns/cfg"}, true)
+	assert.Equal(t, "Dry run, nothing applied. 3 config change(s):\n"+
+		"~ ns/cfg/hbase-site.xml: a: 1 -> 2\n"+
+		"+ ns/cfg/hbase-site.xml: b=3\n"+
+		"- ns/cfg/core-site.xml\n"+
+		"Rollout plan:\n1. update ConfigMap ns/cfg", message)
+
+	many := []configChange{}
+	for i := 0; i < maxConfigChangesInEvent+5; i++ {
+		many = append(many, configChange{ConfigMap: "ns/cfg", File: "hbase-site.xml", Key: "k", Action: configAdded})
+	}
+	assert.Contains(t, formatConfigChangeMessage(many, nil, false), "... and 5 more")
+}
+
+// ---- reconcile with dry run ----
+
+// TestHbaseTenantReconciler_DryRun verifies that in dry run the diff is published as an event and recorded in
+// status, while ConfigMaps and workloads are left untouched.
+func TestHbaseTenantReconciler_DryRun(t *testing.T) {
+	resetHashStore()
+	hbasetenant := getMockHbaseTenant()
+	hbasetenant.Annotations = map[string]string{DRY_RUN_ANNOTATION: "true"}
+
+	k8sMockClient, reconciler, ctx, req := doTenantTestSetup()
+	statusWriter := mockTenantDryRunCalls(k8sMockClient, hbasetenant, ctx, req)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(obj *kvstorev1.HbaseTenant) bool {
+		c := obj.Status.LastConfigChange
+		return c != nil && c.DryRun && c.Added == 1 && c.Removed == 3 &&
+			assert.ObjectsAreEqual([]string{hbasetenant.Namespace + "/" + hbasetenant.Spec.Configuration.HbaseConfigName + "/hbase-site.xml"}, c.Files)
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_CONFIG_DRY_RUN}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestHbaseTenantReconciler_DryRunAlreadyReported verifies an unchanged dry run diff is not reported again.
+func TestHbaseTenantReconciler_DryRunAlreadyReported(t *testing.T) {
+	resetHashStore()
+	hbasetenant := getMockHbaseTenant()
+	hbasetenant.Annotations = map[string]string{DRY_RUN_ANNOTATION: "true"}
+	hbasetenant.Status.LastConfigChange = &kvstorev1.ConfigChangeStatus{
+		DryRun:      true,
+		Added:       1,
+		Removed:     3,
+		Files:       []string{hbasetenant.Namespace + "/" + hbasetenant.Spec.Configuration.HbaseConfigName + "/hbase-site.xml"},
+		RolloutPlan: []string{"update ConfigMap " + hbasetenant.Namespace + "/" + hbasetenant.Spec.Configuration.HbaseConfigName, "no pod restart, changes are picked up on next pod restart"},
+	}
+
+	k8sMockClient, reconciler, ctx, req := doTenantTestSetup()
+	mockTenantDryRunCalls(k8sMockClient, hbasetenant, ctx, req)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Status")
+	k8sMockClient.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// mockTenantDryRunCalls registers the tenant and its ConfigMaps, with hbase-site.xml changed in the existing hbase ConfigMap
+func mockTenantDryRunCalls(k8sMockClient *K8sMockClient, hbasetenant *kvstorev1.HbaseTenant,
+	ctx context.Context, req ctrl.Request) *K8sMockStatusWriter {
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseTenant{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseTenant)
+			*arg = *hbasetenant
+		}).
+		Return(nil)
+
+	existingHbaseCfg := buildConfigMap(hbasetenant.Spec.Configuration.HbaseConfigName, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.Configuration.HbaseConfig, hbasetenant.Spec.Configuration.HbaseTenantConfig, ctrl.Log.WithName("test"))
+	existingHbaseCfg.Data["hbase-site.xml"] = testHbaseSite
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: existingHbaseCfg.Name, Namespace: existingHbaseCfg.Namespace}, &corev1.ConfigMap{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*corev1.ConfigMap)
+			*arg = *existingHbaseCfg
+		}).
+		Return(nil)
+
+	existingHadoopCfg := buildConfigMap(hbasetenant.Spec.Configuration.HadoopConfigName, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.Configuration.HadoopConfig, hbasetenant.Spec.Configuration.HadoopTenantConfig, ctrl.Log.WithName("test"))
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: existingHadoopCfg.Name, Namespace: existingHadoopCfg.Namespace}, &corev1.ConfigMap{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*corev1.ConfigMap)
+			*arg = *existingHadoopCfg
+		}).
+		Return(nil)
+
+	return new(K8sMockStatusWriter)
+}
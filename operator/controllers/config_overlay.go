@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	context "context"
+	xml "encoding/xml"
+	errs "errors"
+	io "io"
+	sort "sort"
+	strings "strings"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+func hasConfigOverlay(d kvstorev1.HbaseClusterDeployment) bool {
+	return d.Configuration != nil && (len(d.Configuration.HbaseConfig) > 0 || len(d.Configuration.HadoopConfig) > 0)
+}
+
+// componentConfigName is the name of the ConfigMap rendered for a component with a config overlay
+func componentConfigName(cfgName string, d kvstorev1.HbaseClusterDeployment) string {
+	return cfgName + "-" + d.Name
+}
+
+// hbaseConfigNameOf returns the name of the hbase ConfigMap mounted by a component
+func hbaseConfigNameOf(c kvstorev1.HbaseClusterConfiguration, d kvstorev1.HbaseClusterDeployment) string {
+	if hasConfigOverlay(d) {
+		return componentConfigName(c.HbaseConfigName, d)
+	}
+	return c.HbaseConfigName
+}
+
+// validateClusterConfiguration validates the shared configuration along with config overlays of the components
+func validateClusterConfiguration(ctx context.Context, log logr.Logger, namespace string, c kvstorev1.HbaseClusterConfiguration,
+	deployments []kvstorev1.HbaseClusterDeployment, cl client.Client) (ctrl.Result, error) {
+	result, err := validateConfiguration(ctx, log, namespace, c, cl)
+	if err != nil {
+		return result, err
+	}
+	for _, d := range deployments {
+		if !hasConfigOverlay(d) {
+			continue
+		}
+		overlay := kvstorev1.HbaseClusterConfiguration{HbaseConfig: d.Configuration.HbaseConfig, HadoopConfig: d.Configuration.HadoopConfig}
+		result, err = validateConfiguration(ctx, log, namespace, overlay, cl)
+		if err != nil {
+			return result, errs.New("Deployment: " + d.Name + ". " + err.Error())
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// buildComponentConfigMaps renders hbase and hadoop ConfigMaps of a component with a config overlay, nil otherwise
+func buildComponentConfigMaps(crName string, namespace string, c kvstorev1.HbaseClusterConfiguration, d kvstorev1.HbaseClusterDeployment, log logr.Logger) []*corev1.ConfigMap {
+	if !hasConfigOverlay(d) {
+		return nil
+	}
+	return []*corev1.ConfigMap{
+		buildConfigMap(componentConfigName(c.HbaseConfigName, d), crName, namespace, mergeConfig(c.HbaseConfig, d.Configuration.HbaseConfig), c.HbaseTenantConfig, log),
+		buildConfigMap(componentConfigName(c.HadoopConfigName, d), crName, namespace, mergeConfig(c.HadoopConfig, d.Configuration.HadoopConfig), c.HadoopTenantConfig, log),
+	}
+}
+
+// useComponentConfigMaps points the config volumes of a component with a config overlay to its own ConfigMaps
+func useComponentConfigMaps(volumes []corev1.Volume, c kvstorev1.HbaseClusterConfiguration, d kvstorev1.HbaseClusterDeployment) {
+	if !hasConfigOverlay(d) {
+		return
+	}
+	for _, v := range volumes {
+		if v.ConfigMap == nil {
+			continue
+		}
+		switch v.Name {
+		case c.HbaseConfigName:
+			v.ConfigMap.Name = componentConfigName(c.HbaseConfigName, d)
+		case c.HadoopConfigName:
+			v.ConfigMap.Name = componentConfigName(c.HadoopConfigName, d)
+		}
+	}
+}
+
+// mergeConfig returns the shared config with files of the overlay merged on top of it
+func mergeConfig(config map[string]string, overlay map[string]string) map[string]string {
+	merged := map[string]string{}
+	for k, v := range config {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		base, ok := merged[k]
+		if !ok {
+			merged[k] = v
+			continue
+		}
+		merged[k] = mergeConfigFile(k, base, v)
+	}
+	return merged
+}
+
+// mergeConfigFile merges properties of the overlay into the base file. Later definitions win for properties and shell
+// files, so the overlay is appended to them. Files which can not be merged are replaced by the overlay.
+func mergeConfigFile(file string, base string, overlay string) string {
+	configType, ok := allowedConfigs[file]
+	if !ok {
+		return overlay
+	}
+
+	switch configType {
+	case XML:
+		merged, err := mergeHadoopConfiguration(base, overlay)
+		if err != nil {
+			return overlay
+		}
+		return merged
+	case PROPS, SHELL:
+		if !strings.HasSuffix(base, "\n") {
+			base += "\n"
+		}
+		return base + overlay
+	default:
+		return overlay
+	}
+}
+
+// xmlSpan is the byte range of an element in a document
+type xmlSpan struct {
+	start int64
+	end   int64
+}
+
+// xmlProperty is a <property> element of a hadoop configuration, along with the spans of its children by tag
+type xmlProperty struct {
+	name     string
+	span     xmlSpan
+	children map[string]xmlSpan
+	tags     []string
+	// closing is the offset of </property>
+	closing int64
+}
+
+// parseHadoopProperties returns the <property> elements of a hadoop configuration and the offset of </configuration>
+func parseHadoopProperties(content string) ([]xmlProperty, int64, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	properties := []xmlProperty{}
+	var property *xmlProperty
+	var child string
+	var childStart int64
+	var name strings.Builder
+	depth := 0
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, 0, errs.New("configuration element not found")
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case depth == 2 && t.Name.Local == "property":
+				property = &xmlProperty{span: xmlSpan{start: offset}, children: map[string]xmlSpan{}}
+			case depth == 3 && property != nil:
+				child, childStart = t.Name.Local, offset
+				if child == "name" {
+					name.Reset()
+				}
+			}
+		case xml.CharData:
+			if depth == 3 && property != nil && child == "name" {
+				name.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case depth == 1:
+				return properties, offset, nil
+			case depth == 2 && property != nil:
+				property.span.end = decoder.InputOffset()
+				property.closing = offset
+				properties = append(properties, *property)
+				property = nil
+			case depth == 3 && property != nil:
+				if child == "name" {
+					property.name = strings.TrimSpace(name.String())
+				}
+				if _, ok := property.children[child]; !ok {
+					property.tags = append(property.tags, child)
+				}
+				property.children[child] = xmlSpan{start: childStart, end: decoder.InputOffset()}
+			}
+			depth--
+		}
+	}
+}
+
+// xmlEdit replaces a span of a document with text, inserting it when the span is empty
+type xmlEdit struct {
+	span xmlSpan
+	text string
+}
+
+// mergeHadoopConfiguration merges the <property> elements of the overlay into the base configuration. Children of a
+// property defined in both replace those of the base with the same tag, other children, comments and formatting of
+// the base are kept as is. Properties only defined in the overlay are appended
+func mergeHadoopConfiguration(base string, overlay string) (string, error) {
+	baseProperties, end, err := parseHadoopProperties(base)
+	if err != nil {
+		return "", err
+	}
+	overlayProperties, _, err := parseHadoopProperties(overlay)
+	if err != nil {
+		return "", err
+	}
+
+	index := map[string]xmlProperty{}
+	for _, p := range baseProperties {
+		index[p.name] = p
+	}
+	// later definitions of a property in the overlay win
+	names := []string{}
+	overrides := map[string]xmlProperty{}
+	for _, p := range overlayProperties {
+		if _, ok := overrides[p.name]; !ok {
+			names = append(names, p.name)
+		}
+		overrides[p.name] = p
+	}
+
+	edits := []xmlEdit{}
+	for _, n := range names {
+		p := overrides[n]
+		b, ok := index[n]
+		if !ok {
+			edits = append(edits, xmlEdit{span: xmlSpan{start: end, end: end}, text: "  " + overlay[p.span.start:p.span.end] + "\n"})
+			continue
+		}
+		for _, tag := range p.tags {
+			if tag == "name" {
+				continue
+			}
+			text := overlay[p.children[tag].start:p.children[tag].end]
+			if s, ok := b.children[tag]; ok {
+				edits = append(edits, xmlEdit{span: s, text: text})
+			} else {
+				edits = append(edits, xmlEdit{span: xmlSpan{start: b.closing, end: b.closing}, text: "  " + text + "\n  "})
+			}
+		}
+	}
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].span.start < edits[j].span.start
+	})
+
+	var sb strings.Builder
+	var offset int64
+	for _, e := range edits {
+		sb.WriteString(base[offset:e.span.start])
+		sb.WriteString(e.text)
+		offset = e.span.end
+	}
+	sb.WriteString(base[offset:])
+	return sb.String(), nil
+}
+
+func renderHadoopConfiguration(conf hadoopConfiguration) string {
+	var sb strings.Builder
+	sb.WriteString("<?xml version=\"1.0\"?>\n<configuration>\n")
+	for _, p := range conf.Properties {
+		sb.WriteString("  <property>\n    <name>")
+		xml.EscapeText(&sb, []byte(strings.TrimSpace(p.Name)))
+		sb.WriteString("</name>\n    <value>")
+		xml.EscapeText(&sb, []byte(p.Value))
+		sb.WriteString("</value>\n  </property>\n")
+	}
+	sb.WriteString("</configuration>\n")
+	return sb.String()
+}
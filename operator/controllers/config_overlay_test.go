@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TestMergeConfigFile_XML verifies overlay properties replace or extend the shared ones.
+func TestMergeConfigFile_XML(t *testing.T) {
+	overlay := `<configuration>
+<property><name>hbase.regionserver.handler.count</name><value>100</value></property>
+<property><name>hbase.hregion.memstore.flush.size</name><value>268435456</value></property>
+</configuration>`
+
+	merged := mergeConfigFile("hbase-site.xml", testHbaseSite, overlay)
+	changes := diffConfigData("ns/hbase-config", map[string]string{"hbase-site.xml": testHbaseSite}, map[string]string{"hbase-site.xml": merged})
+
+	assert.ElementsMatch(t, []configChange{
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.regionserver.handler.count", Action: configModified, OldValue: "30", NewValue: "100"},
+		{ConfigMap: "ns/hbase-config", File: "hbase-site.xml", Key: "hbase.hregion.memstore.flush.size", Action: configAdded, NewValue: "268435456"},
+	}, changes)
+	assert.True(t, isValidXML(merged))
+}
+
+// TestMergeConfigFile_PropertyElements verifies children of the shared properties other than the overridden ones, such
+// as <final> and <description>, and comments are kept.
+func TestMergeConfigFile_PropertyElements(t *testing.T) {
+	base := `<?xml version="1.0"?>
+<configuration>
+  <!-- tuned for the shared cluster -->
+  <property>
+    <name>hbase.regionserver.handler.count</name>
+    <value>30</value>
+    <final>true</final>
+    <description>RPC handlers</description>
+  </property>
+  <property>
+    <name>hbase.rootdir</name>
+    <value>hdfs://nn/hbase</value>
+  </property>
+</configuration>
+`
+	overlay := `<configuration>
+<property><name>hbase.regionserver.handler.count</name><value>100</value></property>
+<property><name>hbase.rootdir</name><value>hdfs://nn/hbase</value><final>true</final></property>
+<property><name>hbase.hregion.memstore.flush.size</name><value>268435456</value><description>flush size</description></property>
+</configuration>`
+
+	assert.Equal(t, `<?xml version="1.0"?>
+<configuration>
+  <!-- tuned for the shared cluster -->
+  <property>
+    <name>hbase.regionserver.handler.count</name>
+    <value>100</value>
+    <final>true</final>
+    <description>RPC handlers</description>
+  </property>
+  <property>
+    <name>hbase.rootdir</name>
+    <value>hdfs://nn/hbase</value>
+    <final>true</final>
+  </property>
+  <property><name>hbase.hregion.memstore.flush.size</name><value>268435456</value><description>flush size</description></property>
+</configuration>
+`, mergeConfigFile("hbase-site.xml", base, overlay))
+}
+
+// TestMergeConfigFile_PropsAndShell verifies the overlay is appended, so that its definitions win.
+func TestMergeConfigFile_PropsAndShell(t *testing.T) {
+	assert.Equal(t, "log4j.rootLogger=INFO\nlog4j.rootLogger=DEBUG", mergeConfigFile("log4j.properties", "log4j.rootLogger=INFO", "log4j.rootLogger=DEBUG"))
+	assert.Equal(t, "export A=1\nexport HBASE_HEAPSIZE=8G", mergeConfigFile("hbase-env.sh", "export A=1\n", "export HBASE_HEAPSIZE=8G"))
+}
+
+// TestMergeConfigFile_Replaced verifies files which can not be merged are replaced by the overlay.
+func TestMergeConfigFile_Replaced(t *testing.T) {
+	assert.Equal(t, "host2", mergeConfigFile("dfs.exclude", "host1", "host2"))
+	assert.Equal(t, "b", mergeConfigFile("unknown.txt", "a", "b"))
+	assert.Equal(t, "<broken", mergeConfigFile("hbase-site.xml", testHbaseSite, "<broken"))
+}
+
+// TestBuildComponentConfigMaps verifies ConfigMaps are rendered only for components with an overlay.
+func TestBuildComponentConfigMaps(t *testing.T) {
+	c := kvstorev1.HbaseClusterConfiguration{
+		HbaseConfigName:  "hbase-config",
+		HbaseConfig:      map[string]string{"hbase-env.sh": "export A=1", "log4j.properties": "a=b"},
+		HadoopConfigName: "hadoop-config",
+		HadoopConfig:     map[string]string{"core-site.xml": "<configuration></configuration>"},
+	}
+	d := kvstorev1.HbaseClusterDeployment{Name: "hmaster"}
+	assert.Nil(t, buildComponentConfigMaps("cluster", "ns", c, d, ctrl.Log.WithName("test")))
+	assert.Equal(t, "hbase-config", hbaseConfigNameOf(c, d))
+
+	d.Configuration = &kvstorev1.HbaseClusterConfigOverlay{HbaseConfig: map[string]string{"hbase-env.sh": "export HBASE_HEAPSIZE=8G"}}
+	cfgs := buildComponentConfigMaps("cluster", "ns", c, d, ctrl.Log.WithName("test"))
+	assert.Len(t, cfgs, 2)
+	assert.Equal(t, "hbase-config-hmaster", cfgs[0].Name)
+	assert.Equal(t, "ns", cfgs[0].Namespace)
+	assert.Equal(t, "export A=1\nexport HBASE_HEAPSIZE=8G", cfgs[0].Data["hbase-env.sh"])
+	assert.Equal(t, "a=b", cfgs[0].Data["log4j.properties"])
+	assert.Equal(t, "hadoop-config-hmaster", cfgs[1].Name)
+	assert.Equal(t, c.HadoopConfig, cfgs[1].Data)
+	assert.Equal(t, "hbase-config-hmaster", hbaseConfigNameOf(c, d))
+	assert.Equal(t, "export A=1", c.HbaseConfig["hbase-env.sh"])
+}
+
+// TestBuildStatefulSet_ConfigOverlay verifies a component with an overlay mounts its own ConfigMaps.
+func TestBuildStatefulSet_ConfigOverlay(t *testing.T) {
+	hbasetenant := getMockHbaseTenant()
+	d := hbasetenant.Spec.Datanode
+	d.Configuration = &kvstorev1.HbaseClusterConfigOverlay{HadoopConfig: map[string]string{"hdfs-site.xml": "<configuration></configuration>"}}
+
+	ss, err := buildStatefulSet(hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.BaseImage, false,
+		hbasetenant.Spec.Configuration, "", hbasetenant.Spec.FSGroup, d, ctrl.Log.WithName("test"), false)
+	assert.NoError(t, err)
+
+	mounted := map[string]string{}
+	for _, v := range ss.Spec.Template.Spec.Volumes {
+		if v.ConfigMap != nil {
+			mounted[v.Name] = v.ConfigMap.Name
+		}
+	}
+	assert.Equal(t, componentConfigName(hbasetenant.Spec.Configuration.HbaseConfigName, d), mounted[hbasetenant.Spec.Configuration.HbaseConfigName])
+	assert.Equal(t, componentConfigName(hbasetenant.Spec.Configuration.HadoopConfigName, d), mounted[hbasetenant.Spec.Configuration.HadoopConfigName])
+}
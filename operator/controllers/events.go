@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	reflect "reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	record "k8s.io/client-go/tools/record"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the events published by the operator. Reasons are CamelCase, named after the object they are about
+// followed by what happened to it, and end with Failed for warnings
+
+// resources created and updated by the operator
+const (
+	REASON_CONFIGMAP_CREATED         = "ConfigMapCreated"
+	REASON_CONFIGMAP_UPDATED         = "ConfigMapUpdated"
+	REASON_CONFIGMAP_CREATE_FAILED   = "ConfigMapCreateFailed"
+	REASON_CONFIGMAP_UPDATE_FAILED   = "ConfigMapUpdateFailed"
+	REASON_SERVICE_CREATED           = "ServiceCreated"
+	REASON_SERVICE_UPDATED           = "ServiceUpdated"
+	REASON_SERVICE_CREATE_FAILED     = "ServiceCreateFailed"
+	REASON_SERVICE_UPDATE_FAILED     = "ServiceUpdateFailed"
+	REASON_STATEFULSET_CREATED       = "StatefulSetCreated"
+	REASON_STATEFULSET_UPDATED       = "StatefulSetUpdated"
+	REASON_STATEFULSET_CREATE_FAILED = "StatefulSetCreateFailed"
+	REASON_STATEFULSET_UPDATE_FAILED = "StatefulSetUpdateFailed"
+	REASON_MONITOR_UPDATE_FAILED     = "MonitorUpdateFailed"
+	REASON_NAMESPACE_CREATED         = "NamespaceCreated"
+	REASON_NAMESPACE_ALTERED         = "NamespaceAltered"
+	REASON_TABLE_CREATED             = "TableCreated"
+	REASON_TABLE_ALTERED             = "TableAltered"
+	REASON_SCHEMA_UPDATE_FAILED      = "SchemaUpdateFailed"
+	REASON_QUOTAS_APPLIED            = "QuotasApplied"
+	REASON_QUOTA_UPDATE_FAILED       = "QuotaUpdateFailed"
+	REASON_RSGROUP_CREATED           = "RSGroupCreated"
+	REASON_RSGROUP_SERVERS_MOVED     = "RSGroupServersMoved"
+	REASON_RSGROUP_NAMESPACES_MOVED  = "RSGroupNamespacesMoved"
+	REASON_RSGROUP_UPDATE_FAILED     = "RSGroupUpdateFailed"
+	REASON_PEER_ADDED                = "PeerAdded"
+	REASON_PEER_UPDATED              = "PeerUpdated"
+	REASON_PEER_ENABLED              = "PeerEnabled"
+	REASON_PEER_DISABLED             = "PeerDisabled"
+	REASON_PEER_UPDATE_FAILED        = "PeerUpdateFailed"
+	REASON_SNAPSHOT_TAKEN            = "SnapshotTaken"
+	REASON_SNAPSHOTS_TAKEN           = "SnapshotsTaken"
+	REASON_SNAPSHOT_FAILED           = "SnapshotFailed"
+	REASON_BACKUP_STARTED            = "BackupStarted"
+	REASON_BACKUP_SUCCEEDED          = "BackupSucceeded"
+	REASON_BACKUP_FAILED             = "BackupFailed"
+	REASON_IMPORT_STARTED            = "ImportStarted"
+	REASON_RESTORE_SUCCEEDED         = "RestoreSucceeded"
+	REASON_RESTORE_FAILED            = "RestoreFailed"
+	REASON_ZOOKEEPER_MEMBER_ADDED    = "ZookeeperMemberAdded"
+	REASON_ZOOKEEPER_MEMBER_REMOVED  = "ZookeeperMemberRemoved"
+	REASON_ZOOKEEPER_RECONFIG_FAILED = "ZookeeperReconfigFailed"
+	REASON_BOOTSTRAP_PHASE_COMPLETED = "BootstrapPhaseCompleted"
+	REASON_BOOTSTRAP_PHASE_SKIPPED   = "BootstrapPhaseSkipped"
+	REASON_UPDATE_POLICY_MIGRATED    = "UpdatePolicyMigrated"
+)
+
+// rollouts of config changes
+const (
+	REASON_CONFIG_CHANGED           = "ConfigChanged"
+	REASON_CONFIG_DRY_RUN           = "ConfigDryRun"
+	REASON_CONFIG_RELOADED          = "ConfigReloaded"
+	REASON_CONFIG_RELOAD_FAILED     = "ConfigReloadFailed"
+	REASON_ROLLOUT_STARTED          = "RolloutStarted"
+	REASON_ROLLOUT_COMPLETED        = "RolloutCompleted"
+	REASON_NAMENODE_RESTARTED       = "NamenodeRestarted"
+	REASON_NAMENODE_FAILED_OVER     = "NamenodeFailedOver"
+	REASON_NAMENODE_FAILOVER_FAILED = "NamenodeFailoverFailed"
+	REASON_HMASTER_RESTARTED        = "HmasterRestarted"
+)
+
+// validation of the spec and of the state of hbase against it
+const (
+	REASON_CONFIG_VALIDATE_FAILED        = "ConfigValidateFailed"
+	REASON_STATEFULSET_BUILD_FAILED      = "StatefulSetBuildFailed"
+	REASON_SCHEDULE_VALIDATE_FAILED      = "ScheduleValidateFailed"
+	REASON_SCHEMA_DRIFT_DETECTED         = "SchemaDriftDetected"
+	REASON_EXTERNAL_SERVICE_CHECK_FAILED = "ExternalServiceCheckFailed"
+	REASON_ZOOKEEPER_QUORUM_UNHEALTHY    = "ZookeeperQuorumUnhealthy"
+	REASON_POLICY_VIOLATED               = "PolicyViolated"
+	REASON_TENANT_CONFIG_IGNORED         = "TenantConfigIgnored"
+	REASON_UPDATE_POLICY_LABEL_IGNORED   = "UpdatePolicyLabelIgnored"
+)
+
+// deletion of objects in hbase
+const (
+	REASON_PEER_REMOVED     = "PeerRemoved"
+	REASON_SNAPSHOTS_PRUNED = "SnapshotsPruned"
+)
+
+// kindOf returns Kind/name of a resource, as used in logs and metrics. Kind is taken from the go type as typed objects
+// read through the client have an empty TypeMeta
+func kindOf(obj client.Object) string {
+	return reflect.TypeOf(obj).Elem().Name() + "/" + obj.GetName()
+}
+
+// recordEvent records an event on the resource being reconciled and on the object the event is about, so that both
+// show it in kubectl describe. The object is skipped until it has been created
+func recordEvent(recorder record.EventRecorder, owner client.Object, object client.Object, eventType string, reason string, message string) {
+	recorder.Event(owner, eventType, reason, message)
+	if object != nil && len(object.GetUID()) > 0 && object.GetUID() != owner.GetUID() {
+		recorder.Event(object, eventType, reason, message)
+	}
+}
+
+// recordWarning records a warning event on the resource being reconciled
+func recordWarning(recorder record.EventRecorder, owner client.Object, reason string, err error) {
+	recorder.Event(owner, corev1.EventTypeWarning, reason, err.Error())
+}
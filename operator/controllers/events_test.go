@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+// newEventOwner returns the resource events of the reconcile helpers are recorded on in tests
+func newEventOwner() *kvstorev1.HbaseCluster {
+	return &kvstorev1.HbaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace, UID: "owner-uid"}}
+}
+
+// recordedReasons drains the events recorded so far and returns their reasons
+func recordedReasons(recorder *record.FakeRecorder) []string {
+	reasons := []string{}
+	for {
+		select {
+		case evt := <-recorder.Events:
+			// events are formatted as "type reason message"
+			reasons = append(reasons, strings.Fields(evt)[1])
+		default:
+			return reasons
+		}
+	}
+}
+
+// TestKindOf verifies that the kind is taken from the go type, as TypeMeta of objects read through the client is empty
+func TestKindOf(t *testing.T) {
+	assert.Equal(t, "HbaseCluster/test", kindOf(newEventOwner()))
+	assert.Equal(t, "HbaseTenant/tenant", kindOf(&kvstorev1.HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant"}}))
+}
+
+// TestRecordEvent verifies that events are recorded on the owner, and on the object once it has been created
+func TestRecordEvent(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	cfg := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "hbase-config", Namespace: testNamespace}}
+
+	recordEvent(recorder, newEventOwner(), cfg, corev1.EventTypeNormal, REASON_CONFIGMAP_CREATED, "Created ConfigMap hbase-config")
+	assert.Equal(t, []string{REASON_CONFIGMAP_CREATED}, recordedReasons(recorder))
+
+	cfg.UID = "configmap-uid"
+	recordEvent(recorder, newEventOwner(), cfg, corev1.EventTypeNormal, REASON_CONFIGMAP_UPDATED, "Updated ConfigMap hbase-config")
+	assert.Equal(t, []string{REASON_CONFIGMAP_UPDATED, REASON_CONFIGMAP_UPDATED}, recordedReasons(recorder))
+}
+
+// TestRecordWarning verifies that errors are recorded as warnings with the error as message
+func TestRecordWarning(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+
+	recordWarning(recorder, newEventOwner(), REASON_CONFIG_VALIDATE_FAILED, assert.AnError)
+	assert.Equal(t, corev1.EventTypeWarning+" "+REASON_CONFIG_VALIDATE_FAILED+" "+assert.AnError.Error(), <-recorder.Events)
+}
@@ -0,0 +1,282 @@
+package controllers
+
+import (
+	context "context"
+	net "net"
+	path "path"
+	strconv "strconv"
+	time "time"
+
+	logr "github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+const (
+	defaultExternalAccessPort      = 16020
+	defaultExternalAccessMountPath = "/etc/hbase-external-access"
+	externalAccessVolume           = "external-access"
+	externalAccessLabel            = "hbasecluster_external_access"
+	EXTERNAL_ACCESS_POD_ENV        = "HBASE_EXTERNAL_ACCESS_POD"
+	EXTERNAL_ACCESS_SITE_ENV       = "HBASE_EXTERNAL_ACCESS_SITE"
+)
+
+// externalAccessConfigName returns the name of the ConfigMap with the hbase-site.xml overrides of the regionservers
+func externalAccessConfigName(crName string) string {
+	return crName + "-external-access"
+}
+
+// externalServiceName returns the name of the Service exposing the pod outside of the kubernetes cluster
+func externalServiceName(pod string) string {
+	return pod + "-external"
+}
+
+// externalAccessSettings returns the service type, regionserver port and mount path, with their defaults
+func externalAccessSettings(e *kvstorev1.HbaseClusterExternalAccess) (corev1.ServiceType, int32, string) {
+	serviceType, port, mountPath := e.Type, e.Port, e.MountPath
+	if len(serviceType) == 0 {
+		serviceType = corev1.ServiceTypeLoadBalancer
+	}
+	if port == 0 {
+		port = defaultExternalAccessPort
+	}
+	if len(mountPath) == 0 {
+		mountPath = defaultExternalAccessMountPath
+	}
+	return serviceType, port, mountPath
+}
+
+// isExternallyAccessible tells whether the pods of the deployment are exposed outside of the kubernetes cluster, which
+// the ones of the datanode deployment running the regionservers are
+func isExternallyAccessible(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) bool {
+	return c.Spec.ExternalAccess != nil && d.Name == c.Spec.Deployments.Datanode.Name
+}
+
+// externalPort returns the port the regionserver of the ordinal is exposed at. With NodePort, it also is the port it
+// listens on, so that the port it advertises is reachable
+func externalPort(e *kvstorev1.HbaseClusterExternalAccess, ordinal int32) int32 {
+	serviceType, port, _ := externalAccessSettings(e)
+	if serviceType == corev1.ServiceTypeNodePort {
+		return e.NodePortBase + ordinal
+	}
+	return port
+}
+
+// buildExternalService returns the Service exposing the pod of the ordinal outside of the kubernetes cluster
+func buildExternalService(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment, ordinal int32) *corev1.Service {
+	e := c.Spec.ExternalAccess
+	serviceType, _, _ := externalAccessSettings(e)
+	pod := d.Name + "-" + strconv.Itoa(int(ordinal))
+	port := externalPort(e, ordinal)
+	servicePort := corev1.ServicePort{
+		Name:       "regionserver",
+		Port:       port,
+		TargetPort: intstr.FromInt(int(port)),
+		Protocol:   corev1.ProtocolTCP,
+	}
+	if serviceType == corev1.ServiceTypeNodePort {
+		servicePort.NodePort = port
+	}
+
+	labels := getSharedLabelsMap(c.Name, nil)
+	labels[externalAccessLabel] = d.Name
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        externalServiceName(pod),
+			Namespace:   c.Namespace,
+			Labels:      labels,
+			Annotations: e.Annotations,
+		},
+		Spec: corev1.ServiceSpec{
+			Type:                     serviceType,
+			PublishNotReadyAddresses: true,
+			Selector:                 labelsForPodService(c.Name, pod, nil),
+			Ports:                    []corev1.ServicePort{servicePort},
+		},
+	}
+}
+
+// externalAddress returns the address the pod is reachable at through its Service: the ingress of the load balancer,
+// or the external IP of the node of the pod, its internal IP without one. It is empty until known
+func externalAddress(ctx context.Context, svc *corev1.Service, namespace string, pod string, cl client.Client) (string, error) {
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if len(ingress.Hostname) > 0 {
+				return ingress.Hostname, nil
+			}
+			if len(ingress.IP) > 0 {
+				return ingress.IP, nil
+			}
+		}
+		return "", nil
+	}
+
+	p := &corev1.Pod{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: pod, Namespace: namespace}, p); err != nil {
+		return "", client.IgnoreNotFound(err)
+	}
+	if len(p.Spec.NodeName) == 0 {
+		return "", nil
+	}
+	node := &corev1.Node{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: p.Spec.NodeName}, node); err != nil {
+		return "", err
+	}
+	address := ""
+	for _, a := range node.Status.Addresses {
+		if a.Type == corev1.NodeExternalIP {
+			return a.Address, nil
+		}
+		if a.Type == corev1.NodeInternalIP && len(address) == 0 {
+			address = a.Address
+		}
+	}
+	return address, nil
+}
+
+// externalAccessSite returns the hbase-site.xml overrides advertising the external address of a regionserver. The rpc
+// server keeps listening on all interfaces, as the address advertised is not one of the pod
+func externalAccessSite(e *kvstorev1.HbaseClusterExternalAccess, address string, ordinal int32) string {
+	serviceType, _, _ := externalAccessSettings(e)
+	properties := []hadoopConfigProperty{
+		{Name: "hbase.regionserver.hostname", Value: address},
+		{Name: "hbase.regionserver.ipc.address", Value: "0.0.0.0"},
+	}
+	if serviceType == corev1.ServiceTypeNodePort {
+		properties = append(properties, hadoopConfigProperty{Name: "hbase.regionserver.port", Value: strconv.Itoa(int(externalPort(e, ordinal)))})
+	}
+	return renderHadoopConfiguration(hadoopConfiguration{Properties: properties})
+}
+
+// injectExternalAccess mounts the overrides into the containers of datanode pods, and sets the path of the ones of the
+// pod in their environment
+func injectExternalAccess(ss *appsv1.StatefulSet, c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) {
+	if !isExternallyAccessible(c, d) {
+		return
+	}
+	_, _, mountPath := externalAccessSettings(c.Spec.ExternalAccess)
+	optional := true
+	pod := &ss.Spec.Template.Spec
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: externalAccessVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: externalAccessConfigName(c.Name)},
+				Optional:             &optional,
+			},
+		},
+	})
+	for i := range pod.Containers {
+		pod.Containers[i].VolumeMounts = append(pod.Containers[i].VolumeMounts,
+			corev1.VolumeMount{Name: externalAccessVolume, MountPath: mountPath, ReadOnly: true})
+		pod.Containers[i].Env = append(pod.Containers[i].Env,
+			corev1.EnvVar{Name: EXTERNAL_ACCESS_POD_ENV, ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}}},
+			corev1.EnvVar{Name: EXTERNAL_ACCESS_SITE_ENV, Value: path.Join(mountPath, "$("+EXTERNAL_ACCESS_POD_ENV+").xml")})
+	}
+}
+
+// deleteExternalServices deletes the Services of the pods removed by a scale down, so that their load balancers are
+// released
+func deleteExternalServices(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment,
+	wanted map[string]bool, cl client.Client) error {
+	services := &corev1.ServiceList{}
+	labels := getSharedLabelsMap(c.Name, nil)
+	labels[externalAccessLabel] = d.Name
+	if err := cl.List(ctx, services, client.InNamespace(c.Namespace), client.MatchingLabels(labels)); err != nil {
+		return err
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		if wanted[svc.Name] {
+			continue
+		}
+		log.Info("Deleting Service of a removed pod", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+		if err := cl.Delete(ctx, svc); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileExternalAccess exposes the regionservers through a Service each, renders the overrides advertising their
+// external address, and lists their endpoints in status. It requeues until all addresses are known. Regionservers read
+// the overrides on start, so the ones started before their address was known pick it up on their next restart
+func reconcileExternalAccess(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, recorder record.EventRecorder,
+	scheme *runtime.Scheme, cl client.Client) (ctrl.Result, error) {
+	e := c.Spec.ExternalAccess
+	if e == nil {
+		return ctrl.Result{}, nil
+	}
+	d := c.Spec.Deployments.Datanode
+	_, port, _ := externalAccessSettings(e)
+
+	wanted := map[string]bool{}
+	endpoints := []kvstorev1.ExternalEndpointStatus{}
+	sites := map[string]string{}
+	for i := int32(0); i < d.Size; i++ {
+		pod := d.Name + "-" + strconv.Itoa(int(i))
+		svc := buildExternalService(c, d, i)
+		ctrl.SetControllerReference(c, svc, scheme)
+		if result, err := reconcileService(ctx, log, c.Namespace, svc, c, recorder, cl); err != nil {
+			return result, err
+		}
+		wanted[svc.Name] = true
+
+		live := &corev1.Service{}
+		if err := cl.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: c.Namespace}, live); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, client.IgnoreNotFound(err)
+		}
+		address, err := externalAddress(ctx, live, c.Namespace, pod, cl)
+		if err != nil {
+			log.Error(err, "Failed to get the external address", "Pod", pod)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		endpoint := kvstorev1.ExternalEndpointStatus{Pod: pod, Service: svc.Name}
+		if len(address) > 0 {
+			if live.Spec.Type == corev1.ServiceTypeLoadBalancer {
+				endpoint.Endpoint = net.JoinHostPort(address, strconv.Itoa(int(port)))
+			} else {
+				endpoint.Endpoint = net.JoinHostPort(address, strconv.Itoa(int(externalPort(e, i))))
+			}
+			sites[pod+".xml"] = externalAccessSite(e, address, i)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	if err := deleteExternalServices(ctx, log, c, d, wanted, cl); err != nil {
+		log.Error(err, "Failed to delete the Services of removed pods")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	cfg := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: externalAccessConfigName(c.Name), Namespace: c.Namespace},
+		Data:       sites,
+	}
+	ctrl.SetControllerReference(c, cfg, scheme)
+	if result, err := reconcileConfigMap(ctx, log, c.Namespace, cfg, c, recorder, cl); err != nil {
+		return result, err
+	}
+
+	if !equality.Semantic.DeepEqual(endpoints, c.Status.ExternalEndpoints) {
+		c.Status.ExternalEndpoints = endpoints
+		if err := cl.Status().Update(ctx, c); err != nil {
+			log.Error(err, "Failed to update HbaseCluster status with the external endpoints")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+	if len(sites) < len(endpoints) {
+		log.Info("Waiting for the external address of regionservers", "Known", len(sites), "Regionservers", len(endpoints))
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	return ctrl.Result{}, nil
+}
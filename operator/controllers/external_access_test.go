@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newExternalAccessCluster(e *kvstorev1.HbaseClusterExternalAccess) *kvstorev1.HbaseCluster {
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{
+				Datanode: kvstorev1.HbaseClusterDeployment{Name: "dn", Size: 2},
+				Hmaster:  kvstorev1.HbaseClusterDeployment{Name: "hmaster", Size: 2},
+			},
+			ExternalAccess: e,
+		},
+	}
+}
+
+// TestBuildExternalService verifies each pod gets a Service of its own, listening on its node port with NodePort.
+func TestBuildExternalService(t *testing.T) {
+	c := newExternalAccessCluster(&kvstorev1.HbaseClusterExternalAccess{Type: corev1.ServiceTypeNodePort, NodePortBase: 30020,
+		Annotations: map[string]string{"example.com/lb": "internal"}})
+
+	svc := buildExternalService(c, c.Spec.Deployments.Datanode, 1)
+	assert.Equal(t, "dn-1-external", svc.Name)
+	assert.Equal(t, "internal", svc.Annotations["example.com/lb"])
+	assert.Equal(t, corev1.ServiceTypeNodePort, svc.Spec.Type)
+	assert.Equal(t, "dn-1", svc.Spec.Selector["statefulset.kubernetes.io/pod-name"])
+	assert.Equal(t, int32(30021), svc.Spec.Ports[0].Port)
+	assert.Equal(t, int32(30021), svc.Spec.Ports[0].NodePort)
+	assert.Equal(t, 30021, svc.Spec.Ports[0].TargetPort.IntValue())
+
+	site, _ := parseConfigProperties("hbase-site.xml", externalAccessSite(c.Spec.ExternalAccess, "203.0.113.1", 1))
+	assert.Equal(t, map[string]string{"hbase.regionserver.hostname": "203.0.113.1", "hbase.regionserver.ipc.address": "0.0.0.0",
+		"hbase.regionserver.port": "30021"}, site)
+
+	c.Spec.ExternalAccess = &kvstorev1.HbaseClusterExternalAccess{}
+	svc = buildExternalService(c, c.Spec.Deployments.Datanode, 1)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, int32(16020), svc.Spec.Ports[0].Port)
+	assert.Zero(t, svc.Spec.Ports[0].NodePort)
+	site, _ = parseConfigProperties("hbase-site.xml", externalAccessSite(c.Spec.ExternalAccess, "rs.example.com", 1))
+	assert.NotContains(t, site, "hbase.regionserver.port")
+}
+
+// TestInjectExternalAccess verifies the overrides are mounted into datanode pods only, with their path in the
+// environment of the containers.
+func TestInjectExternalAccess(t *testing.T) {
+	c := newExternalAccessCluster(&kvstorev1.HbaseClusterExternalAccess{})
+	newSS := func() *appsv1.StatefulSet {
+		ss := &appsv1.StatefulSet{}
+		ss.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main"}, {Name: "sidecar"}}
+		return ss
+	}
+
+	ss := newSS()
+	injectExternalAccess(ss, c, c.Spec.Deployments.Datanode)
+	assert.Equal(t, "test-external-access", ss.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+	assert.True(t, *ss.Spec.Template.Spec.Volumes[0].ConfigMap.Optional)
+	for _, container := range ss.Spec.Template.Spec.Containers {
+		assert.Equal(t, defaultExternalAccessMountPath, container.VolumeMounts[0].MountPath)
+		assert.Equal(t, "metadata.name", container.Env[0].ValueFrom.FieldRef.FieldPath)
+		assert.Equal(t, corev1.EnvVar{Name: EXTERNAL_ACCESS_SITE_ENV, Value: "/etc/hbase-external-access/$(HBASE_EXTERNAL_ACCESS_POD).xml"}, container.Env[1])
+	}
+
+	ss = newSS()
+	injectExternalAccess(ss, c, c.Spec.Deployments.Hmaster)
+	assert.Empty(t, ss.Spec.Template.Spec.Volumes)
+}
+
+// TestReconcileExternalAccess verifies overrides are rendered for the pods whose load balancer has an address, the
+// endpoints listed in status, the Services of removed pods deleted, and the reconciliation requeued until all
+// addresses are known.
+func TestReconcileExternalAccess(t *testing.T) {
+	ctx := context.TODO()
+	c := newExternalAccessCluster(&kvstorev1.HbaseClusterExternalAccess{})
+	scheme := runtime.NewScheme()
+	_ = kvstorev1.AddToScheme(scheme)
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "dn-0-external", Namespace: testNamespace}, &corev1.Service{}).
+		Run(func(args mock.Arguments) {
+			svc := args.Get(2).(*corev1.Service)
+			svc.Spec.Type = corev1.ServiceTypeLoadBalancer
+			svc.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.1"}}
+		}).
+		Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "dn-1-external", Namespace: testNamespace}, &corev1.Service{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*corev1.Service).Spec.Type = corev1.ServiceTypeLoadBalancer
+		}).
+		Return(nil)
+	k8sMockClient.On("Update", ctx, mock.Anything, mock.Anything).Return(nil)
+	stale := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "dn-2-external", Namespace: testNamespace}}
+	k8sMockClient.On("List", ctx, &corev1.ServiceList{}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*corev1.ServiceList).Items = []corev1.Service{
+				{ObjectMeta: metav1.ObjectMeta{Name: "dn-0-external", Namespace: testNamespace}}, stale,
+			}
+		}).
+		Return(nil)
+	k8sMockClient.On("Delete", ctx, &stale).Return(nil).Once()
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "test-external-access", Namespace: testNamespace}, &corev1.ConfigMap{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, "test-external-access"))
+	var sites map[string]string
+	k8sMockClient.On("Create", ctx, mock.AnythingOfType("*v1.ConfigMap"), mock.Anything).
+		Run(func(args mock.Arguments) {
+			sites = args.Get(1).(*corev1.ConfigMap).Data
+		}).
+		Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileExternalAccess(ctx, ctrl.Log.WithName("test"), c, recorder, scheme, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{"dn-0.xml"}, sortedKeys(sites))
+	assert.Equal(t, []kvstorev1.ExternalEndpointStatus{
+		{Pod: "dn-0", Service: "dn-0-external", Endpoint: "203.0.113.1:16020"},
+		{Pod: "dn-1", Service: "dn-1-external"},
+	}, c.Status.ExternalEndpoints)
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestExternalAddress_NodePort verifies the external IP of the node of the pod is preferred over its internal IP, and
+// that pods not scheduled yet have no address.
+func TestExternalAddress_NodePort(t *testing.T) {
+	ctx := context.TODO()
+	svc := &corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeNodePort}}
+	k8sMockClient := new(K8sMockClient)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "dn-0", Namespace: testNamespace}, &corev1.Pod{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*corev1.Pod).Spec.NodeName = "node-a"
+		}).
+		Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "dn-1", Namespace: testNamespace}, &corev1.Pod{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, "dn-1"))
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "node-a"}, &corev1.Node{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*corev1.Node).Status.Addresses = []corev1.NodeAddress{
+				{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: corev1.NodeExternalIP, Address: "203.0.113.1"},
+			}
+		}).
+		Return(nil)
+
+	address, err := externalAddress(ctx, svc, testNamespace, "dn-0", k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, "203.0.113.1", address)
+	address, err = externalAddress(ctx, svc, testNamespace, "dn-1", k8sMockClient)
+	assert.NoError(t, err)
+	assert.Empty(t, address)
+}
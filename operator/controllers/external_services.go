@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	context "context"
+	errs "errors"
+	net "net"
+	strings "strings"
+	time "time"
+
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// EXTERNAL_SERVICE_DIAL_TIMEOUT timeout of the connection to a server of an external ZooKeeper or HDFS
+const EXTERNAL_SERVICE_DIAL_TIMEOUT = 3 * time.Second
+
+const defaultZookeeperPort = "2181"
+const defaultNamenodePort = "8020"
+
+// externalConfigSourceFiles files of the config source of an external HDFS merged into hadoopConfig
+var externalConfigSourceFiles = []string{"core-site.xml", "hdfs-site.xml"}
+
+// clusterDeployments returns the deployments of the cluster, without the ones replaced by external services
+func clusterDeployments(spec kvstorev1.HbaseClusterSpec) []kvstorev1.HbaseClusterDeployment {
+	d := spec.Deployments
+	deployments := []kvstorev1.HbaseClusterDeployment{}
+	if d.Zookeeper.Size != 0 && spec.ExternalZookeeper == nil {
+		deployments = append(deployments, d.Zookeeper)
+	}
+	if spec.ExternalHDFS == nil {
+		deployments = append(deployments, d.Journalnode, d.Namenode)
+	}
+	return append(deployments, d.Datanode, d.Hmaster)
+}
+
+// zookeeperServers returns host:port of the servers of an external ZooKeeper
+func zookeeperServers(zk *kvstorev1.HbaseExternalZookeeper) []string {
+	servers := []string{}
+	for _, server := range strings.Split(zk.Quorum, ",") {
+		server = strings.TrimSpace(server)
+		if len(server) == 0 {
+			continue
+		}
+		if !strings.Contains(server, ":") {
+			server = server + ":" + defaultZookeeperPort
+		}
+		servers = append(servers, server)
+	}
+	return servers
+}
+
+// hdfsRootDir returns the URI of the directory of hbase in an external HDFS
+func hdfsRootDir(hdfs *kvstorev1.HbaseExternalHDFS) string {
+	rootDir := hdfs.RootDir
+	if len(rootDir) == 0 {
+		rootDir = "/hbase"
+	}
+	return "hdfs://" + hdfs.Nameservice + "/" + strings.TrimPrefix(rootDir, "/")
+}
+
+// namenodeAddresses returns host:port of the namenodes of an external HDFS, as defined for its nameservice in
+// hdfs-site.xml. The nameservice is taken as the address of the namenode when hdfs-site.xml does not define it
+func namenodeAddresses(hdfs *kvstorev1.HbaseExternalHDFS, hadoopConfig map[string]string) []string {
+	addresses := []string{}
+	properties, _ := parseConfigProperties("hdfs-site.xml", hadoopConfig["hdfs-site.xml"])
+	for _, nn := range strings.Split(properties["dfs.ha.namenodes."+hdfs.Nameservice], ",") {
+		nn = strings.TrimSpace(nn)
+		if address := properties["dfs.namenode.rpc-address."+hdfs.Nameservice+"."+nn]; len(nn) > 0 && len(address) > 0 {
+			addresses = append(addresses, address)
+		}
+	}
+	if address := properties["dfs.namenode.rpc-address."+hdfs.Nameservice]; len(addresses) == 0 && len(address) > 0 {
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		address := hdfs.Nameservice
+		if !strings.Contains(address, ":") {
+			address = address + ":" + defaultNamenodePort
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// externalServicesConfig returns the hbase-site.xml and core-site.xml properties pointing hbase to the external
+// ZooKeeper and HDFS of the cluster, as config overlays of hbaseConfig and hadoopConfig
+func externalServicesConfig(spec kvstorev1.HbaseClusterSpec) (map[string]string, map[string]string) {
+	hbaseSite := hadoopConfiguration{}
+	hbaseConfig, hadoopConfig := map[string]string{}, map[string]string{}
+	if zk := spec.ExternalZookeeper; zk != nil {
+		hbaseSite.Properties = append(hbaseSite.Properties, hadoopConfigProperty{Name: "hbase.zookeeper.quorum", Value: strings.Join(zookeeperServers(zk), ",")})
+		if len(zk.Chroot) > 0 {
+			hbaseSite.Properties = append(hbaseSite.Properties, hadoopConfigProperty{Name: "zookeeper.znode.parent", Value: zk.Chroot})
+		}
+	}
+	if hdfs := spec.ExternalHDFS; hdfs != nil {
+		hbaseSite.Properties = append(hbaseSite.Properties, hadoopConfigProperty{Name: "hbase.rootdir", Value: hdfsRootDir(hdfs)})
+		coreSite := hadoopConfiguration{Properties: []hadoopConfigProperty{{Name: "fs.defaultFS", Value: "hdfs://" + hdfs.Nameservice}}}
+		hadoopConfig["core-site.xml"] = renderHadoopConfiguration(coreSite)
+	}
+	if len(hbaseSite.Properties) > 0 {
+		hbaseConfig["hbase-site.xml"] = renderHadoopConfiguration(hbaseSite)
+	}
+	return hbaseConfig, hadoopConfig
+}
+
+// resolveExternalServices merges the config source of the external HDFS into hadoopConfig, and points hbase-site.xml
+// and core-site.xml to the external ZooKeeper and HDFS of the cluster
+func resolveExternalServices(ctx context.Context, log logr.Logger, namespace string, spec kvstorev1.HbaseClusterSpec,
+	c kvstorev1.HbaseClusterConfiguration, cl client.Client) (kvstorev1.HbaseClusterConfiguration, error) {
+	if spec.ExternalZookeeper == nil && spec.ExternalHDFS == nil {
+		return c, nil
+	}
+
+	if hdfs := spec.ExternalHDFS; hdfs != nil && hdfs.ConfigSource != nil {
+		source, err := getConfigMap(log, cl, ctx, hdfs.ConfigSource.Name, namespace)
+		if err != nil {
+			return c, err
+		}
+		files := map[string]string{}
+		for _, file := range externalConfigSourceFiles {
+			if content, ok := source.Data[file]; ok {
+				if !isValidXML(content) {
+					return c, errs.New("ConfigMap: " + hdfs.ConfigSource.Name + ". Invalid XML file " + file)
+				}
+				files[file] = content
+			}
+		}
+		c.HadoopConfig = mergeConfig(c.HadoopConfig, files)
+	}
+
+	hbaseConfig, hadoopConfig := externalServicesConfig(spec)
+	c.HbaseConfig = mergeConfig(c.HbaseConfig, hbaseConfig)
+	c.HadoopConfig = mergeConfig(c.HadoopConfig, hadoopConfig)
+	return c, nil
+}
+
+// checkReachable returns nil if a connection can be opened to one of the servers, the last error otherwise
+func checkReachable(servers []string) error {
+	var err error
+	for _, server := range servers {
+		var conn net.Conn
+		conn, err = net.DialTimeout("tcp", server, EXTERNAL_SERVICE_DIAL_TIMEOUT)
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+	}
+	return err
+}
+
+// checkExternalServices checks that the external ZooKeeper and the namenodes of the external HDFS can be reached
+func checkExternalServices(log logr.Logger, spec kvstorev1.HbaseClusterSpec, hadoopConfig map[string]string) error {
+	if zk := spec.ExternalZookeeper; zk != nil {
+		servers := zookeeperServers(zk)
+		if err := checkReachable(servers); err != nil {
+			log.Error(err, "External ZooKeeper is not reachable", "Servers", servers)
+			return errs.New("External ZooKeeper " + strings.Join(servers, ",") + " is not reachable. " + err.Error())
+		}
+	}
+	if hdfs := spec.ExternalHDFS; hdfs != nil {
+		namenodes := namenodeAddresses(hdfs, hadoopConfig)
+		if err := checkReachable(namenodes); err != nil {
+			log.Error(err, "External HDFS is not reachable", "Namenodes", namenodes)
+			return errs.New("External HDFS " + hdfs.Nameservice + " is not reachable at " + strings.Join(namenodes, ",") + ". " + err.Error())
+		}
+	}
+	return nil
+}
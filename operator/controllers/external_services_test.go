@@ -0,0 +1,155 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const testHdfsSite = `<configuration>
+<property><name>dfs.nameservices</name><value>shared</value></property>
+<property><name>dfs.ha.namenodes.shared</name><value>nn0,nn1</value></property>
+<property><name>dfs.namenode.rpc-address.shared.nn0</name><value>nn-0.hdfs:8020</value></property>
+<property><name>dfs.namenode.rpc-address.shared.nn1</name><value>nn-1.hdfs:8020</value></property>
+</configuration>`
+
+func newExternalServicesSpec() kvstorev1.HbaseClusterSpec {
+	return kvstorev1.HbaseClusterSpec{
+		Deployments: kvstorev1.HbaseClusterDeployments{
+			Zookeeper:   kvstorev1.HbaseClusterDeployment{Name: "zk", Size: 3},
+			Journalnode: kvstorev1.HbaseClusterDeployment{Name: "jn", Size: 3},
+			Namenode:    kvstorev1.HbaseClusterDeployment{Name: "nn", Size: 2},
+			Datanode:    kvstorev1.HbaseClusterDeployment{Name: "dn", Size: 3},
+			Hmaster:     kvstorev1.HbaseClusterDeployment{Name: "hmaster", Size: 2},
+		},
+		Configuration: kvstorev1.HbaseClusterConfiguration{
+			HbaseConfigName:  "hbase-config",
+			HbaseConfig:      map[string]string{"hbase-site.xml": testHbaseSite},
+			HadoopConfigName: "hadoop-config",
+			HadoopConfig:     map[string]string{},
+		},
+	}
+}
+
+// listenLocal starts a local stand-in of an external server and returns its address
+func listenLocal(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return l.Addr().String()
+}
+
+// closedLocalAddress returns a local address nothing listens on
+func closedLocalAddress(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	address := l.Addr().String()
+	l.Close()
+	return address
+}
+
+// TestClusterDeployments verifies the deployments replaced by external services are skipped.
+func TestClusterDeployments(t *testing.T) {
+	names := func(deployments []kvstorev1.HbaseClusterDeployment) []string {
+		result := []string{}
+		for _, d := range deployments {
+			result = append(result, d.Name)
+		}
+		return result
+	}
+
+	spec := newExternalServicesSpec()
+	assert.Equal(t, []string{"zk", "jn", "nn", "dn", "hmaster"}, names(clusterDeployments(spec)))
+
+	spec.Deployments.Zookeeper.Size = 0
+	assert.Equal(t, []string{"jn", "nn", "dn", "hmaster"}, names(clusterDeployments(spec)))
+
+	spec = newExternalServicesSpec()
+	spec.ExternalZookeeper = &kvstorev1.HbaseExternalZookeeper{Quorum: "zk-0"}
+	spec.ExternalHDFS = &kvstorev1.HbaseExternalHDFS{Nameservice: "shared"}
+	assert.Equal(t, []string{"dn", "hmaster"}, names(clusterDeployments(spec)))
+}
+
+// TestZookeeperServers verifies the default port is added to servers of the quorum without one.
+func TestZookeeperServers(t *testing.T) {
+	zk := &kvstorev1.HbaseExternalZookeeper{Quorum: "zk-0, zk-1:2182,,zk-2"}
+	assert.Equal(t, []string{"zk-0:2181", "zk-1:2182", "zk-2:2181"}, zookeeperServers(zk))
+}
+
+// TestNamenodeAddresses verifies namenodes are taken from hdfs-site.xml, and from the nameservice without it.
+func TestNamenodeAddresses(t *testing.T) {
+	hdfs := &kvstorev1.HbaseExternalHDFS{Nameservice: "shared"}
+	assert.Equal(t, []string{"nn-0.hdfs:8020", "nn-1.hdfs:8020"}, namenodeAddresses(hdfs, map[string]string{"hdfs-site.xml": testHdfsSite}))
+	assert.Equal(t, []string{"shared:8020"}, namenodeAddresses(hdfs, map[string]string{}))
+
+	hdfs.Nameservice = "namenode.hdfs:9000"
+	assert.Equal(t, []string{"namenode.hdfs:9000"}, namenodeAddresses(hdfs, map[string]string{"hdfs-site.xml": testHdfsSite}))
+}
+
+// TestResolveExternalServices verifies endpoints are set in the site files, on top of the config source.
+func TestResolveExternalServices(t *testing.T) {
+	ctx := context.TODO()
+	mockClient := new(K8sMockClient)
+	mockClient.On("Get", ctx, types.NamespacedName{Name: "shared-hdfs", Namespace: testNamespace}, &corev1.ConfigMap{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*corev1.ConfigMap)
+			arg.Data = map[string]string{"hdfs-site.xml": testHdfsSite, "log4j.properties": "ignored=true"}
+		}).
+		Return(nil)
+
+	spec := newExternalServicesSpec()
+	spec.ExternalZookeeper = &kvstorev1.HbaseExternalZookeeper{Quorum: "zk-0,zk-1", Chroot: "/hbase-a"}
+	spec.ExternalHDFS = &kvstorev1.HbaseExternalHDFS{Nameservice: "shared", RootDir: "/hbase-a",
+		ConfigSource: &corev1.LocalObjectReference{Name: "shared-hdfs"}}
+
+	c, err := resolveExternalServices(ctx, ctrl.Log.WithName("test"), testNamespace, spec, spec.Configuration, mockClient)
+	assert.NoError(t, err)
+
+	hbaseSite, _ := parseConfigProperties("hbase-site.xml", c.HbaseConfig["hbase-site.xml"])
+	assert.Equal(t, "zk-0:2181,zk-1:2181", hbaseSite["hbase.zookeeper.quorum"])
+	assert.Equal(t, "/hbase-a", hbaseSite["zookeeper.znode.parent"])
+	assert.Equal(t, "hdfs://shared/hbase-a", hbaseSite["hbase.rootdir"])
+	assert.Equal(t, "30", hbaseSite["hbase.regionserver.handler.count"])
+	coreSite, _ := parseConfigProperties("core-site.xml", c.HadoopConfig["core-site.xml"])
+	assert.Equal(t, "hdfs://shared", coreSite["fs.defaultFS"])
+	assert.Equal(t, []string{"nn-0.hdfs:8020", "nn-1.hdfs:8020"}, namenodeAddresses(spec.ExternalHDFS, c.HadoopConfig))
+	assert.NotContains(t, c.HadoopConfig, "log4j.properties")
+	// the spec itself is left untouched
+	assert.Empty(t, spec.Configuration.HadoopConfig)
+	mockClient.AssertExpectations(t)
+}
+
+// TestResolveExternalServices_NotSet verifies the configuration is returned as is without external services.
+func TestResolveExternalServices_NotSet(t *testing.T) {
+	spec := newExternalServicesSpec()
+	c, err := resolveExternalServices(context.TODO(), ctrl.Log.WithName("test"), testNamespace, spec, spec.Configuration, new(K8sMockClient))
+	assert.NoError(t, err)
+	assert.Equal(t, spec.Configuration, c)
+}
+
+// TestCheckExternalServices verifies external services are reachable as long as one of their servers is.
+func TestCheckExternalServices(t *testing.T) {
+	log := ctrl.Log.WithName("test")
+	zk := listenLocal(t)
+	nn := listenLocal(t)
+	closed := closedLocalAddress(t)
+
+	spec := newExternalServicesSpec()
+	spec.ExternalZookeeper = &kvstorev1.HbaseExternalZookeeper{Quorum: closed + "," + zk}
+	spec.ExternalHDFS = &kvstorev1.HbaseExternalHDFS{Nameservice: nn}
+	assert.NoError(t, checkExternalServices(log, spec, map[string]string{}))
+
+	spec.ExternalZookeeper.Quorum = closed
+	assert.ErrorContains(t, checkExternalServices(log, spec, map[string]string{}), "External ZooKeeper")
+
+	spec.ExternalZookeeper = nil
+	spec.ExternalHDFS.Nameservice = closed
+	assert.ErrorContains(t, checkExternalServices(log, spec, map[string]string{}), "External HDFS")
+}
@@ -0,0 +1,310 @@
+package controllers
+
+import (
+	bytes "bytes"
+	context "context"
+	json "encoding/json"
+	errs "errors"
+	fmt "fmt"
+	io "io"
+	http "net/http"
+	url "net/url"
+	strings "strings"
+	time "time"
+)
+
+// HbaseAdmin performs online administrative operations against a running HBase cluster. HBase does not serve them over
+// HTTP, they go through an admin endpoint implementing the contract of docs/setup/additional/adminendpoint.md, such as
+// the reference server of utilities/adminserver
+type HbaseAdmin interface {
+	// UpdateAllConfig asks the master and all the regionservers to reload their configuration from disk
+	UpdateAllConfig(ctx context.Context) error
+	// GetRSGroup returns the RSGroup with the given name, nil when it does not exist
+	GetRSGroup(ctx context.Context, name string) (*RSGroupInfo, error)
+	// AddRSGroup creates an empty RSGroup
+	AddRSGroup(ctx context.Context, name string) error
+	// MoveServersToRSGroup moves regionservers, as host:port, into the RSGroup
+	MoveServersToRSGroup(ctx context.Context, name string, servers []string) error
+	// MoveNamespacesToRSGroup moves HBase namespaces into the RSGroup, along with all of their tables
+	MoveNamespacesToRSGroup(ctx context.Context, name string, namespaces []string) error
+	// ListQuotas returns all the throttle and space quotas
+	ListQuotas(ctx context.Context) ([]QuotaSettings, error)
+	// SetQuota creates or replaces the quota of the same target and type
+	SetQuota(ctx context.Context, q QuotaSettings) error
+	// RemoveQuota removes the quota of the same target and type
+	RemoveQuota(ctx context.Context, q QuotaSettings) error
+	// GetSpaceQuotaUsage returns the usage of the namespaces with a space quota
+	GetSpaceQuotaUsage(ctx context.Context) ([]SpaceQuotaUsage, error)
+	// CreateTable creates the table pre-split at the given keys, which the REST gateway does not support
+	CreateTable(ctx context.Context, schema TableSchema, splitKeys []string) error
+	// ListTables returns the names of all the tables, as namespace:name
+	ListTables(ctx context.Context) ([]string, error)
+	// Snapshot takes a snapshot of the table with the given name
+	Snapshot(ctx context.Context, name string, table string) error
+	// ListSnapshots returns all the snapshots
+	ListSnapshots(ctx context.Context) ([]SnapshotInfo, error)
+	// DeleteSnapshot deletes the snapshot with the given name
+	DeleteSnapshot(ctx context.Context, name string) error
+	// RestoreSnapshot restores the table of the snapshot in place, the table must be disabled
+	RestoreSnapshot(ctx context.Context, name string) error
+	// CloneSnapshot creates a new table with the contents of the snapshot
+	CloneSnapshot(ctx context.Context, name string, table string) error
+	// ListReplicationPeers returns all the replication peers
+	ListReplicationPeers(ctx context.Context) ([]ReplicationPeer, error)
+	// AddReplicationPeer adds a peer, enabled or not
+	AddReplicationPeer(ctx context.Context, p ReplicationPeer) error
+	// UpdateReplicationPeer updates the tables replicated to the peer, its cluster key and serial flag can not change
+	UpdateReplicationPeer(ctx context.Context, p ReplicationPeer) error
+	// SetReplicationPeerEnabled enables or disables shipping edits to the peer
+	SetReplicationPeerEnabled(ctx context.Context, id string, enabled bool) error
+	// RemoveReplicationPeer removes the peer, edits queued for it are dropped
+	RemoveReplicationPeer(ctx context.Context, id string) error
+	// GetReplicationLoad returns the progress of the replication sources of all the regionservers
+	GetReplicationLoad(ctx context.Context) ([]ReplicationSourceLoad, error)
+}
+
+// RSGroupInfo is an RSGroup as returned by the admin endpoint
+type RSGroupInfo struct {
+	Name       string   `json:"name"`
+	Servers    []string `json:"servers,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// QuotaSettings is a throttle quota, when ThrottleType is set, or a space quota of a namespace otherwise
+type QuotaSettings struct {
+	User         string `json:"user,omitempty"`
+	Namespace    string `json:"namespace,omitempty"`
+	Table        string `json:"table,omitempty"`
+	ThrottleType string `json:"throttleType,omitempty"`
+	// Limit of a throttle quota, as in the hbase shell
+	Limit string `json:"limit,omitempty"`
+	// SpaceLimit in bytes and violation Policy of a space quota
+	SpaceLimit int64  `json:"spaceLimit,omitempty"`
+	Policy     string `json:"policy,omitempty"`
+}
+
+// SpaceQuotaUsage is the space used by a namespace with a space quota, in bytes
+type SpaceQuotaUsage struct {
+	Namespace   string `json:"namespace"`
+	Usage       int64  `json:"usage"`
+	Limit       int64  `json:"limit"`
+	InViolation bool   `json:"inViolation"`
+}
+
+// SnapshotInfo is a snapshot as returned by the admin endpoint, created at CreationTime in milliseconds since epoch
+type SnapshotInfo struct {
+	Name         string `json:"name"`
+	Table        string `json:"table"`
+	CreationTime int64  `json:"creationTime"`
+}
+
+// ReplicationPeer is a replication peer as returned by the admin endpoint
+type ReplicationPeer struct {
+	ID         string `json:"id"`
+	ClusterKey string `json:"clusterKey"`
+	Enabled    bool   `json:"enabled"`
+	Serial     bool   `json:"serial,omitempty"`
+	// Column families replicated by table, all of them when empty. Tables with replication scope set are replicated
+	// when nil
+	TableCFs map[string][]string `json:"tableCFs,omitempty"`
+}
+
+// ReplicationSourceLoad is the progress of a regionserver replicating to a peer
+type ReplicationSourceLoad struct {
+	PeerID string `json:"peerId"`
+	Server string `json:"server"`
+	// ReplicationLag in milliseconds
+	ReplicationLag int64 `json:"replicationLag"`
+	SizeOfLogQueue int64 `json:"sizeOfLogQueue"`
+}
+
+// hbaseAdminError is returned for requests the admin endpoint answered with a non 2xx status
+type hbaseAdminError struct {
+	method     string
+	path       string
+	statusCode int
+	message    string
+}
+
+func (e *hbaseAdminError) Error() string {
+	return fmt.Sprintf("%s %s failed with status %d: %s", e.method, e.path, e.statusCode, e.message)
+}
+
+// isNotFoundStatus tells whether the endpoint answered the request with 404
+func isNotFoundStatus(err error) bool {
+	var adminErr *hbaseAdminError
+	return errs.As(err, &adminErr) && adminErr.statusCode == http.StatusNotFound
+}
+
+// httpHbaseAdmin talks to the admin endpoint over HTTP, every operation maps to a path under /admin. Non 2xx statuses
+// fail with the response body as message, 404 meaning the resource of the path does not exist
+type httpHbaseAdmin struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHbaseAdmin(endpoint string) HbaseAdmin {
+	return &httpHbaseAdmin{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: time.Second * 30},
+	}
+}
+
+func (a *httpHbaseAdmin) UpdateAllConfig(ctx context.Context) error {
+	return a.do(ctx, http.MethodPost, "/admin/update_all_config", nil, nil)
+}
+
+func (a *httpHbaseAdmin) GetRSGroup(ctx context.Context, name string) (*RSGroupInfo, error) {
+	group := &RSGroupInfo{}
+	err := a.do(ctx, http.MethodGet, "/admin/rsgroups/"+url.PathEscape(name), nil, group)
+	if isNotFoundStatus(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+func (a *httpHbaseAdmin) AddRSGroup(ctx context.Context, name string) error {
+	return a.do(ctx, http.MethodPost, "/admin/rsgroups", map[string]string{"name": name}, nil)
+}
+
+func (a *httpHbaseAdmin) MoveServersToRSGroup(ctx context.Context, name string, servers []string) error {
+	return a.do(ctx, http.MethodPost, "/admin/rsgroups/"+url.PathEscape(name)+"/servers", map[string][]string{"servers": servers}, nil)
+}
+
+func (a *httpHbaseAdmin) MoveNamespacesToRSGroup(ctx context.Context, name string, namespaces []string) error {
+	return a.do(ctx, http.MethodPost, "/admin/rsgroups/"+url.PathEscape(name)+"/namespaces", map[string][]string{"namespaces": namespaces}, nil)
+}
+
+func (a *httpHbaseAdmin) ListQuotas(ctx context.Context) ([]QuotaSettings, error) {
+	quotas := []QuotaSettings{}
+	if err := a.do(ctx, http.MethodGet, "/admin/quotas", nil, &quotas); err != nil {
+		return nil, err
+	}
+	return quotas, nil
+}
+
+func (a *httpHbaseAdmin) SetQuota(ctx context.Context, q QuotaSettings) error {
+	return a.do(ctx, http.MethodPut, "/admin/quotas", q, nil)
+}
+
+func (a *httpHbaseAdmin) RemoveQuota(ctx context.Context, q QuotaSettings) error {
+	return a.do(ctx, http.MethodDelete, "/admin/quotas", q, nil)
+}
+
+func (a *httpHbaseAdmin) GetSpaceQuotaUsage(ctx context.Context) ([]SpaceQuotaUsage, error) {
+	usage := []SpaceQuotaUsage{}
+	if err := a.do(ctx, http.MethodGet, "/admin/quotas/space_usage", nil, &usage); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+func (a *httpHbaseAdmin) CreateTable(ctx context.Context, schema TableSchema, splitKeys []string) error {
+	return a.do(ctx, http.MethodPost, "/admin/tables", map[string]interface{}{"schema": schema, "splitKeys": splitKeys}, nil)
+}
+
+func (a *httpHbaseAdmin) ListTables(ctx context.Context) ([]string, error) {
+	tables := []string{}
+	if err := a.do(ctx, http.MethodGet, "/admin/tables", nil, &tables); err != nil {
+		return nil, err
+	}
+	return tables, nil
+}
+
+func (a *httpHbaseAdmin) Snapshot(ctx context.Context, name string, table string) error {
+	return a.do(ctx, http.MethodPost, "/admin/snapshots", SnapshotInfo{Name: name, Table: table}, nil)
+}
+
+func (a *httpHbaseAdmin) ListSnapshots(ctx context.Context) ([]SnapshotInfo, error) {
+	snapshots := []SnapshotInfo{}
+	if err := a.do(ctx, http.MethodGet, "/admin/snapshots", nil, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+func (a *httpHbaseAdmin) DeleteSnapshot(ctx context.Context, name string) error {
+	return a.do(ctx, http.MethodDelete, "/admin/snapshots/"+url.PathEscape(name), nil, nil)
+}
+
+func (a *httpHbaseAdmin) RestoreSnapshot(ctx context.Context, name string) error {
+	return a.do(ctx, http.MethodPost, "/admin/snapshots/"+url.PathEscape(name)+"/restore", nil, nil)
+}
+
+func (a *httpHbaseAdmin) CloneSnapshot(ctx context.Context, name string, table string) error {
+	return a.do(ctx, http.MethodPost, "/admin/snapshots/"+url.PathEscape(name)+"/clone", map[string]string{"table": table}, nil)
+}
+
+func (a *httpHbaseAdmin) ListReplicationPeers(ctx context.Context) ([]ReplicationPeer, error) {
+	peers := []ReplicationPeer{}
+	if err := a.do(ctx, http.MethodGet, "/admin/replication/peers", nil, &peers); err != nil {
+		return nil, err
+	}
+	return peers, nil
+}
+
+func (a *httpHbaseAdmin) AddReplicationPeer(ctx context.Context, p ReplicationPeer) error {
+	return a.do(ctx, http.MethodPost, "/admin/replication/peers", p, nil)
+}
+
+func (a *httpHbaseAdmin) UpdateReplicationPeer(ctx context.Context, p ReplicationPeer) error {
+	return a.do(ctx, http.MethodPut, "/admin/replication/peers/"+url.PathEscape(p.ID), p, nil)
+}
+
+func (a *httpHbaseAdmin) SetReplicationPeerEnabled(ctx context.Context, id string, enabled bool) error {
+	action := "/disable"
+	if enabled {
+		action = "/enable"
+	}
+	return a.do(ctx, http.MethodPost, "/admin/replication/peers/"+url.PathEscape(id)+action, nil, nil)
+}
+
+func (a *httpHbaseAdmin) RemoveReplicationPeer(ctx context.Context, id string) error {
+	return a.do(ctx, http.MethodDelete, "/admin/replication/peers/"+url.PathEscape(id), nil, nil)
+}
+
+func (a *httpHbaseAdmin) GetReplicationLoad(ctx context.Context) ([]ReplicationSourceLoad, error) {
+	load := []ReplicationSourceLoad{}
+	if err := a.do(ctx, http.MethodGet, "/admin/replication/load", nil, &load); err != nil {
+		return nil, err
+	}
+	return load, nil
+}
+
+// do sends the request with body encoded as json and decodes the response into out, when they are not nil
+func (a *httpHbaseAdmin) do(ctx context.Context, method string, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, a.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &hbaseAdminError{method: method, path: path, statusCode: resp.StatusCode, message: strings.TrimSpace(string(msg))}
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHbaseAdmin_UpdateAllConfig verifies update_all_config is sent as a POST to the admin endpoint.
+func TestHbaseAdmin_UpdateAllConfig(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+	}))
+	defer server.Close()
+
+	err := newHbaseAdmin(server.URL + "/").UpdateAllConfig(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/admin/update_all_config", path)
+}
+
+// TestHbaseAdmin_ErrorStatus verifies non 2xx responses are returned as errors with the response body.
+func TestHbaseAdmin_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "access denied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := newHbaseAdmin(server.URL).UpdateAllConfig(context.TODO())
+	assert.EqualError(t, err, "POST /admin/update_all_config failed with status 403: access denied")
+}
+
+// TestHbaseAdmin_Unreachable verifies connection failures are returned as errors.
+func TestHbaseAdmin_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	assert.Error(t, newHbaseAdmin(server.URL).UpdateAllConfig(context.TODO()))
+}
+
+// TestHbaseAdmin_JsonBody verifies request bodies are sent and responses decoded as json.
+func TestHbaseAdmin_JsonBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		in := map[string]string{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+		json.NewEncoder(w).Encode(map[string]string{"echo": in["name"]})
+	}))
+	defer server.Close()
+
+	out := map[string]string{}
+	err := newHbaseAdmin(server.URL).(*httpHbaseAdmin).do(context.TODO(), http.MethodPut, "/admin/test", map[string]string{"name": "a"}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", out["echo"])
+}
+
+// fakeHbaseAdmin is an in memory admin endpoint, serving RSGroups over the same API as the real one
+type fakeHbaseAdmin struct {
+	mu         sync.Mutex
+	groups     map[string]*RSGroupInfo
+	quotas     map[string]QuotaSettings
+	spaceUsage []SpaceQuotaUsage
+	// tables by name, with the split keys they were created with
+	tables      map[string][]string
+	snapshots   map[string]SnapshotInfo
+	peers       map[string]ReplicationPeer
+	replication []ReplicationSourceLoad
+	requests    []string
+}
+
+// newFakeHbaseAdminServer starts a fake admin endpoint, closed along with the test
+func newFakeHbaseAdminServer(t *testing.T) (*fakeHbaseAdmin, *httptest.Server) {
+	fake := &fakeHbaseAdmin{groups: map[string]*RSGroupInfo{}, quotas: map[string]QuotaSettings{}, tables: map[string][]string{},
+		snapshots: map[string]SnapshotInfo{}, peers: map[string]ReplicationPeer{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /admin/update_all_config", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("GET /admin/rsgroups/{name}", func(w http.ResponseWriter, r *http.Request) {
+		group, ok := fake.groups[r.PathValue("name")]
+		if !ok {
+			http.Error(w, "rsgroup not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(group)
+	})
+	mux.HandleFunc("POST /admin/rsgroups", func(w http.ResponseWriter, r *http.Request) {
+		in := RSGroupInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if _, ok := fake.groups[in.Name]; ok {
+			http.Error(w, "rsgroup already exists", http.StatusConflict)
+			return
+		}
+		fake.groups[in.Name] = &RSGroupInfo{Name: in.Name}
+	})
+	mux.HandleFunc("POST /admin/rsgroups/{name}/servers", func(w http.ResponseWriter, r *http.Request) {
+		group, ok := fake.groups[r.PathValue("name")]
+		if !ok {
+			http.Error(w, "rsgroup not found", http.StatusNotFound)
+			return
+		}
+		in := RSGroupInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		group.Servers = append(group.Servers, in.Servers...)
+	})
+	mux.HandleFunc("POST /admin/rsgroups/{name}/namespaces", func(w http.ResponseWriter, r *http.Request) {
+		group, ok := fake.groups[r.PathValue("name")]
+		if !ok {
+			http.Error(w, "rsgroup not found", http.StatusNotFound)
+			return
+		}
+		in := RSGroupInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		group.Namespaces = append(group.Namespaces, in.Namespaces...)
+	})
+	mux.HandleFunc("GET /admin/quotas", func(w http.ResponseWriter, r *http.Request) {
+		quotas := []QuotaSettings{}
+		for _, key := range sortedKeys(fake.quotas) {
+			quotas = append(quotas, fake.quotas[key])
+		}
+		json.NewEncoder(w).Encode(quotas)
+	})
+	mux.HandleFunc("PUT /admin/quotas", func(w http.ResponseWriter, r *http.Request) {
+		q := QuotaSettings{}
+		json.NewDecoder(r.Body).Decode(&q)
+		fake.quotas[quotaKey(q)] = q
+	})
+	mux.HandleFunc("DELETE /admin/quotas", func(w http.ResponseWriter, r *http.Request) {
+		q := QuotaSettings{}
+		json.NewDecoder(r.Body).Decode(&q)
+		delete(fake.quotas, quotaKey(q))
+	})
+	mux.HandleFunc("GET /admin/quotas/space_usage", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(fake.spaceUsage)
+	})
+	mux.HandleFunc("POST /admin/tables", func(w http.ResponseWriter, r *http.Request) {
+		in := struct {
+			Schema    TableSchema `json:"schema"`
+			SplitKeys []string    `json:"splitKeys"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&in)
+		fake.tables[in.Schema.Name] = in.SplitKeys
+	})
+	mux.HandleFunc("GET /admin/tables", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(sortedKeys(fake.tables))
+	})
+	mux.HandleFunc("POST /admin/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		in := SnapshotInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if _, ok := fake.tables[in.Table]; !ok {
+			http.Error(w, "table not found", http.StatusNotFound)
+			return
+		}
+		in.CreationTime = time.Now().UnixMilli()
+		fake.snapshots[in.Name] = in
+	})
+	mux.HandleFunc("GET /admin/snapshots", func(w http.ResponseWriter, r *http.Request) {
+		snapshots := []SnapshotInfo{}
+		for _, name := range sortedKeys(fake.snapshots) {
+			snapshots = append(snapshots, fake.snapshots[name])
+		}
+		json.NewEncoder(w).Encode(snapshots)
+	})
+	mux.HandleFunc("DELETE /admin/snapshots/{name}", func(w http.ResponseWriter, r *http.Request) {
+		delete(fake.snapshots, r.PathValue("name"))
+	})
+	mux.HandleFunc("POST /admin/snapshots/{name}/restore", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := fake.snapshots[r.PathValue("name")]; !ok {
+			http.Error(w, "snapshot not found", http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("POST /admin/snapshots/{name}/clone", func(w http.ResponseWriter, r *http.Request) {
+		in := map[string]string{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if _, ok := fake.snapshots[r.PathValue("name")]; !ok {
+			http.Error(w, "snapshot not found", http.StatusNotFound)
+			return
+		}
+		if _, ok := fake.tables[in["table"]]; ok {
+			http.Error(w, "table already exists", http.StatusConflict)
+			return
+		}
+		fake.tables[in["table"]] = nil
+	})
+	mux.HandleFunc("GET /admin/replication/peers", func(w http.ResponseWriter, r *http.Request) {
+		peers := []ReplicationPeer{}
+		for _, id := range sortedKeys(fake.peers) {
+			peers = append(peers, fake.peers[id])
+		}
+		json.NewEncoder(w).Encode(peers)
+	})
+	mux.HandleFunc("POST /admin/replication/peers", func(w http.ResponseWriter, r *http.Request) {
+		in := ReplicationPeer{}
+		json.NewDecoder(r.Body).Decode(&in)
+		if _, ok := fake.peers[in.ID]; ok {
+			http.Error(w, "peer already exists", http.StatusConflict)
+			return
+		}
+		fake.peers[in.ID] = in
+	})
+	mux.HandleFunc("PUT /admin/replication/peers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		in := ReplicationPeer{}
+		json.NewDecoder(r.Body).Decode(&in)
+		peer, ok := fake.peers[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "peer not found", http.StatusNotFound)
+			return
+		}
+		if in.ClusterKey != peer.ClusterKey || in.Serial != peer.Serial {
+			http.Error(w, "changing the cluster key or serial flag of a peer is not allowed", http.StatusBadRequest)
+			return
+		}
+		peer.TableCFs = in.TableCFs
+		fake.peers[peer.ID] = peer
+	})
+	mux.HandleFunc("POST /admin/replication/peers/{id}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		peer, ok := fake.peers[r.PathValue("id")]
+		if !ok {
+			http.Error(w, "peer not found", http.StatusNotFound)
+			return
+		}
+		peer.Enabled = r.PathValue("action") == "enable"
+		fake.peers[peer.ID] = peer
+	})
+	mux.HandleFunc("DELETE /admin/replication/peers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := fake.peers[r.PathValue("id")]; !ok {
+			http.Error(w, "peer not found", http.StatusNotFound)
+			return
+		}
+		delete(fake.peers, r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /admin/replication/load", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(fake.replication)
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.requests = append(fake.requests, r.Method+" "+r.URL.Path)
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+// TestHbaseAdmin_RSGroups verifies RSGroups are created and servers and namespaces moved into them.
+func TestHbaseAdmin_RSGroups(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	admin := newHbaseAdmin(server.URL)
+	ctx := context.TODO()
+
+	group, err := admin.GetRSGroup(ctx, "tenant")
+	assert.NoError(t, err)
+	assert.Nil(t, group)
+
+	assert.NoError(t, admin.AddRSGroup(ctx, "tenant"))
+	assert.Error(t, admin.AddRSGroup(ctx, "tenant"))
+	assert.NoError(t, admin.MoveServersToRSGroup(ctx, "tenant", []string{"rs-0:16020"}))
+	assert.NoError(t, admin.MoveNamespacesToRSGroup(ctx, "tenant", []string{"team"}))
+	assert.Error(t, admin.MoveServersToRSGroup(ctx, "unknown", []string{"rs-0:16020"}))
+
+	group, err = admin.GetRSGroup(ctx, "tenant")
+	assert.NoError(t, err)
+	assert.Equal(t, &RSGroupInfo{Name: "tenant", Servers: []string{"rs-0:16020"}, Namespaces: []string{"team"}}, group)
+	assert.Contains(t, fake.requests, "POST /admin/rsgroups/tenant/servers")
+}
+
+// TestHbaseAdmin_GetRSGroupError verifies errors other than not found are returned.
+func TestHbaseAdmin_GetRSGroupError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "master not running", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	group, err := newHbaseAdmin(server.URL).GetRSGroup(context.TODO(), "tenant")
+	assert.Nil(t, group)
+	assert.EqualError(t, err, "GET /admin/rsgroups/tenant failed with status 503: master not running")
+}
+
+// TestHbaseAdmin_Quotas verifies quotas are set, listed and removed by target and type.
+func TestHbaseAdmin_Quotas(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.spaceUsage = []SpaceQuotaUsage{{Namespace: "team", Usage: 1024, Limit: 2048}}
+	admin := newHbaseAdmin(server.URL)
+	ctx := context.TODO()
+
+	throttle := QuotaSettings{User: "app", ThrottleType: "REQUEST_NUMBER", Limit: "1000req/sec"}
+	space := QuotaSettings{Namespace: "team", SpaceLimit: 2048, Policy: "NO_INSERTS"}
+	assert.NoError(t, admin.SetQuota(ctx, throttle))
+	assert.NoError(t, admin.SetQuota(ctx, space))
+
+	quotas, err := admin.ListQuotas(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []QuotaSettings{throttle, space}, quotas)
+
+	assert.NoError(t, admin.RemoveQuota(ctx, QuotaSettings{User: "app", ThrottleType: "REQUEST_NUMBER"}))
+	quotas, err = admin.ListQuotas(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []QuotaSettings{space}, quotas)
+
+	usage, err := admin.GetSpaceQuotaUsage(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fake.spaceUsage, usage)
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	bytes "bytes"
+	context "context"
+	json "encoding/json"
+	fmt "fmt"
+	http "net/http"
+	url "net/url"
+	strings "strings"
+	time "time"
+)
+
+// HbaseRest manages namespaces and table schemas through the HBase REST gateway
+type HbaseRest interface {
+	// GetNamespace returns the namespace with the given name, nil when it does not exist
+	GetNamespace(ctx context.Context, name string) (*NamespaceInfo, error)
+	CreateNamespace(ctx context.Context, name string, properties map[string]string) error
+	// AlterNamespace replaces the properties of the namespace
+	AlterNamespace(ctx context.Context, name string, properties map[string]string) error
+	// GetTableSchema returns the schema of the table, as namespace:name, nil when it does not exist
+	GetTableSchema(ctx context.Context, table string) (*TableSchema, error)
+	// UpdateTableSchema creates the table, or adds and modifies the column families of the schema. Table attributes
+	// of an existing table are left unchanged
+	UpdateTableSchema(ctx context.Context, schema TableSchema) error
+	// ReplaceTableSchema replaces the schema of the table, dropping column families which are not in the schema
+	ReplaceTableSchema(ctx context.Context, schema TableSchema) error
+}
+
+// NamespaceInfo is a namespace as returned by the REST gateway
+type NamespaceInfo struct {
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+// TableSchema is the schema of a table. The REST gateway has attributes next to the name of the table and of its
+// column families, and the column families under ColumnSchema
+type TableSchema struct {
+	Name           string
+	Attributes     map[string]string
+	ColumnFamilies []ColumnSchema
+}
+
+// ColumnSchema is a column family with its attributes, e.g. VERSIONS or COMPRESSION
+type ColumnSchema struct {
+	Name       string
+	Attributes map[string]string
+}
+
+func (s TableSchema) MarshalJSON() ([]byte, error) {
+	table := map[string]interface{}{}
+	for k, v := range s.Attributes {
+		table[k] = v
+	}
+	families := []map[string]string{}
+	for _, f := range s.ColumnFamilies {
+		family := map[string]string{}
+		for k, v := range f.Attributes {
+			family[k] = v
+		}
+		family["name"] = f.Name
+		families = append(families, family)
+	}
+	table["name"] = s.Name
+	table["ColumnSchema"] = families
+	return json.Marshal(table)
+}
+
+func (s *TableSchema) UnmarshalJSON(data []byte) error {
+	// numbers are kept as they are sent, e.g. TTL 2147483647, instead of being formatted as floats
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	table := map[string]interface{}{}
+	if err := decoder.Decode(&table); err != nil {
+		return err
+	}
+	*s = TableSchema{Attributes: map[string]string{}}
+	for k, v := range table {
+		switch k {
+		case "name":
+			s.Name = fmt.Sprint(v)
+		case "ColumnSchema":
+			families, ok := v.([]interface{})
+			if !ok {
+				return fmt.Errorf("unexpected ColumnSchema of table %v", table["name"])
+			}
+			for _, f := range families {
+				attributes, ok := f.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("unexpected column family of table %v", table["name"])
+				}
+				family := ColumnSchema{Attributes: map[string]string{}}
+				for fk, fv := range attributes {
+					if fk == "name" {
+						family.Name = fmt.Sprint(fv)
+					} else {
+						family.Attributes[fk] = fmt.Sprint(fv)
+					}
+				}
+				s.ColumnFamilies = append(s.ColumnFamilies, family)
+			}
+		default:
+			s.Attributes[k] = fmt.Sprint(v)
+		}
+	}
+	return nil
+}
+
+// httpHbaseRest talks to the HBase REST gateway, with json requests and responses
+type httpHbaseRest struct {
+	admin *httpHbaseAdmin
+}
+
+func newHbaseRest(endpoint string) HbaseRest {
+	return &httpHbaseRest{admin: &httpHbaseAdmin{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: time.Second * 30},
+	}}
+}
+
+func (r *httpHbaseRest) GetNamespace(ctx context.Context, name string) (*NamespaceInfo, error) {
+	info := &NamespaceInfo{}
+	err := r.admin.do(ctx, http.MethodGet, "/namespaces/"+url.PathEscape(name), nil, info)
+	if isNotFoundStatus(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func (r *httpHbaseRest) CreateNamespace(ctx context.Context, name string, properties map[string]string) error {
+	return r.admin.do(ctx, http.MethodPost, "/namespaces/"+url.PathEscape(name), NamespaceInfo{Properties: properties}, nil)
+}
+
+func (r *httpHbaseRest) AlterNamespace(ctx context.Context, name string, properties map[string]string) error {
+	return r.admin.do(ctx, http.MethodPut, "/namespaces/"+url.PathEscape(name), NamespaceInfo{Properties: properties}, nil)
+}
+
+func (r *httpHbaseRest) GetTableSchema(ctx context.Context, table string) (*TableSchema, error) {
+	schema := &TableSchema{}
+	err := r.admin.do(ctx, http.MethodGet, "/"+url.PathEscape(table)+"/schema", nil, schema)
+	if isNotFoundStatus(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (r *httpHbaseRest) UpdateTableSchema(ctx context.Context, schema TableSchema) error {
+	return r.admin.do(ctx, http.MethodPost, "/"+url.PathEscape(schema.Name)+"/schema", schema, nil)
+}
+
+func (r *httpHbaseRest) ReplaceTableSchema(ctx context.Context, schema TableSchema) error {
+	return r.admin.do(ctx, http.MethodPut, "/"+url.PathEscape(schema.Name)+"/schema", schema, nil)
+}
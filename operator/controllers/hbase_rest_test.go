@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHbaseRest is an in memory REST gateway, serving namespaces and table schemas over the same API as the real one
+type fakeHbaseRest struct {
+	mu         sync.Mutex
+	namespaces map[string]map[string]string
+	tables     map[string]*TableSchema
+	requests   []string
+}
+
+// newFakeHbaseRestServer starts a fake REST gateway, closed along with the test
+func newFakeHbaseRestServer(t *testing.T) (*fakeHbaseRest, *httptest.Server) {
+	fake := &fakeHbaseRest{namespaces: map[string]map[string]string{}, tables: map[string]*TableSchema{}}
+	// table names can not be told apart from the namespaces path by patterns, so they are served separately
+	mux, tables := http.NewServeMux(), http.NewServeMux()
+	mux.HandleFunc("GET /namespaces/{name}", func(w http.ResponseWriter, r *http.Request) {
+		properties, ok := fake.namespaces[r.PathValue("name")]
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(NamespaceInfo{Properties: properties})
+	})
+	mux.HandleFunc("POST /namespaces/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := fake.namespaces[r.PathValue("name")]; ok {
+			http.Error(w, "Namespace already exists", http.StatusForbidden)
+			return
+		}
+		in := NamespaceInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		fake.namespaces[r.PathValue("name")] = in.Properties
+	})
+	mux.HandleFunc("PUT /namespaces/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := fake.namespaces[r.PathValue("name")]; !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		in := NamespaceInfo{}
+		json.NewDecoder(r.Body).Decode(&in)
+		fake.namespaces[r.PathValue("name")] = in.Properties
+	})
+	tables.HandleFunc("GET /{table}/schema", func(w http.ResponseWriter, r *http.Request) {
+		schema, ok := fake.tables[r.PathValue("table")]
+		if !ok {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(schema)
+	})
+	tables.HandleFunc("POST /{table}/schema", func(w http.ResponseWriter, r *http.Request) {
+		in := &TableSchema{}
+		json.NewDecoder(r.Body).Decode(in)
+		current, ok := fake.tables[r.PathValue("table")]
+		if !ok {
+			fake.tables[r.PathValue("table")] = in
+			return
+		}
+		// column families are added or modified, table attributes are left unchanged
+		for _, f := range in.ColumnFamilies {
+			modified := false
+			for i := range current.ColumnFamilies {
+				if current.ColumnFamilies[i].Name == f.Name {
+					current.ColumnFamilies[i] = f
+					modified = true
+				}
+			}
+			if !modified {
+				current.ColumnFamilies = append(current.ColumnFamilies, f)
+			}
+		}
+	})
+	tables.HandleFunc("PUT /{table}/schema", func(w http.ResponseWriter, r *http.Request) {
+		in := &TableSchema{}
+		json.NewDecoder(r.Body).Decode(in)
+		fake.tables[r.PathValue("table")] = in
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		fake.requests = append(fake.requests, r.Method+" "+r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/namespaces/") {
+			mux.ServeHTTP(w, r)
+		} else {
+			tables.ServeHTTP(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+// TestTableSchema_Json verifies attributes are flattened next to the names of the table and its column families.
+func TestTableSchema_Json(t *testing.T) {
+	schema := TableSchema{Name: "ns:t", Attributes: map[string]string{"DURABILITY": "ASYNC_WAL"},
+		ColumnFamilies: []ColumnSchema{{Name: "d", Attributes: map[string]string{"VERSIONS": "1"}}}}
+	data, err := json.Marshal(schema)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name":"ns:t","DURABILITY":"ASYNC_WAL","ColumnSchema":[{"name":"d","VERSIONS":"1"}]}`, string(data))
+
+	decoded := TableSchema{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"name":"ns:t","IS_META":false,"ColumnSchema":[{"name":"d","TTL":2147483647}]}`), &decoded))
+	assert.Equal(t, TableSchema{Name: "ns:t", Attributes: map[string]string{"IS_META": "false"},
+		ColumnFamilies: []ColumnSchema{{Name: "d", Attributes: map[string]string{"TTL": "2147483647"}}}}, decoded)
+}
+
+// TestHbaseRest_Namespaces verifies namespaces are created and altered, and missing ones returned as nil.
+func TestHbaseRest_Namespaces(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	rest := newHbaseRest(server.URL)
+	ctx := context.TODO()
+
+	ns, err := rest.GetNamespace(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Nil(t, ns)
+
+	assert.NoError(t, rest.CreateNamespace(ctx, "orders", map[string]string{"a": "1"}))
+	assert.Error(t, rest.CreateNamespace(ctx, "orders", nil))
+	assert.NoError(t, rest.AlterNamespace(ctx, "orders", map[string]string{"a": "2"}))
+
+	ns, err = rest.GetNamespace(ctx, "orders")
+	assert.NoError(t, err)
+	assert.Equal(t, &NamespaceInfo{Properties: map[string]string{"a": "2"}}, ns)
+	assert.Equal(t, []string{"GET /namespaces/orders", "POST /namespaces/orders", "POST /namespaces/orders", "PUT /namespaces/orders",
+		"GET /namespaces/orders"}, fake.requests)
+}
+
+// TestHbaseRest_TableSchema verifies schemas are read, updated and replaced under the table name.
+func TestHbaseRest_TableSchema(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	rest := newHbaseRest(server.URL)
+	ctx := context.TODO()
+
+	schema, err := rest.GetTableSchema(ctx, "orders:items")
+	assert.NoError(t, err)
+	assert.Nil(t, schema)
+
+	created := TableSchema{Name: "orders:items", Attributes: map[string]string{},
+		ColumnFamilies: []ColumnSchema{{Name: "d", Attributes: map[string]string{"VERSIONS": "1"}}}}
+	assert.NoError(t, rest.UpdateTableSchema(ctx, created))
+	schema, err = rest.GetTableSchema(ctx, "orders:items")
+	assert.NoError(t, err)
+	assert.Equal(t, &created, schema)
+
+	replaced := TableSchema{Name: "orders:items", Attributes: map[string]string{},
+		ColumnFamilies: []ColumnSchema{{Name: "h", Attributes: map[string]string{}}}}
+	assert.NoError(t, rest.ReplaceTableSchema(ctx, replaced))
+	assert.Equal(t, &replaced, fake.tables["orders:items"])
+	assert.Contains(t, fake.requests, "PUT /orders:items/schema")
+}
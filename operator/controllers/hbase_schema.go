@@ -0,0 +1,187 @@
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	sort "sort"
+	strconv "strconv"
+	time "time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+// CONDITION_READY condition of objects managed in HBase, true once the spec is applied
+const CONDITION_READY = "Ready"
+
+// schemaSyncInterval after which HbaseNamespaces and HbaseTables are compared with HBase again, to report drift
+const schemaSyncInterval = time.Minute * 5
+
+// schemaClusterRefOf returns the cluster a schema object refers to, in the namespace of the object unless set otherwise
+func schemaClusterRefOf(ref kvstorev1.HbaseSchemaClusterReference, namespace string) types.NamespacedName {
+	name := types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}
+	if len(name.Namespace) == 0 {
+		name.Namespace = namespace
+	}
+	return name
+}
+
+// referencedCluster is what objects referring to an HbaseCluster or HbaseStandalone use of it
+type referencedCluster struct {
+	Name          string
+	BaseImage     string
+	FSGroup       int64
+	Configuration kvstorev1.HbaseClusterConfiguration
+}
+
+// referencedClusterOf returns the HbaseCluster or HbaseStandalone an object refers to
+func referencedClusterOf(ctx context.Context, ref kvstorev1.HbaseSchemaClusterReference, namespace string, cl client.Client) (referencedCluster, error) {
+	name := schemaClusterRefOf(ref, namespace)
+	if ref.Kind == "HbaseStandalone" {
+		standalone := &kvstorev1.HbaseStandalone{}
+		err := cl.Get(ctx, name, standalone)
+		return referencedCluster{Name: name.Name, BaseImage: standalone.Spec.BaseImage, FSGroup: standalone.Spec.FSGroup, Configuration: standalone.Spec.Configuration}, err
+	}
+	cluster := &kvstorev1.HbaseCluster{}
+	err := cl.Get(ctx, name, cluster)
+	return referencedCluster{Name: name.Name, BaseImage: cluster.Spec.BaseImage, FSGroup: cluster.Spec.FSGroup, Configuration: cluster.Spec.Configuration}, err
+}
+
+// tableNameOf returns the name of the table in HBase, as namespace:name
+func tableNameOf(t *kvstorev1.HbaseTable) string {
+	namespace, name := t.Spec.Namespace, t.Spec.Name
+	if len(namespace) == 0 {
+		namespace = "default"
+	}
+	if len(name) == 0 {
+		name = t.Name
+	}
+	return namespace + ":" + name
+}
+
+// desiredTableSchema returns the schema of the spec, with only the attributes the spec declares
+func desiredTableSchema(t *kvstorev1.HbaseTable) TableSchema {
+	schema := TableSchema{Name: tableNameOf(t), Attributes: map[string]string{}}
+	for k, v := range t.Spec.Attributes {
+		schema.Attributes[k] = v
+	}
+	for _, f := range t.Spec.ColumnFamilies {
+		family := ColumnSchema{Name: f.Name, Attributes: map[string]string{}}
+		for k, v := range f.Attributes {
+			family.Attributes[k] = v
+		}
+		if len(f.Compression) > 0 {
+			family.Attributes["COMPRESSION"] = f.Compression
+		}
+		if len(f.BloomFilter) > 0 {
+			family.Attributes["BLOOMFILTER"] = f.BloomFilter
+		}
+		if f.TTL != nil {
+			family.Attributes["TTL"] = strconv.Itoa(int(*f.TTL))
+		}
+		if f.MaxVersions != nil {
+			family.Attributes["VERSIONS"] = strconv.Itoa(int(*f.MaxVersions))
+		}
+		schema.ColumnFamilies = append(schema.ColumnFamilies, family)
+	}
+	return schema
+}
+
+// tableSchemaDiff is what differs between the desired schema of a table and the one in HBase
+type tableSchemaDiff struct {
+	// column families missing or with declared attributes which differ
+	families []string
+	// declared table attributes which differ
+	attributes []string
+	// column families in HBase which are not in the spec
+	extraFamilies []string
+}
+
+// diffTableSchema compares the attributes the desired schema declares with the current schema, attributes left to
+// HBase are ignored
+func diffTableSchema(desired TableSchema, current TableSchema) tableSchemaDiff {
+	diff := tableSchemaDiff{}
+	for _, k := range sortedKeys(desired.Attributes) {
+		if v, ok := current.Attributes[k]; !ok || v != desired.Attributes[k] {
+			diff.attributes = append(diff.attributes, k)
+		}
+	}
+
+	currentFamilies := map[string]ColumnSchema{}
+	for _, f := range current.ColumnFamilies {
+		currentFamilies[f.Name] = f
+	}
+	desiredFamilies := map[string]bool{}
+	for _, f := range desired.ColumnFamilies {
+		desiredFamilies[f.Name] = true
+		c, ok := currentFamilies[f.Name]
+		if !ok {
+			diff.families = append(diff.families, f.Name)
+			continue
+		}
+		for k, v := range f.Attributes {
+			if c.Attributes[k] != v {
+				diff.families = append(diff.families, f.Name)
+				break
+			}
+		}
+	}
+	for _, f := range current.ColumnFamilies {
+		if !desiredFamilies[f.Name] {
+			diff.extraFamilies = append(diff.extraFamilies, f.Name)
+		}
+	}
+	sort.Strings(diff.extraFamilies)
+	return diff
+}
+
+// mergeTableSchema returns the current schema with the declared attributes and column families of the desired one.
+// Column families which are not desired are kept unless dropped
+func mergeTableSchema(desired TableSchema, current TableSchema, dropFamilies bool) TableSchema {
+	merged := TableSchema{Name: desired.Name, Attributes: map[string]string{}}
+	for k, v := range current.Attributes {
+		merged.Attributes[k] = v
+	}
+	for k, v := range desired.Attributes {
+		merged.Attributes[k] = v
+	}
+
+	desiredFamilies := map[string]ColumnSchema{}
+	for _, f := range desired.ColumnFamilies {
+		desiredFamilies[f.Name] = f
+	}
+	seen := map[string]bool{}
+	for _, c := range current.ColumnFamilies {
+		f, ok := desiredFamilies[c.Name]
+		if !ok && dropFamilies {
+			continue
+		}
+		family := ColumnSchema{Name: c.Name, Attributes: map[string]string{}}
+		for k, v := range c.Attributes {
+			family.Attributes[k] = v
+		}
+		for k, v := range f.Attributes {
+			family.Attributes[k] = v
+		}
+		seen[c.Name] = true
+		merged.ColumnFamilies = append(merged.ColumnFamilies, family)
+	}
+	for _, f := range desired.ColumnFamilies {
+		if !seen[f.Name] {
+			merged.ColumnFamilies = append(merged.ColumnFamilies, f)
+		}
+	}
+	return merged
+}
+
+// schemaCondition returns the Ready condition of a schema object
+func schemaCondition(ready bool, generation int64, reason string, format string, args ...interface{}) metav1.Condition {
+	status := metav1.ConditionFalse
+	if ready {
+		status = metav1.ConditionTrue
+	}
+	return metav1.Condition{Type: CONDITION_READY, Status: status, ObservedGeneration: generation, Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	time "time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// snapshotJobPollInterval after which running snapshot jobs are checked again
+const snapshotJobPollInterval = time.Second * 30
+
+// HbaseBackupReconciler exports a snapshot to an object store or HDFS with a job running ExportSnapshot. Backups
+// run once, changes to the spec after they finished are ignored
+type HbaseBackupReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasebackups,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasebackups/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile snapshots the table when the snapshot does not exist, starts the export job, and records its outcome
+func (r *HbaseBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasebackup", req.NamespacedName)
+
+	b := &kvstorev1.HbaseBackup{}
+	err := r.Client.Get(ctx, req.NamespacedName, b)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseBackup resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseBackup")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if b.Status.Phase == kvstorev1.HbaseSnapshotJobSucceeded || b.Status.Phase == kvstorev1.HbaseSnapshotJobFailed {
+		return ctrl.Result{}, nil
+	}
+
+	if len(b.Status.JobName) == 0 {
+		return r.start(ctx, log, b)
+	}
+
+	job := &batchv1.Job{}
+	err = r.Client.Get(ctx, types.NamespacedName{Name: b.Status.JobName, Namespace: b.Namespace}, job)
+	if errors.IsNotFound(err) {
+		return r.failed(ctx, log, b, "job "+b.Status.JobName+" was deleted before it finished")
+	} else if err != nil {
+		log.Error(err, "Failed to get export job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	result, err := snapshotJobResultOf(ctx, job, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to get pods of export job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if !result.finished {
+		return ctrl.Result{RequeueAfter: snapshotJobPollInterval}, nil
+	}
+	if !result.succeeded {
+		return r.failed(ctx, log, b, "export job failed: "+result.message)
+	}
+
+	log.Info("Exported snapshot", "Snapshot", b.Spec.Snapshot, "BytesCopied", result.bytesCopied)
+	r.Recorder.Event(b, corev1.EventTypeNormal, REASON_BACKUP_SUCCEEDED, fmt.Sprintf("Exported snapshot %s to %s, %d bytes copied", b.Spec.Snapshot,
+		b.Spec.Target.URL, result.bytesCopied))
+	b.Status.Phase = kvstorev1.HbaseSnapshotJobSucceeded
+	b.Status.BytesCopied = result.bytesCopied
+	b.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	return r.updateStatus(ctx, log, b, ctrl.Result{})
+}
+
+// start takes the snapshot when needed and creates the export job
+func (r *HbaseBackupReconciler) start(ctx context.Context, log logr.Logger, b *kvstorev1.HbaseBackup) (ctrl.Result, error) {
+	cluster, err := referencedClusterOf(ctx, b.Spec.ClusterRef, b.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		b.Status.Phase = kvstorev1.HbaseSnapshotJobPending
+		b.Status.Message = fmt.Sprintf("cluster %s not found", schemaClusterRefOf(b.Spec.ClusterRef, b.Namespace))
+		return r.updateStatus(ctx, log, b, ctrl.Result{RequeueAfter: time.Second * 30})
+	} else if err != nil {
+		log.Error(err, "Failed to get cluster of HbaseBackup")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	if len(b.Spec.Table) > 0 {
+		if len(cluster.Configuration.AdminEndpoint) == 0 {
+			return r.failed(ctx, log, b, "configuration.adminEndpoint of the cluster is required to snapshot spec.table")
+		}
+		taken, err := ensureSnapshot(ctx, newHbaseAdmin(cluster.Configuration.AdminEndpoint), b.Spec.Snapshot, b.Spec.Table)
+		if err != nil && isRejectedByHbase(err) {
+			return r.failed(ctx, log, b, "snapshot failed: "+err.Error())
+		} else if err != nil {
+			log.Error(err, "Failed to snapshot table")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		if taken {
+			r.Recorder.Event(b, corev1.EventTypeNormal, REASON_SNAPSHOT_TAKEN, "Took snapshot "+b.Spec.Snapshot+" of table "+b.Spec.Table)
+		}
+	}
+
+	args := exportSnapshotArgs(b.Spec.Snapshot, "", b.Spec.Target.URL, b.Spec.Target, b.Spec.Job)
+	job, err := buildSnapshotJob(b.Name+"-export", b.Namespace, cluster, b.Spec.Target, b.Spec.Job, args)
+	if err != nil {
+		return r.failed(ctx, log, b, err.Error())
+	}
+	if err = ctrl.SetControllerReference(b, job, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("Creating export job", "Job", job.Name, "Target", b.Spec.Target.URL)
+	if err = runSnapshotJob(ctx, job, r.Client); err != nil {
+		log.Error(err, "Failed to create export job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	r.Recorder.Event(b, corev1.EventTypeNormal, REASON_BACKUP_STARTED, "Exporting snapshot "+b.Spec.Snapshot+" to "+b.Spec.Target.URL)
+	b.Status.Phase = kvstorev1.HbaseSnapshotJobRunning
+	b.Status.JobName = job.Name
+	b.Status.StartTime = &metav1.Time{Time: time.Now()}
+	b.Status.Message = ""
+	return r.updateStatus(ctx, log, b, ctrl.Result{RequeueAfter: snapshotJobPollInterval})
+}
+
+func (r *HbaseBackupReconciler) updateStatus(ctx context.Context, log logr.Logger, b *kvstorev1.HbaseBackup, result ctrl.Result) (ctrl.Result, error) {
+	b.Status.ObservedGeneration = b.Generation
+	if err := r.Client.Status().Update(ctx, b); err != nil {
+		log.Error(err, "Failed to update HbaseBackup status")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return result, nil
+}
+
+func (r *HbaseBackupReconciler) failed(ctx context.Context, log logr.Logger, b *kvstorev1.HbaseBackup, message string) (ctrl.Result, error) {
+	log.Info("HbaseBackup failed", "Message", message)
+	r.Recorder.Event(b, corev1.EventTypeWarning, REASON_BACKUP_FAILED, message)
+	b.Status.Phase = kvstorev1.HbaseSnapshotJobFailed
+	b.Status.Message = message
+	b.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	return r.updateStatus(ctx, log, b, ctrl.Result{})
+}
+
+// ensureSnapshot takes a snapshot of the table unless a snapshot with the same name exists, true when it was taken
+func ensureSnapshot(ctx context.Context, admin HbaseAdmin, name string, table string) (bool, error) {
+	snapshots, err := admin.ListSnapshots(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, s := range snapshots {
+		if s.Name == name {
+			return false, nil
+		}
+	}
+	return true, admin.Snapshot(ctx, name, table)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseBackup{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func doBackupTestSetup(b *kvstorev1.HbaseBackup) (*K8sMockClient, *K8sMockStatusWriter, *HbaseBackupReconciler, context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: b.Name, Namespace: b.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseBackup{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseBackup) = *b
+		}).
+		Return(nil)
+	k8sMockClient.On("Status").Return(statusWriter)
+	reconciler := &HbaseBackupReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100), Scheme: newSnapshotJobScheme()}
+	return k8sMockClient, statusWriter, reconciler, ctx, req
+}
+
+func newBackup() *kvstorev1.HbaseBackup {
+	return &kvstorev1.HbaseBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: testNamespace, Generation: 1},
+		Spec: kvstorev1.HbaseBackupSpec{
+			ClusterRef: kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			Snapshot:   "orders-snapshot",
+			Table:      "orders:items",
+			Target:     kvstorev1.HbaseBackupLocation{URL: "s3a://backups/hbase"},
+		},
+	}
+}
+
+// TestHbaseBackupReconcile_Start verifies the missing snapshot is taken and the export job created, owned by the backup.
+func TestHbaseBackupReconcile_Start(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.tables["orders:items"] = nil
+	k8sMockClient, statusWriter, reconciler, ctx, req := doBackupTestSetup(newBackup())
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	k8sMockClient.On("Create", ctx, mock.MatchedBy(func(job *batchv1.Job) bool {
+		return job.Name == "orders-export" && len(job.OwnerReferences) == 1 && job.OwnerReferences[0].Kind == "HbaseBackup" &&
+			assert.ObjectsAreEqual([]string{EXPORT_SNAPSHOT_CLASS, "-snapshot", "orders-snapshot", "-copy-to", "s3a://backups/hbase"},
+				job.Spec.Template.Spec.Containers[0].Args)
+	}), []client.CreateOption(nil)).Return(nil)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(b *kvstorev1.HbaseBackup) bool {
+		return b.Status.Phase == kvstorev1.HbaseSnapshotJobRunning && b.Status.JobName == "orders-export" && b.Status.StartTime != nil
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: snapshotJobPollInterval}, result)
+	assert.Contains(t, fake.snapshots, "orders-snapshot")
+	assert.ElementsMatch(t, []string{REASON_SNAPSHOT_TAKEN, REASON_BACKUP_STARTED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseBackupReconcile_Finished verifies the outcome of the export job is recorded, with the bytes copied.
+func TestHbaseBackupReconcile_Finished(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		condition batchv1.JobConditionType
+		exitCode  int32
+		message   string
+		reason    string
+		expected  kvstorev1.HbaseSnapshotJobStatus
+	}{
+		{"succeeded", batchv1.JobComplete, 0, "BYTES_COPIED=4096", REASON_BACKUP_SUCCEEDED,
+			kvstorev1.HbaseSnapshotJobStatus{Phase: kvstorev1.HbaseSnapshotJobSucceeded, JobName: "orders-export", BytesCopied: 4096, ObservedGeneration: 1}},
+		{"failed", batchv1.JobFailed, 1, "Access Denied", REASON_BACKUP_FAILED,
+			kvstorev1.HbaseSnapshotJobStatus{Phase: kvstorev1.HbaseSnapshotJobFailed, JobName: "orders-export", Message: "export job failed: Access Denied",
+				ObservedGeneration: 1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBackup()
+			b.Status = kvstorev1.HbaseSnapshotJobStatus{Phase: kvstorev1.HbaseSnapshotJobRunning, JobName: "orders-export"}
+			k8sMockClient, statusWriter, reconciler, ctx, req := doBackupTestSetup(b)
+			k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "orders-export", Namespace: testNamespace}, &batchv1.Job{}).
+				Run(func(args mock.Arguments) {
+					job := args.Get(2).(*batchv1.Job)
+					job.Name, job.Namespace = "orders-export", testNamespace
+					job.Status.Conditions = []batchv1.JobCondition{{Type: tc.condition, Status: corev1.ConditionTrue}}
+				}).
+				Return(nil)
+			mockSnapshotJobPods(k8sMockClient, ctx, "orders-export", tc.exitCode, tc.message)
+			var updated *kvstorev1.HbaseBackup
+			statusWriter.On("Update", ctx, mock.Anything).
+				Run(func(args mock.Arguments) {
+					updated = args.Get(1).(*kvstorev1.HbaseBackup)
+				}).
+				Return(nil)
+
+			result, err := reconciler.Reconcile(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, ctrl.Result{}, result)
+			assert.NotNil(t, updated.Status.CompletionTime)
+			updated.Status.CompletionTime = nil
+			assert.Equal(t, tc.expected, updated.Status)
+			assert.Equal(t, []string{tc.reason}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+			k8sMockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHbaseBackupReconcile_Done verifies finished backups are left alone.
+func TestHbaseBackupReconcile_Done(t *testing.T) {
+	b := newBackup()
+	b.Status.Phase = kvstorev1.HbaseSnapshotJobSucceeded
+	k8sMockClient, _, reconciler, ctx, req := doBackupTestSetup(b)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
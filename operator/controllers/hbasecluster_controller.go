@@ -24,18 +24,24 @@ import (
 	time "time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	errors "k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
+	handler "sigs.k8s.io/controller-runtime/pkg/handler"
 
 	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
 )
 
 // HbaseClusterReconciler reconciles a HbaseCluster object.
 type HbaseClusterReconciler struct {
-	Client client.Client
-	Scheme *runtime.Scheme
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// Runs haadmin in namenode pods, failovers are not possible without it
+	PodExecutor PodExecutor
 }
 
 func asSha256(o interface{}) string {
@@ -52,10 +58,15 @@ var hashStore = make(map[string]string)
 //+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=pods/exec,verbs=create
 //+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,70 +83,234 @@ func (r *HbaseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			log.Info("HbaseCluster resource not found. Ignoring since object must be deleted")
+			deleteResourceMetrics("HbaseCluster/"+req.Name, req.Namespace)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		log.Error(err, "Failed to get HbaseCluster")
 		return ctrl.Result{RequeueAfter: time.Second * 5}, err
 	}
+	kind := "HbaseCluster/" + hbasecluster.Name
+	defer observeReconcilePhase(kind, hbasecluster.Namespace, "", "reconcile", time.Now())
 
-	// Check if the configmap reconciliation is enabled from cluster, this is controlled from serviceLabels
-	// If the desired service label is set to true, then we will reconcile the configmaps
-	value, exists := hbasecluster.Spec.ServiceLabels[RECONCILE_CONFIG_LABEL]
+	// CRs still using the deprecated label are migrated once, the update triggers another reconcile
+	migrated, err := migrateConfigUpdatePolicy(ctx, log, hbasecluster, &hbasecluster.Spec.Configuration, hbasecluster.Spec.ServiceLabels, r.Recorder, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if migrated {
+		return ctrl.Result{}, nil
+	}
+
+	// ConfigMaps are reconciled without restarting pods, unless the update policy says otherwise
+	policy := getConfigUpdatePolicy(hbasecluster.Spec.Configuration, kvstorev1.ConfigUpdatePolicyConfigOnly)
+
+	// zookeeper, journalnode and namenode are not deployed when the cluster runs on an external ZooKeeper or HDFS
+	deployments := clusterDeployments(hbasecluster.Spec)
+	// a zookeeper ensemble managed by the operator is resized one member at a time, from the members recorded in status
+	for i, d := range deployments {
+		if isZookeeperEnsemble(hbasecluster, d) {
+			deployments[i].Size = zookeeperEnsembleSize(hbasecluster)
+		}
+	}
+
+	// ConfigMaps are rendered in the namespaces of the tenants referring to the cluster, besides the deprecated
+	// tenantNamespaces list and the namespace of the HbaseCluster itself
+	tenants, err := listClusterTenants(ctx, log, hbasecluster, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if err = reportClusterTenants(ctx, log, hbasecluster, tenants, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	namespaces := tenantNamespacesOf(hbasecluster, tenants)
+	// overrides targeting other namespaces are ignored, and reported in status
+	if err = reportTenantConfigValidity(ctx, log, hbasecluster, &hbasecluster.Status.Conditions, hbasecluster.Spec.Configuration,
+		namespaces, r.Recorder, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	configuration, err := resolveTenantConfig(ctx, log, r.Client, hbasecluster.Spec.Configuration, namespaces)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	configuration, err = resolveExternalServices(ctx, log, hbasecluster.Namespace, hbasecluster.Spec, configuration, r.Client)
+	if err != nil {
+		recordWarning(r.Recorder, hbasecluster, REASON_CONFIG_VALIDATE_FAILED, err)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if isZookeeperEnsemble(hbasecluster, hbasecluster.Spec.Deployments.Zookeeper) {
+		configuration.HbaseConfig = mergeConfig(configuration.HbaseConfig, zookeeperEnsembleConfig(hbasecluster, zookeeperEnsembleSize(hbasecluster)))
+	}
+	if hbasecluster.Spec.RackAwareness != nil {
+		hbaseConfig, hadoopConfig := rackAwarenessConfig(hbasecluster.Spec.RackAwareness)
+		configuration.HbaseConfig = mergeConfig(configuration.HbaseConfig, hbaseConfig)
+		configuration.HadoopConfig = mergeConfig(configuration.HadoopConfig, hadoopConfig)
+	}
+	cfgs := []*corev1.ConfigMap{}
+	overrides := []kvstorev1.TenantConfigOverrideStatus{}
+	for _, namespace := range namespaces {
+		cfg := buildConfigMap(configuration.HbaseConfigName, hbasecluster.Name, namespace, configuration.HbaseConfig, configuration.HbaseTenantConfig, log)
+		ctrl.SetControllerReference(hbasecluster, cfg, r.Scheme)
+		cfgs = append(cfgs, cfg)
+		overrides = appendTenantConfigOverrideStatus(overrides, cfg, configuration.HbaseTenantConfig)
 
-	deployments := []kvstorev1.HbaseClusterDeployment{hbasecluster.Spec.Deployments.Journalnode, hbasecluster.Spec.Deployments.Namenode, hbasecluster.Spec.Deployments.Datanode, hbasecluster.Spec.Deployments.Hmaster}
-	if hbasecluster.Spec.Deployments.Zookeeper.Size != 0 {
-		deployments = append([]kvstorev1.HbaseClusterDeployment{hbasecluster.Spec.Deployments.Zookeeper}, deployments...)
+		cfg = buildConfigMap(configuration.HadoopConfigName, hbasecluster.Name, namespace, configuration.HadoopConfig, configuration.HadoopTenantConfig, log)
+		ctrl.SetControllerReference(hbasecluster, cfg, r.Scheme)
+		cfgs = append(cfgs, cfg)
+		overrides = appendTenantConfigOverrideStatus(overrides, cfg, configuration.HadoopTenantConfig)
+	}
+
+	// components with a config overlay get their own ConfigMaps, and are bound to them instead of the shared ones
+	restartTargets := map[string][]string{}
+	for _, d := range deployments {
+		for _, cfg := range buildComponentConfigMaps(hbasecluster.Name, hbasecluster.Namespace, configuration, d, log) {
+			ctrl.SetControllerReference(hbasecluster, cfg, r.Scheme)
+			cfgs = append(cfgs, cfg)
+		}
+		restartConfigMap := hbasecluster.Namespace + "/" + hbaseConfigNameOf(hbasecluster.Spec.Configuration, d)
+		restartTargets[restartConfigMap] = append(restartTargets[restartConfigMap], d.Name)
+	}
+
+	if !isConfigReconciled(policy) {
+		log.Info("Configmap recon disabled by update policy", "UpdatePolicy", policy)
+		cfgs = nil
+		overrides = nil
+	}
+
+	restartEnabled := isRestartOnConfigChange(policy)
+	hotReloadEnabled := isHotReloadEnabled(policy, hbasecluster.Spec.Configuration)
+
+	// In dry run, only report the config diff and the rollout plan without applying anything
+	if isDryRun(hbasecluster) {
+		log.Info("Dry run enabled, computing config diff without applying changes")
+		result, err := validateClusterConfiguration(ctx, log, hbasecluster.Namespace, hbasecluster.Spec.Configuration, deployments, r.Client)
+		recordConfigValidation(kind, hbasecluster.Namespace, err)
+		if err != nil {
+			recordWarning(r.Recorder, hbasecluster, REASON_CONFIG_VALIDATE_FAILED, err)
+			log.Error(err, "Failed to validate configuration")
+			return result, err
+		}
+		changes := []configChange{}
+		for _, cfg := range cfgs {
+			cfgChanges, _, err := computeConfigMapDiff(ctx, log, cfg, r.Client)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
+			changes = append(changes, cfgChanges...)
+		}
+		plan := buildRolloutPlan(changes, restartTargets, restartEnabled, hotReloadEnabled)
+		reportConfigChanges(ctx, log, hbasecluster, &hbasecluster.Status.LastConfigChange, changes, plan, true, r.Recorder, r.Client)
+		return ctrl.Result{}, nil
 	}
 
 	svc := buildService(hbasecluster.Name, hbasecluster.Name, hbasecluster.Namespace, hbasecluster.Spec.ServiceLabels, hbasecluster.Spec.ServiceSelectorLabels, deployments, true)
+	applyServiceSpec(svc, hbasecluster.Spec.Service)
+	addMetricsPort(svc, hbasecluster.Name, hbasecluster.Spec.Monitoring, deployments)
 	ctrl.SetControllerReference(hbasecluster, svc, r.Scheme)
-	result, err := reconcileService(ctx, log, hbasecluster.Namespace, svc, r.Client)
+	result, err := reconcileService(ctx, log, hbasecluster.Namespace, svc, hbasecluster, r.Recorder, r.Client)
 	if (ctrl.Result{}) != result || err != nil {
 		return result, err
 	}
 
-	result, err = validateConfiguration(ctx, log, hbasecluster.Namespace, hbasecluster.Spec.Configuration, r.Client)
+	validateStart := time.Now()
+	result, err = validateClusterConfiguration(ctx, log, hbasecluster.Namespace, hbasecluster.Spec.Configuration, deployments, r.Client)
+	observeReconcilePhase(kind, hbasecluster.Namespace, "", "validation", validateStart)
+	recordConfigValidation(kind, hbasecluster.Namespace, err)
 	if err != nil {
-		publishEvent(ctx, log, hbasecluster.Namespace, "ConfigValidateFailed", err.Error(), "Warning", "ConfigMap", r.Client)
+		recordWarning(r.Recorder, hbasecluster, REASON_CONFIG_VALIDATE_FAILED, err)
 		log.Error(err, "Failed to validate configuration")
 		return result, err
 	}
 
-	namespaces := hbasecluster.Spec.TenantNamespaces
-	namespaces = append(namespaces, hbasecluster.Namespace)
-	// if namespaces are not specified under tenantNamespaces of HbaseClusterDeploymentSpec, then use the namespace of the HbaseCluster only
-	// make sure "hbase.operator.tenant-config/enable" is set to true in HbaseTenant deployment, under service-Labels
-	for _, namespace := range namespaces {
-		cfg := buildConfigMap(hbasecluster.Spec.Configuration.HbaseConfigName, hbasecluster.Name, namespace, hbasecluster.Spec.Configuration.HbaseConfig, hbasecluster.Spec.Configuration.HbaseTenantConfig, log)
-		ctrl.SetControllerReference(hbasecluster, cfg, r.Scheme)
-		result, err = reconcileConfigMap(ctx, log, namespace, cfg, r.Client)
-		if (ctrl.Result{}) != result || err != nil {
-			return result, err
+	// pods of the cluster can not start until the external ZooKeeper and HDFS can be reached
+	if err = checkExternalServices(log, hbasecluster.Spec, configuration.HadoopConfig); err != nil {
+		recordWarning(r.Recorder, hbasecluster, REASON_EXTERNAL_SERVICE_CHECK_FAILED, err)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, err
+	}
+
+	configStart := time.Now()
+	// changes of the ConfigMaps are reported together, so that status is written once per reconcile
+	configChanges := []configChange{}
+	for _, cfg := range cfgs {
+		changes, existing, cfgErr := computeConfigMapDiff(ctx, log, cfg, r.Client)
+		if cfgErr != nil {
+			result, err = ctrl.Result{RequeueAfter: time.Second * 5}, cfgErr
+			break
+		}
+		if _, ok := restartTargets[cfg.Namespace+"/"+cfg.Name]; ok {
+			prepareHotReload(existing, cfg, changes, hotReloadEnabled)
+		}
+		result, err = reconcileConfigMap(ctx, log, cfg.Namespace, cfg, hbasecluster, r.Recorder, r.Client)
+		if err != nil {
+			break
 		}
+		configChanges = append(configChanges, changes...)
+		if (ctrl.Result{}) != result {
+			break
+		}
+	}
+	plan := buildRolloutPlan(configChanges, restartTargets, restartEnabled, hotReloadEnabled)
+	reportConfigChanges(ctx, log, hbasecluster, &hbasecluster.Status.LastConfigChange, configChanges, plan, false, r.Recorder, r.Client)
+	if (ctrl.Result{}) != result || err != nil {
+		return result, err
+	}
+	observeReconcilePhase(kind, hbasecluster.Namespace, "", "configmaps", configStart)
+	if err = reportTenantConfigOverrides(ctx, log, hbasecluster, &hbasecluster.Status.TenantConfigOverrides, overrides, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
 
-		cfg = buildConfigMap(hbasecluster.Spec.Configuration.HadoopConfigName, hbasecluster.Name, namespace, hbasecluster.Spec.Configuration.HadoopConfig, hbasecluster.Spec.Configuration.HadoopTenantConfig, log)
-		ctrl.SetControllerReference(hbasecluster, cfg, r.Scheme)
-		result, err = reconcileConfigMap(ctx, log, namespace, cfg, r.Client)
-		if (ctrl.Result{}) != result || err != nil {
-			return result, err
+	if restartEnabled {
+		// Changes which can be reloaded online are applied once kubelet has synced the configmap, without restarting pods
+		adminEndpoint := ""
+		if hotReloadEnabled {
+			adminEndpoint = hbasecluster.Spec.Configuration.AdminEndpoint
+		}
+		reloaded := map[string]bool{}
+		for _, d := range deployments {
+			cfgName := hbaseConfigNameOf(hbasecluster.Spec.Configuration, d)
+			if reloaded[cfgName] {
+				continue
+			}
+			reloaded[cfgName] = true
+			result, err = reconcileHotReload(ctx, log, hbasecluster.Namespace, cfgName,
+				adminEndpoint, hbasecluster, r.Recorder, r.Client)
+			if (ctrl.Result{}) != result || err != nil {
+				return result, err
+			}
 		}
 	}
 
 	// gets the resource version of the configmap if it has create-time annotation - else returns nil
 	// this is to make deployment backward compatible with v1 - else upon new operator deployment, entire cluster will
 	// be restarted at the sametime - which is not desirable.
-	resourceVersionOfHbaseConfigMap := ""
-	// If label exists and set to true, then validate the config format and reconcile afterwards
-	if exists && (value == "true" || value == "yes") {
-		// Get the resource version of the configmap, if it is v2 then we will use the resource version
-		log.Info("Configmap recon enabled, new Resource Version will be used")
-		resourceVersionOfHbaseConfigMap = getCfgResourceVersionIfV2OrNil(log, r.Client, ctx,
-			hbasecluster.Spec.Configuration.HbaseConfigName, hbasecluster.Namespace)
-	} else {
-		// if Restart of statefulSet is turned off, use existing Annotation as reference
-		log.Info("Configmap recon not enabled, getting existing resource version")
-		resourceVersionOfHbaseConfigMap = getExistingAnnotationOfClusterStatefulSet(log, r.Client, ctx, hbasecluster)
+	resourceVersionOfHbaseConfigMap := getConfigVersion(log, r.Client, ctx, policy, hbasecluster.Spec.Configuration.HbaseConfigName,
+		hbasecluster.Spec.Deployments.Datanode.Name, hbasecluster.Namespace)
+
+	// Exporters are scraped through a ServiceMonitor or PodMonitor, or through pod annotations without prometheus-operator
+	scrapeAnnotations, err := reconcileMonitoring(ctx, log, hbasecluster, hbasecluster.Spec.Monitoring, deployments, r.Recorder, r.Scheme, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	// policies are in place before the pods they select are created
+	if result, err = reconcileNetworkPolicies(ctx, log, hbasecluster, deployments, namespaces, tenants, r.Scheme, r.Client); err != nil {
+		return result, err
+	}
+
+	// racks are published before the StatefulSets are rolled, and refreshed as their pods are scheduled
+	if err = reconcileRackTopology(ctx, log, hbasecluster, deployments, r.Recorder, r.Scheme, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	// bootstrap init containers are dropped from the StatefulSets as soon as their phase is completed
+	if result, err = reconcileBootstrap(ctx, log, hbasecluster, deployments, r.Recorder, r.Client); err != nil {
+		return result, err
+	}
+
+	// external addresses are published as they become known, without holding back the rest of the cluster
+	externalAccessResult, err := reconcileExternalAccess(ctx, log, hbasecluster, r.Recorder, r.Scheme, r.Client)
+	if err != nil {
+		return externalAccessResult, err
 	}
 
 	for _, d := range deployments {
@@ -146,8 +321,9 @@ func (r *HbaseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			for index < d.Size {
 				name = d.Name + "-" + strconv.Itoa(int(index))
 				svc = buildService(name, hbasecluster.Name, hbasecluster.Namespace, nil, nil, []kvstorev1.HbaseClusterDeployment{d}, false)
+				applyServiceSpec(svc, d.Service)
 				ctrl.SetControllerReference(hbasecluster, svc, r.Scheme)
-				result, err = reconcileService(ctx, log, hbasecluster.Namespace, svc, r.Client)
+				result, err = reconcileService(ctx, log, hbasecluster.Namespace, svc, hbasecluster, r.Recorder, r.Client)
 				if (ctrl.Result{}) != result || err != nil {
 					return result, err
 				}
@@ -155,17 +331,53 @@ func (r *HbaseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 			}
 		}
 
+		configVersion := resourceVersionOfHbaseConfigMap
+		if hasConfigOverlay(d) {
+			configVersion = getConfigVersion(log, r.Client, ctx, policy, hbaseConfigNameOf(hbasecluster.Spec.Configuration, d), d.Name, hbasecluster.Namespace)
+		}
+
+		statefulSetStart := time.Now()
+		d.InitContainers = bootstrapInitContainers(hbasecluster, d)
 		newSS, err := buildStatefulSet(hbasecluster.Name, hbasecluster.Namespace, hbasecluster.Spec.BaseImage,
-			hbasecluster.Spec.IsBootstrap, hbasecluster.Spec.Configuration, resourceVersionOfHbaseConfigMap,
+			isBootstrapping(hbasecluster), hbasecluster.Spec.Configuration, configVersion,
 			hbasecluster.Spec.FSGroup, d, log, true)
+		if err == nil {
+			err = injectJmxExporter(newSS, hbasecluster.Name, hbasecluster.Spec.Monitoring, d, scrapeAnnotations)
+		}
+		if err == nil {
+			injectRackTopology(newSS, hbasecluster, d)
+			injectExternalAccess(newSS, hbasecluster, d)
+		}
 		if err != nil {
-			publishEvent(ctx, log, hbasecluster.Namespace, "StatefulSetBuildFailed", err.Error(), "Warning", "StatefulSet/"+d.Name, r.Client)
+			recordWarning(r.Recorder, hbasecluster, REASON_STATEFULSET_BUILD_FAILED, err)
 			log.Error(err, "Failed to build StatefulSet", "StatefulSet.Name", d.Name)
 			return ctrl.Result{}, err
 		}
+		// namenodes and masters are restarted by the operator in the order of their HA state, instead of by ordinal
+		if isNamenodeHA(hbasecluster, d) || isHmasterHA(hbasecluster, d) {
+			newSS.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType}
+		}
 		ctrl.SetControllerReference(hbasecluster, newSS, r.Scheme)
-		result, err := reconcileStatefulSet(ctx, log, hbasecluster.Namespace, newSS, d, r.Client)
+		result, err := reconcileStatefulSet(ctx, log, hbasecluster.Namespace, newSS, d, hbasecluster, r.Recorder, r.Client)
+		observeReconcilePhase(kind, hbasecluster.Namespace, d.Name, "statefulset", statefulSetStart)
+		if err == nil && isNamenodeHA(hbasecluster, d) {
+			var haResult ctrl.Result
+			haResult, err = reconcileNamenodeHA(ctx, log, hbasecluster, configuration.HadoopConfig, r.PodExecutor, r.Recorder, r.Client)
+			if (ctrl.Result{}) != haResult {
+				result = haResult
+			}
+		}
+		if err == nil && isHmasterHA(hbasecluster, d) {
+			var haResult ctrl.Result
+			haResult, err = reconcileHmasterHA(ctx, log, hbasecluster, r.Recorder, r.Client)
+			if (ctrl.Result{}) != haResult {
+				result = haResult
+			}
+		}
 		if (ctrl.Result{}) != result || err != nil {
+			if err := updateAvailableCondition(ctx, log, hbasecluster, false, "StatefulSetNotReady", "StatefulSet "+d.Name+" is not ready", r.Client); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
 			return result, err
 		}
 
@@ -178,15 +390,33 @@ func (r *HbaseClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				return result, err
 			}
 		}
+
+		// other components depend on the quorum, so they wait for the ensemble to be healthy and resized
+		if isZookeeperEnsemble(hbasecluster, d) {
+			result, err = reconcileZookeeperEnsemble(ctx, log, hbasecluster, r.PodExecutor, r.Recorder, r.Client)
+			if (ctrl.Result{}) != result || err != nil {
+				return result, err
+			}
+		}
 	}
 
-	return ctrl.Result{}, nil
+	if err = updateAvailableCondition(ctx, log, hbasecluster, true, "StatefulSetsReady", "All StatefulSets are ready", r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if err = completeBootstrap(ctx, log, hbasecluster, r.Recorder, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return externalAccessResult, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *HbaseClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &kvstorev1.HbaseTenant{}, TENANT_CLUSTER_REF_INDEX, indexTenantClusterRef); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kvstorev1.HbaseCluster{}).
 		Owns(&appsv1.StatefulSet{}).
+		Watches(&kvstorev1.HbaseTenant{}, handler.EnqueueRequestsFromMapFunc(clusterOfTenant)).
 		Complete(r)
 }
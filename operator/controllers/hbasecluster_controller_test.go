@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -49,6 +50,32 @@ func (m *K8sMockClient) Create(ctx context.Context, obj client.Object, opts ...c
 	return args.Error(0)
 }
 
+func (m *K8sMockClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	args := m.Called(ctx, obj)
+	return args.Error(0)
+}
+
+func (m *K8sMockClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	args := m.Called(ctx, list, opts)
+	return args.Error(0)
+}
+
+func (m *K8sMockClient) Status() client.SubResourceWriter {
+	args := m.Called()
+	return args.Get(0).(client.SubResourceWriter)
+}
+
+// K8sMockStatusWriter represents the mock status writer for k8s
+type K8sMockStatusWriter struct {
+	mock.Mock
+	client.SubResourceWriter
+}
+
+func (m *K8sMockStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	args := m.Called(ctx, obj)
+	return args.Error(0)
+}
+
 // TestHbaseClusterReconciler_ResNotFound reconciliation logic test case when nont of the resources not found
 func TestHbaseClusterReconciler_ResNotFound(t *testing.T) {
 	resetHashStore()
@@ -296,9 +323,15 @@ func getMockClientAndReconciler() (*K8sMockClient, *HbaseClusterReconciler) {
 	_ = kvstorev1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
 
+	// overrides of the fixture target namespaces the cluster does not render, which is reported in status
+	statusWriter := new(K8sMockStatusWriter)
+	statusWriter.On("Update", mock.Anything, mock.AnythingOfType("*v1.HbaseCluster")).Return(nil).Maybe()
+	k8sMockClient.On("Status").Return(statusWriter).Maybe()
+
 	reconciler := &HbaseClusterReconciler{
-		Client: k8sMockClient,
-		Scheme: scheme,
+		Client:   k8sMockClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
 	}
 	return k8sMockClient, reconciler
 }
@@ -307,6 +340,8 @@ func getMockClientAndReconciler() (*K8sMockClient, *HbaseClusterReconciler) {
 func doClusterTestSetup() (*K8sMockClient, *HbaseClusterReconciler, context.Context, ctrl.Request) {
 	k8sMockClient, reconciler := getMockClientAndReconciler()
 	ctx := context.TODO()
+	// no HbaseTenant refers to the cluster, unless a test says otherwise
+	k8sMockClient.On("List", ctx, &kvstorev1.HbaseTenantList{}, mock.Anything).Return(nil).Maybe()
 	req := ctrl.Request{
 		NamespacedName: types.NamespacedName{
 			Name:      testCluster,
@@ -460,13 +495,11 @@ func TestHbaseClusterReconciler_ConfigValidationFailure(t *testing.T) {
 	k8sMockClient.On("Get", ctx, req.NamespacedName, &corev1.Service{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
 	k8sMockClient.On("Create", ctx, mockSvc, []client.CreateOption(nil)).Return(nil)
 
-	k8sMockClient.On("Get", ctx, types.NamespacedName{Namespace: hbasecluster.Namespace, Name: "ConfigValidateFailed"}, &corev1.Event{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-	k8sMockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(nil)
-
 	result, err := reconciler.Reconcile(ctx, req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Invalid XML")
 	assert.Equal(t, ctrl.Result{}, result)
+	assert.Contains(t, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)), REASON_CONFIG_VALIDATE_FAILED)
 
 	k8sMockClient.AssertExpectations(t)
 }
@@ -518,14 +551,12 @@ func TestHbaseClusterReconciler_InvalidSizeLimitStatefulSetBuildFailure(t *testi
 	}
 
 	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: hbasecluster.Spec.Deployments.Datanode.Name, Namespace: hbasecluster.Namespace}, &appsv1.StatefulSet{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-	k8sMockClient.On("Get", ctx, types.NamespacedName{Namespace: hbasecluster.Namespace, Name: "StatefulSetBuildFailed"}, &corev1.Event{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-	k8sMockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(nil)
-
 	result, err := reconciler.Reconcile(ctx, req)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Invalid sizeLimit")
 	assert.Contains(t, err.Error(), "app-log")
 	assert.Equal(t, ctrl.Result{}, result)
+	assert.Contains(t, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)), REASON_STATEFULSET_BUILD_FAILED)
 
 	k8sMockClient.AssertExpectations(t)
 }
\ No newline at end of file
@@ -0,0 +1,145 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// HbaseNamespaceReconciler creates HBase namespaces and keeps their properties in sync through the REST gateway of
+// the cluster. Namespaces are left in HBase when the HbaseNamespace object is deleted
+type HbaseNamespaceReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasenamespaces,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasenamespaces/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile creates the namespace when it does not exist, and sets the properties of the spec which differ. Other
+// properties are left as they are
+func (r *HbaseNamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasenamespace", req.NamespacedName)
+
+	ns := &kvstorev1.HbaseNamespace{}
+	err := r.Client.Get(ctx, req.NamespacedName, ns)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseNamespace resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseNamespace")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	name := ns.Spec.Name
+	if len(name) == 0 {
+		name = ns.Name
+	}
+
+	cluster, err := referencedClusterOf(ctx, ns.Spec.ClusterRef, ns.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		ref := schemaClusterRefOf(ns.Spec.ClusterRef, ns.Namespace)
+		return r.updateStatus(ctx, log, ns, schemaCondition(false, ns.Generation, "ClusterNotFound", "Cluster %s not found", ref),
+			ctrl.Result{RequeueAfter: time.Second * 30}, nil)
+	} else if err != nil {
+		log.Error(err, "Failed to get cluster of HbaseNamespace")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if len(cluster.Configuration.RestEndpoint) == 0 {
+		return r.updateStatus(ctx, log, ns, schemaCondition(false, ns.Generation, "RestEndpointNotSet", "configuration.restEndpoint of the cluster is not set"),
+			ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+	}
+	rest := newHbaseRest(cluster.Configuration.RestEndpoint)
+
+	current, err := rest.GetNamespace(ctx, name)
+	if err != nil {
+		return r.failed(ctx, log, ns, err)
+	}
+	if current == nil {
+		log.Info("Creating HBase namespace", "Namespace", name)
+		if err = rest.CreateNamespace(ctx, name, ns.Spec.Properties); err != nil {
+			return r.failed(ctx, log, ns, err)
+		}
+		r.Recorder.Event(ns, corev1.EventTypeNormal, REASON_NAMESPACE_CREATED, "Created HBase namespace "+name)
+	} else {
+		properties := map[string]string{}
+		for k, v := range current.Properties {
+			properties[k] = v
+		}
+		changed := false
+		for k, v := range ns.Spec.Properties {
+			if current.Properties[k] != v {
+				properties[k] = v
+				changed = true
+			}
+		}
+		if changed {
+			log.Info("Altering HBase namespace", "Namespace", name)
+			if err = rest.AlterNamespace(ctx, name, properties); err != nil {
+				return r.failed(ctx, log, ns, err)
+			}
+			r.Recorder.Event(ns, corev1.EventTypeNormal, REASON_NAMESPACE_ALTERED, "Altered properties of HBase namespace "+name)
+		}
+	}
+	return r.updateStatus(ctx, log, ns, schemaCondition(true, ns.Generation, "Synced", "HBase namespace %s is in sync", name),
+		ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+}
+
+// updateStatus sets the Ready condition, when it changed, and returns the given result
+func (r *HbaseNamespaceReconciler) updateStatus(ctx context.Context, log logr.Logger, ns *kvstorev1.HbaseNamespace, condition metav1.Condition,
+	result ctrl.Result, err error) (ctrl.Result, error) {
+	changed := ns.Status.ObservedGeneration != ns.Generation
+	ns.Status.ObservedGeneration = ns.Generation
+	if meta.SetStatusCondition(&ns.Status.Conditions, condition) || changed {
+		if updateErr := r.Client.Status().Update(ctx, ns); updateErr != nil {
+			log.Error(updateErr, "Failed to update HbaseNamespace status")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *HbaseNamespaceReconciler) failed(ctx context.Context, log logr.Logger, ns *kvstorev1.HbaseNamespace, err error) (ctrl.Result, error) {
+	r.Recorder.Event(ns, corev1.EventTypeWarning, REASON_SCHEMA_UPDATE_FAILED, err.Error())
+	log.Error(err, "Failed to sync HBase namespace through the REST gateway")
+	return r.updateStatus(ctx, log, ns, schemaCondition(false, ns.Generation, "RestGatewayError", "%s", err.Error()),
+		ctrl.Result{RequeueAfter: time.Second * 5}, err)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseNamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseNamespace{}).
+		Complete(r)
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// mockSchemaCluster returns the HbaseCluster schema objects refer to, with the given REST gateway
+func mockSchemaCluster(k8sMockClient *K8sMockClient, ctx context.Context, restEndpoint string, adminEndpoint string) {
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "cluster", Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Run(func(args mock.Arguments) {
+			c := args.Get(2).(*kvstorev1.HbaseCluster)
+			c.Spec.Configuration.RestEndpoint = restEndpoint
+			c.Spec.Configuration.AdminEndpoint = adminEndpoint
+		}).
+		Return(nil)
+}
+
+func doNamespaceTestSetup(ns *kvstorev1.HbaseNamespace) (*K8sMockClient, *K8sMockStatusWriter, *HbaseNamespaceReconciler, context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: ns.Name, Namespace: ns.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseNamespace{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseNamespace) = *ns
+		}).
+		Return(nil)
+	return k8sMockClient, statusWriter, &HbaseNamespaceReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100)}, ctx, req
+}
+
+func newHbaseNamespace() *kvstorev1.HbaseNamespace {
+	return &kvstorev1.HbaseNamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: testNamespace, Generation: 1},
+		Spec: kvstorev1.HbaseNamespaceSpec{ClusterRef: kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			Properties: map[string]string{"hbase.namespace.quota.maxtables": "20"}},
+	}
+}
+
+// TestHbaseNamespaceReconcile_Created verifies a missing namespace is created with the properties of the spec.
+func TestHbaseNamespaceReconcile_Created(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	k8sMockClient, statusWriter, reconciler, ctx, req := doNamespaceTestSetup(newHbaseNamespace())
+	mockSchemaCluster(k8sMockClient, ctx, server.URL, "")
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(ns *kvstorev1.HbaseNamespace) bool {
+		return meta.IsStatusConditionTrue(ns.Status.Conditions, CONDITION_READY) && ns.Status.ObservedGeneration == 1
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: schemaSyncInterval}, result)
+	assert.Equal(t, map[string]string{"hbase.namespace.quota.maxtables": "20"}, fake.namespaces["orders"])
+	assert.Equal(t, []string{REASON_NAMESPACE_CREATED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseNamespaceReconcile_Altered verifies only properties of the spec which differ are set, others being kept.
+func TestHbaseNamespaceReconcile_Altered(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	fake.namespaces["team"] = map[string]string{"hbase.namespace.quota.maxtables": "10", "owner": "team"}
+	ns := newHbaseNamespace()
+	ns.Spec.Name = "team"
+	meta.SetStatusCondition(&ns.Status.Conditions, schemaCondition(true, 1, "Synced", "HBase namespace %s is in sync", "team"))
+	ns.Status.ObservedGeneration = 1
+	k8sMockClient, _, reconciler, ctx, req := doNamespaceTestSetup(ns)
+	mockSchemaCluster(k8sMockClient, ctx, server.URL, "")
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: schemaSyncInterval}, result)
+	assert.Equal(t, map[string]string{"hbase.namespace.quota.maxtables": "20", "owner": "team"}, fake.namespaces["team"])
+	assert.Equal(t, []string{REASON_NAMESPACE_ALTERED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
+
+// TestHbaseNamespaceReconcile_ClusterNotFound verifies the namespace waits for its cluster.
+func TestHbaseNamespaceReconcile_ClusterNotFound(t *testing.T) {
+	k8sMockClient, statusWriter, reconciler, ctx, req := doNamespaceTestSetup(newHbaseNamespace())
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "cluster", Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, "cluster"))
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(ns *kvstorev1.HbaseNamespace) bool {
+		c := meta.FindStatusCondition(ns.Status.Conditions, CONDITION_READY)
+		return c != nil && c.Status == metav1.ConditionFalse && c.Reason == "ClusterNotFound"
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 30}, result)
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
@@ -0,0 +1,329 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	sort "sort"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// REPLICATION_PEER_FINALIZER removes the peer from the source cluster before the HbaseReplicationPeer is deleted
+const REPLICATION_PEER_FINALIZER = "hbase-operator/replication-peer"
+
+// replicationSyncInterval after which peers are compared with the source cluster again, and their lag refreshed
+const replicationSyncInterval = time.Minute
+
+// HbaseReplicationPeerReconciler adds, updates and removes replication peers through the admin endpoint of the
+// source cluster, and reports their lag
+type HbaseReplicationPeerReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasereplicationpeers,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasereplicationpeers/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasereplicationpeers/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile adds the peer when it does not exist in the source cluster, and applies its cluster key, tables and
+// enabled state when they differ. Peers whose cluster key or serial flag changed are re-created, as HBase does not
+// allow changing them
+func (r *HbaseReplicationPeerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasereplicationpeer", req.NamespacedName)
+
+	p := &kvstorev1.HbaseReplicationPeer{}
+	err := r.Client.Get(ctx, req.NamespacedName, p)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseReplicationPeer resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseReplicationPeer")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	if !p.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, log, p)
+	}
+	if controllerutil.AddFinalizer(p, REPLICATION_PEER_FINALIZER) {
+		if err = r.Client.Update(ctx, p); err != nil {
+			log.Error(err, "Failed to add finalizer to HbaseReplicationPeer")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+
+	source, err := referencedClusterOf(ctx, p.Spec.SourceClusterRef, p.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		ref := schemaClusterRefOf(p.Spec.SourceClusterRef, p.Namespace)
+		return r.updateStatus(ctx, log, p, schemaCondition(false, p.Generation, "ClusterNotFound", "Source cluster %s not found", ref),
+			ctrl.Result{RequeueAfter: time.Second * 30}, nil)
+	} else if err != nil {
+		log.Error(err, "Failed to get source cluster of HbaseReplicationPeer")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if len(source.Configuration.AdminEndpoint) == 0 {
+		return r.updateStatus(ctx, log, p, schemaCondition(false, p.Generation, "AdminEndpointNotSet", "configuration.adminEndpoint of the source cluster is not set"),
+			ctrl.Result{RequeueAfter: replicationSyncInterval}, nil)
+	}
+
+	desired, err := r.desiredPeer(ctx, p)
+	if err != nil {
+		if !errors.IsNotFound(err) && !isInvalidTarget(err) {
+			log.Error(err, "Failed to get target cluster of HbaseReplicationPeer")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		return r.updateStatus(ctx, log, p, schemaCondition(false, p.Generation, "InvalidTarget", "%s", err.Error()),
+			ctrl.Result{RequeueAfter: time.Second * 30}, nil)
+	}
+
+	admin := newHbaseAdmin(source.Configuration.AdminEndpoint)
+	if err = r.syncPeer(ctx, log, admin, desired, p); err != nil {
+		return r.failed(ctx, log, p, err)
+	}
+	load, err := admin.GetReplicationLoad(ctx)
+	if err != nil {
+		return r.failed(ctx, log, p, err)
+	}
+
+	p.Status.ClusterKey = desired.ClusterKey
+	p.Status.Enabled = desired.Enabled
+	p.Status.ReplicationLag, p.Status.SizeOfLogQueue = replicationLagOf(load, desired.ID)
+	return r.updateStatus(ctx, log, p, schemaCondition(true, p.Generation, "Synced", "Replication peer %s is in sync", desired.ID),
+		ctrl.Result{RequeueAfter: replicationSyncInterval}, nil)
+}
+
+// invalidTargetError is returned when the cluster key of the target can not be resolved from its configuration
+type invalidTargetError struct {
+	message string
+}
+
+func (e *invalidTargetError) Error() string {
+	return e.message
+}
+
+func isInvalidTarget(err error) bool {
+	_, ok := err.(*invalidTargetError)
+	return ok
+}
+
+// desiredPeer returns the peer of the spec, with the cluster key of the target cluster unless set
+func (r *HbaseReplicationPeerReconciler) desiredPeer(ctx context.Context, p *kvstorev1.HbaseReplicationPeer) (ReplicationPeer, error) {
+	peer := ReplicationPeer{ID: p.PeerID(), ClusterKey: p.Spec.ClusterKey, Enabled: p.Spec.Enabled == nil || *p.Spec.Enabled, Serial: p.Spec.Serial}
+	if len(p.Spec.TableCFs) > 0 {
+		peer.TableCFs = map[string][]string{}
+		for _, t := range p.Spec.TableCFs {
+			peer.TableCFs[t.Table] = append([]string{}, t.ColumnFamilies...)
+		}
+	}
+	if p.Spec.TargetClusterRef == nil {
+		return peer, nil
+	}
+
+	target, err := referencedClusterOf(ctx, *p.Spec.TargetClusterRef, p.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		return peer, &invalidTargetError{message: fmt.Sprintf("target cluster %s not found", schemaClusterRefOf(*p.Spec.TargetClusterRef, p.Namespace))}
+	} else if err != nil {
+		return peer, err
+	}
+	peer.ClusterKey, err = clusterKeyOf(target.Configuration)
+	return peer, err
+}
+
+// clusterKeyOf returns the cluster key of a cluster, as quorum:clientPort:znodeParent of its hbase-site.xml
+func clusterKeyOf(c kvstorev1.HbaseClusterConfiguration) (string, error) {
+	properties, _ := parseConfigProperties("hbase-site.xml", c.HbaseConfig["hbase-site.xml"])
+	quorum := properties["hbase.zookeeper.quorum"]
+	if len(quorum) == 0 {
+		return "", &invalidTargetError{message: "hbase.zookeeper.quorum is not set in hbase-site.xml of the target cluster"}
+	}
+	port, znode := properties["hbase.zookeeper.property.clientPort"], properties["zookeeper.znode.parent"]
+	if len(port) == 0 {
+		port = "2181"
+	}
+	if len(znode) == 0 {
+		znode = "/hbase"
+	}
+	return quorum + ":" + port + ":" + znode, nil
+}
+
+// syncPeer adds, re-creates or updates the peer in the source cluster so that it matches the desired one
+func (r *HbaseReplicationPeerReconciler) syncPeer(ctx context.Context, log logr.Logger, admin HbaseAdmin, desired ReplicationPeer,
+	p *kvstorev1.HbaseReplicationPeer) error {
+	peers, err := admin.ListReplicationPeers(ctx)
+	if err != nil {
+		return err
+	}
+	var current *ReplicationPeer
+	for i := range peers {
+		if peers[i].ID == desired.ID {
+			current = &peers[i]
+		}
+	}
+
+	if current != nil && (current.ClusterKey != desired.ClusterKey || current.Serial != desired.Serial) {
+		log.Info("Removing replication peer to re-create it", "Peer", desired.ID, "ClusterKey", desired.ClusterKey)
+		if err = admin.RemoveReplicationPeer(ctx, desired.ID); err != nil {
+			return err
+		}
+		current = nil
+	}
+	if current == nil {
+		log.Info("Adding replication peer", "Peer", desired.ID, "ClusterKey", desired.ClusterKey)
+		if err = admin.AddReplicationPeer(ctx, desired); err != nil {
+			return err
+		}
+		r.Recorder.Event(p, corev1.EventTypeNormal, REASON_PEER_ADDED, "Added replication peer "+desired.ID+" to "+desired.ClusterKey)
+		return nil
+	}
+
+	if !equalTableCFs(current.TableCFs, desired.TableCFs) {
+		log.Info("Updating tables of replication peer", "Peer", desired.ID)
+		if err = admin.UpdateReplicationPeer(ctx, desired); err != nil {
+			return err
+		}
+		r.Recorder.Event(p, corev1.EventTypeNormal, REASON_PEER_UPDATED, "Updated tables of replication peer "+desired.ID)
+	}
+	if current.Enabled != desired.Enabled {
+		log.Info("Changing state of replication peer", "Peer", desired.ID, "Enabled", desired.Enabled)
+		if err = admin.SetReplicationPeerEnabled(ctx, desired.ID, desired.Enabled); err != nil {
+			return err
+		}
+		reason, message := REASON_PEER_DISABLED, "Disabled replication peer "+desired.ID
+		if desired.Enabled {
+			reason, message = REASON_PEER_ENABLED, "Enabled replication peer "+desired.ID
+		}
+		r.Recorder.Event(p, corev1.EventTypeNormal, reason, message)
+	}
+	return nil
+}
+
+// equalTableCFs compares the replicated tables regardless of the order of their column families
+func equalTableCFs(a map[string][]string, b map[string][]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for table, families := range a {
+		other, ok := b[table]
+		if !ok || len(families) != len(other) {
+			return false
+		}
+		x, y := append([]string{}, families...), append([]string{}, other...)
+		sort.Strings(x)
+		sort.Strings(y)
+		if !equality.Semantic.DeepEqual(x, y) {
+			return false
+		}
+	}
+	return true
+}
+
+// replicationLagOf returns the largest lag and the total log queue of the peer across the regionservers, no lag when
+// no regionserver reports the peer
+func replicationLagOf(load []ReplicationSourceLoad, id string) (*metav1.Duration, int64) {
+	var lag *metav1.Duration
+	queue := int64(0)
+	for _, l := range load {
+		if l.PeerID != id {
+			continue
+		}
+		if d := time.Duration(l.ReplicationLag) * time.Millisecond; lag == nil || d > lag.Duration {
+			lag = &metav1.Duration{Duration: d}
+		}
+		queue += l.SizeOfLogQueue
+	}
+	return lag, queue
+}
+
+// finalize removes the peer from the source cluster, and the finalizer once done. Peers of source clusters which are
+// gone, or without admin endpoint, are left alone
+func (r *HbaseReplicationPeerReconciler) finalize(ctx context.Context, log logr.Logger, p *kvstorev1.HbaseReplicationPeer) (ctrl.Result, error) {
+	if !controllerutil.ContainsFinalizer(p, REPLICATION_PEER_FINALIZER) {
+		return ctrl.Result{}, nil
+	}
+	source, err := referencedClusterOf(ctx, p.Spec.SourceClusterRef, p.Namespace, r.Client)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get source cluster of HbaseReplicationPeer")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if err == nil && len(source.Configuration.AdminEndpoint) > 0 {
+		log.Info("Removing replication peer", "Peer", p.PeerID())
+		err = newHbaseAdmin(source.Configuration.AdminEndpoint).RemoveReplicationPeer(ctx, p.PeerID())
+		if err != nil && !isNotFoundStatus(err) {
+			r.Recorder.Event(p, corev1.EventTypeWarning, REASON_PEER_UPDATE_FAILED, err.Error())
+			log.Error(err, "Failed to remove replication peer")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		r.Recorder.Event(p, corev1.EventTypeNormal, REASON_PEER_REMOVED, "Removed replication peer "+p.PeerID())
+	} else {
+		log.Info("Source cluster is gone or has no admin endpoint, replication peer is left alone", "Peer", p.PeerID())
+	}
+
+	controllerutil.RemoveFinalizer(p, REPLICATION_PEER_FINALIZER)
+	if err = r.Client.Update(ctx, p); err != nil {
+		log.Error(err, "Failed to remove finalizer of HbaseReplicationPeer")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// updateStatus sets the Ready condition, and updates the status when it changed
+func (r *HbaseReplicationPeerReconciler) updateStatus(ctx context.Context, log logr.Logger, p *kvstorev1.HbaseReplicationPeer, condition metav1.Condition,
+	result ctrl.Result, err error) (ctrl.Result, error) {
+	previous := p.Status.DeepCopy()
+	p.Status.ObservedGeneration = p.Generation
+	meta.SetStatusCondition(&p.Status.Conditions, condition)
+	if !equality.Semantic.DeepEqual(previous, &p.Status) {
+		if updateErr := r.Client.Status().Update(ctx, p); updateErr != nil {
+			log.Error(updateErr, "Failed to update HbaseReplicationPeer status")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *HbaseReplicationPeerReconciler) failed(ctx context.Context, log logr.Logger, p *kvstorev1.HbaseReplicationPeer, err error) (ctrl.Result, error) {
+	r.Recorder.Event(p, corev1.EventTypeWarning, REASON_PEER_UPDATE_FAILED, err.Error())
+	log.Error(err, "Failed to sync replication peer through the admin endpoint")
+	return r.updateStatus(ctx, log, p, schemaCondition(false, p.Generation, "AdminEndpointError", "%s", err.Error()),
+		ctrl.Result{RequeueAfter: time.Second * 5}, err)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseReplicationPeerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseReplicationPeer{}).
+		Complete(r)
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func doReplicationPeerTestSetup(p *kvstorev1.HbaseReplicationPeer) (*K8sMockClient, *K8sMockStatusWriter, *HbaseReplicationPeerReconciler,
+	context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: p.Name, Namespace: p.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseReplicationPeer{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseReplicationPeer) = *p.DeepCopy()
+		}).
+		Return(nil)
+	reconciler := &HbaseReplicationPeerReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100)}
+	return k8sMockClient, statusWriter, reconciler, ctx, req
+}
+
+func newReplicationPeer() *kvstorev1.HbaseReplicationPeer {
+	return &kvstorev1.HbaseReplicationPeer{
+		ObjectMeta: metav1.ObjectMeta{Name: "dr-site", Namespace: testNamespace, Generation: 1, Finalizers: []string{REPLICATION_PEER_FINALIZER}},
+		Spec: kvstorev1.HbaseReplicationPeerSpec{
+			SourceClusterRef: kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			TargetClusterRef: &kvstorev1.HbaseSchemaClusterReference{Name: "target"},
+			TableCFs:         []kvstorev1.HbaseReplicationTableCFs{{Table: "orders:items", ColumnFamilies: []string{"d"}}},
+		},
+	}
+}
+
+func mockTargetCluster(k8sMockClient *K8sMockClient, ctx context.Context) {
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "target", Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Run(func(args mock.Arguments) {
+			c := args.Get(2).(*kvstorev1.HbaseCluster)
+			c.Spec.Configuration.HbaseConfig = map[string]string{"hbase-site.xml": `<configuration>
+  <property><name>hbase.zookeeper.quorum</name><value>zk-0.target,zk-1.target</value></property>
+  <property><name>zookeeper.znode.parent</name><value>/hbase-target</value></property>
+</configuration>`}
+		}).
+		Return(nil)
+}
+
+// TestHbaseReplicationPeerReconcile_Add verifies the finalizer is added, and the peer added with the cluster key of
+// the target cluster and its lag reported.
+func TestHbaseReplicationPeerReconcile_Add(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.replication = []ReplicationSourceLoad{{PeerID: "dr_site", Server: "rs-0", ReplicationLag: 1500, SizeOfLogQueue: 2},
+		{PeerID: "dr_site", Server: "rs-1", ReplicationLag: 300, SizeOfLogQueue: 1}, {PeerID: "other", Server: "rs-0", ReplicationLag: 9000}}
+	p := newReplicationPeer()
+	p.Finalizers = nil
+	k8sMockClient, statusWriter, reconciler, ctx, req := doReplicationPeerTestSetup(p)
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	mockTargetCluster(k8sMockClient, ctx)
+	k8sMockClient.On("Update", ctx, mock.MatchedBy(func(p *kvstorev1.HbaseReplicationPeer) bool {
+		return assert.ObjectsAreEqual([]string{REPLICATION_PEER_FINALIZER}, p.Finalizers)
+	}), []client.UpdateOption(nil)).Return(nil)
+	k8sMockClient.On("Status").Return(statusWriter)
+	var updated *kvstorev1.HbaseReplicationPeer
+	statusWriter.On("Update", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			updated = args.Get(1).(*kvstorev1.HbaseReplicationPeer)
+		}).
+		Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: replicationSyncInterval}, result)
+	assert.Equal(t, ReplicationPeer{ID: "dr_site", ClusterKey: "zk-0.target,zk-1.target:2181:/hbase-target", Enabled: true,
+		TableCFs: map[string][]string{"orders:items": {"d"}}}, fake.peers["dr_site"])
+	assert.Equal(t, "zk-0.target,zk-1.target:2181:/hbase-target", updated.Status.ClusterKey)
+	assert.True(t, updated.Status.Enabled)
+	assert.Equal(t, &metav1.Duration{Duration: time.Millisecond * 1500}, updated.Status.ReplicationLag)
+	assert.Equal(t, int64(3), updated.Status.SizeOfLogQueue)
+	assert.Equal(t, "Synced", updated.Status.Conditions[0].Reason)
+	assert.Equal(t, []string{REASON_PEER_ADDED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestHbaseReplicationPeerReconcile_Update verifies tables and state are updated in place, while a changed cluster key
+// re-creates the peer.
+func TestHbaseReplicationPeerReconcile_Update(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.peers["dr_site"] = ReplicationPeer{ID: "dr_site", ClusterKey: "zk-0.target,zk-1.target:2181:/hbase-target", Enabled: true,
+		TableCFs: map[string][]string{"orders:items": {"d", "h"}}}
+	fake.peers["archive"] = ReplicationPeer{ID: "archive", ClusterKey: "zk-old:2181:/hbase", Enabled: true}
+	for _, tc := range []struct {
+		name    string
+		update  func(p *kvstorev1.HbaseReplicationPeer)
+		events  []string
+		id      string
+		expects ReplicationPeer
+	}{
+		{"in place", func(p *kvstorev1.HbaseReplicationPeer) { p.Spec.Enabled = new(bool) }, []string{REASON_PEER_UPDATED, REASON_PEER_DISABLED}, "dr_site",
+			ReplicationPeer{ID: "dr_site", ClusterKey: "zk-0.target,zk-1.target:2181:/hbase-target", TableCFs: map[string][]string{"orders:items": {"d"}}}},
+		{"re-created", func(p *kvstorev1.HbaseReplicationPeer) {
+			p.Name, p.Spec.TargetClusterRef, p.Spec.ClusterKey, p.Spec.TableCFs = "archive", nil, "zk-new:2181:/hbase", nil
+		}, []string{REASON_PEER_ADDED}, "archive", ReplicationPeer{ID: "archive", ClusterKey: "zk-new:2181:/hbase", Enabled: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p := newReplicationPeer()
+			tc.update(p)
+			k8sMockClient, statusWriter, reconciler, ctx, req := doReplicationPeerTestSetup(p)
+			mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+			if p.Spec.TargetClusterRef != nil {
+				mockTargetCluster(k8sMockClient, ctx)
+			}
+			k8sMockClient.On("Status").Return(statusWriter)
+			statusWriter.On("Update", ctx, mock.Anything).Return(nil)
+
+			_, err := reconciler.Reconcile(ctx, req)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expects, fake.peers[tc.id])
+			assert.Equal(t, tc.events, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+			k8sMockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestHbaseReplicationPeerReconcile_Delete verifies the peer is removed from the source cluster before the finalizer.
+func TestHbaseReplicationPeerReconcile_Delete(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.peers["dr_site"] = ReplicationPeer{ID: "dr_site", ClusterKey: "zk-0:2181:/hbase", Enabled: true}
+	p := newReplicationPeer()
+	p.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	k8sMockClient, _, reconciler, ctx, req := doReplicationPeerTestSetup(p)
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	k8sMockClient.On("Update", ctx, mock.MatchedBy(func(p *kvstorev1.HbaseReplicationPeer) bool {
+		return len(p.Finalizers) == 0
+	}), []client.UpdateOption(nil)).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Empty(t, fake.peers)
+	assert.Equal(t, []string{REASON_PEER_REMOVED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	time "time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// HbaseRestoreReconciler imports an exported snapshot into a cluster with a job running ExportSnapshot, and restores
+// or clones it through the admin endpoint. Restores run once, changes to the spec after they finished are ignored
+type HbaseRestoreReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaserestores,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaserestores/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile starts the import job when the snapshot has a source, and once the snapshot is in the cluster restores
+// the table of the snapshot or clones it into a new table
+func (r *HbaseRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbaserestore", req.NamespacedName)
+
+	rs := &kvstorev1.HbaseRestore{}
+	err := r.Client.Get(ctx, req.NamespacedName, rs)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseRestore resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseRestore")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if rs.Status.Phase == kvstorev1.HbaseSnapshotJobSucceeded || rs.Status.Phase == kvstorev1.HbaseSnapshotJobFailed {
+		return ctrl.Result{}, nil
+	}
+
+	cluster, err := referencedClusterOf(ctx, rs.Spec.ClusterRef, rs.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		rs.Status.Phase = kvstorev1.HbaseSnapshotJobPending
+		rs.Status.Message = fmt.Sprintf("cluster %s not found", schemaClusterRefOf(rs.Spec.ClusterRef, rs.Namespace))
+		return r.updateStatus(ctx, log, rs, ctrl.Result{RequeueAfter: time.Second * 30})
+	} else if err != nil {
+		log.Error(err, "Failed to get cluster of HbaseRestore")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	switch rs.Status.Phase {
+	case kvstorev1.HbaseSnapshotJobRunning:
+		return r.awaitImport(ctx, log, rs)
+	case kvstorev1.HbaseSnapshotJobRestoring:
+		return r.restore(ctx, log, rs, cluster)
+	}
+	if rs.Spec.Source == nil {
+		rs.Status.Phase = kvstorev1.HbaseSnapshotJobRestoring
+		rs.Status.StartTime = &metav1.Time{Time: time.Now()}
+		return r.restore(ctx, log, rs, cluster)
+	}
+
+	rootDir, err := hbaseRootDirOf(cluster.Configuration)
+	if err != nil {
+		return r.failed(ctx, log, rs, err.Error())
+	}
+	args := exportSnapshotArgs(rs.Spec.Snapshot, rs.Spec.Source.URL, rootDir, *rs.Spec.Source, rs.Spec.Job)
+	job, err := buildSnapshotJob(rs.Name+"-import", rs.Namespace, cluster, *rs.Spec.Source, rs.Spec.Job, args)
+	if err != nil {
+		return r.failed(ctx, log, rs, err.Error())
+	}
+	if err = ctrl.SetControllerReference(rs, job, r.Scheme); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("Creating import job", "Job", job.Name, "Source", rs.Spec.Source.URL)
+	if err = runSnapshotJob(ctx, job, r.Client); err != nil {
+		log.Error(err, "Failed to create import job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	r.Recorder.Event(rs, corev1.EventTypeNormal, REASON_IMPORT_STARTED, "Importing snapshot "+rs.Spec.Snapshot+" from "+rs.Spec.Source.URL)
+	rs.Status.Phase = kvstorev1.HbaseSnapshotJobRunning
+	rs.Status.JobName = job.Name
+	rs.Status.StartTime = &metav1.Time{Time: time.Now()}
+	rs.Status.Message = ""
+	return r.updateStatus(ctx, log, rs, ctrl.Result{RequeueAfter: snapshotJobPollInterval})
+}
+
+// awaitImport moves on to restoring once the import job succeeded
+func (r *HbaseRestoreReconciler) awaitImport(ctx context.Context, log logr.Logger, rs *kvstorev1.HbaseRestore) (ctrl.Result, error) {
+	job := &batchv1.Job{}
+	err := r.Client.Get(ctx, types.NamespacedName{Name: rs.Status.JobName, Namespace: rs.Namespace}, job)
+	if errors.IsNotFound(err) {
+		return r.failed(ctx, log, rs, "job "+rs.Status.JobName+" was deleted before it finished")
+	} else if err != nil {
+		log.Error(err, "Failed to get import job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	result, err := snapshotJobResultOf(ctx, job, r.Client)
+	if err != nil {
+		log.Error(err, "Failed to get pods of import job")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if !result.finished {
+		return ctrl.Result{RequeueAfter: snapshotJobPollInterval}, nil
+	}
+	if !result.succeeded {
+		return r.failed(ctx, log, rs, "import job failed: "+result.message)
+	}
+	log.Info("Imported snapshot", "Snapshot", rs.Spec.Snapshot, "BytesCopied", result.bytesCopied)
+	rs.Status.Phase = kvstorev1.HbaseSnapshotJobRestoring
+	rs.Status.BytesCopied = result.bytesCopied
+	return r.updateStatus(ctx, log, rs, ctrl.Result{RequeueAfter: time.Second})
+}
+
+// restore restores the table of the snapshot in place, or clones the snapshot into the table of the spec
+func (r *HbaseRestoreReconciler) restore(ctx context.Context, log logr.Logger, rs *kvstorev1.HbaseRestore,
+	cluster referencedCluster) (ctrl.Result, error) {
+	if len(cluster.Configuration.AdminEndpoint) == 0 {
+		return r.failed(ctx, log, rs, "configuration.adminEndpoint of the cluster is not set")
+	}
+	admin := newHbaseAdmin(cluster.Configuration.AdminEndpoint)
+
+	var err error
+	message := "Restored snapshot " + rs.Spec.Snapshot
+	if len(rs.Spec.Table) > 0 {
+		log.Info("Cloning snapshot", "Snapshot", rs.Spec.Snapshot, "Table", rs.Spec.Table)
+		err = admin.CloneSnapshot(ctx, rs.Spec.Snapshot, rs.Spec.Table)
+		message = "Cloned snapshot " + rs.Spec.Snapshot + " into table " + rs.Spec.Table
+	} else {
+		log.Info("Restoring snapshot", "Snapshot", rs.Spec.Snapshot)
+		err = admin.RestoreSnapshot(ctx, rs.Spec.Snapshot)
+	}
+	if err != nil && isRejectedByHbase(err) {
+		return r.failed(ctx, log, rs, err.Error())
+	} else if err != nil {
+		log.Error(err, "Failed to reach the admin endpoint")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	r.Recorder.Event(rs, corev1.EventTypeNormal, REASON_RESTORE_SUCCEEDED, message)
+	rs.Status.Phase = kvstorev1.HbaseSnapshotJobSucceeded
+	rs.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	return r.updateStatus(ctx, log, rs, ctrl.Result{})
+}
+
+func (r *HbaseRestoreReconciler) updateStatus(ctx context.Context, log logr.Logger, rs *kvstorev1.HbaseRestore, result ctrl.Result) (ctrl.Result, error) {
+	rs.Status.ObservedGeneration = rs.Generation
+	if err := r.Client.Status().Update(ctx, rs); err != nil {
+		log.Error(err, "Failed to update HbaseRestore status")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return result, nil
+}
+
+func (r *HbaseRestoreReconciler) failed(ctx context.Context, log logr.Logger, rs *kvstorev1.HbaseRestore, message string) (ctrl.Result, error) {
+	log.Info("HbaseRestore failed", "Message", message)
+	r.Recorder.Event(rs, corev1.EventTypeWarning, REASON_RESTORE_FAILED, message)
+	rs.Status.Phase = kvstorev1.HbaseSnapshotJobFailed
+	rs.Status.Message = message
+	rs.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	return r.updateStatus(ctx, log, rs, ctrl.Result{})
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func doRestoreTestSetup(rs *kvstorev1.HbaseRestore) (*K8sMockClient, *K8sMockStatusWriter, *HbaseRestoreReconciler, context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: rs.Name, Namespace: rs.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseRestore{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseRestore) = *rs
+		}).
+		Return(nil)
+	k8sMockClient.On("Status").Return(statusWriter)
+	reconciler := &HbaseRestoreReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100), Scheme: newSnapshotJobScheme()}
+	return k8sMockClient, statusWriter, reconciler, ctx, req
+}
+
+func newRestore() *kvstorev1.HbaseRestore {
+	return &kvstorev1.HbaseRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: testNamespace, Generation: 1},
+		Spec: kvstorev1.HbaseRestoreSpec{
+			ClusterRef: kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			Snapshot:   "orders-snapshot",
+			Table:      "orders:items_restored",
+		},
+	}
+}
+
+// TestHbaseRestoreReconcile_Import verifies snapshots with a source are first imported into hbase.rootdir of the cluster.
+func TestHbaseRestoreReconcile_Import(t *testing.T) {
+	rs := newRestore()
+	rs.Spec.Source = &kvstorev1.HbaseBackupLocation{URL: "file:///backups"}
+	k8sMockClient, statusWriter, reconciler, ctx, req := doRestoreTestSetup(rs)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "cluster", Namespace: testNamespace}, &kvstorev1.HbaseCluster{}).
+		Run(func(args mock.Arguments) {
+			c := args.Get(2).(*kvstorev1.HbaseCluster)
+			c.Spec.Configuration = newSnapshotJobCluster().Configuration
+		}).
+		Return(nil)
+	k8sMockClient.On("Create", ctx, mock.MatchedBy(func(job *batchv1.Job) bool {
+		return job.Name == "orders-import" && assert.ObjectsAreEqual([]string{EXPORT_SNAPSHOT_CLASS, "-snapshot", "orders-snapshot",
+			"-copy-from", "file:///backups", "-copy-to", "hdfs://namenode:8020/hbase"}, job.Spec.Template.Spec.Containers[0].Args)
+	}), []client.CreateOption(nil)).Return(nil)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(rs *kvstorev1.HbaseRestore) bool {
+		return rs.Status.Phase == kvstorev1.HbaseSnapshotJobRunning && rs.Status.JobName == "orders-import"
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: snapshotJobPollInterval}, result)
+	assert.Equal(t, []string{REASON_IMPORT_STARTED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseRestoreReconcile_Clone verifies snapshots already in the cluster are cloned into the table of the spec.
+func TestHbaseRestoreReconcile_Clone(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.snapshots["orders-snapshot"] = SnapshotInfo{Name: "orders-snapshot", Table: "orders:items"}
+	k8sMockClient, statusWriter, reconciler, ctx, req := doRestoreTestSetup(newRestore())
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(rs *kvstorev1.HbaseRestore) bool {
+		return rs.Status.Phase == kvstorev1.HbaseSnapshotJobSucceeded && rs.Status.CompletionTime != nil
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Contains(t, fake.tables, "orders:items_restored")
+	assert.Equal(t, []string{REASON_RESTORE_SUCCEEDED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseRestoreReconcile_Rejected verifies restores rejected by HBase are failed rather than retried.
+func TestHbaseRestoreReconcile_Rejected(t *testing.T) {
+	_, server := newFakeHbaseAdminServer(t)
+	rs := newRestore()
+	rs.Spec.Table = ""
+	k8sMockClient, statusWriter, reconciler, ctx, req := doRestoreTestSetup(rs)
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(rs *kvstorev1.HbaseRestore) bool {
+		return rs.Status.Phase == kvstorev1.HbaseSnapshotJobFailed && rs.Status.Message ==
+			"POST /admin/snapshots/orders-snapshot/restore failed with status 404: snapshot not found"
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_RESTORE_FAILED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	path "path"
+	sort "sort"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// snapshotTimeFormat suffix of the names of snapshots taken by a schedule, the time of the run in UTC
+const snapshotTimeFormat = "20060102150405"
+
+// HbaseSnapshotScheduleReconciler snapshots the tables selected by a HbaseSnapshotSchedule through the admin endpoint
+// of the cluster, and prunes the snapshots it took beyond the retention
+type HbaseSnapshotScheduleReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// now returns the current time, time.Now unless set
+	now      func() time.Time
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasesnapshotschedules,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasesnapshotschedules/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetables,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile runs the schedule once it is due and requeues until the next run. Runs missed while the operator was down
+// are not caught up, only the latest one is taken
+func (r *HbaseSnapshotScheduleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasesnapshotschedule", req.NamespacedName)
+
+	s := &kvstorev1.HbaseSnapshotSchedule{}
+	err := r.Client.Get(ctx, req.NamespacedName, s)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseSnapshotSchedule resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseSnapshotSchedule")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	schedule, err := kvstorev1.ParseCronSchedule(s.Spec.Schedule)
+	if err != nil {
+		log.Error(err, "Invalid schedule, snapshots are not taken")
+		r.Recorder.Event(s, corev1.EventTypeWarning, REASON_SCHEDULE_VALIDATE_FAILED, err.Error())
+		return ctrl.Result{}, nil
+	}
+	if s.Spec.Suspend {
+		log.Info("HbaseSnapshotSchedule is suspended")
+		return ctrl.Result{}, nil
+	}
+
+	now := r.clock().UTC()
+	last := s.CreationTimestamp.Time
+	if s.Status.LastScheduleTime != nil {
+		last = s.Status.LastScheduleTime.Time
+	}
+	scheduled := schedule.Next(last.UTC())
+	if scheduled.IsZero() {
+		log.Info("Schedule never runs", "Schedule", s.Spec.Schedule)
+		return ctrl.Result{}, nil
+	}
+	if now.Before(scheduled) {
+		return ctrl.Result{RequeueAfter: scheduled.Sub(now)}, nil
+	}
+	for next := schedule.Next(scheduled); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		scheduled = next
+	}
+
+	snapshots, failure := r.run(ctx, log, s, scheduled, now)
+	s.Status.LastScheduleTime = &metav1.Time{Time: scheduled}
+	s.Status.ObservedGeneration = s.Generation
+	if failure != nil {
+		log.Error(failure, "Failed to snapshot tables")
+		r.Recorder.Event(s, corev1.EventTypeWarning, REASON_SNAPSHOT_FAILED, failure.Error())
+		s.Status.LastFailureTime = &metav1.Time{Time: scheduled}
+		s.Status.LastFailureMessage = failure.Error()
+	} else {
+		r.Recorder.Event(s, corev1.EventTypeNormal, REASON_SNAPSHOTS_TAKEN, "Took snapshots "+strings.Join(snapshots, ", "))
+		s.Status.LastSuccessfulTime = &metav1.Time{Time: scheduled}
+		s.Status.LastSnapshots = snapshots
+	}
+	if err = r.Client.Status().Update(ctx, s); err != nil {
+		log.Error(err, "Failed to update HbaseSnapshotSchedule status")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	next := schedule.Next(now)
+	if next.IsZero() {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: next.Sub(now)}, nil
+}
+
+// run snapshots the selected tables and prunes the snapshots of the schedule beyond the retention. Snapshots of all
+// the tables are attempted even when some fail, failures are returned together
+func (r *HbaseSnapshotScheduleReconciler) run(ctx context.Context, log logr.Logger, s *kvstorev1.HbaseSnapshotSchedule, scheduled time.Time,
+	now time.Time) ([]string, error) {
+	cluster, err := referencedClusterOf(ctx, s.Spec.ClusterRef, s.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		return nil, fmt.Errorf("cluster %s not found", schemaClusterRefOf(s.Spec.ClusterRef, s.Namespace))
+	} else if err != nil {
+		return nil, err
+	}
+	if len(cluster.Configuration.AdminEndpoint) == 0 {
+		return nil, fmt.Errorf("configuration.adminEndpoint of the cluster is not set")
+	}
+	admin := newHbaseAdmin(cluster.Configuration.AdminEndpoint)
+
+	tables, err := r.selectTables(ctx, s, admin)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no table matches the tableSelector")
+	}
+
+	snapshots, failures := []string{}, []string{}
+	for _, table := range tables {
+		name := snapshotNameOf(s, table, scheduled)
+		log.Info("Taking snapshot", "Table", table, "Snapshot", name)
+		if err = admin.Snapshot(ctx, name, table); err != nil {
+			failures = append(failures, table+": "+err.Error())
+			continue
+		}
+		snapshots = append(snapshots, name)
+	}
+
+	pruned, err := pruneSnapshots(ctx, admin, s, now)
+	if err != nil {
+		failures = append(failures, "pruning: "+err.Error())
+	}
+	if len(pruned) > 0 {
+		r.Recorder.Event(s, corev1.EventTypeNormal, REASON_SNAPSHOTS_PRUNED, "Deleted snapshots "+strings.Join(pruned, ", "))
+	}
+	if len(failures) > 0 {
+		return snapshots, fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return snapshots, nil
+}
+
+// selectTables returns the existing tables matching the listed patterns or managed by the selected HbaseTables
+func (r *HbaseSnapshotScheduleReconciler) selectTables(ctx context.Context, s *kvstorev1.HbaseSnapshotSchedule, admin HbaseAdmin) ([]string, error) {
+	selected := map[string]bool{}
+	if s.Spec.TableSelector.HbaseTableSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(s.Spec.TableSelector.HbaseTableSelector)
+		if err != nil {
+			return nil, err
+		}
+		hbaseTables := &kvstorev1.HbaseTableList{}
+		if err = r.Client.List(ctx, hbaseTables, client.InNamespace(s.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, err
+		}
+		for i := range hbaseTables.Items {
+			selected[tableNameOf(&hbaseTables.Items[i])] = true
+		}
+	}
+
+	existing, err := admin.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables := []string{}
+	for _, table := range existing {
+		matches := selected[table]
+		for _, pattern := range s.Spec.TableSelector.Tables {
+			if ok, _ := path.Match(pattern, table); ok {
+				matches = true
+			}
+		}
+		if matches {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// snapshotNameOf returns the name of the snapshot of the table taken by the schedule at the given time
+func snapshotNameOf(s *kvstorev1.HbaseSnapshotSchedule, table string, t time.Time) string {
+	return snapshotPrefixOf(s, table) + t.UTC().Format(snapshotTimeFormat)
+}
+
+// snapshotPrefixOf is the prefix of the snapshots of the table taken by the schedule. The kubernetes namespace keeps
+// schedules of the same name apart, its dot can not be part of it. Snapshot names can not contain the colon between
+// namespace and table
+func snapshotPrefixOf(s *kvstorev1.HbaseSnapshotSchedule, table string) string {
+	return s.Namespace + "." + s.Name + "-" + strings.ReplaceAll(table, ":", "_") + "-"
+}
+
+// pruneSnapshots deletes the snapshots taken by the schedule beyond the maximum count of their table, or older than
+// the maximum age. Snapshots taken otherwise are left alone
+func pruneSnapshots(ctx context.Context, admin HbaseAdmin, s *kvstorev1.HbaseSnapshotSchedule, now time.Time) ([]string, error) {
+	existing, err := admin.ListSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byTable := map[string][]SnapshotInfo{}
+	for _, snapshot := range existing {
+		suffix, ok := strings.CutPrefix(snapshot.Name, snapshotPrefixOf(s, snapshot.Table))
+		if !ok {
+			continue
+		}
+		if _, err := time.Parse(snapshotTimeFormat, suffix); err != nil {
+			continue
+		}
+		byTable[snapshot.Table] = append(byTable[snapshot.Table], snapshot)
+	}
+
+	retention := s.Spec.Retention
+	pruned := []string{}
+	for _, table := range sortedKeys(byTable) {
+		snapshots := byTable[table]
+		sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreationTime > snapshots[j].CreationTime })
+		for i, snapshot := range snapshots {
+			expired := retention.MaxAge != nil && now.Sub(time.UnixMilli(snapshot.CreationTime)) > retention.MaxAge.Duration
+			if !expired && (retention.MaxCount == nil || i < int(*retention.MaxCount)) {
+				continue
+			}
+			if err = admin.DeleteSnapshot(ctx, snapshot.Name); err != nil {
+				return pruned, err
+			}
+			pruned = append(pruned, snapshot.Name)
+		}
+	}
+	return pruned, nil
+}
+
+func (r *HbaseSnapshotScheduleReconciler) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseSnapshotScheduleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseSnapshotSchedule{}).
+		Complete(r)
+}
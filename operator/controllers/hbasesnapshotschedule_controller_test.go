@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func doSnapshotScheduleTestSetup(s *kvstorev1.HbaseSnapshotSchedule, now time.Time) (*K8sMockClient, *K8sMockStatusWriter, *HbaseSnapshotScheduleReconciler,
+	context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: s.Name, Namespace: s.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseSnapshotSchedule{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseSnapshotSchedule) = *s
+		}).
+		Return(nil)
+	reconciler := &HbaseSnapshotScheduleReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100), now: func() time.Time { return now }}
+	return k8sMockClient, statusWriter, reconciler, ctx, req
+}
+
+func newSnapshotSchedule(lastSchedule time.Time) *kvstorev1.HbaseSnapshotSchedule {
+	count := int32(2)
+	return &kvstorev1.HbaseSnapshotSchedule{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: testNamespace, Generation: 1},
+		Spec: kvstorev1.HbaseSnapshotScheduleSpec{
+			ClusterRef:    kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			Schedule:      "0 2 * * *",
+			TableSelector: kvstorev1.HbaseSnapshotTableSelector{Tables: []string{"orders:*"}},
+			Retention:     kvstorev1.HbaseSnapshotRetention{MaxCount: &count},
+		},
+		Status: kvstorev1.HbaseSnapshotScheduleStatus{LastScheduleTime: &metav1.Time{Time: lastSchedule}},
+	}
+}
+
+// TestHbaseSnapshotScheduleReconcile_NotDue verifies nothing is done before the next run, which is requeued.
+func TestHbaseSnapshotScheduleReconcile_NotDue(t *testing.T) {
+	now := time.Date(2026, 10, 21, 1, 30, 0, 0, time.UTC)
+	k8sMockClient, _, reconciler, ctx, req := doSnapshotScheduleTestSetup(newSnapshotSchedule(now.Add(-time.Hour*23)), now)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Minute * 30}, result)
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
+
+// TestHbaseSnapshotScheduleReconcile_Run verifies the selected tables are snapshotted, only the latest missed run is
+// taken, and snapshots of the schedule beyond the retention are pruned, not the ones of schedules of other namespaces.
+func TestHbaseSnapshotScheduleReconcile_Run(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.tables = map[string][]string{"orders:items": nil, "orders:carts": nil, "default:users": nil}
+	now := time.Now().UTC()
+	old := now.Add(-time.Hour * 72).UnixMilli()
+	fake.snapshots[testNamespace+".nightly-orders_items-20260101020000"] = SnapshotInfo{Name: testNamespace + ".nightly-orders_items-20260101020000", Table: "orders:items", CreationTime: old}
+	fake.snapshots[testNamespace+".nightly-orders_items-20260102020000"] = SnapshotInfo{Name: testNamespace + ".nightly-orders_items-20260102020000", Table: "orders:items", CreationTime: old + 1}
+	fake.snapshots["manual-orders_items"] = SnapshotInfo{Name: "manual-orders_items", Table: "orders:items", CreationTime: old - 1}
+	// a schedule of the same name in another namespace
+	fake.snapshots["other.nightly-orders_items-20260101020000"] = SnapshotInfo{Name: "other.nightly-orders_items-20260101020000", Table: "orders:items", CreationTime: old}
+
+	s := newSnapshotSchedule(now.Add(-time.Hour * 72))
+	k8sMockClient, statusWriter, reconciler, ctx, req := doSnapshotScheduleTestSetup(s, now)
+	mockSchemaCluster(k8sMockClient, ctx, "", server.URL)
+	k8sMockClient.On("Status").Return(statusWriter)
+	var updated *kvstorev1.HbaseSnapshotSchedule
+	statusWriter.On("Update", ctx, mock.Anything).
+		Run(func(args mock.Arguments) {
+			updated = args.Get(1).(*kvstorev1.HbaseSnapshotSchedule)
+		}).
+		Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+
+	schedule, _ := kvstorev1.ParseCronSchedule("0 2 * * *")
+	next := schedule.Next(now)
+	scheduled := next.AddDate(0, 0, -1)
+	assert.Equal(t, ctrl.Result{RequeueAfter: next.Sub(now)}, result)
+	taken := []string{testNamespace + ".nightly-orders_carts-" + scheduled.Format(snapshotTimeFormat), testNamespace + ".nightly-orders_items-" + scheduled.Format(snapshotTimeFormat)}
+	assert.Equal(t, taken, updated.Status.LastSnapshots)
+	assert.Equal(t, scheduled, updated.Status.LastScheduleTime.Time)
+	assert.Equal(t, scheduled, updated.Status.LastSuccessfulTime.Time)
+	assert.Nil(t, updated.Status.LastFailureTime)
+	assert.ElementsMatch(t, append(taken, testNamespace+".nightly-orders_items-20260102020000", "manual-orders_items",
+		"other.nightly-orders_items-20260101020000"), sortedKeys(fake.snapshots))
+	assert.ElementsMatch(t, []string{REASON_SNAPSHOTS_TAKEN, REASON_SNAPSHOTS_PRUNED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseSnapshotScheduleReconcile_Failure verifies failures are recorded in status and the next run awaited.
+func TestHbaseSnapshotScheduleReconcile_Failure(t *testing.T) {
+	now := time.Date(2026, 10, 21, 2, 0, 10, 0, time.UTC)
+	s := newSnapshotSchedule(now.Add(-time.Hour * 24))
+	k8sMockClient, statusWriter, reconciler, ctx, req := doSnapshotScheduleTestSetup(s, now)
+	mockSchemaCluster(k8sMockClient, ctx, "", "")
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(s *kvstorev1.HbaseSnapshotSchedule) bool {
+		return s.Status.LastFailureTime != nil && s.Status.LastFailureMessage == "configuration.adminEndpoint of the cluster is not set" &&
+			s.Status.LastSuccessfulTime == nil
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Hour*24 - time.Second*10}, result)
+	assert.Equal(t, []string{REASON_SNAPSHOT_FAILED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseSnapshotScheduleReconcile_Suspended verifies suspended schedules take no snapshots.
+func TestHbaseSnapshotScheduleReconcile_Suspended(t *testing.T) {
+	now := time.Date(2026, 10, 21, 2, 0, 10, 0, time.UTC)
+	s := newSnapshotSchedule(now.Add(-time.Hour * 24))
+	s.Spec.Suspend = true
+	k8sMockClient, _, reconciler, ctx, req := doSnapshotScheduleTestSetup(s, now)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertExpectations(t)
+}
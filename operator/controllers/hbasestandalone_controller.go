@@ -20,8 +20,10 @@ import (
 	context "context"
 	time "time"
 
+	corev1 "k8s.io/api/core/v1"
 	errors "k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -31,7 +33,8 @@ import (
 // HbaseStandaloneReconciler reconciles a HbaseStandalone object.
 type HbaseStandaloneReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch;create;update;patch;delete
@@ -59,52 +62,170 @@ func (r *HbaseStandaloneReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			log.Info("HbaseStandalone resource not found. Ignoring since object must be deleted")
+			deleteResourceMetrics("HbaseStandalone/"+req.Name, req.Namespace)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		log.Error(err, "Failed to get HbaseStandalone")
 		return ctrl.Result{RequeueAfter: time.Second * 5}, err
 	}
+	kind := "HbaseStandalone/" + hbasestandalone.Name
+	defer observeReconcilePhase(kind, hbasestandalone.Namespace, "", "reconcile", time.Now())
 
-	svc := buildService(hbasestandalone.Name, hbasestandalone.Name, hbasestandalone.Namespace, hbasestandalone.Spec.ServiceLabels, hbasestandalone.Spec.ServiceSelectorLabels, []kvstorev1.HbaseClusterDeployment{hbasestandalone.Spec.Standalone}, true)
+	// CRs still using the deprecated label are migrated once, the update triggers another reconcile
+	migrated, err := migrateConfigUpdatePolicy(ctx, log, hbasestandalone, &hbasestandalone.Spec.Configuration, hbasestandalone.Spec.ServiceLabels, r.Recorder, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if migrated {
+		return ctrl.Result{}, nil
+	}
+
+	// overrides targeting other namespaces are ignored, and reported in status
+	if err = reportTenantConfigValidity(ctx, log, hbasestandalone, &hbasestandalone.Status.Conditions, hbasestandalone.Spec.Configuration,
+		[]string{hbasestandalone.Namespace}, r.Recorder, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	configuration, err := resolveTenantConfig(ctx, log, r.Client, hbasestandalone.Spec.Configuration, []string{hbasestandalone.Namespace})
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	hbaseCfg := buildConfigMap(configuration.HbaseConfigName, hbasestandalone.Name, hbasestandalone.Namespace, configuration.HbaseConfig, configuration.HbaseTenantConfig, log)
+	ctrl.SetControllerReference(hbasestandalone, hbaseCfg, r.Scheme)
+	cfg := buildConfigMap(configuration.HadoopConfigName, hbasestandalone.Name, hbasestandalone.Namespace, configuration.HadoopConfig, configuration.HadoopTenantConfig, log)
+	ctrl.SetControllerReference(hbasestandalone, cfg, r.Scheme)
+	overrides := appendTenantConfigOverrideStatus(nil, hbaseCfg, configuration.HbaseTenantConfig)
+	overrides = appendTenantConfigOverrideStatus(overrides, cfg, configuration.HadoopTenantConfig)
+	cfgs := []*corev1.ConfigMap{hbaseCfg, cfg}
+	for _, c := range buildComponentConfigMaps(hbasestandalone.Name, hbasestandalone.Namespace, configuration, hbasestandalone.Spec.Standalone, log) {
+		ctrl.SetControllerReference(hbasestandalone, c, r.Scheme)
+		cfgs = append(cfgs, c)
+	}
+
+	// ConfigMaps are reconciled without restarting pods, unless the update policy says otherwise
+	policy := getConfigUpdatePolicy(hbasestandalone.Spec.Configuration, kvstorev1.ConfigUpdatePolicyConfigOnly)
+	if !isConfigReconciled(policy) {
+		log.Info("Configmap recon disabled by update policy", "UpdatePolicy", policy)
+		cfgs = nil
+		overrides = nil
+	}
+
+	restartEnabled := isRestartOnConfigChange(policy)
+	hotReloadEnabled := isHotReloadEnabled(policy, hbasestandalone.Spec.Configuration)
+	cfgName := hbaseConfigNameOf(hbasestandalone.Spec.Configuration, hbasestandalone.Spec.Standalone)
+	restartTargets := map[string][]string{hbasestandalone.Namespace + "/" + cfgName: {hbasestandalone.Spec.Standalone.Name}}
+	standalones := []kvstorev1.HbaseClusterDeployment{hbasestandalone.Spec.Standalone}
+
+	// In dry run, only report the config diff and the rollout plan without applying anything
+	if isDryRun(hbasestandalone) {
+		log.Info("Dry run enabled, computing config diff without applying changes")
+		result, err := validateClusterConfiguration(ctx, log, hbasestandalone.Namespace, hbasestandalone.Spec.Configuration, standalones, r.Client)
+		recordConfigValidation(kind, hbasestandalone.Namespace, err)
+		if err != nil {
+			recordWarning(r.Recorder, hbasestandalone, REASON_CONFIG_VALIDATE_FAILED, err)
+			log.Error(err, "Failed to validate configuration")
+			return result, err
+		}
+		changes := []configChange{}
+		for _, c := range cfgs {
+			cfgChanges, _, err := computeConfigMapDiff(ctx, log, c, r.Client)
+			if err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
+			changes = append(changes, cfgChanges...)
+		}
+		plan := buildRolloutPlan(changes, restartTargets, restartEnabled, hotReloadEnabled)
+		reportConfigChanges(ctx, log, hbasestandalone, &hbasestandalone.Status.LastConfigChange, changes, plan, true, r.Recorder, r.Client)
+		return ctrl.Result{}, nil
+	}
+
+	svc := buildService(hbasestandalone.Name, hbasestandalone.Name, hbasestandalone.Namespace, hbasestandalone.Spec.ServiceLabels, hbasestandalone.Spec.ServiceSelectorLabels, standalones, true)
+	applyServiceSpec(svc, hbasestandalone.Spec.Service)
+	addMetricsPort(svc, hbasestandalone.Name, hbasestandalone.Spec.Monitoring, standalones)
 	ctrl.SetControllerReference(hbasestandalone, svc, r.Scheme)
 
-	result, err := reconcileService(ctx, log, hbasestandalone.Namespace, svc, r.Client)
+	result, err := reconcileService(ctx, log, hbasestandalone.Namespace, svc, hbasestandalone, r.Recorder, r.Client)
 	if (ctrl.Result{}) != result || err != nil {
 		return result, err
 	}
 
-	result, err = validateConfiguration(ctx, log, hbasestandalone.Namespace, hbasestandalone.Spec.Configuration, r.Client)
+	validateStart := time.Now()
+	result, err = validateClusterConfiguration(ctx, log, hbasestandalone.Namespace, hbasestandalone.Spec.Configuration, standalones, r.Client)
+	observeReconcilePhase(kind, hbasestandalone.Namespace, "", "validation", validateStart)
+	recordConfigValidation(kind, hbasestandalone.Namespace, err)
 	if err != nil {
-		publishEvent(ctx, log, hbasestandalone.Namespace, "ConfigValidateFailed", err.Error(), "Warning", "ConfigMap", r.Client)
+		recordWarning(r.Recorder, hbasestandalone, REASON_CONFIG_VALIDATE_FAILED, err)
 		log.Error(err, "Failed to validate configuration")
 		return result, err
 	}
 
-	cfg := buildConfigMap(hbasestandalone.Spec.Configuration.HbaseConfigName, hbasestandalone.Name, hbasestandalone.Namespace, hbasestandalone.Spec.Configuration.HbaseConfig, hbasestandalone.Spec.Configuration.HbaseTenantConfig, log)
-	ctrl.SetControllerReference(hbasestandalone, cfg, r.Scheme)
-	result, err = reconcileConfigMap(ctx, log, hbasestandalone.Namespace, cfg, r.Client)
+	configStart := time.Now()
+	// changes of the ConfigMaps are reported together, so that status is written once per reconcile
+	configChanges := []configChange{}
+	for _, c := range cfgs {
+		changes, existing, cfgErr := computeConfigMapDiff(ctx, log, c, r.Client)
+		if cfgErr != nil {
+			result, err = ctrl.Result{RequeueAfter: time.Second * 5}, cfgErr
+			break
+		}
+		if c.Name == cfgName {
+			prepareHotReload(existing, c, changes, hotReloadEnabled)
+		}
+		result, err = reconcileConfigMap(ctx, log, hbasestandalone.Namespace, c, hbasestandalone, r.Recorder, r.Client)
+		if err != nil {
+			break
+		}
+		configChanges = append(configChanges, changes...)
+		if (ctrl.Result{}) != result {
+			break
+		}
+	}
+	plan := buildRolloutPlan(configChanges, restartTargets, restartEnabled, hotReloadEnabled)
+	reportConfigChanges(ctx, log, hbasestandalone, &hbasestandalone.Status.LastConfigChange, configChanges, plan, false, r.Recorder, r.Client)
 	if (ctrl.Result{}) != result || err != nil {
 		return result, err
 	}
+	observeReconcilePhase(kind, hbasestandalone.Namespace, "", "configmaps", configStart)
+	if err = reportTenantConfigOverrides(ctx, log, hbasestandalone, &hbasestandalone.Status.TenantConfigOverrides, overrides, r.Client); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
 
-	cfg = buildConfigMap(hbasestandalone.Spec.Configuration.HadoopConfigName, hbasestandalone.Name, hbasestandalone.Namespace, hbasestandalone.Spec.Configuration.HadoopConfig, hbasestandalone.Spec.Configuration.HadoopTenantConfig, log)
-	ctrl.SetControllerReference(hbasestandalone, cfg, r.Scheme)
-	result, err = reconcileConfigMap(ctx, log, hbasestandalone.Namespace, cfg, r.Client)
-	if (ctrl.Result{}) != result || err != nil {
-		return result, err
+	if restartEnabled {
+		// Changes which can be reloaded online are applied once kubelet has synced the configmap, without restarting pods
+		adminEndpoint := ""
+		if hotReloadEnabled {
+			adminEndpoint = hbasestandalone.Spec.Configuration.AdminEndpoint
+		}
+		result, err = reconcileHotReload(ctx, log, hbasestandalone.Namespace, cfgName,
+			adminEndpoint, hbasestandalone, r.Recorder, r.Client)
+		if (ctrl.Result{}) != result || err != nil {
+			return result, err
+		}
 	}
+	configVersion := getConfigVersion(log, r.Client, ctx, policy, cfgName,
+		hbasestandalone.Spec.Standalone.Name, hbasestandalone.Namespace)
 
+	// Exporters are scraped through a ServiceMonitor or PodMonitor, or through pod annotations without prometheus-operator
+	scrapeAnnotations, err := reconcileMonitoring(ctx, log, hbasestandalone, hbasestandalone.Spec.Monitoring, standalones, r.Recorder, r.Scheme, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	statefulSetStart := time.Now()
 	newSS, err := buildStatefulSet(hbasestandalone.Name, hbasestandalone.Namespace, hbasestandalone.Spec.BaseImage,
-		false, hbasestandalone.Spec.Configuration, cfg.ResourceVersion, hbasestandalone.Spec.FSGroup,
+		false, hbasestandalone.Spec.Configuration, configVersion, hbasestandalone.Spec.FSGroup,
 		hbasestandalone.Spec.Standalone, log, true)
+	if err == nil {
+		err = injectJmxExporter(newSS, hbasestandalone.Name, hbasestandalone.Spec.Monitoring, hbasestandalone.Spec.Standalone, scrapeAnnotations)
+	}
 	if err != nil {
-		publishEvent(ctx, log, hbasestandalone.Namespace, "StatefulSetBuildFailed", err.Error(), "Warning", "StatefulSet/"+hbasestandalone.Spec.Standalone.Name, r.Client)
+		recordWarning(r.Recorder, hbasestandalone, REASON_STATEFULSET_BUILD_FAILED, err)
 		log.Error(err, "Failed to build StatefulSet", "StatefulSet.Name", hbasestandalone.Spec.Standalone.Name)
 		return ctrl.Result{}, err
 	}
 	ctrl.SetControllerReference(hbasestandalone, newSS, r.Scheme)
-	result, err = reconcileStatefulSet(ctx, log, hbasestandalone.Namespace, newSS, hbasestandalone.Spec.Standalone, r.Client)
+	result, err = reconcileStatefulSet(ctx, log, hbasestandalone.Namespace, newSS, hbasestandalone.Spec.Standalone, hbasestandalone, r.Recorder, r.Client)
+	observeReconcilePhase(kind, hbasestandalone.Namespace, hbasestandalone.Spec.Standalone.Name, "statefulset", statefulSetStart)
 	if (ctrl.Result{}) != result || err != nil {
 		return result, err
 	}
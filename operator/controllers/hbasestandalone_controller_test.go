@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -33,8 +34,9 @@ func getMockClientAndStandaloneReconciler() (*K8sMockClient, *HbaseStandaloneRec
 	_ = appsv1.AddToScheme(scheme)
 
 	reconciler := &HbaseStandaloneReconciler{
-		Client: mockClient,
-		Scheme: scheme,
+		Client:   mockClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
 	}
 	return mockClient, reconciler
 }
@@ -244,12 +246,10 @@ func TestHbaseStandaloneReconciler_InvalidConfig_EventPublish(t *testing.T) {
 	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: mockSvc.Name, Namespace: standalone.Namespace}, &corev1.Service{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
 	k8sMockClient.On("Create", ctx, mockSvc, []client.CreateOption(nil)).Return(nil)
 
-	k8sMockClient.On("Get", ctx, types.NamespacedName{Namespace: standalone.Namespace, Name: "ConfigValidateFailed"}, &corev1.Event{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-	k8sMockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(nil)
-
 	result, err := reconciler.Reconcile(ctx, req)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_SERVICE_CREATED, REASON_CONFIG_VALIDATE_FAILED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
 
 	k8sMockClient.AssertExpectations(t)
 }
@@ -271,11 +271,10 @@ func TestHbaseStandaloneReconciler_ServiceCreateError(t *testing.T) {
 	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: standalone.Name, Namespace: standalone.Namespace}, &corev1.Service{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
 	k8sMockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(assert.AnError)
 
-	k8sMockClient.On("Get", ctx, mock.Anything, &corev1.Event{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-
 	result, err := reconciler.Reconcile(ctx, req)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_SERVICE_CREATE_FAILED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
 }
 
 // TestHbaseStandaloneReconciler_NoPDB verifies that reconciliation completes successfully when PodDisruptionBudget is nil,
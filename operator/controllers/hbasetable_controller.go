@@ -0,0 +1,179 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// HbaseTableReconciler creates HBase tables and keeps their schema in sync through the REST gateway of the cluster.
+// Tables are left in HBase when the HbaseTable object is deleted
+type HbaseTableReconciler struct {
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetables,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetables/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasestandalones,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
+
+// Reconcile creates the table when it does not exist, pre-split when split keys are set. Column families and
+// attributes of the spec which differ are applied once altering is allowed, as the table is disabled meanwhile, and
+// column families which are not in the spec are only dropped when destructive changes are allowed as well. Changes
+// which are not applied are reported as drift
+func (r *HbaseTableReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasetable", req.NamespacedName)
+
+	t := &kvstorev1.HbaseTable{}
+	err := r.Client.Get(ctx, req.NamespacedName, t)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseTable resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseTable")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	desired := desiredTableSchema(t)
+
+	cluster, err := referencedClusterOf(ctx, t.Spec.ClusterRef, t.Namespace, r.Client)
+	if errors.IsNotFound(err) {
+		ref := schemaClusterRefOf(t.Spec.ClusterRef, t.Namespace)
+		return r.updateStatus(ctx, log, t, schemaCondition(false, t.Generation, "ClusterNotFound", "Cluster %s not found", ref),
+			t.Status.Drift, ctrl.Result{RequeueAfter: time.Second * 30}, nil)
+	} else if err != nil {
+		log.Error(err, "Failed to get cluster of HbaseTable")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if len(cluster.Configuration.RestEndpoint) == 0 {
+		return r.updateStatus(ctx, log, t, schemaCondition(false, t.Generation, "RestEndpointNotSet", "configuration.restEndpoint of the cluster is not set"),
+			t.Status.Drift, ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+	}
+	rest := newHbaseRest(cluster.Configuration.RestEndpoint)
+
+	current, err := rest.GetTableSchema(ctx, desired.Name)
+	if err != nil {
+		return r.failed(ctx, log, t, err)
+	}
+	if current == nil {
+		log.Info("Creating HBase table", "Table", desired.Name)
+		if len(t.Spec.SplitKeys) > 0 {
+			// the REST gateway can not create pre-split tables
+			if len(cluster.Configuration.AdminEndpoint) == 0 {
+				return r.updateStatus(ctx, log, t, schemaCondition(false, t.Generation, "AdminEndpointNotSet",
+					"configuration.adminEndpoint of the cluster is required to create tables with splitKeys"), nil, ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+			}
+			err = newHbaseAdmin(cluster.Configuration.AdminEndpoint).CreateTable(ctx, desired, t.Spec.SplitKeys)
+		} else {
+			err = rest.UpdateTableSchema(ctx, desired)
+		}
+		if err != nil {
+			return r.failed(ctx, log, t, err)
+		}
+		r.Recorder.Event(t, corev1.EventTypeNormal, REASON_TABLE_CREATED, "Created HBase table "+desired.Name)
+		return r.updateStatus(ctx, log, t, schemaCondition(true, t.Generation, "Synced", "HBase table %s is in sync", desired.Name),
+			nil, ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+	}
+
+	diff := diffTableSchema(desired, *current)
+	drift := []string{}
+	dropFamilies := t.Spec.AllowAlter && t.Spec.AllowDestructiveChanges
+	if !t.Spec.AllowAlter {
+		// the REST gateway disables the table to alter it, which is only done once allowed
+		for _, k := range diff.attributes {
+			drift = append(drift, "attribute "+k+" differs from the spec")
+		}
+		for _, f := range diff.families {
+			drift = append(drift, "column family "+f+" differs from the spec")
+		}
+	} else if len(diff.attributes) > 0 || dropFamilies && len(diff.extraFamilies) > 0 {
+		// table attributes and dropped column families need the whole schema to be replaced
+		log.Info("Replacing schema of HBase table", "Table", desired.Name, "Attributes", diff.attributes, "Dropped", dropFamilies)
+		if err = rest.ReplaceTableSchema(ctx, mergeTableSchema(desired, *current, dropFamilies)); err != nil {
+			return r.failed(ctx, log, t, err)
+		}
+		r.Recorder.Event(t, corev1.EventTypeNormal, REASON_TABLE_ALTERED, "Altered schema of HBase table "+desired.Name)
+	} else if len(diff.families) > 0 {
+		log.Info("Updating column families of HBase table", "Table", desired.Name, "Families", diff.families)
+		if err = rest.UpdateTableSchema(ctx, mergeTableSchema(desired, *current, false)); err != nil {
+			return r.failed(ctx, log, t, err)
+		}
+		r.Recorder.Event(t, corev1.EventTypeNormal, REASON_TABLE_ALTERED, "Altered column families "+strings.Join(diff.families, ", ")+" of HBase table "+desired.Name)
+	}
+
+	if !dropFamilies {
+		for _, f := range diff.extraFamilies {
+			drift = append(drift, "column family "+f+" is not in the spec")
+		}
+	}
+	if len(drift) == 0 {
+		return r.updateStatus(ctx, log, t, schemaCondition(true, t.Generation, "Synced", "HBase table %s is in sync", desired.Name),
+			nil, ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+	}
+	if !equality.Semantic.DeepEqual(drift, t.Status.Drift) {
+		r.Recorder.Event(t, corev1.EventTypeWarning, REASON_SCHEMA_DRIFT_DETECTED, "HBase table "+desired.Name+" drifted from the spec: "+strings.Join(drift, ", "))
+	}
+	return r.updateStatus(ctx, log, t, schemaCondition(true, t.Generation, "DriftDetected",
+		"HBase table %s has changes which are not applied without spec.allowAlter or spec.allowDestructiveChanges", desired.Name), drift, ctrl.Result{RequeueAfter: schemaSyncInterval}, nil)
+}
+
+// updateStatus sets the Ready condition and drift, when they changed, and returns the given result
+func (r *HbaseTableReconciler) updateStatus(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTable, condition metav1.Condition, drift []string,
+	result ctrl.Result, err error) (ctrl.Result, error) {
+	changed := t.Status.ObservedGeneration != t.Generation || !(len(drift) == 0 && len(t.Status.Drift) == 0 || equality.Semantic.DeepEqual(drift, t.Status.Drift))
+	t.Status.ObservedGeneration = t.Generation
+	t.Status.Drift = drift
+	if meta.SetStatusCondition(&t.Status.Conditions, condition) || changed {
+		if updateErr := r.Client.Status().Update(ctx, t); updateErr != nil {
+			log.Error(updateErr, "Failed to update HbaseTable status")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, updateErr
+		}
+	}
+	return result, err
+}
+
+func (r *HbaseTableReconciler) failed(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTable, err error) (ctrl.Result, error) {
+	r.Recorder.Event(t, corev1.EventTypeWarning, REASON_SCHEMA_UPDATE_FAILED, err.Error())
+	log.Error(err, "Failed to sync HBase table through the REST gateway")
+	return r.updateStatus(ctx, log, t, schemaCondition(false, t.Generation, "RestGatewayError", "%s", err.Error()),
+		t.Status.Drift, ctrl.Result{RequeueAfter: time.Second * 5}, err)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseTableReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseTable{}).
+		Complete(r)
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func doTableTestSetup(table *kvstorev1.HbaseTable) (*K8sMockClient, *K8sMockStatusWriter, *HbaseTableReconciler, context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: table.Name, Namespace: table.Namespace}}
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseTable{}).
+		Run(func(args mock.Arguments) {
+			*args.Get(2).(*kvstorev1.HbaseTable) = *table
+		}).
+		Return(nil)
+	k8sMockClient.On("Status").Return(statusWriter)
+	return k8sMockClient, statusWriter, &HbaseTableReconciler{Client: k8sMockClient, Recorder: record.NewFakeRecorder(100)}, ctx, req
+}
+
+func newHbaseTable() *kvstorev1.HbaseTable {
+	versions := int32(1)
+	return &kvstorev1.HbaseTable{
+		ObjectMeta: metav1.ObjectMeta{Name: "items", Namespace: testNamespace, Generation: 1},
+		Spec: kvstorev1.HbaseTableSpec{
+			ClusterRef: kvstorev1.HbaseSchemaClusterReference{Name: "cluster"},
+			Namespace:  "orders",
+			ColumnFamilies: []kvstorev1.HbaseColumnFamily{
+				{Name: "d", Compression: "SNAPPY", MaxVersions: &versions},
+			},
+		},
+	}
+}
+
+// TestDesiredTableSchema verifies only the settings declared in the spec end up in the schema.
+func TestDesiredTableSchema(t *testing.T) {
+	table := newHbaseTable()
+	ttl := int32(86400)
+	table.Spec.Attributes = map[string]string{"DURABILITY": "ASYNC_WAL"}
+	table.Spec.ColumnFamilies = append(table.Spec.ColumnFamilies, kvstorev1.HbaseColumnFamily{Name: "h", TTL: &ttl, BloomFilter: "ROW",
+		Attributes: map[string]string{"BLOCKSIZE": "65536"}})
+
+	assert.Equal(t, TableSchema{Name: "orders:items", Attributes: map[string]string{"DURABILITY": "ASYNC_WAL"}, ColumnFamilies: []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "SNAPPY", "VERSIONS": "1"}},
+		{Name: "h", Attributes: map[string]string{"TTL": "86400", "BLOOMFILTER": "ROW", "BLOCKSIZE": "65536"}},
+	}}, desiredTableSchema(table))
+}
+
+// TestDiffTableSchema verifies attributes left to HBase are ignored and extra column families reported.
+func TestDiffTableSchema(t *testing.T) {
+	desired := TableSchema{Name: "t", Attributes: map[string]string{"DURABILITY": "ASYNC_WAL"}, ColumnFamilies: []ColumnSchema{
+		{Name: "a", Attributes: map[string]string{"VERSIONS": "1"}},
+		{Name: "b", Attributes: map[string]string{"VERSIONS": "1"}},
+		{Name: "c", Attributes: map[string]string{}},
+	}}
+	current := TableSchema{Name: "t", Attributes: map[string]string{"DURABILITY": "ASYNC_WAL", "IS_META": "false"}, ColumnFamilies: []ColumnSchema{
+		{Name: "a", Attributes: map[string]string{"VERSIONS": "1", "TTL": "FOREVER"}},
+		{Name: "b", Attributes: map[string]string{"VERSIONS": "3"}},
+		{Name: "z", Attributes: map[string]string{}},
+	}}
+	assert.Equal(t, tableSchemaDiff{families: []string{"b", "c"}, extraFamilies: []string{"z"}}, diffTableSchema(desired, current))
+
+	current.Attributes["DURABILITY"] = "SYNC_WAL"
+	assert.Equal(t, []string{"DURABILITY"}, diffTableSchema(desired, current).attributes)
+}
+
+// TestHbaseTableReconcile_CreatedPreSplit verifies tables with split keys are created through the admin endpoint.
+func TestHbaseTableReconcile_CreatedPreSplit(t *testing.T) {
+	_, restServer := newFakeHbaseRestServer(t)
+	admin, adminServer := newFakeHbaseAdminServer(t)
+	table := newHbaseTable()
+	table.Spec.SplitKeys = []string{"4", "8"}
+	k8sMockClient, statusWriter, reconciler, ctx, req := doTableTestSetup(table)
+	mockSchemaCluster(k8sMockClient, ctx, restServer.URL, adminServer.URL)
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(t *kvstorev1.HbaseTable) bool {
+		return meta.IsStatusConditionTrue(t.Status.Conditions, CONDITION_READY)
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: schemaSyncInterval}, result)
+	assert.Equal(t, map[string][]string{"orders:items": {"4", "8"}}, admin.tables)
+	assert.Equal(t, []string{REASON_TABLE_CREATED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseTableReconcile_AlterNotAllowed verifies the existing table is left alone without allowAlter, differences
+// being reported.
+func TestHbaseTableReconcile_AlterNotAllowed(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	fake.tables["orders:items"] = &TableSchema{Name: "orders:items", Attributes: map[string]string{}, ColumnFamilies: []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "NONE", "VERSIONS": "1"}},
+		{Name: "old", Attributes: map[string]string{}},
+	}}
+	table := newHbaseTable()
+	table.Spec.Attributes = map[string]string{"DURABILITY": "ASYNC_WAL"}
+	table.Spec.AllowDestructiveChanges = true
+	k8sMockClient, statusWriter, reconciler, ctx, req := doTableTestSetup(table)
+	mockSchemaCluster(k8sMockClient, ctx, server.URL, "")
+	drift := []string{"attribute DURABILITY differs from the spec", "column family d differs from the spec", "column family old is not in the spec"}
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(t *kvstorev1.HbaseTable) bool {
+		c := meta.FindStatusCondition(t.Status.Conditions, CONDITION_READY)
+		return c != nil && c.Reason == "DriftDetected" && assert.ObjectsAreEqual(drift, t.Status.Drift)
+	})).Return(nil)
+
+	_, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET /orders:items/schema"}, fake.requests)
+	assert.Equal(t, "NONE", fake.tables["orders:items"].ColumnFamilies[0].Attributes["COMPRESSION"])
+	assert.Equal(t, []string{REASON_SCHEMA_DRIFT_DETECTED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseTableReconcile_DriftReported verifies drifted column families are updated while extra ones are only reported.
+func TestHbaseTableReconcile_DriftReported(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	fake.tables["orders:items"] = &TableSchema{Name: "orders:items", Attributes: map[string]string{}, ColumnFamilies: []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "NONE", "VERSIONS": "1", "TTL": "FOREVER"}},
+		{Name: "old", Attributes: map[string]string{}},
+	}}
+	table := newHbaseTable()
+	table.Spec.AllowAlter = true
+	k8sMockClient, statusWriter, reconciler, ctx, req := doTableTestSetup(table)
+	mockSchemaCluster(k8sMockClient, ctx, server.URL, "")
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(t *kvstorev1.HbaseTable) bool {
+		c := meta.FindStatusCondition(t.Status.Conditions, CONDITION_READY)
+		return c != nil && c.Reason == "DriftDetected" && len(t.Status.Drift) == 1
+	})).Return(nil)
+
+	_, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "SNAPPY", "VERSIONS": "1", "TTL": "FOREVER"}},
+		{Name: "old", Attributes: map[string]string{}},
+	}, fake.tables["orders:items"].ColumnFamilies)
+	assert.Contains(t, fake.requests, "POST /orders:items/schema")
+	assert.ElementsMatch(t, []string{REASON_TABLE_ALTERED, REASON_SCHEMA_DRIFT_DETECTED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseTableReconcile_DestructiveChanges verifies column families missing from the spec are dropped once allowed.
+func TestHbaseTableReconcile_DestructiveChanges(t *testing.T) {
+	fake, server := newFakeHbaseRestServer(t)
+	fake.tables["orders:items"] = &TableSchema{Name: "orders:items", Attributes: map[string]string{"IS_META": "false"}, ColumnFamilies: []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "SNAPPY", "VERSIONS": "1"}},
+		{Name: "old", Attributes: map[string]string{}},
+	}}
+	table := newHbaseTable()
+	table.Spec.AllowAlter = true
+	table.Spec.AllowDestructiveChanges = true
+	table.Status.Drift = []string{"column family old is not in the spec"}
+	k8sMockClient, statusWriter, reconciler, ctx, req := doTableTestSetup(table)
+	mockSchemaCluster(k8sMockClient, ctx, server.URL, "")
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(t *kvstorev1.HbaseTable) bool {
+		return meta.IsStatusConditionTrue(t.Status.Conditions, CONDITION_READY) && len(t.Status.Drift) == 0
+	})).Return(nil)
+
+	_, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, &TableSchema{Name: "orders:items", Attributes: map[string]string{"IS_META": "false"}, ColumnFamilies: []ColumnSchema{
+		{Name: "d", Attributes: map[string]string{"COMPRESSION": "SNAPPY", "VERSIONS": "1"}},
+	}}, fake.tables["orders:items"])
+	assert.Contains(t, fake.requests, "PUT /orders:items/schema")
+	assert.Equal(t, []string{REASON_TABLE_ALTERED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
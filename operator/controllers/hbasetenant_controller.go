@@ -21,8 +21,10 @@ import (
 	time "time"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	errors "k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	record "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -32,19 +34,22 @@ import (
 
 // HbaseTenantReconciler reconciles a HbaseTenant object.
 type HbaseTenantReconciler struct {
-	Client client.Client
-	Scheme *runtime.Scheme
+	Client   client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenants,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenants/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenants/finalizers,verbs=update
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbaseclusters,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
 //+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -65,75 +70,193 @@ func (r *HbaseTenantReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
 			log.Info("HbaseTenant resource not found. Ignoring since object must be deleted")
+			deleteResourceMetrics("HbaseTenant/"+req.Name, req.Namespace)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		log.Error(err, "Failed to get HbaseTenant")
 		return ctrl.Result{RequeueAfter: time.Second * 5}, err
 	}
+	kind := "HbaseTenant/" + hbasetenant.Name
+	defer observeReconcilePhase(kind, hbasetenant.Namespace, "", "reconcile", time.Now())
 
-	// Check if the configmap reconciliation is enabled from tenant controller, this is controlled from serviceLabels
-	// If the desired service label is set to true, then we will reconcile the configmaps
-	value, exists := hbasetenant.Spec.ServiceLabels[RECONCILE_CONFIG_LABEL]
+	// CRs still using the deprecated label are migrated once, the update triggers another reconcile
+	migrated, err := migrateConfigUpdatePolicy(ctx, log, hbasetenant, &hbasetenant.Spec.Configuration, hbasetenant.Spec.ServiceLabels, r.Recorder, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if migrated {
+		return ctrl.Result{}, nil
+	}
 
-	// Reconcile configmap only if set from label. "config-only" value will lead configMap update but not restart of StatefulSet
-	if exists && (value == "config-only" || value == "true" || value == "yes") {
+	// A tenant referring to an HbaseCluster waits for it to be available and inherits its defaults
+	if hbasetenant.Spec.ClusterRef != nil {
+		result, err := reconcileClusterRef(ctx, log, hbasetenant, r.Client)
+		if (ctrl.Result{}) != result || err != nil {
+			return result, err
+		}
+	}
+
+	// Tenants beyond the limits of an HbaseTenantPolicy are neither scaled nor rendered
+	if result, err := enforceTenantPolicies(ctx, log, hbasetenant, r.Recorder, r.Client); (ctrl.Result{}) != result || err != nil {
+		return result, err
+	}
+
+	// ConfigMaps of a tenant are not reconciled, unless the update policy says otherwise
+	policy := getConfigUpdatePolicy(hbasetenant.Spec.Configuration, kvstorev1.ConfigUpdatePolicyIgnore)
+
+	restartEnabled := isRestartOnConfigChange(policy)
+	hotReloadEnabled := isHotReloadEnabled(policy, hbasetenant.Spec.Configuration)
+	cfgName := hbaseConfigNameOf(hbasetenant.Spec.Configuration, hbasetenant.Spec.Datanode)
+	restartTargets := map[string][]string{hbasetenant.Namespace + "/" + cfgName: {hbasetenant.Spec.Datanode.Name}}
+	dryRun := isDryRun(hbasetenant)
+
+	// ConfigOnly policy will lead configMap update but not restart of StatefulSet
+	changes := []configChange{}
+	if isConfigReconciled(policy) {
 		log.Info("Reconciling configmaps for tenant, starting to validate")
-		validated, err := validateConfiguration(ctx, log, hbasetenant.Namespace, hbasetenant.Spec.Configuration, r.Client)
+		validateStart := time.Now()
+		validated, err := validateClusterConfiguration(ctx, log, hbasetenant.Namespace, hbasetenant.Spec.Configuration,
+			[]kvstorev1.HbaseClusterDeployment{hbasetenant.Spec.Datanode}, r.Client)
+		observeReconcilePhase(kind, hbasetenant.Namespace, "", "validation", validateStart)
+		recordConfigValidation(kind, hbasetenant.Namespace, err)
 		if err != nil {
-			publishEvent(ctx, log, hbasetenant.Namespace, "ConfigValidateFailed", err.Error(), "Warning", "ConfigMap", r.Client)
+			recordWarning(r.Recorder, hbasetenant, REASON_CONFIG_VALIDATE_FAILED, err)
 			log.Error(err, "Failed to validate configuration")
 			return validated, err
 		}
-		log.Info("Configuration validated successfully, starting reconcile for HBASE configMaps")
-		cfg := buildConfigMap(hbasetenant.Spec.Configuration.HbaseConfigName, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.Configuration.HbaseConfig, hbasetenant.Spec.Configuration.HbaseTenantConfig, log)
-		ctrl.SetControllerReference(hbasetenant, cfg, r.Scheme)
-		hbaseCfgReconRes, err := reconcileConfigMap(ctx, log, hbasetenant.Namespace, cfg, r.Client)
-		if (ctrl.Result{}) != hbaseCfgReconRes || err != nil {
-			return hbaseCfgReconRes, err
+		// overrides targeting other namespaces are ignored, and reported in status
+		if err = reportTenantConfigValidity(ctx, log, hbasetenant, &hbasetenant.Status.Conditions, hbasetenant.Spec.Configuration,
+			[]string{hbasetenant.Namespace}, r.Recorder, r.Client); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		configuration, err := resolveTenantConfig(ctx, log, r.Client, hbasetenant.Spec.Configuration, []string{hbasetenant.Namespace})
+		if err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		hbaseCfg := buildConfigMap(configuration.HbaseConfigName, hbasetenant.Name, hbasetenant.Namespace, configuration.HbaseConfig, configuration.HbaseTenantConfig, log)
+		ctrl.SetControllerReference(hbasetenant, hbaseCfg, r.Scheme)
+		hadoopCfg := buildConfigMap(configuration.HadoopConfigName, hbasetenant.Name, hbasetenant.Namespace, configuration.HadoopConfig, configuration.HadoopTenantConfig, log)
+		ctrl.SetControllerReference(hbasetenant, hadoopCfg, r.Scheme)
+		overrides := appendTenantConfigOverrideStatus(nil, hbaseCfg, configuration.HbaseTenantConfig)
+		overrides = appendTenantConfigOverrideStatus(overrides, hadoopCfg, configuration.HadoopTenantConfig)
+
+		cfgs := []*corev1.ConfigMap{hbaseCfg, hadoopCfg}
+		for _, cfg := range buildComponentConfigMaps(hbasetenant.Name, hbasetenant.Namespace, configuration, hbasetenant.Spec.Datanode, log) {
+			ctrl.SetControllerReference(hbasetenant, cfg, r.Scheme)
+			cfgs = append(cfgs, cfg)
+		}
+
+		configStart := time.Now()
+		cfgReconRes := ctrl.Result{}
+		for _, cfg := range cfgs {
+			log.Info("Configuration validated successfully, starting reconcile for configMap", "ConfigMap.Name", cfg.Name)
+			var cfgChanges []configChange
+			var existing *corev1.ConfigMap
+			cfgChanges, existing, err = computeConfigMapDiff(ctx, log, cfg, r.Client)
+			if err != nil {
+				cfgReconRes = ctrl.Result{RequeueAfter: time.Second * 5}
+				break
+			}
+			if dryRun {
+				changes = append(changes, cfgChanges...)
+				continue
+			}
+			if cfg.Name == cfgName {
+				prepareHotReload(existing, cfg, cfgChanges, hotReloadEnabled)
+			}
+			cfgReconRes, err = reconcileConfigMap(ctx, log, hbasetenant.Namespace, cfg, hbasetenant, r.Recorder, r.Client)
+			if err != nil {
+				break
+			}
+			changes = append(changes, cfgChanges...)
+			if (ctrl.Result{}) != cfgReconRes {
+				break
+			}
+		}
+		// changes of the ConfigMaps are reported together, so that status is written once per reconcile
+		if !dryRun {
+			plan := buildRolloutPlan(changes, restartTargets, restartEnabled, hotReloadEnabled)
+			reportConfigChanges(ctx, log, hbasetenant, &hbasetenant.Status.LastConfigChange, changes, plan, false, r.Recorder, r.Client)
 		}
-		log.Info("Configuration validated successfully, starting reconcile for HADOOP configMaps")
-		cfg = buildConfigMap(hbasetenant.Spec.Configuration.HadoopConfigName, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.Configuration.HadoopConfig, hbasetenant.Spec.Configuration.HadoopTenantConfig, log)
-		ctrl.SetControllerReference(hbasetenant, cfg, r.Scheme)
-		hadoopCfgReconRes, err := reconcileConfigMap(ctx, log, hbasetenant.Namespace, cfg, r.Client)
-		if (ctrl.Result{}) != hadoopCfgReconRes || err != nil {
-			return hadoopCfgReconRes, err
+		if (ctrl.Result{}) != cfgReconRes || err != nil {
+			return cfgReconRes, err
 		}
+		observeReconcilePhase(kind, hbasetenant.Namespace, "", "configmaps", configStart)
+		if !dryRun {
+			if err = reportTenantConfigOverrides(ctx, log, hbasetenant, &hbasetenant.Status.TenantConfigOverrides, overrides, r.Client); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
+		}
+	}
+
+	// In dry run, only report the config diff and the rollout plan without applying anything
+	if dryRun {
+		log.Info("Dry run enabled, config diff computed without applying changes")
+		plan := buildRolloutPlan(changes, restartTargets, restartEnabled, hotReloadEnabled)
+		reportConfigChanges(ctx, log, hbasetenant, &hbasetenant.Status.LastConfigChange, changes, plan, true, r.Recorder, r.Client)
+		return ctrl.Result{}, nil
 	}
 
-	resourceVersionOfHbaseConfigMap := ""
-	// If label exists and set to true, then validate the config format and reconcile afterwards
-	if exists && (value == "true" || value == "yes") {
-		// Get the resource version of the configmap, if it is v2 then we will use the resource version
-		log.Info("Configmap recon enabled, new Resource Version will be used")
-		resourceVersionOfHbaseConfigMap = getCfgResourceVersionIfV2OrNil(log, r.Client, ctx,
-			hbasetenant.Spec.Configuration.HbaseConfigName, hbasetenant.Namespace)
-	} else {
-		// if Restart of statefulSet is turned off, use existing Annotation as reference
-		log.Info("Configmap recon not enabled, getting existing resource version")
-		resourceVersionOfHbaseConfigMap = getExistingAnnotationOfStatefulSet(log, r.Client, ctx, hbasetenant)
+	if restartEnabled {
+		// Changes which can be reloaded online are applied once kubelet has synced the configmap, without restarting pods
+		adminEndpoint := ""
+		if hotReloadEnabled {
+			adminEndpoint = hbasetenant.Spec.Configuration.AdminEndpoint
+		}
+		result, err := reconcileHotReload(ctx, log, hbasetenant.Namespace, cfgName,
+			adminEndpoint, hbasetenant, r.Recorder, r.Client)
+		if (ctrl.Result{}) != result || err != nil {
+			return result, err
+		}
 	}
+	resourceVersionOfHbaseConfigMap := getConfigVersion(log, r.Client, ctx, policy, cfgName,
+		hbasetenant.Spec.Datanode.Name, hbasetenant.Namespace)
 
 	svc := buildService(hbasetenant.Name, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.ServiceLabels, hbasetenant.Spec.ServiceSelectorLabels, []kvstorev1.HbaseClusterDeployment{hbasetenant.Spec.Datanode}, true)
+	applyServiceSpec(svc, hbasetenant.Spec.Service)
+	addMetricsPort(svc, hbasetenant.Name, hbasetenant.Spec.Monitoring, []kvstorev1.HbaseClusterDeployment{hbasetenant.Spec.Datanode})
 	ctrl.SetControllerReference(hbasetenant, svc, r.Scheme)
-	result, err := reconcileService(ctx, log, hbasetenant.Namespace, svc, r.Client)
+	result, err := reconcileService(ctx, log, hbasetenant.Namespace, svc, hbasetenant, r.Recorder, r.Client)
 	if (ctrl.Result{}) != result || err != nil {
 		return result, err
 	}
 
+	// Exporters are scraped through a ServiceMonitor or PodMonitor, or through pod annotations without prometheus-operator
+	scrapeAnnotations, err := reconcileMonitoring(ctx, log, hbasetenant, hbasetenant.Spec.Monitoring, []kvstorev1.HbaseClusterDeployment{hbasetenant.Spec.Datanode}, r.Recorder, r.Scheme, r.Client)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	statefulSetStart := time.Now()
 	newSS, err := buildStatefulSet(hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.BaseImage, false,
 		hbasetenant.Spec.Configuration, resourceVersionOfHbaseConfigMap, hbasetenant.Spec.FSGroup, hbasetenant.Spec.Datanode, log, false)
+	if err == nil {
+		err = injectJmxExporter(newSS, hbasetenant.Name, hbasetenant.Spec.Monitoring, hbasetenant.Spec.Datanode, scrapeAnnotations)
+	}
 	if err != nil {
-		publishEvent(ctx, log, hbasetenant.Namespace, "StatefulSetBuildFailed", err.Error(), "Warning", "StatefulSet/"+hbasetenant.Spec.Datanode.Name, r.Client)
+		recordWarning(r.Recorder, hbasetenant, REASON_STATEFULSET_BUILD_FAILED, err)
 		log.Error(err, "Failed to build StatefulSet", "StatefulSet.Name", hbasetenant.Spec.Datanode.Name)
 		return ctrl.Result{}, err
 	}
 	ctrl.SetControllerReference(hbasetenant, newSS, r.Scheme)
-	result, err = reconcileStatefulSet(ctx, log, hbasetenant.Namespace, newSS, hbasetenant.Spec.Datanode, r.Client)
-	if (ctrl.Result{}) != result || err != nil {
+	result, err = reconcileStatefulSet(ctx, log, hbasetenant.Namespace, newSS, hbasetenant.Spec.Datanode, hbasetenant, r.Recorder, r.Client)
+	observeReconcilePhase(kind, hbasetenant.Namespace, hbasetenant.Spec.Datanode.Name, "statefulset", statefulSetStart)
+	if err != nil {
 		return result, err
 	}
 
+	// Regionservers are moved into the RSGroup as they become ready, without waiting for the whole StatefulSet
+	if hbasetenant.Spec.RSGroup != nil {
+		rsGroupResult, err := reconcileRSGroup(ctx, log, hbasetenant, newSS.Spec.Selector.MatchLabels, r.Recorder, r.Client)
+		if (ctrl.Result{}) != rsGroupResult || err != nil {
+			return rsGroupResult, err
+		}
+	}
+	if (ctrl.Result{}) != result {
+		return result, nil
+	}
+
 	log.Info("starting pdb reconciliation")
 	pdb := buildPodDisruptionBudget(hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.Datanode, log)
 	if pdb != nil {
@@ -144,6 +267,11 @@ func (r *HbaseTenantReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}
 
+	// Quotas are synced periodically, to correct drift and report space usage, and removed once unset
+	if hbasetenant.Spec.Quotas != nil || hbasetenant.Status.Quotas != nil {
+		return reconcileQuotas(ctx, log, hbasetenant, r.Recorder, r.Client)
+	}
+
 	return ctrl.Result{}, nil
 }
 
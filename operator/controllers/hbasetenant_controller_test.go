@@ -16,6 +16,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -197,7 +198,7 @@ func TestHbaseTenantReconciler_SuccessfulReconciliation_AllObjectsFoundRestFlow(
 	k8sMockClient.AssertExpectations(t)
 }
 
-// TestHbaseTenantReconciler_Failure_EventPublish tests the Reconcile method when error is returned while publishing event
+// TestHbaseTenantReconciler_Failure_EventPublish tests the Reconcile method publishes a warning event when the config is invalid
 func TestHbaseTenantReconciler_Failure_EventPublish(t *testing.T) {
 	//mock hbase tenant object
 	hbasetenant := getInvalidConfigHbasetenant()
@@ -211,12 +212,10 @@ func TestHbaseTenantReconciler_Failure_EventPublish(t *testing.T) {
 		}).
 		Return(nil)
 
-	k8sMockClient.On("Get", ctx, types.NamespacedName{Namespace: hbasetenant.Namespace, Name: "ConfigValidateFailed"}, &corev1.Event{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
-	k8sMockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(nil)
-
 	result, err := reconciler.Reconcile(ctx, req)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{Requeue: false, RequeueAfter: 0}, result)
+	assert.Equal(t, []string{REASON_CONFIG_VALIDATE_FAILED}, recordedReasons(reconciler.Recorder.(*record.FakeRecorder)))
 
 	// AssertExpectations asserts that everything specified with On and Return was in fact called as expected.
 	k8sMockClient.AssertExpectations(t)
@@ -229,9 +228,13 @@ func getMockClientAndTenantReconciler() (*K8sMockClient, *HbaseTenantReconciler)
 	_ = kvstorev1.AddToScheme(scheme)
 	_ = appsv1.AddToScheme(scheme)
 
+	// no HbaseTenantPolicy applies to the tenants unless a test says otherwise
+	mockClient.On("List", mock.Anything, &kvstorev1.HbaseTenantPolicyList{}, mock.Anything).Return(nil).Maybe()
+
 	reconciler := &HbaseTenantReconciler{
-		Client: mockClient,
-		Scheme: scheme,
+		Client:   mockClient,
+		Scheme:   scheme,
+		Recorder: record.NewFakeRecorder(100),
 	}
 	return mockClient, reconciler
 }
@@ -287,11 +290,11 @@ func populateTenantHashStore(hbasetenant *kvstorev1.HbaseTenant, reconciler *Hba
 	hashStore["ss-"+mockSts.Name] = asSha256(stsMarshal)
 }
 
-// TestHbaseTenantReconciler_ConfigReconcileDisabled verifies behavior when config reconcile label is absent
+// TestHbaseTenantReconciler_ConfigReconcileDisabled verifies behavior when the update policy is not set
 func TestHbaseTenantReconciler_ConfigReconcileDisabled(t *testing.T) {
 	resetHashStore()
 	hbasetenant := getMockHbaseTenant()
-	delete(hbasetenant.Spec.ServiceLabels, RECONCILE_CONFIG_LABEL)
+	hbasetenant.Spec.Configuration.UpdatePolicy = ""
 
 	k8sMockClient, reconciler, ctx, req := doTenantTestSetup()
 
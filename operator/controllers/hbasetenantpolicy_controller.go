@@ -0,0 +1,137 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	context "context"
+	sort "sort"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+	handler "sigs.k8s.io/controller-runtime/pkg/handler"
+	reconcile "sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+// HbaseTenantPolicyReconciler reports the usage of the namespaces selected by a HbaseTenantPolicy object.
+// Limits of the policy are enforced by the HbaseTenant controller, and by the HbaseTenant webhook when enabled.
+type HbaseTenantPolicyReconciler struct {
+	Client client.Client
+	Scheme *runtime.Scheme
+	// AdmissionEnforced is set when the operator runs with webhooks
+	AdmissionEnforced bool
+}
+
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenantpolicies,verbs=get;list;watch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenantpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=kvstore.flipkart.com,resources=hbasetenants,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch
+
+// Reconcile updates the status of the HbaseTenantPolicy with the namespaces it selects, along with the HbaseTenants
+// and regionservers in them.
+func (r *HbaseTenantPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx).WithValues("hbasetenantpolicy", req.Name)
+
+	policy := &kvstorev1.HbaseTenantPolicy{}
+	err := r.Client.Get(ctx, req.NamespacedName, policy)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("HbaseTenantPolicy resource not found. Ignoring since object must be deleted")
+			return ctrl.Result{}, nil
+		}
+		log.Error(err, "Failed to get HbaseTenantPolicy")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err = r.Client.List(ctx, namespaces); err != nil {
+		log.Error(err, "Failed to list namespaces")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	tenants := &kvstorev1.HbaseTenantList{}
+	if err = r.Client.List(ctx, tenants); err != nil {
+		log.Error(err, "Failed to list HbaseTenants")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	status := kvstorev1.HbaseTenantPolicyStatus{ObservedGeneration: policy.Generation, Enforcement: kvstorev1.TenantPolicyEnforcementReconcile}
+	if r.AdmissionEnforced {
+		status.Enforcement = kvstorev1.TenantPolicyEnforcementAdmission
+	}
+	selected := map[string]bool{}
+	for i := range namespaces.Items {
+		ok, err := policy.Selects(&namespaces.Items[i])
+		if err != nil {
+			log.Error(err, "Invalid namespace selector of HbaseTenantPolicy")
+			return ctrl.Result{}, nil
+		}
+		if ok {
+			selected[namespaces.Items[i].Name] = true
+			status.Namespaces = append(status.Namespaces, namespaces.Items[i].Name)
+		}
+	}
+	for _, t := range tenants.Items {
+		if selected[t.Namespace] {
+			status.Tenants = append(status.Tenants, t.Namespace+"/"+t.Name)
+			status.RegionServers += t.Spec.Datanode.Size
+		}
+	}
+	sort.Strings(status.Namespaces)
+	sort.Strings(status.Tenants)
+
+	if equality.Semantic.DeepEqual(status, policy.Status) {
+		return ctrl.Result{}, nil
+	}
+	if policy.Spec.MaxRegionServers != nil && status.RegionServers > *policy.Spec.MaxRegionServers {
+		log.Info("Regionservers exceed the limit of the policy", "RegionServers", status.RegionServers, "MaxRegionServers", *policy.Spec.MaxRegionServers)
+	}
+	policy.Status = status
+	if err = r.Client.Status().Update(ctx, policy); err != nil {
+		log.Error(err, "Failed to update HbaseTenantPolicy status")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+// allPolicies maps any object to reconcile requests of all the HbaseTenantPolicies, as any of them may select it
+func (r *HbaseTenantPolicyReconciler) allPolicies(ctx context.Context, o client.Object) []reconcile.Request {
+	policies := &kvstorev1.HbaseTenantPolicyList{}
+	if err := r.Client.List(ctx, policies); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "Failed to list HbaseTenantPolicies")
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for _, p := range policies.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&p)})
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *HbaseTenantPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kvstorev1.HbaseTenantPolicy{}).
+		Watches(&kvstorev1.HbaseTenant{}, handler.EnqueueRequestsFromMapFunc(r.allPolicies)).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(r.allPolicies)).
+		Complete(r)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func doTenantPolicyTestSetup(policy *kvstorev1.HbaseTenantPolicy) (*K8sMockClient, *HbaseTenantPolicyReconciler, context.Context, ctrl.Request) {
+	k8sMockClient := new(K8sMockClient)
+	reconciler := &HbaseTenantPolicyReconciler{Client: k8sMockClient}
+	ctx := context.TODO()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: policy.Name}}
+
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseTenantPolicy{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseTenantPolicy)
+			*arg = *policy
+		}).
+		Return(nil)
+	k8sMockClient.On("List", ctx, &corev1.NamespaceList{}, []client.ListOption(nil)).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*corev1.NamespaceList).Items = []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: tenantNamespace2, Labels: map[string]string{"tier": "gold"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: tenantNamespace1, Labels: map[string]string{"tier": "gold"}}},
+				{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}},
+			}
+		}).
+		Return(nil)
+	k8sMockClient.On("List", ctx, &kvstorev1.HbaseTenantList{}, []client.ListOption(nil)).
+		Run(func(args mock.Arguments) {
+			tenant := getMockHbaseTenant()
+			other := getMockHbaseTenant()
+			other.Namespace = testNamespace
+			args.Get(1).(*kvstorev1.HbaseTenantList).Items = []kvstorev1.HbaseTenant{*tenant, *other}
+		}).
+		Return(nil)
+	return k8sMockClient, reconciler, ctx, req
+}
+
+// TestHbaseTenantPolicyReconciler_Usage verifies the selected namespaces, their tenants and regionservers are reported,
+// along with the enforcement through the webhook.
+func TestHbaseTenantPolicyReconciler_Usage(t *testing.T) {
+	policy := &kvstorev1.HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "gold", Generation: 2},
+		Spec: kvstorev1.HbaseTenantPolicySpec{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}}}}
+	k8sMockClient, reconciler, ctx, req := doTenantPolicyTestSetup(policy)
+	reconciler.AdmissionEnforced = true
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+
+	tenant := getMockHbaseTenant()
+	expected := kvstorev1.HbaseTenantPolicyStatus{
+		Namespaces:         []string{tenantNamespace1, tenantNamespace2},
+		Tenants:            []string{tenant.Namespace + "/" + tenant.Name},
+		RegionServers:      tenant.Spec.Datanode.Size,
+		Enforcement:        kvstorev1.TenantPolicyEnforcementAdmission,
+		ObservedGeneration: 2,
+	}
+	statusWriter.On("Update", ctx, mock.MatchedBy(func(p *kvstorev1.HbaseTenantPolicy) bool {
+		return assert.ObjectsAreEqual(expected, p.Status)
+	})).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestHbaseTenantPolicyReconciler_Unchanged verifies the status is not updated when the usage did not change.
+func TestHbaseTenantPolicyReconciler_Unchanged(t *testing.T) {
+	tenant := getMockHbaseTenant()
+	policy := &kvstorev1.HbaseTenantPolicy{ObjectMeta: metav1.ObjectMeta{Name: "tenant-1", Generation: 1},
+		Spec: kvstorev1.HbaseTenantPolicySpec{Namespaces: []string{tenantNamespace1}},
+		Status: kvstorev1.HbaseTenantPolicyStatus{Namespaces: []string{tenantNamespace1}, Tenants: []string{tenant.Namespace + "/" + tenant.Name},
+			RegionServers: tenant.Spec.Datanode.Size, Enforcement: kvstorev1.TenantPolicyEnforcementReconcile, ObservedGeneration: 1}}
+	k8sMockClient, reconciler, ctx, req := doTenantPolicyTestSetup(policy)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	context "context"
+	json "encoding/json"
+	errs "errors"
+	fmt "fmt"
+	http "net/http"
+	strconv "strconv"
+	time "time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+const (
+	HMASTER_ACTIVE  = "active"
+	HMASTER_BACKUP  = "backup"
+	HMASTER_UNKNOWN = "unknown"
+)
+
+const defaultHmasterInfoPort = 16010
+
+// isHmasterHA tells whether the deployment is the hmaster deployment rolled out according to the state of the masters
+func isHmasterHA(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) bool {
+	return c.Spec.HmasterHA != nil && c.Spec.Deployments.Hmaster.Size > 0 && d.Name == c.Spec.Deployments.Hmaster.Name
+}
+
+// hmasterInfoAddress returns host:port of the info server of the master running in the pod
+func hmasterInfoAddress(c *kvstorev1.HbaseCluster, pod *corev1.Pod) string {
+	port := c.Spec.HmasterHA.InfoPort
+	if port == 0 {
+		port = defaultHmasterInfoPort
+	}
+	return pod.Status.PodIP + ":" + strconv.Itoa(int(port))
+}
+
+// hmasterState tells whether a master is active or backup, from the Server bean of its jmx servlet
+func hmasterState(ctx context.Context, scheme string, infoAddress string) (string, error) {
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+infoAddress+"/jmx?qry=Hadoop:service=HBase,name=Master,sub=Server", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := (&http.Client{Timeout: time.Second * 10}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET /jmx of %s failed with status %d", infoAddress, resp.StatusCode)
+	}
+
+	out := struct {
+		Beans []struct {
+			IsActiveMaster string `json:"tag.isActiveMaster"`
+		} `json:"beans"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Beans) == 0 || len(out.Beans[0].IsActiveMaster) == 0 {
+		return "", errs.New("Master Server bean not found on " + infoAddress)
+	}
+	if out.Beans[0].IsActiveMaster == "true" {
+		return HMASTER_ACTIVE, nil
+	}
+	return HMASTER_BACKUP, nil
+}
+
+// reconcileHmasterHA records the active and backup masters, and rolls out the hmaster StatefulSet, whose update
+// strategy is OnDelete: outdated backup masters are restarted first, then the active one once the updated backup
+// masters are ready to take over
+func reconcileHmasterHA(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, recorder record.EventRecorder,
+	cl client.Client) (ctrl.Result, error) {
+	ss := &appsv1.StatefulSet{}
+	if err := cl.Get(ctx, types.NamespacedName{Name: c.Spec.Deployments.Hmaster.Name, Namespace: c.Namespace}, ss); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, client.IgnoreNotFound(err)
+	}
+
+	pods := []*corev1.Pod{}
+	states := map[string]string{}
+	active := ""
+	backups := []string{}
+	for i := int32(0); i < c.Spec.Deployments.Hmaster.Size; i++ {
+		name := fmt.Sprintf("%s-%d", c.Spec.Deployments.Hmaster.Name, i)
+		pod := &corev1.Pod{}
+		if err := cl.Get(ctx, types.NamespacedName{Name: name, Namespace: c.Namespace}, pod); err != nil {
+			if !errors.IsNotFound(err) {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
+			pod = nil
+		}
+		pods = append(pods, pod)
+		states[name] = HMASTER_UNKNOWN
+		if pod == nil || !isPodReady(*pod) {
+			continue
+		}
+		state, err := hmasterState(ctx, c.Spec.HmasterHA.Scheme, hmasterInfoAddress(c, pod))
+		if err != nil {
+			log.Info("Failed to get state of master", "Pod", name, "Error", err.Error())
+			continue
+		}
+		states[name] = state
+		if state == HMASTER_ACTIVE {
+			active = name
+		} else {
+			backups = append(backups, name)
+		}
+	}
+
+	if active != c.Status.ActiveHmaster || !equality.Semantic.DeepEqual(backups, c.Status.BackupHmasters) {
+		c.Status.ActiveHmaster = active
+		c.Status.BackupHmasters = backups
+		if err := cl.Status().Update(ctx, c); err != nil {
+			log.Error(err, "Failed to update HbaseCluster status with the masters")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+
+	outdated := []*corev1.Pod{}
+	for _, pod := range pods {
+		if pod != nil && len(ss.Status.UpdateRevision) > 0 && pod.Labels[appsv1.StatefulSetRevisionLabel] != ss.Status.UpdateRevision {
+			outdated = append(outdated, pod)
+		}
+	}
+	if len(outdated) == 0 {
+		return ctrl.Result{}, nil
+	}
+	for _, pod := range pods {
+		if pod == nil || states[pod.Name] == HMASTER_UNKNOWN {
+			// a restarted master has to be back, and the state of every master known, before the next one is restarted
+			log.Info("Waiting for masters to be ready", "States", states)
+			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+	}
+
+	// the active master is restarted last, when every backup master is updated, ready and known to be a backup
+	restart := outdated[0]
+	for _, pod := range outdated {
+		if states[pod.Name] == HMASTER_BACKUP {
+			restart = pod
+			break
+		}
+	}
+
+	log.Info("Restarting master", "Pod", restart.Name, "State", states[restart.Name])
+	if err := cl.Delete(ctx, restart); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, client.IgnoreNotFound(err)
+	}
+	recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_HMASTER_RESTARTED, "Restarted "+states[restart.Name]+" master "+restart.Name)
+	return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+}
@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// startHmasterStandIns serves the master Server bean, active for the first master and backup for the others, on
+// the same port of a loopback address per master. It returns the port and the addresses
+func startHmasterStandIns(t *testing.T, masters int) (int32, []string) {
+	port := 0
+	ips := []string{}
+	for i := 0; i < masters; i++ {
+		ip := "127.0.0." + strconv.Itoa(i+1)
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err != nil {
+			t.Skip("Loopback address " + ip + " not available: " + err.Error())
+		}
+		port = l.Addr().(*net.TCPAddr).Port
+		active := strconv.FormatBool(i == 0)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Hadoop:service=HBase,name=Master,sub=Server", r.URL.Query().Get("qry"))
+			w.Write([]byte(`{"beans":[{"name":"Hadoop:service=HBase,name=Master,sub=Server","tag.isActiveMaster":"` + active + `"}]}`))
+		}))
+		server.Listener.Close()
+		server.Listener = l
+		server.Start()
+		t.Cleanup(server.Close)
+		ips = append(ips, ip)
+	}
+	return int32(port), ips
+}
+
+func newHmasterHACluster(port int32, size int32) *kvstorev1.HbaseCluster {
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{Hmaster: kvstorev1.HbaseClusterDeployment{Name: "hmaster", Size: size}},
+			HmasterHA:   &kvstorev1.HbaseClusterHmasterHA{InfoPort: port},
+		},
+	}
+}
+
+// mockHmasterPods returns the hmaster pods at the given revisions from the mock client, along with the StatefulSet
+func mockHmasterPods(k8sMockClient *K8sMockClient, ctx context.Context, ips []string, updateRevision string, revisions ...string) []*corev1.Pod {
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "hmaster", Namespace: testNamespace}, &appsv1.StatefulSet{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*appsv1.StatefulSet).Status.UpdateRevision = updateRevision
+		}).
+		Return(nil)
+	pods := []*corev1.Pod{}
+	for i, revision := range revisions {
+		pod := newRegionServerPod("hmaster-"+strconv.Itoa(i), true)
+		pod.Labels = map[string]string{appsv1.StatefulSetRevisionLabel: revision}
+		pod.Status.PodIP = ips[i]
+		pods = append(pods, &pod)
+		k8sMockClient.On("Get", ctx, types.NamespacedName{Name: pod.Name, Namespace: testNamespace}, &corev1.Pod{}).
+			Run(func(args mock.Arguments) {
+				*args.Get(2).(*corev1.Pod) = pod
+			}).
+			Return(nil)
+	}
+	return pods
+}
+
+// TestHmasterState verifies the state is read from the isActiveMaster tag of the Server bean.
+func TestHmasterState(t *testing.T) {
+	port, ips := startHmasterStandIns(t, 2)
+	state, err := hmasterState(context.TODO(), "", net.JoinHostPort(ips[0], strconv.Itoa(int(port))))
+	assert.NoError(t, err)
+	assert.Equal(t, HMASTER_ACTIVE, state)
+	state, err = hmasterState(context.TODO(), "http", net.JoinHostPort(ips[1], strconv.Itoa(int(port))))
+	assert.NoError(t, err)
+	assert.Equal(t, HMASTER_BACKUP, state)
+
+	_, err = hmasterState(context.TODO(), "http", closedLocalAddress(t))
+	assert.Error(t, err)
+}
+
+// TestReconcileHmasterHA_RestartsBackupFirst verifies the backup master is restarted before the active one, and that
+// the masters are recorded in status.
+func TestReconcileHmasterHA_RestartsBackupFirst(t *testing.T) {
+	ctx := context.TODO()
+	port, ips := startHmasterStandIns(t, 2)
+	c := newHmasterHACluster(port, 2)
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	pods := mockHmasterPods(k8sMockClient, ctx, ips, "v2", "v1", "v1")
+	k8sMockClient.On("Delete", ctx, pods[1]).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileHmasterHA(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ctrl.Result{}, result)
+	assert.Equal(t, "hmaster-0", c.Status.ActiveHmaster)
+	assert.Equal(t, []string{"hmaster-1"}, c.Status.BackupHmasters)
+	assert.Equal(t, []string{REASON_HMASTER_RESTARTED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestReconcileHmasterHA_RestartsActiveLast verifies the active master is restarted once an updated backup master
+// can take over.
+func TestReconcileHmasterHA_RestartsActiveLast(t *testing.T) {
+	ctx := context.TODO()
+	port, ips := startHmasterStandIns(t, 2)
+	c := newHmasterHACluster(port, 2)
+	c.Status.ActiveHmaster = "hmaster-0"
+	c.Status.BackupHmasters = []string{"hmaster-1"}
+	k8sMockClient := new(K8sMockClient)
+	pods := mockHmasterPods(k8sMockClient, ctx, ips, "v2", "v1", "v2")
+	k8sMockClient.On("Delete", ctx, pods[0]).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := reconcileHmasterHA(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{REASON_HMASTER_RESTARTED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestReconcileHmasterHA_WaitsForBackup verifies the active master is not restarted while the updated backup master
+// is not ready to take over.
+func TestReconcileHmasterHA_WaitsForBackup(t *testing.T) {
+	ctx := context.TODO()
+	port, ips := startHmasterStandIns(t, 2)
+	c := newHmasterHACluster(port, 2)
+	c.Status.ActiveHmaster = "hmaster-0"
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	pods := mockHmasterPods(k8sMockClient, ctx, ips, "v2", "v1", "v2")
+	pods[1].Status.Conditions[0].Status = corev1.ConditionFalse
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileHmasterHA(ctx, ctrl.Log.WithName("test"), c, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ctrl.Result{}, result)
+	assert.Empty(t, c.Status.BackupHmasters)
+	assert.Empty(t, recordedReasons(recorder))
+	k8sMockClient.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
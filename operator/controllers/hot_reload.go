@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	context "context"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	logr "github.com/go-logr/logr"
+)
+
+// CFG_RESTART_VERSION_ANNOTATION configmap annotation with the config version StatefulSets are bound to. When present it
+// is used instead of the resource version, so that changes reloaded online do not restart the pods
+const CFG_RESTART_VERSION_ANNOTATION = "hbase-operator/restart-version"
+
+// CFG_HOT_RELOAD_ANNOTATION configmap annotation with the time of a change which is yet to be reloaded online
+const CFG_HOT_RELOAD_ANNOTATION = "hbase-operator/hot-reload-requested"
+
+// time given to kubelet to sync an updated configmap into the pods before asking hbase to reload it
+var configSyncWaitTime = time.Second * 90
+
+// properties of hbase-site.xml which hbase reloads online with update_all_config
+var reloadableConfigKeys = map[string]bool{
+	"hbase.cleaner.scan.dir.concurrent.size":             true,
+	"hbase.ipc.server.fallback-to-simple-auth-allowed":   true,
+	"hbase.ipc.server.max.callqueue.length":              true,
+	"hbase.ipc.server.priority.max.callqueue.length":     true,
+	"hbase.offpeak.end.hour":                             true,
+	"hbase.offpeak.start.hour":                           true,
+	"hbase.oldwals.cleaner.thread.size":                  true,
+	"hbase.regions.overallSlop":                          true,
+	"hbase.regions.slop":                                 true,
+	"hbase.regionserver.flush.throughput.controller":     true,
+	"hbase.regionserver.hfilecleaner.large.queue.size":   true,
+	"hbase.regionserver.hfilecleaner.large.thread.count": true,
+	"hbase.regionserver.hfilecleaner.small.queue.size":   true,
+	"hbase.regionserver.hfilecleaner.small.thread.count": true,
+	"hbase.regionserver.thread.compaction.large":         true,
+	"hbase.regionserver.thread.compaction.small":         true,
+	"hbase.regionserver.thread.hfilecleaner.throttle":    true,
+	"hbase.regionserver.thread.split":                    true,
+	"hbase.regionserver.throughput.controller":           true,
+	"hbase.util.ip.to.rack.determiner":                   true,
+}
+
+// property prefixes of hbase-site.xml which hbase reloads online with update_all_config
+var reloadableConfigPrefixes = []string{
+	"hbase.balancer.",
+	"hbase.hstore.compaction.",
+	"hbase.hstore.flusher.",
+	"hbase.ipc.server.callqueue.",
+	"hbase.master.balancer.",
+	"hbase.procedure.worker.",
+}
+
+func isReloadableConfigChange(c configChange) bool {
+	// whole file changes and files other than hbase-site.xml are never reloaded online
+	if c.File != "hbase-site.xml" || len(c.Key) == 0 {
+		return false
+	}
+	if reloadableConfigKeys[c.Key] {
+		return true
+	}
+	for _, prefix := range reloadableConfigPrefixes {
+		if strings.HasPrefix(c.Key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// canHotReload returns true if there are changes and all of them can be reloaded online
+func canHotReload(changes []configChange) bool {
+	for _, c := range changes {
+		if !isReloadableConfigChange(c) {
+			return false
+		}
+	}
+	return len(changes) > 0
+}
+
+// prepareHotReload carries the restart version and any pending reload over from the existing ConfigMap. If all the
+// changes can be reloaded online, the ConfigMap keeps the version StatefulSets are bound to and is marked for a reload.
+func prepareHotReload(existing *corev1.ConfigMap, cfg *corev1.ConfigMap, changes []configChange, hotReloadEnabled bool) bool {
+	if existing == nil {
+		return false
+	}
+
+	restartVersion, hasRestartVersion := existing.Annotations[CFG_RESTART_VERSION_ANNOTATION]
+	if len(changes) == 0 {
+		for _, key := range []string{CFG_RESTART_VERSION_ANNOTATION, CFG_HOT_RELOAD_ANNOTATION} {
+			if value, ok := existing.Annotations[key]; ok {
+				if cfg.Annotations == nil {
+					cfg.Annotations = make(map[string]string)
+				}
+				cfg.Annotations[key] = value
+			}
+		}
+		return false
+	}
+	if !hotReloadEnabled || !canHotReload(changes) {
+		return false
+	}
+
+	if !hasRestartVersion {
+		// StatefulSets are still bound to the resource version of the existing ConfigMap
+		restartVersion = ""
+		if _, ok := existing.Annotations[CFG_V2_ANNOTATION]; ok {
+			restartVersion = existing.ResourceVersion
+		}
+	}
+	if cfg.Annotations == nil {
+		cfg.Annotations = make(map[string]string)
+	}
+	cfg.Annotations[CFG_RESTART_VERSION_ANNOTATION] = restartVersion
+	cfg.Annotations[CFG_HOT_RELOAD_ANNOTATION] = time.Now().UTC().Format(time.RFC3339)
+	return true
+}
+
+// reconcileHotReload reloads the config online once kubelet had time to sync the ConfigMap marked for a reload. Without an
+// admin endpoint, the mark and restart version are dropped so that StatefulSets roll with the change instead.
+func reconcileHotReload(ctx context.Context, log logr.Logger, namespace string, cfgName string, adminEndpoint string, owner client.Object,
+	recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	cfg, err := getConfigMap(log, cl, ctx, cfgName, namespace)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	requested, pending := cfg.Annotations[CFG_HOT_RELOAD_ANNOTATION]
+	if !pending {
+		return ctrl.Result{}, nil
+	}
+
+	if len(adminEndpoint) == 0 {
+		log.Info("Admin endpoint not set, falling back to rolling restart", "ConfigMap.Name", cfgName)
+		delete(cfg.Annotations, CFG_HOT_RELOAD_ANNOTATION)
+		delete(cfg.Annotations, CFG_RESTART_VERSION_ANNOTATION)
+		if err = cl.Update(ctx, cfg); err != nil {
+			log.Error(err, "Failed to update ConfigMap", "ConfigMap.Namespace", namespace, "ConfigMap.Name", cfgName)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
+	requestedTime, err := time.Parse(time.RFC3339, requested)
+	if err == nil {
+		if wait := configSyncWaitTime - time.Since(requestedTime); wait > 0 {
+			log.Info("Waiting for kubelet to sync ConfigMap before reloading config", "ConfigMap.Name", cfgName, "Wait", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	log.Info("Reloading config online", "ConfigMap.Name", cfgName, "AdminEndpoint", adminEndpoint)
+	if err = newHbaseAdmin(adminEndpoint).UpdateAllConfig(ctx); err != nil {
+		recordWarning(recorder, owner, REASON_CONFIG_RELOAD_FAILED, err)
+		log.Error(err, "Failed to reload config online", "ConfigMap.Name", cfgName)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	delete(cfg.Annotations, CFG_HOT_RELOAD_ANNOTATION)
+	if err = cl.Update(ctx, cfg); err != nil {
+		log.Error(err, "Failed to update ConfigMap", "ConfigMap.Namespace", namespace, "ConfigMap.Name", cfgName)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	recordEvent(recorder, owner, cfg, corev1.EventTypeNormal, REASON_CONFIG_RELOADED, "Reloaded "+cfgName+" online with update_all_config")
+	return ctrl.Result{}, nil
+}
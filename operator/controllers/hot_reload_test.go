@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ---- isReloadableConfigChange / canHotReload ----
+
+// TestIsReloadableConfigChange verifies only property changes of known reloadable hbase-site.xml keys are reloadable.
+func TestIsReloadableConfigChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   configChange
+		expected bool
+	}{
+		{"exact key", configChange{File: "hbase-site.xml", Key: "hbase.regions.slop"}, true},
+		{"balancer prefix", configChange{File: "hbase-site.xml", Key: "hbase.master.balancer.stochastic.maxSteps"}, true},
+		{"compaction prefix", configChange{File: "hbase-site.xml", Key: "hbase.hstore.compaction.max.size"}, true},
+		{"not reloadable", configChange{File: "hbase-site.xml", Key: "hbase.regionserver.handler.count"}, false},
+		{"whole file", configChange{File: "hbase-site.xml"}, false},
+		{"other file", configChange{File: "hbase-env.sh", Key: "hbase.regions.slop"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isReloadableConfigChange(tt.change))
+		})
+	}
+}
+
+// TestCanHotReload verifies hot reload requires at least one change and all of them to be reloadable.
+func TestCanHotReload(t *testing.T) {
+	reloadable := configChange{File: "hbase-site.xml", Key: "hbase.regions.slop"}
+	other := configChange{File: "hbase-site.xml", Key: "hbase.rootdir"}
+
+	assert.False(t, canHotReload(nil))
+	assert.True(t, canHotReload([]configChange{reloadable}))
+	assert.False(t, canHotReload([]configChange{reloadable, other}))
+}
+
+// ---- prepareHotReload ----
+
+// TestPrepareHotReload_Reloadable verifies the ConfigMap keeps the version StatefulSets are bound to and is marked for reload.
+func TestPrepareHotReload_Reloadable(t *testing.T) {
+	changes := []configChange{{File: "hbase-site.xml", Key: "hbase.regions.slop", Action: configModified}}
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42", Annotations: map[string]string{CFG_V2_ANNOTATION: "t"}}}
+	cfg := &corev1.ConfigMap{}
+
+	assert.True(t, prepareHotReload(existing, cfg, changes, true))
+	assert.Equal(t, "42", cfg.Annotations[CFG_RESTART_VERSION_ANNOTATION])
+	assert.Contains(t, cfg.Annotations, CFG_HOT_RELOAD_ANNOTATION)
+
+	// version from an earlier reload is kept
+	existing.Annotations[CFG_RESTART_VERSION_ANNOTATION] = "7"
+	cfg = &corev1.ConfigMap{}
+	assert.True(t, prepareHotReload(existing, cfg, changes, true))
+	assert.Equal(t, "7", cfg.Annotations[CFG_RESTART_VERSION_ANNOTATION])
+}
+
+// TestPrepareHotReload_NotReloadable verifies a restart is left to happen when hot reload is disabled or not possible.
+func TestPrepareHotReload_NotReloadable(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "42", Annotations: map[string]string{CFG_RESTART_VERSION_ANNOTATION: "7"}}}
+
+	cfg := &corev1.ConfigMap{}
+	assert.False(t, prepareHotReload(existing, cfg, []configChange{{File: "hbase-site.xml", Key: "hbase.regions.slop"}}, false))
+	assert.Nil(t, cfg.Annotations)
+
+	cfg = &corev1.ConfigMap{}
+	assert.False(t, prepareHotReload(existing, cfg, []configChange{{File: "hbase-site.xml", Key: "hbase.rootdir"}}, true))
+	assert.Nil(t, cfg.Annotations)
+
+	assert.False(t, prepareHotReload(nil, cfg, []configChange{{File: "hbase-site.xml", Key: "hbase.regions.slop"}}, true))
+}
+
+// TestPrepareHotReload_NoChanges verifies restart version and pending reload are carried over when data is unchanged.
+func TestPrepareHotReload_NoChanges(t *testing.T) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+		CFG_RESTART_VERSION_ANNOTATION: "7",
+		CFG_HOT_RELOAD_ANNOTATION:      "2021-01-01T00:00:00Z",
+	}}}
+	cfg := &corev1.ConfigMap{}
+
+	assert.False(t, prepareHotReload(existing, cfg, nil, true))
+	assert.Equal(t, existing.Annotations, cfg.Annotations)
+
+	cfg = &corev1.ConfigMap{}
+	assert.False(t, prepareHotReload(&corev1.ConfigMap{}, cfg, nil, true))
+	assert.Nil(t, cfg.Annotations)
+}
+
+// ---- reconcileHotReload ----
+
+// TestReconcileHotReload_NotPending verifies nothing is done when the ConfigMap is not marked for reload.
+func TestReconcileHotReload_NotPending(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, nil)
+
+	result, err := reconcileHotReload(ctx, ctrl.Log.WithName("test"), testNamespace, "hbase-config", "http://localhost:1", newEventOwner(), record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileHotReload_WaitForSync verifies the reload waits for kubelet to sync the ConfigMap.
+func TestReconcileHotReload_WaitForSync(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, map[string]string{CFG_HOT_RELOAD_ANNOTATION: time.Now().UTC().Format(time.RFC3339)})
+
+	result, err := reconcileHotReload(ctx, ctrl.Log.WithName("test"), testNamespace, "hbase-config", "http://localhost:1", newEventOwner(), record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= configSyncWaitTime)
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileHotReload_Reloaded verifies update_all_config is called and the reload mark is removed.
+func TestReconcileHotReload_Reloaded(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/admin/update_all_config", r.URL.Path)
+		calls += 1
+	}))
+	defer server.Close()
+
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, map[string]string{
+		CFG_HOT_RELOAD_ANNOTATION:      time.Now().Add(-configSyncWaitTime).UTC().Format(time.RFC3339),
+		CFG_RESTART_VERSION_ANNOTATION: "7",
+	})
+	k8sMockClient.On("Update", ctx, mock.MatchedBy(func(cfg *corev1.ConfigMap) bool {
+		_, pending := cfg.Annotations[CFG_HOT_RELOAD_ANNOTATION]
+		return !pending && cfg.Annotations[CFG_RESTART_VERSION_ANNOTATION] == "7"
+	}), []client.UpdateOption(nil)).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileHotReload(ctx, ctrl.Log.WithName("test"), testNamespace, "hbase-config", server.URL, newEventOwner(), recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, []string{REASON_CONFIG_RELOADED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestReconcileHotReload_AdminFailure verifies a failed reload is reported and retried.
+func TestReconcileHotReload_AdminFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "master not running", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, map[string]string{CFG_HOT_RELOAD_ANNOTATION: "invalid-time"})
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileHotReload(ctx, ctrl.Log.WithName("test"), testNamespace, "hbase-config", server.URL, newEventOwner(), recorder, k8sMockClient)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "master not running")
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_CONFIG_RELOAD_FAILED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileHotReload_NoAdminEndpoint verifies the reload falls back to a restart when the admin endpoint is removed.
+func TestReconcileHotReload_NoAdminEndpoint(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, map[string]string{
+		CFG_HOT_RELOAD_ANNOTATION:      time.Now().UTC().Format(time.RFC3339),
+		CFG_RESTART_VERSION_ANNOTATION: "7",
+	})
+	k8sMockClient.On("Update", ctx, mock.MatchedBy(func(cfg *corev1.ConfigMap) bool {
+		return len(cfg.Annotations) == 0
+	}), []client.UpdateOption(nil)).Return(nil)
+
+	result, err := reconcileHotReload(ctx, ctrl.Log.WithName("test"), testNamespace, "hbase-config", "", newEventOwner(), record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestGetCfgResourceVersionIfV2OrNil_RestartVersion verifies the restart version takes precedence over the resource version.
+func TestGetCfgResourceVersionIfV2OrNil_RestartVersion(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockHotReloadConfigMap(k8sMockClient, ctx, map[string]string{CFG_V2_ANNOTATION: "t", CFG_RESTART_VERSION_ANNOTATION: "7"})
+
+	assert.Equal(t, "7", getCfgResourceVersionIfV2OrNil(ctrl.Log.WithName("test"), k8sMockClient, ctx, "hbase-config", testNamespace))
+}
+
+// mockHotReloadConfigMap registers an existing hbase-config ConfigMap with the given annotations
+func mockHotReloadConfigMap(k8sMockClient *K8sMockClient, ctx context.Context, annotations map[string]string) {
+	existing := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "hbase-config", Namespace: testNamespace, ResourceVersion: "42", Annotations: annotations}}
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "hbase-config", Namespace: testNamespace}, &corev1.ConfigMap{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*corev1.ConfigMap)
+			*arg = *existing.DeepCopy()
+		}).
+		Return(nil)
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	strings "strings"
+	sync "sync"
+	time "time"
+
+	prometheus "github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// METRICS_NAMESPACE prefix of the metrics of the operator
+const METRICS_NAMESPACE = "hbase_operator"
+
+// metricLabels of all the metrics of the operator. Component is the StatefulSet or ConfigMap a metric is about, empty
+// for metrics of the whole resource
+var metricLabels = []string{"kind", "namespace", "name", "component"}
+
+var (
+	desiredReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "desired_replicas",
+		Help:      "Replicas of the StatefulSet of a component in the spec",
+	}, metricLabels)
+	readyReplicasGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "ready_replicas",
+		Help:      "Ready replicas of the StatefulSet of a component",
+	}, metricLabels)
+	rolloutInProgressGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "rollout_in_progress",
+		Help:      "1 while the StatefulSet of a component is not ready or not on its latest revision",
+	}, metricLabels)
+	configValidationFailedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "config_validation_failed",
+		Help:      "1 when the configuration of a resource failed validation in its last reconcile",
+	}, metricLabels)
+	statefulSetUpdatesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "statefulset_updates_total",
+		Help:      "StatefulSets created or updated by the operator",
+	}, metricLabels)
+	configMapUpdatesCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "configmap_updates_total",
+		Help:      "ConfigMaps created or updated by the operator",
+	}, metricLabels)
+	restartsTriggeredCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "restarts_triggered_total",
+		Help:      "Rolling restarts of a component triggered by a config change",
+	}, metricLabels)
+	reconcilePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: METRICS_NAMESPACE,
+		Name:      "reconcile_phase_duration_seconds",
+		Help:      "Duration of the phases of a reconcile: reconcile, validation, configmaps and statefulset",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, append(metricLabels, "phase"))
+	configRevisionAge = newConfigRevisionCollector()
+)
+
+func init() {
+	metrics.Registry.MustRegister(desiredReplicasGauge, readyReplicasGauge, rolloutInProgressGauge, configValidationFailedGauge,
+		statefulSetUpdatesCounter, configMapUpdatesCounter, restartsTriggeredCounter, reconcilePhaseDuration, configRevisionAge)
+}
+
+// metricLabelValuesOf returns the label values of a component of the resource, kind being Kind/name as for events
+func metricLabelValuesOf(kind string, namespace string, component string) []string {
+	kind, name, _ := strings.Cut(kind, "/")
+	return []string{kind, namespace, name, component}
+}
+
+// recordStatefulSetStatus sets the replica and rollout gauges of a component
+func recordStatefulSetStatus(kind string, namespace string, component string, desired int32, ready int32, rollout bool) {
+	labels := metricLabelValuesOf(kind, namespace, component)
+	desiredReplicasGauge.WithLabelValues(labels...).Set(float64(desired))
+	readyReplicasGauge.WithLabelValues(labels...).Set(float64(ready))
+	inProgress := 0.0
+	if rollout {
+		inProgress = 1
+	}
+	rolloutInProgressGauge.WithLabelValues(labels...).Set(inProgress)
+}
+
+// recordStatefulSetUpdate counts an applied StatefulSet, and the rolling restart it triggers on a config change
+func recordStatefulSetUpdate(kind string, namespace string, component string, restart bool) {
+	labels := metricLabelValuesOf(kind, namespace, component)
+	statefulSetUpdatesCounter.WithLabelValues(labels...).Inc()
+	if restart {
+		restartsTriggeredCounter.WithLabelValues(labels...).Inc()
+	}
+}
+
+// recordConfigMapUpdate counts an applied ConfigMap, whose revision is the current one from now on
+func recordConfigMapUpdate(kind string, namespace string, component string) {
+	configMapUpdatesCounter.WithLabelValues(metricLabelValuesOf(kind, namespace, component)...).Inc()
+	configRevisionAge.set(metricLabelValuesOf(kind, namespace, component), time.Now())
+}
+
+// recordConfigRevision records when the current revision of a ConfigMap was applied, unless known already
+func recordConfigRevision(kind string, namespace string, config *corev1.ConfigMap) {
+	labels := metricLabelValuesOf(kind, namespace, config.Name)
+	if configRevisionAge.has(labels) {
+		return
+	}
+	revision := config.CreationTimestamp.Time
+	// the update time annotation is formatted by time.Time.String, which may carry the monotonic clock reading
+	if updated, ok := config.Annotations[CFG_V2_ANNOTATION]; ok {
+		updated, _, _ = strings.Cut(updated, " m=")
+		if t, err := time.Parse("2006-01-02 15:04:05.999999999 -0700 MST", updated); err == nil {
+			revision = t
+		}
+	}
+	configRevisionAge.set(labels, revision)
+}
+
+// recordConfigValidation sets the validation gauge of a resource from the outcome of its validation
+func recordConfigValidation(kind string, namespace string, err error) {
+	failed := 0.0
+	if err != nil {
+		failed = 1
+	}
+	configValidationFailedGauge.WithLabelValues(metricLabelValuesOf(kind, namespace, "")...).Set(failed)
+}
+
+// observeReconcilePhase records the duration of a reconcile phase which began at start
+func observeReconcilePhase(kind string, namespace string, component string, phase string, start time.Time) {
+	labels := append(metricLabelValuesOf(kind, namespace, component), phase)
+	reconcilePhaseDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+}
+
+// deleteResourceMetrics drops all the metrics of a deleted resource
+func deleteResourceMetrics(kind string, namespace string) {
+	labels := metricLabelValuesOf(kind, namespace, "")
+	match := prometheus.Labels{"kind": labels[0], "namespace": labels[1], "name": labels[2]}
+	for _, vec := range []*prometheus.MetricVec{desiredReplicasGauge.MetricVec, readyReplicasGauge.MetricVec, rolloutInProgressGauge.MetricVec,
+		configValidationFailedGauge.MetricVec, statefulSetUpdatesCounter.MetricVec, configMapUpdatesCounter.MetricVec,
+		restartsTriggeredCounter.MetricVec, reconcilePhaseDuration.MetricVec} {
+		vec.DeletePartialMatch(match)
+	}
+	configRevisionAge.delete(labels[0], labels[1], labels[2])
+}
+
+// configRevisionCollector reports the age of the current revision of ConfigMaps, computed at scrape time
+type configRevisionCollector struct {
+	mu        sync.Mutex
+	desc      *prometheus.Desc
+	revisions map[[4]string]time.Time
+}
+
+func newConfigRevisionCollector() *configRevisionCollector {
+	return &configRevisionCollector{
+		desc: prometheus.NewDesc(prometheus.BuildFQName(METRICS_NAMESPACE, "", "config_revision_age_seconds"),
+			"Time since the current revision of a ConfigMap was applied", metricLabels, nil),
+		revisions: map[[4]string]time.Time{},
+	}
+}
+
+func (c *configRevisionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *configRevisionCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for labels, revision := range c.revisions {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, time.Since(revision).Seconds(), labels[:]...)
+	}
+}
+
+// set records the time the current revision was applied
+func (c *configRevisionCollector) set(labels []string, revision time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.revisions[[4]string(labels)] = revision
+}
+
+func (c *configRevisionCollector) has(labels []string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.revisions[[4]string(labels)]
+	return ok
+}
+
+func (c *configRevisionCollector) delete(kind string, namespace string, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for labels := range c.revisions {
+		if labels[0] == kind && labels[1] == namespace && labels[2] == name {
+			delete(c.revisions, labels)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// TestMetricLabelValuesOf verifies that the kind of events is split into the kind and name labels
+func TestMetricLabelValuesOf(t *testing.T) {
+	assert.Equal(t, []string{"HbaseCluster", "ns", "cluster", "hmaster"}, metricLabelValuesOf("HbaseCluster/cluster", "ns", "hmaster"))
+}
+
+// TestRecordStatefulSetMetrics verifies replica gauges, update counters and restarts triggered by a config change
+func TestRecordStatefulSetMetrics(t *testing.T) {
+	labels := metricLabelValuesOf("HbaseCluster/metrics-ss", "ns", "datanode")
+
+	recordStatefulSetUpdate("HbaseCluster/metrics-ss", "ns", "datanode", false)
+	recordStatefulSetUpdate("HbaseCluster/metrics-ss", "ns", "datanode", true)
+	recordStatefulSetStatus("HbaseCluster/metrics-ss", "ns", "datanode", 3, 1, true)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(statefulSetUpdatesCounter.WithLabelValues(labels...)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(restartsTriggeredCounter.WithLabelValues(labels...)))
+	assert.Equal(t, 3.0, testutil.ToFloat64(desiredReplicasGauge.WithLabelValues(labels...)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(readyReplicasGauge.WithLabelValues(labels...)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(rolloutInProgressGauge.WithLabelValues(labels...)))
+
+	recordStatefulSetStatus("HbaseCluster/metrics-ss", "ns", "datanode", 3, 3, false)
+	assert.Equal(t, 0.0, testutil.ToFloat64(rolloutInProgressGauge.WithLabelValues(labels...)))
+}
+
+// TestRecordConfigValidation verifies that the validation gauge follows the outcome of the last validation
+func TestRecordConfigValidation(t *testing.T) {
+	labels := metricLabelValuesOf("HbaseTenant/metrics-validation", "ns", "")
+
+	recordConfigValidation("HbaseTenant/metrics-validation", "ns", assert.AnError)
+	assert.Equal(t, 1.0, testutil.ToFloat64(configValidationFailedGauge.WithLabelValues(labels...)))
+
+	recordConfigValidation("HbaseTenant/metrics-validation", "ns", nil)
+	assert.Equal(t, 0.0, testutil.ToFloat64(configValidationFailedGauge.WithLabelValues(labels...)))
+}
+
+// TestRecordConfigRevision verifies that the revision age is taken from the update time annotation of the ConfigMap
+func TestRecordConfigRevision(t *testing.T) {
+	updated := time.Now().Add(-time.Hour)
+	config := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              "hbase-config",
+		CreationTimestamp: metav1.NewTime(updated.Add(-24 * time.Hour)),
+		Annotations:       map[string]string{CFG_V2_ANNOTATION: updated.String()},
+	}}
+
+	labels := [4]string(metricLabelValuesOf("HbaseCluster/metrics-revision", "ns", "hbase-config"))
+
+	recordConfigRevision("HbaseCluster/metrics-revision", "ns", config)
+	assert.WithinDuration(t, updated, configRevisionAge.revisions[labels], time.Millisecond)
+
+	// an applied ConfigMap becomes the current revision
+	recordConfigMapUpdate("HbaseCluster/metrics-revision", "ns", "hbase-config")
+	assert.WithinDuration(t, time.Now(), configRevisionAge.revisions[labels], time.Minute)
+	assert.Equal(t, 1.0, testutil.ToFloat64(configMapUpdatesCounter.WithLabelValues(labels[:]...)))
+
+	deleteResourceMetrics("HbaseCluster/metrics-revision", "ns")
+	assert.False(t, configRevisionAge.has(labels[:]))
+}
+
+// TestDeleteResourceMetrics verifies that the series of a deleted resource are dropped, and only those
+func TestDeleteResourceMetrics(t *testing.T) {
+	recordStatefulSetStatus("HbaseStandalone/metrics-deleted", "ns", "standalone", 1, 1, false)
+	recordStatefulSetStatus("HbaseStandalone/metrics-kept", "ns", "standalone", 1, 1, false)
+	before := testutil.CollectAndCount(desiredReplicasGauge)
+
+	deleteResourceMetrics("HbaseStandalone/metrics-deleted", "ns")
+
+	assert.Equal(t, before-1, testutil.CollectAndCount(desiredReplicasGauge))
+	assert.Equal(t, 1.0, testutil.ToFloat64(desiredReplicasGauge.WithLabelValues(
+		metricLabelValuesOf("HbaseStandalone/metrics-kept", "ns", "standalone")...)))
+}
+
+// TestMetricsRegistered verifies that the metrics are served by the controller-runtime registry
+func TestMetricsRegistered(t *testing.T) {
+	recordStatefulSetStatus("HbaseCluster/metrics-registered", "ns", "hmaster", 2, 2, false)
+	count, err := testutil.GatherAndCount(metrics.Registry, "hbase_operator_desired_replicas", "hbase_operator_ready_replicas")
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, count, 2)
+}
@@ -0,0 +1,338 @@
+package controllers
+
+import (
+	context "context"
+	fmt "fmt"
+	path "path"
+	strconv "strconv"
+	strings "strings"
+
+	logr "github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	unstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+// JMX_EXPORTER_PORT_NAME name of the port the exporters serve metrics on
+const JMX_EXPORTER_PORT_NAME = "jmx-metrics"
+
+// MONITORED_LABEL service label, with the name of the resource, selected by its ServiceMonitor
+const MONITORED_LABEL = "hbase-operator/monitored"
+
+const (
+	defaultJmxExporterPort      = 7071
+	defaultJmxExporterAgentPath = "/opt/jmx_exporter/jmx_prometheus_javaagent.jar"
+	jmxExporterConfigVolume     = "jmx-exporter-config"
+	jmxExporterConfigPath       = "/etc/jmx-exporter"
+	jmxExporterAgentVolume      = "jmx-exporter-agent"
+	jmxExporterAgentPath        = "/opt/jmx-exporter"
+)
+
+// daemonJavaOptsEnvs are the environment variables passed to the JVM of a single daemon, which hbase-env.sh and
+// hadoop-env.sh append to, by the daemon found in the name of its container. Unlike HBASE_OPTS or HADOOP_OPTS, they
+// are not passed to the CLIs run in the container. The standalone daemon runs as master
+var daemonJavaOptsEnvs = []struct {
+	daemon string
+	env    string
+}{
+	{"regionserver", "HBASE_REGIONSERVER_OPTS"},
+	{"master", "HBASE_MASTER_OPTS"},
+	{"standalone", "HBASE_MASTER_OPTS"},
+	{"zookeeper", "HBASE_ZOOKEEPER_OPTS"},
+	{"zkfc", "HDFS_ZKFC_OPTS"},
+	{"journalnode", "HDFS_JOURNALNODE_OPTS"},
+	{"namenode", "HDFS_NAMENODE_OPTS"},
+	{"datanode", "HDFS_DATANODE_OPTS"},
+}
+
+// defaultJmxExporterRules exports all MBeans
+const defaultJmxExporterRules = "lowercaseOutputName: true\nrules:\n- pattern: \".*\"\n"
+
+// monitoringGroupVersion of the prometheus-operator resources
+var monitoringGroupVersion = schema.GroupVersion{Group: "monitoring.coreos.com", Version: "v1"}
+
+func jmxExporterConfigName(crName string) string {
+	return crName + "-jmx-exporter"
+}
+
+func jmxExporterPortOf(m *kvstorev1.HbaseClusterMonitoring) int32 {
+	if m.Port > 0 {
+		return m.Port
+	}
+	return defaultJmxExporterPort
+}
+
+// isMonitored is true when the exporter runs alongside the component
+func isMonitored(m *kvstorev1.HbaseClusterMonitoring, d kvstorev1.HbaseClusterDeployment) bool {
+	return m != nil && !m.Components[d.Name].Disabled
+}
+
+// jmxExporterAgentContainer returns the container of the deployment the agent is loaded in, empty when not found
+func jmxExporterAgentContainer(m *kvstorev1.HbaseClusterMonitoring, d kvstorev1.HbaseClusterDeployment) string {
+	name := m.Components[d.Name].Container
+	for i, c := range d.Containers {
+		if (len(name) == 0 && i == 0) || c.Name == name {
+			return c.Name
+		}
+	}
+	return ""
+}
+
+// jmxExporterAgentEnv returns the environment variable the agent is set in for the container, the one of the daemon
+// it runs when javaOptsEnv is not set
+func jmxExporterAgentEnv(m *kvstorev1.HbaseClusterMonitoring, d kvstorev1.HbaseClusterDeployment, container string) (string, error) {
+	if env := m.Components[d.Name].JavaOptsEnv; len(env) > 0 {
+		return env, nil
+	}
+	for _, e := range daemonJavaOptsEnvs {
+		if strings.Contains(strings.ToLower(container), e.daemon) {
+			return e.env, nil
+		}
+	}
+	return "", fmt.Errorf("daemon of container %q of %s not known, javaOptsEnv must be set to load the jmx exporter agent", container, d.Name)
+}
+
+// withoutJmxExporterAgent prefixes a command run in a container with the removal of the agent from its environment,
+// so that the JVM of the command does not bind the port of the exporter of the daemon
+func withoutJmxExporterAgent(m *kvstorev1.HbaseClusterMonitoring, d kvstorev1.HbaseClusterDeployment, container string, command []string) []string {
+	if !isMonitored(m, d) || m.Mode == kvstorev1.HbaseMonitoringSidecar || jmxExporterAgentContainer(m, d) != container {
+		return command
+	}
+	env, err := jmxExporterAgentEnv(m, d, container)
+	if err != nil {
+		return command
+	}
+	return append([]string{"env", "-u", env}, command...)
+}
+
+// buildJmxExporterConfigMap returns the ConfigMap holding the exporter config of each monitored component
+func buildJmxExporterConfigMap(crName string, namespace string, m *kvstorev1.HbaseClusterMonitoring, deployments []kvstorev1.HbaseClusterDeployment) *corev1.ConfigMap {
+	data := map[string]string{}
+	for _, d := range deployments {
+		if !isMonitored(m, d) {
+			continue
+		}
+		rules := m.Components[d.Name].Rules
+		if len(rules) == 0 {
+			rules = m.Rules
+		}
+		if len(rules) == 0 {
+			rules = defaultJmxExporterRules
+		}
+		data[d.Name+".yaml"] = rules
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: jmxExporterConfigName(crName), Namespace: namespace},
+		Data:       data,
+	}
+}
+
+// injectJmxExporter runs the exporter in the pods of the StatefulSet, either as java agent of the daemon or as a
+// sidecar. Pods are annotated for scraping when annotate is set, for Prometheus without prometheus-operator
+func injectJmxExporter(ss *appsv1.StatefulSet, crName string, m *kvstorev1.HbaseClusterMonitoring, d kvstorev1.HbaseClusterDeployment, annotate bool) error {
+	if !isMonitored(m, d) {
+		return nil
+	}
+	port := jmxExporterPortOf(m)
+	config := path.Join(jmxExporterConfigPath, d.Name+".yaml")
+	pod := &ss.Spec.Template.Spec
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: jmxExporterConfigVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: jmxExporterConfigName(crName)}},
+		},
+	})
+	configMount := corev1.VolumeMount{Name: jmxExporterConfigVolume, MountPath: jmxExporterConfigPath, ReadOnly: true}
+	metricsPort := corev1.ContainerPort{Name: JMX_EXPORTER_PORT_NAME, ContainerPort: port}
+
+	if m.Mode == kvstorev1.HbaseMonitoringSidecar {
+		pod.Containers = append(pod.Containers, corev1.Container{
+			Name:         "jmx-exporter",
+			Image:        m.Image,
+			Args:         []string{strconv.Itoa(int(port)), config},
+			Ports:        []corev1.ContainerPort{metricsPort},
+			VolumeMounts: []corev1.VolumeMount{configMount},
+			Resources:    m.Resources,
+		})
+	} else {
+		component := m.Components[d.Name]
+		container := -1
+		for i, c := range pod.Containers {
+			if (len(component.Container) == 0 && i == 0) || c.Name == component.Container {
+				container = i
+				break
+			}
+		}
+		if container < 0 {
+			return fmt.Errorf("container %q of %s to load the jmx exporter agent in not found", component.Container, d.Name)
+		}
+		env, err := jmxExporterAgentEnv(m, d, pod.Containers[container].Name)
+		if err != nil {
+			return err
+		}
+
+		agent := m.AgentPath
+		if len(agent) == 0 {
+			agent = defaultJmxExporterAgentPath
+		}
+		if len(m.Image) > 0 {
+			// the agent is copied from the exporter image, the base image is used as is
+			pod.Volumes = append(pod.Volumes, corev1.Volume{
+				Name:         jmxExporterAgentVolume,
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			})
+			agentMount := corev1.VolumeMount{Name: jmxExporterAgentVolume, MountPath: jmxExporterAgentPath}
+			pod.InitContainers = append(pod.InitContainers, corev1.Container{
+				Name:         "jmx-exporter-agent",
+				Image:        m.Image,
+				Command:      []string{"cp", agent, jmxExporterAgentPath + "/"},
+				VolumeMounts: []corev1.VolumeMount{agentMount},
+				Resources:    m.Resources,
+			})
+			agent = path.Join(jmxExporterAgentPath, path.Base(agent))
+			pod.Containers[container].VolumeMounts = append(pod.Containers[container].VolumeMounts, agentMount)
+		}
+
+		c := &pod.Containers[container]
+		c.Env = append(c.Env, corev1.EnvVar{Name: env, Value: fmt.Sprintf("-javaagent:%s=%d:%s", agent, port, config)})
+		c.VolumeMounts = append(c.VolumeMounts, configMount)
+		c.Ports = append(c.Ports, metricsPort)
+	}
+
+	if annotate {
+		// annotations of the template are the ones of the deployment in the spec, which must be left untouched
+		annotations := map[string]string{}
+		for k, v := range ss.Spec.Template.Annotations {
+			annotations[k] = v
+		}
+		annotations["prometheus.io/scrape"] = "true"
+		annotations["prometheus.io/port"] = strconv.Itoa(int(port))
+		annotations["prometheus.io/path"] = "/metrics"
+		ss.Spec.Template.Annotations = annotations
+	}
+	return nil
+}
+
+// addMetricsPort adds the port of the exporters to the service of the resource when one of its components is
+// monitored, along with the label its ServiceMonitor selects
+func addMetricsPort(svc *corev1.Service, crName string, m *kvstorev1.HbaseClusterMonitoring, deployments []kvstorev1.HbaseClusterDeployment) {
+	for _, d := range deployments {
+		if !isMonitored(m, d) {
+			continue
+		}
+		port := jmxExporterPortOf(m)
+		svc.Spec.Ports = append(svc.Spec.Ports, corev1.ServicePort{
+			Name:       JMX_EXPORTER_PORT_NAME,
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+			Protocol:   corev1.ProtocolTCP,
+		})
+		// labels of the service are the ones of the spec, which must be left untouched
+		labels := map[string]string{MONITORED_LABEL: crName}
+		for k, v := range svc.Labels {
+			labels[k] = v
+		}
+		svc.Labels = labels
+		return
+	}
+}
+
+// buildMonitor returns the ServiceMonitor or PodMonitor scraping the exporters of the resource
+func buildMonitor(crName string, namespace string, m *kvstorev1.HbaseClusterMonitoring) *unstructured.Unstructured {
+	endpoint := map[string]interface{}{"port": JMX_EXPORTER_PORT_NAME, "path": "/metrics"}
+	if len(m.Interval) > 0 {
+		endpoint["interval"] = m.Interval
+	}
+
+	kind := m.MonitorKind
+	if len(kind) == 0 {
+		kind = kvstorev1.HbaseServiceMonitor
+	}
+	var spec map[string]interface{}
+	if kind == kvstorev1.HbasePodMonitor {
+		selector := map[string]interface{}{}
+		for k, v := range getSharedLabelsMap(crName, nil) {
+			selector[k] = v
+		}
+		spec = map[string]interface{}{
+			"selector":            map[string]interface{}{"matchLabels": selector},
+			"podMetricsEndpoints": []interface{}{endpoint},
+		}
+	} else {
+		spec = map[string]interface{}{
+			"selector":  map[string]interface{}{"matchLabels": map[string]interface{}{MONITORED_LABEL: crName}},
+			"endpoints": []interface{}{endpoint},
+		}
+	}
+
+	monitor := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	monitor.SetGroupVersionKind(monitoringGroupVersion.WithKind(string(kind)))
+	monitor.SetName(crName)
+	monitor.SetNamespace(namespace)
+	monitor.SetLabels(m.Labels)
+	return monitor
+}
+
+// reconcileMonitor creates or updates the ServiceMonitor or PodMonitor. It returns false, without error, when
+// prometheus-operator is not installed
+func reconcileMonitor(ctx context.Context, log logr.Logger, monitor *unstructured.Unstructured, cl client.Client) (bool, error) {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(monitor.GroupVersionKind())
+	err := cl.Get(ctx, types.NamespacedName{Name: monitor.GetName(), Namespace: monitor.GetNamespace()}, existing)
+	if meta.IsNoMatchError(err) {
+		log.V(1).Info("prometheus-operator is not installed, pods are annotated for scraping instead", "Kind", monitor.GetKind())
+		return false, nil
+	}
+	if errors.IsNotFound(err) {
+		log.Info("Creating a new "+monitor.GetKind(), "Namespace", monitor.GetNamespace(), "Name", monitor.GetName())
+		return true, cl.Create(ctx, monitor)
+	}
+	if err != nil {
+		log.Error(err, "Failed to get "+monitor.GetKind(), "Namespace", monitor.GetNamespace(), "Name", monitor.GetName())
+		return true, err
+	}
+
+	if equality.Semantic.DeepEqual(existing.Object["spec"], monitor.Object["spec"]) &&
+		equality.Semantic.DeepEqual(existing.GetLabels(), monitor.GetLabels()) {
+		return true, nil
+	}
+	log.Info("Updating "+monitor.GetKind(), "Namespace", monitor.GetNamespace(), "Name", monitor.GetName())
+	monitor.SetResourceVersion(existing.GetResourceVersion())
+	return true, cl.Update(ctx, monitor)
+}
+
+// reconcileMonitoring reconciles the exporter config and the monitor of the resource. It returns whether pods are to
+// be annotated for scraping, as prometheus-operator is not installed
+func reconcileMonitoring(ctx context.Context, log logr.Logger, owner client.Object, m *kvstorev1.HbaseClusterMonitoring,
+	deployments []kvstorev1.HbaseClusterDeployment, recorder record.EventRecorder, scheme *runtime.Scheme, cl client.Client) (bool, error) {
+	if m == nil {
+		return false, nil
+	}
+	cfg := buildJmxExporterConfigMap(owner.GetName(), owner.GetNamespace(), m, deployments)
+	ctrl.SetControllerReference(owner, cfg, scheme)
+	// the agent reloads its config once kubelet synced the ConfigMap, nothing to wait for
+	if _, err := reconcileConfigMap(ctx, log, owner.GetNamespace(), cfg, owner, recorder, cl); err != nil {
+		return false, err
+	}
+
+	monitor := buildMonitor(owner.GetName(), owner.GetNamespace(), m)
+	ctrl.SetControllerReference(owner, monitor, scheme)
+	installed, err := reconcileMonitor(ctx, log, monitor, cl)
+	if err != nil {
+		recordWarning(recorder, owner, REASON_MONITOR_UPDATE_FAILED, err)
+	}
+	return !installed, err
+}
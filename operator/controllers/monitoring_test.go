@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newMonitoredDeployment(name string) kvstorev1.HbaseClusterDeployment {
+	return kvstorev1.HbaseClusterDeployment{
+		Name: name, Size: 1, TerminationGracePeriodSeconds: 30,
+		Annotations: map[string]string{"team": "kv"},
+		Containers: []kvstorev1.HbaseClusterContainer{
+			{Name: name, Command: []string{"/bin/start"}, CpuLimit: "1", CpuRequest: "1", MemoryLimit: "1Gi", MemoryRequest: "1Gi"},
+			{Name: "log-shipper", Command: []string{"/bin/ship"}, CpuLimit: "1", CpuRequest: "1", MemoryLimit: "1Gi", MemoryRequest: "1Gi"},
+		},
+	}
+}
+
+// TestBuildJmxExporterConfigMap verifies component rules win over the shared ones, which default to exporting all MBeans
+func TestBuildJmxExporterConfigMap(t *testing.T) {
+	m := &kvstorev1.HbaseClusterMonitoring{Components: map[string]kvstorev1.HbaseComponentMonitoring{
+		"hmaster":  {Rules: "rules: [master]"},
+		"datanode": {Disabled: true},
+	}}
+	deployments := []kvstorev1.HbaseClusterDeployment{{Name: "hmaster"}, {Name: "datanode"}, {Name: "namenode"}}
+
+	cfg := buildJmxExporterConfigMap("cluster", "test-ns", m, deployments)
+	assert.Equal(t, "cluster-jmx-exporter", cfg.Name)
+	assert.Equal(t, map[string]string{"hmaster.yaml": "rules: [master]", "namenode.yaml": defaultJmxExporterRules}, cfg.Data)
+
+	m.Rules = "rules: [shared]"
+	cfg = buildJmxExporterConfigMap("cluster", "test-ns", m, deployments)
+	assert.Equal(t, "rules: [shared]", cfg.Data["namenode.yaml"])
+}
+
+// TestInjectJmxExporter_Agent verifies the agent is copied from the exporter image and loaded in the daemon container
+func TestInjectJmxExporter_Agent(t *testing.T) {
+	d := newMonitoredDeployment("hmaster")
+	config := kvstorev1.HbaseClusterConfiguration{HbaseConfigName: "hbase-cfg", HadoopConfigName: "hadoop-cfg"}
+	ss, err := buildStatefulSet("cluster", "test-ns", "hbase:2.5", false, config, "", 1000, d, ctrl.Log, true)
+	assert.NoError(t, err)
+	m := &kvstorev1.HbaseClusterMonitoring{Image: "jmx-exporter:1.0", AgentPath: "/jars/agent.jar"}
+
+	assert.NoError(t, injectJmxExporter(ss, "cluster", m, d, false))
+	pod := ss.Spec.Template.Spec
+	assert.Len(t, pod.InitContainers, 1)
+	assert.Equal(t, []string{"cp", "/jars/agent.jar", "/opt/jmx-exporter/"}, pod.InitContainers[0].Command)
+	daemon := pod.Containers[0]
+	assert.Equal(t, []corev1.EnvVar{{Name: "HBASE_MASTER_OPTS", Value: "-javaagent:/opt/jmx-exporter/agent.jar=7071:/etc/jmx-exporter/hmaster.yaml"}}, daemon.Env)
+	assert.Contains(t, daemon.Ports, corev1.ContainerPort{Name: JMX_EXPORTER_PORT_NAME, ContainerPort: 7071})
+	assert.Contains(t, daemon.VolumeMounts, corev1.VolumeMount{Name: jmxExporterAgentVolume, MountPath: jmxExporterAgentPath})
+	assert.Empty(t, pod.Containers[1].Env)
+	assert.NotContains(t, ss.Spec.Template.Annotations, "prometheus.io/scrape")
+}
+
+// TestInjectJmxExporter_AgentInBaseImage verifies the agent of the base image is loaded in the selected container through its env
+func TestInjectJmxExporter_AgentInBaseImage(t *testing.T) {
+	d := newMonitoredDeployment("namenode")
+	config := kvstorev1.HbaseClusterConfiguration{HbaseConfigName: "hbase-cfg", HadoopConfigName: "hadoop-cfg"}
+	ss, err := buildStatefulSet("cluster", "test-ns", "hbase:2.5", false, config, "", 1000, d, ctrl.Log, true)
+	assert.NoError(t, err)
+	m := &kvstorev1.HbaseClusterMonitoring{Port: 9404, Components: map[string]kvstorev1.HbaseComponentMonitoring{
+		"namenode": {Container: "log-shipper", JavaOptsEnv: "HADOOP_OPTS"},
+	}}
+
+	assert.NoError(t, injectJmxExporter(ss, "cluster", m, d, true))
+	pod := ss.Spec.Template.Spec
+	assert.Empty(t, pod.InitContainers)
+	assert.Empty(t, pod.Containers[0].Env)
+	assert.Equal(t, []corev1.EnvVar{{Name: "HADOOP_OPTS",
+		Value: "-javaagent:/opt/jmx_exporter/jmx_prometheus_javaagent.jar=9404:/etc/jmx-exporter/namenode.yaml"}}, pod.Containers[1].Env)
+
+	// pods are annotated for scraping, without touching the annotations of the spec
+	assert.Equal(t, "9404", ss.Spec.Template.Annotations["prometheus.io/port"])
+	assert.Equal(t, "kv", ss.Spec.Template.Annotations["team"])
+	assert.Equal(t, map[string]string{"team": "kv"}, d.Annotations)
+
+	m.Components["namenode"] = kvstorev1.HbaseComponentMonitoring{Container: "missing"}
+	assert.Error(t, injectJmxExporter(ss, "cluster", m, d, false))
+}
+
+// TestJmxExporterAgentEnv verifies the agent is set in the variable of the daemon of the container, unless javaOptsEnv
+// is set, and removed from the environment of the commands run in that container.
+func TestJmxExporterAgentEnv(t *testing.T) {
+	m := &kvstorev1.HbaseClusterMonitoring{}
+	for container, env := range map[string]string{"regionserver": "HBASE_REGIONSERVER_OPTS", "hmaster": "HBASE_MASTER_OPTS",
+		"zookeeper": "HBASE_ZOOKEEPER_OPTS", "namenode": "HDFS_NAMENODE_OPTS", "datanode": "HDFS_DATANODE_OPTS",
+		"journalnode": "HDFS_JOURNALNODE_OPTS", "zkfc": "HDFS_ZKFC_OPTS", "standalone": "HBASE_MASTER_OPTS"} {
+		actual, err := jmxExporterAgentEnv(m, newMonitoredDeployment(container), container)
+		assert.NoError(t, err)
+		assert.Equal(t, env, actual)
+	}
+	_, err := jmxExporterAgentEnv(m, newMonitoredDeployment("hbase"), "hbase")
+	assert.Error(t, err)
+
+	d := newMonitoredDeployment("nn")
+	d.Containers[0].Name = "namenode"
+	command := []string{"hdfs", "haadmin", "-failover", "nn0", "nn1"}
+	assert.Equal(t, append([]string{"env", "-u", "HDFS_NAMENODE_OPTS"}, command...), withoutJmxExporterAgent(m, d, "namenode", command))
+	assert.Equal(t, command, withoutJmxExporterAgent(m, d, "log-shipper", command))
+	assert.Equal(t, command, withoutJmxExporterAgent(nil, d, "namenode", command))
+	m.Components = map[string]kvstorev1.HbaseComponentMonitoring{"nn": {JavaOptsEnv: "HADOOP_OPTS"}}
+	assert.Equal(t, append([]string{"env", "-u", "HADOOP_OPTS"}, command...), withoutJmxExporterAgent(m, d, "namenode", command))
+}
+
+// TestInjectJmxExporter_Sidecar verifies the standalone exporter runs next to the daemon, and disabled components are left as is
+func TestInjectJmxExporter_Sidecar(t *testing.T) {
+	d := newMonitoredDeployment("datanode")
+	config := kvstorev1.HbaseClusterConfiguration{HbaseConfigName: "hbase-cfg", HadoopConfigName: "hadoop-cfg"}
+	ss, err := buildStatefulSet("cluster", "test-ns", "hbase:2.5", false, config, "", 1000, d, ctrl.Log, true)
+	assert.NoError(t, err)
+	m := &kvstorev1.HbaseClusterMonitoring{Mode: kvstorev1.HbaseMonitoringSidecar, Image: "jmx-exporter-server:1.0"}
+
+	assert.NoError(t, injectJmxExporter(ss, "cluster", m, d, false))
+	pod := ss.Spec.Template.Spec
+	assert.Len(t, pod.Containers, 3)
+	sidecar := pod.Containers[2]
+	assert.Equal(t, "jmx-exporter-server:1.0", sidecar.Image)
+	assert.Equal(t, []string{"7071", "/etc/jmx-exporter/datanode.yaml"}, sidecar.Args)
+	assert.Empty(t, pod.Containers[0].Env)
+
+	ss, _ = buildStatefulSet("cluster", "test-ns", "hbase:2.5", false, config, "", 1000, d, ctrl.Log, true)
+	m.Components = map[string]kvstorev1.HbaseComponentMonitoring{"datanode": {Disabled: true}}
+	assert.NoError(t, injectJmxExporter(ss, "cluster", m, d, true))
+	assert.Len(t, ss.Spec.Template.Spec.Containers, 2)
+	assert.NotContains(t, ss.Spec.Template.Annotations, "prometheus.io/scrape")
+}
+
+// TestAddMetricsPort verifies the metrics port and monitored label are added once, when a component is monitored
+func TestAddMetricsPort(t *testing.T) {
+	deployments := []kvstorev1.HbaseClusterDeployment{newMonitoredDeployment("hmaster"), newMonitoredDeployment("datanode")}
+	labels := map[string]string{"team": "kv"}
+
+	svc := buildService("cluster", "cluster", "test-ns", labels, nil, deployments, true)
+	addMetricsPort(svc, "cluster", nil, deployments)
+	assert.Empty(t, svc.Spec.Ports)
+	assert.NotContains(t, svc.Labels, MONITORED_LABEL)
+
+	addMetricsPort(svc, "cluster", &kvstorev1.HbaseClusterMonitoring{}, deployments)
+	assert.Len(t, svc.Spec.Ports, 1)
+	assert.Equal(t, JMX_EXPORTER_PORT_NAME, svc.Spec.Ports[0].Name)
+	assert.Equal(t, "cluster", svc.Labels[MONITORED_LABEL])
+	assert.Equal(t, map[string]string{"team": "kv"}, labels)
+}
+
+// TestBuildMonitor verifies the ServiceMonitor selects the service of the resource and the PodMonitor its pods
+func TestBuildMonitor(t *testing.T) {
+	m := &kvstorev1.HbaseClusterMonitoring{Interval: "30s", Labels: map[string]string{"release": "prometheus"}}
+
+	monitor := buildMonitor("cluster", "test-ns", m)
+	assert.Equal(t, "ServiceMonitor", monitor.GetKind())
+	assert.Equal(t, "monitoring.coreos.com/v1", monitor.GetAPIVersion())
+	assert.Equal(t, map[string]string{"release": "prometheus"}, monitor.GetLabels())
+	selector, _, _ := unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{MONITORED_LABEL: "cluster"}, selector)
+	endpoints, _, _ := unstructured.NestedSlice(monitor.Object, "spec", "endpoints")
+	assert.Equal(t, []interface{}{map[string]interface{}{"port": JMX_EXPORTER_PORT_NAME, "path": "/metrics", "interval": "30s"}}, endpoints)
+
+	m.MonitorKind = kvstorev1.HbasePodMonitor
+	monitor = buildMonitor("cluster", "test-ns", m)
+	assert.Equal(t, "PodMonitor", monitor.GetKind())
+	selector, _, _ = unstructured.NestedStringMap(monitor.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, getSharedLabelsMap("cluster", nil), selector)
+}
+
+// TestReconcileMonitor verifies the monitor is created, updated on drift, and skipped without prometheus-operator
+func TestReconcileMonitor(t *testing.T) {
+	ctx := context.TODO()
+	key := types.NamespacedName{Name: "cluster", Namespace: "test-ns"}
+	monitor := buildMonitor("cluster", "test-ns", &kvstorev1.HbaseClusterMonitoring{})
+
+	t.Run("not installed", func(t *testing.T) {
+		mockClient := new(K8sMockClient)
+		mockClient.On("Get", ctx, key, mock.AnythingOfType("*unstructured.Unstructured")).
+			Return(&meta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "monitoring.coreos.com", Kind: "ServiceMonitor"}})
+		installed, err := reconcileMonitor(ctx, ctrl.Log, monitor.DeepCopy(), mockClient)
+		assert.NoError(t, err)
+		assert.False(t, installed)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("created", func(t *testing.T) {
+		mockClient := new(K8sMockClient)
+		mockClient.On("Get", ctx, key, mock.AnythingOfType("*unstructured.Unstructured")).
+			Return(errors.NewNotFound(schema.GroupResource{Resource: "servicemonitors"}, "cluster"))
+		mockClient.On("Create", ctx, monitor, []client.CreateOption(nil)).Return(nil)
+		installed, err := reconcileMonitor(ctx, ctrl.Log, monitor.DeepCopy(), mockClient)
+		assert.NoError(t, err)
+		assert.True(t, installed)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		mockClient := new(K8sMockClient)
+		mockClient.On("Get", ctx, key, mock.AnythingOfType("*unstructured.Unstructured")).
+			Run(func(args mock.Arguments) {
+				args.Get(2).(*unstructured.Unstructured).Object = monitor.DeepCopy().Object
+			}).Return(nil)
+		installed, err := reconcileMonitor(ctx, ctrl.Log, monitor.DeepCopy(), mockClient)
+		assert.NoError(t, err)
+		assert.True(t, installed)
+		mockClient.AssertExpectations(t)
+	})
+
+	t.Run("updated", func(t *testing.T) {
+		mockClient := new(K8sMockClient)
+		mockClient.On("Get", ctx, key, mock.AnythingOfType("*unstructured.Unstructured")).
+			Run(func(args mock.Arguments) {
+				existing := monitor.DeepCopy()
+				existing.SetResourceVersion("7")
+				unstructured.SetNestedField(existing.Object, "other", "spec", "selector", "matchLabels", MONITORED_LABEL)
+				args.Get(2).(*unstructured.Unstructured).Object = existing.Object
+			}).Return(nil)
+		mockClient.On("Update", ctx, mock.MatchedBy(func(u *unstructured.Unstructured) bool {
+			return u.GetResourceVersion() == "7"
+		}), []client.UpdateOption(nil)).Return(nil)
+		installed, err := reconcileMonitor(ctx, ctrl.Log, monitor.DeepCopy(), mockClient)
+		assert.NoError(t, err)
+		assert.True(t, installed)
+		mockClient.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	bytes "bytes"
+	context "context"
+	json "encoding/json"
+	errs "errors"
+	fmt "fmt"
+	http "net/http"
+	strings "strings"
+	time "time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	types "k8s.io/apimachinery/pkg/types"
+	kubernetes "k8s.io/client-go/kubernetes"
+	scheme "k8s.io/client-go/kubernetes/scheme"
+	rest "k8s.io/client-go/rest"
+	record "k8s.io/client-go/tools/record"
+	remotecommand "k8s.io/client-go/tools/remotecommand"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// NAMENODE_FAILOVER_ANNOTATION HbaseCluster annotation with the pod of the standby namenode to fail over to. It is
+// removed once the failover is done
+const NAMENODE_FAILOVER_ANNOTATION = "hbase-operator/namenode-failover-to"
+
+const (
+	NAMENODE_ACTIVE  = "active"
+	NAMENODE_STANDBY = "standby"
+	NAMENODE_UNKNOWN = "unknown"
+)
+
+// PodExecutor runs commands in a container of a pod
+type PodExecutor interface {
+	// Exec runs the command and returns its stdout, and its stderr along with the error when it fails
+	Exec(ctx context.Context, namespace string, pod string, container string, command []string) (string, error)
+}
+
+// remotePodExecutor runs commands through the exec subresource of pods
+type remotePodExecutor struct {
+	config    *rest.Config
+	clientset kubernetes.Interface
+}
+
+// NewPodExecutor returns a PodExecutor using the given config to reach the API server
+func NewPodExecutor(config *rest.Config) (PodExecutor, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return &remotePodExecutor{config: config, clientset: clientset}, nil
+}
+
+func (e *remotePodExecutor) Exec(ctx context.Context, namespace string, pod string, container string, command []string) (string, error) {
+	req := e.clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(pod).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{Container: container, Command: command, Stdout: true, Stderr: true}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(e.config, http.MethodPost, req.URL())
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	if err = executor.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return stdout.String(), fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// namenode is a pod of the namenode deployment, along with its id and addresses in the nameservice
+type namenode struct {
+	Pod         string
+	ID          string
+	HTTPAddress string
+}
+
+// isNamenodeHA tells whether the deployment is the namenode deployment rolled out according to the HA state
+func isNamenodeHA(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) bool {
+	return c.Spec.NamenodeHA != nil && c.Spec.ExternalHDFS == nil &&
+		c.Spec.Deployments.Namenode.Size > 0 && d.Name == c.Spec.Deployments.Namenode.Name
+}
+
+// namenodesOf returns the nameservice and the namenodes of the cluster. Pods are matched to namenode ids by the host
+// of their dfs.namenode.rpc-address in hdfs-site.xml, which is expected to start with the name of the pod
+func namenodesOf(c *kvstorev1.HbaseCluster, hadoopConfig map[string]string) (string, []namenode, error) {
+	properties, _ := parseConfigProperties("hdfs-site.xml", hadoopConfig["hdfs-site.xml"])
+	nameservice := c.Spec.NamenodeHA.Nameservice
+	if len(nameservice) == 0 {
+		nameservice = strings.TrimSpace(strings.Split(properties["dfs.nameservices"], ",")[0])
+	}
+	if len(nameservice) == 0 {
+		return "", nil, errs.New("Config: hdfs-site.xml. dfs.nameservices is not set")
+	}
+	httpKey := "dfs.namenode.http-address."
+	if c.Spec.NamenodeHA.Scheme == "https" {
+		httpKey = "dfs.namenode.https-address."
+	}
+
+	namenodes := []namenode{}
+	for i := int32(0); i < c.Spec.Deployments.Namenode.Size; i++ {
+		nn := namenode{Pod: fmt.Sprintf("%s-%d", c.Spec.Deployments.Namenode.Name, i)}
+		for _, id := range strings.Split(properties["dfs.ha.namenodes."+nameservice], ",") {
+			id = strings.TrimSpace(id)
+			host := strings.Split(properties["dfs.namenode.rpc-address."+nameservice+"."+id], ":")[0]
+			if len(id) > 0 && (host == nn.Pod || strings.HasPrefix(host, nn.Pod+".")) {
+				nn.ID = id
+				nn.HTTPAddress = properties[httpKey+nameservice+"."+id]
+			}
+		}
+		if len(nn.ID) == 0 {
+			return "", nil, errs.New("Config: hdfs-site.xml. No namenode of " + nameservice + " has an rpc address on pod " + nn.Pod)
+		}
+		namenodes = append(namenodes, nn)
+	}
+	return nameservice, namenodes, nil
+}
+
+// namenodeHAState reads the HA state of a namenode from the NameNodeStatus bean of its jmx servlet
+func namenodeHAState(ctx context.Context, scheme string, httpAddress string) (string, error) {
+	if len(scheme) == 0 {
+		scheme = "http"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+httpAddress+"/jmx?qry=Hadoop:service=NameNode,name=NameNodeStatus", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := (&http.Client{Timeout: time.Second * 10}).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GET /jmx of %s failed with status %d", httpAddress, resp.StatusCode)
+	}
+
+	out := struct {
+		Beans []struct {
+			State string `json:"State"`
+		} `json:"beans"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if len(out.Beans) == 0 || len(out.Beans[0].State) == 0 {
+		return "", errs.New("NameNodeStatus bean not found on " + httpAddress)
+	}
+	return strings.ToLower(out.Beans[0].State), nil
+}
+
+// namenodeContainer returns the container haadmin is run in
+func namenodeContainer(c *kvstorev1.HbaseCluster) string {
+	if len(c.Spec.NamenodeHA.Container) > 0 || len(c.Spec.Deployments.Namenode.Containers) == 0 {
+		return c.Spec.NamenodeHA.Container
+	}
+	return c.Spec.Deployments.Namenode.Containers[0].Name
+}
+
+// failoverNamenode gracefully fails over from the active namenode to the standby one with haadmin, run in the standby
+func failoverNamenode(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, executor PodExecutor, nameservice string, from namenode, to namenode) error {
+	if executor == nil {
+		return errs.New("Failover of namenodes needs the operator to exec in pods")
+	}
+	command := []string{"hdfs", "haadmin", "-ns", nameservice, "-failover", from.ID, to.ID}
+	command = withoutJmxExporterAgent(c.Spec.Monitoring, c.Spec.Deployments.Namenode, namenodeContainer(c), command)
+	log.Info("Failing over namenode", "From", from.Pod, "To", to.Pod, "Command", strings.Join(command, " "))
+	_, err := executor.Exec(ctx, c.Namespace, to.Pod, namenodeContainer(c), command)
+	return err
+}
+
+// reconcileNamenodeHA records the HA state of the namenodes, applies failovers requested through the annotation, and
+// rolls out the namenode StatefulSet, whose update strategy is OnDelete: outdated standby namenodes are restarted
+// first, then the active one after failing over to an updated standby
+func reconcileNamenodeHA(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, hadoopConfig map[string]string,
+	executor PodExecutor, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	nameservice, namenodes, err := namenodesOf(c, hadoopConfig)
+	if err != nil {
+		log.Error(err, "Failed to find namenodes")
+		recordWarning(recorder, c, REASON_CONFIG_VALIDATE_FAILED, err)
+		return ctrl.Result{}, err
+	}
+
+	pods := map[string]*corev1.Pod{}
+	states := map[string]string{}
+	statuses := []kvstorev1.NamenodeStatus{}
+	var active, standby *namenode
+	for i, nn := range namenodes {
+		pod := &corev1.Pod{}
+		if err = cl.Get(ctx, types.NamespacedName{Name: nn.Pod, Namespace: c.Namespace}, pod); err == nil {
+			pods[nn.Pod] = pod
+		} else if !errors.IsNotFound(err) {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		state, err := namenodeHAState(ctx, c.Spec.NamenodeHA.Scheme, nn.HTTPAddress)
+		if err != nil {
+			log.Info("Failed to get HA state of namenode", "Pod", nn.Pod, "Error", err.Error())
+			state = NAMENODE_UNKNOWN
+		}
+		states[nn.Pod] = state
+		statuses = append(statuses, kvstorev1.NamenodeStatus{Pod: nn.Pod, ID: nn.ID, State: state})
+		switch state {
+		case NAMENODE_ACTIVE:
+			active = &namenodes[i]
+		case NAMENODE_STANDBY:
+			if standby == nil {
+				standby = &namenodes[i]
+			}
+		}
+	}
+
+	activePod := ""
+	if active != nil {
+		activePod = active.Pod
+	}
+	if activePod != c.Status.ActiveNamenode || !equality.Semantic.DeepEqual(statuses, c.Status.Namenodes) {
+		c.Status.ActiveNamenode = activePod
+		c.Status.Namenodes = statuses
+		if err = cl.Status().Update(ctx, c); err != nil {
+			log.Error(err, "Failed to update HbaseCluster status with the namenodes")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+
+	if target, ok := c.Annotations[NAMENODE_FAILOVER_ANNOTATION]; ok {
+		return reconcileNamenodeFailover(ctx, log, c, target, nameservice, namenodes, states, active, executor, recorder, cl)
+	}
+
+	ss := &appsv1.StatefulSet{}
+	if err = cl.Get(ctx, types.NamespacedName{Name: c.Spec.Deployments.Namenode.Name, Namespace: c.Namespace}, ss); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, client.IgnoreNotFound(err)
+	}
+	outdated := []namenode{}
+	notReady := []string{}
+	for _, nn := range namenodes {
+		pod, ok := pods[nn.Pod]
+		if !ok || !isPodReady(*pod) {
+			notReady = append(notReady, nn.Pod)
+			continue
+		}
+		if len(ss.Status.UpdateRevision) > 0 && pod.Labels[appsv1.StatefulSetRevisionLabel] != ss.Status.UpdateRevision {
+			outdated = append(outdated, nn)
+		}
+	}
+	if len(outdated) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if len(notReady) > 0 {
+		// a restarted namenode has to be back before the next one is restarted
+		log.Info("Waiting for namenodes to be ready", "Pods", notReady)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	// a namenode whose state is not known may be the active one, so only known standby namenodes are restarted, and
+	// only while the active one is known
+	if active == nil {
+		log.Info("Waiting for an active namenode", "States", states)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	for _, nn := range outdated {
+		if states[nn.Pod] != NAMENODE_STANDBY {
+			continue
+		}
+		log.Info("Restarting namenode", "Pod", nn.Pod, "State", states[nn.Pod])
+		if err = cl.Delete(ctx, pods[nn.Pod]); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, client.IgnoreNotFound(err)
+		}
+		recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_NAMENODE_RESTARTED, "Restarted "+states[nn.Pod]+" namenode "+nn.Pod)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	for _, nn := range outdated {
+		if states[nn.Pod] != NAMENODE_ACTIVE {
+			log.Info("Waiting for the HA state of outdated namenode", "Pod", nn.Pod, "State", states[nn.Pod])
+			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+	}
+
+	// only the active namenode is outdated, it is restarted once an updated standby took over
+	if standby == nil {
+		err = errs.New("No standby namenode to fail over to before restarting active namenode " + outdated[0].Pod)
+		recordWarning(recorder, c, REASON_NAMENODE_FAILOVER_FAILED, err)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	if err = failoverNamenode(ctx, log, c, executor, nameservice, *active, *standby); err != nil {
+		log.Error(err, "Failed to fail over namenode", "From", active.Pod, "To", standby.Pod)
+		recordWarning(recorder, c, REASON_NAMENODE_FAILOVER_FAILED, err)
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_NAMENODE_FAILED_OVER, "Failed over from "+active.Pod+" to "+standby.Pod+" to restart it")
+	return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+}
+
+// reconcileNamenodeFailover fails over to the standby namenode requested through the annotation, and removes it once
+// the namenode is active or the request can not be applied
+func reconcileNamenodeFailover(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, target string, nameservice string,
+	namenodes []namenode, states map[string]string, active *namenode, executor PodExecutor, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	var to *namenode
+	for i, nn := range namenodes {
+		if nn.Pod == target {
+			to = &namenodes[i]
+		}
+	}
+
+	var err error
+	switch {
+	case to == nil:
+		err = errs.New("Namenode " + target + " requested by " + NAMENODE_FAILOVER_ANNOTATION + " is not a namenode of the cluster")
+	case states[target] == NAMENODE_ACTIVE:
+		log.Info("Namenode is already active", "Pod", target)
+	case states[target] != NAMENODE_STANDBY || active == nil:
+		log.Info("Waiting for namenodes to fail over", "To", target, "State", states[target])
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	default:
+		if err = failoverNamenode(ctx, log, c, executor, nameservice, *active, *to); err == nil {
+			recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_NAMENODE_FAILED_OVER, "Failed over from "+active.Pod+" to "+target)
+		}
+	}
+	if err != nil {
+		log.Error(err, "Failed to fail over namenode", "To", target)
+		recordWarning(recorder, c, REASON_NAMENODE_FAILOVER_FAILED, err)
+	}
+
+	delete(c.Annotations, NAMENODE_FAILOVER_ANNOTATION)
+	if updateErr := cl.Update(ctx, c); updateErr != nil {
+		log.Error(updateErr, "Failed to remove annotation", "Annotation", NAMENODE_FAILOVER_ANNOTATION)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, updateErr
+	}
+	return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// MockPodExecutor represents the mock executor of commands in pods
+type MockPodExecutor struct {
+	mock.Mock
+}
+
+func (m *MockPodExecutor) Exec(ctx context.Context, namespace string, pod string, container string, command []string) (string, error) {
+	args := m.Called(ctx, namespace, pod, container, command)
+	return args.String(0), args.Error(1)
+}
+
+// startNamenodeStandIn serves the NameNodeStatus bean with the given state, and returns its address
+func startNamenodeStandIn(t *testing.T, state string) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Hadoop:service=NameNode,name=NameNodeStatus", r.URL.Query().Get("qry"))
+		w.Write([]byte(`{"beans":[{"name":"Hadoop:service=NameNode,name=NameNodeStatus","State":"` + state + `"}]}`))
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func newNamenodeHACluster(httpAddresses ...string) (*kvstorev1.HbaseCluster, map[string]string) {
+	c := &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{Namenode: kvstorev1.HbaseClusterDeployment{Name: "nn", Size: int32(len(httpAddresses)),
+				Containers: []kvstorev1.HbaseClusterContainer{{Name: "namenode"}}}},
+			NamenodeHA: &kvstorev1.HbaseClusterNamenodeHA{},
+		},
+	}
+	hdfsSite := hadoopConfiguration{Properties: []hadoopConfigProperty{{Name: "dfs.nameservices", Value: "hbase"}, {Name: "dfs.ha.namenodes.hbase", Value: "nn0,nn1"}}}
+	for i, address := range httpAddresses {
+		id := "nn" + strconv.Itoa(i)
+		hdfsSite.Properties = append(hdfsSite.Properties,
+			hadoopConfigProperty{Name: "dfs.namenode.rpc-address.hbase." + id, Value: "nn-" + strconv.Itoa(i) + ".test.test-namespace:8020"},
+			hadoopConfigProperty{Name: "dfs.namenode.http-address.hbase." + id, Value: address})
+	}
+	return c, map[string]string{"hdfs-site.xml": renderHadoopConfiguration(hdfsSite)}
+}
+
+// mockNamenodePods returns the namenode pods at the given revisions from the mock client, along with the StatefulSet
+func mockNamenodePods(k8sMockClient *K8sMockClient, ctx context.Context, updateRevision string, revisions ...string) []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for i, revision := range revisions {
+		pod := newRegionServerPod("nn-"+strconv.Itoa(i), true)
+		pod.Labels = map[string]string{appsv1.StatefulSetRevisionLabel: revision}
+		pods = append(pods, &pod)
+		k8sMockClient.On("Get", ctx, types.NamespacedName{Name: pod.Name, Namespace: testNamespace}, &corev1.Pod{}).
+			Run(func(args mock.Arguments) {
+				*args.Get(2).(*corev1.Pod) = pod
+			}).
+			Return(nil)
+	}
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "nn", Namespace: testNamespace}, &appsv1.StatefulSet{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*appsv1.StatefulSet).Status.UpdateRevision = updateRevision
+		}).
+		Return(nil).Maybe()
+	return pods
+}
+
+// TestNamenodesOf verifies pods are matched to namenode ids by the host of their rpc address.
+func TestNamenodesOf(t *testing.T) {
+	c, hadoopConfig := newNamenodeHACluster("nn-0.test:9870", "nn-1.test:9870")
+	nameservice, namenodes, err := namenodesOf(c, hadoopConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, "hbase", nameservice)
+	assert.Equal(t, []namenode{{Pod: "nn-0", ID: "nn0", HTTPAddress: "nn-0.test:9870"}, {Pod: "nn-1", ID: "nn1", HTTPAddress: "nn-1.test:9870"}}, namenodes)
+
+	c.Spec.Deployments.Namenode.Size = 3
+	_, _, err = namenodesOf(c, hadoopConfig)
+	assert.ErrorContains(t, err, "nn-2")
+
+	_, _, err = namenodesOf(c, map[string]string{})
+	assert.ErrorContains(t, err, "dfs.nameservices")
+}
+
+// TestNamenodeHAState verifies the state is read from the NameNodeStatus bean.
+func TestNamenodeHAState(t *testing.T) {
+	state, err := namenodeHAState(context.TODO(), "", startNamenodeStandIn(t, "Active"))
+	assert.NoError(t, err)
+	assert.Equal(t, NAMENODE_ACTIVE, state)
+
+	_, err = namenodeHAState(context.TODO(), "http", closedLocalAddress(t))
+	assert.Error(t, err)
+}
+
+// TestReconcileNamenodeHA_RestartsStandbyFirst verifies the standby namenode is restarted before the active one,
+// and that the HA state is recorded in status.
+func TestReconcileNamenodeHA_RestartsStandbyFirst(t *testing.T) {
+	ctx := context.TODO()
+	c, hadoopConfig := newNamenodeHACluster(startNamenodeStandIn(t, "active"), startNamenodeStandIn(t, "standby"))
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	pods := mockNamenodePods(k8sMockClient, ctx, "v2", "v1", "v1")
+	k8sMockClient.On("Delete", ctx, pods[1]).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileNamenodeHA(ctx, ctrl.Log.WithName("test"), c, hadoopConfig, new(MockPodExecutor), recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ctrl.Result{}, result)
+	assert.Equal(t, "nn-0", c.Status.ActiveNamenode)
+	assert.Equal(t, []kvstorev1.NamenodeStatus{{Pod: "nn-0", ID: "nn0", State: NAMENODE_ACTIVE}, {Pod: "nn-1", ID: "nn1", State: NAMENODE_STANDBY}}, c.Status.Namenodes)
+	assert.Equal(t, []string{REASON_NAMENODE_RESTARTED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestReconcileNamenodeHA_UnknownState verifies no namenode is restarted while the state of one of them is not known,
+// as it may be the active one.
+func TestReconcileNamenodeHA_UnknownState(t *testing.T) {
+	ctx := context.TODO()
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	c, hadoopConfig := newNamenodeHACluster(strings.TrimPrefix(unreachable.URL, "http://"), startNamenodeStandIn(t, "standby"))
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	mockNamenodePods(k8sMockClient, ctx, "v2", "v1", "v1")
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileNamenodeHA(ctx, ctrl.Log.WithName("test"), c, hadoopConfig, new(MockPodExecutor), recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	assert.Empty(t, c.Status.ActiveNamenode)
+	assert.Equal(t, NAMENODE_UNKNOWN, c.Status.Namenodes[0].State)
+	assert.Empty(t, recordedReasons(recorder))
+	k8sMockClient.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileNamenodeHA_FailsOverActive verifies the outdated active namenode is failed over to the updated standby.
+func TestReconcileNamenodeHA_FailsOverActive(t *testing.T) {
+	ctx := context.TODO()
+	c, hadoopConfig := newNamenodeHACluster(startNamenodeStandIn(t, "active"), startNamenodeStandIn(t, "standby"))
+	c.Status.ActiveNamenode = "nn-0"
+	c.Status.Namenodes = []kvstorev1.NamenodeStatus{{Pod: "nn-0", ID: "nn0", State: NAMENODE_ACTIVE}, {Pod: "nn-1", ID: "nn1", State: NAMENODE_STANDBY}}
+	k8sMockClient := new(K8sMockClient)
+	mockNamenodePods(k8sMockClient, ctx, "v2", "v1", "v2")
+	executor := new(MockPodExecutor)
+	executor.On("Exec", ctx, testNamespace, "nn-1", "namenode", []string{"hdfs", "haadmin", "-ns", "hbase", "-failover", "nn0", "nn1"}).Return("", nil)
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := reconcileNamenodeHA(ctx, ctrl.Log.WithName("test"), c, hadoopConfig, executor, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{REASON_NAMENODE_FAILED_OVER}, recordedReasons(recorder))
+	executor.AssertExpectations(t)
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestReconcileNamenodeHA_UpToDate verifies nothing is done once all namenodes run the update revision.
+func TestReconcileNamenodeHA_UpToDate(t *testing.T) {
+	ctx := context.TODO()
+	c, hadoopConfig := newNamenodeHACluster(startNamenodeStandIn(t, "active"), startNamenodeStandIn(t, "standby"))
+	c.Status.ActiveNamenode = "nn-0"
+	c.Status.Namenodes = []kvstorev1.NamenodeStatus{{Pod: "nn-0", ID: "nn0", State: NAMENODE_ACTIVE}, {Pod: "nn-1", ID: "nn1", State: NAMENODE_STANDBY}}
+	k8sMockClient := new(K8sMockClient)
+	mockNamenodePods(k8sMockClient, ctx, "v2", "v2", "v2")
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileNamenodeHA(ctx, ctrl.Log.WithName("test"), c, hadoopConfig, new(MockPodExecutor), recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Empty(t, recordedReasons(recorder))
+}
+
+// TestReconcileNamenodeHA_FailoverAnnotation verifies a failover requested through the annotation is applied, and the
+// annotation removed.
+func TestReconcileNamenodeHA_FailoverAnnotation(t *testing.T) {
+	ctx := context.TODO()
+	c, hadoopConfig := newNamenodeHACluster(startNamenodeStandIn(t, "active"), startNamenodeStandIn(t, "standby"))
+	c.Annotations = map[string]string{NAMENODE_FAILOVER_ANNOTATION: "nn-1"}
+	c.Status.ActiveNamenode = "nn-0"
+	c.Status.Namenodes = []kvstorev1.NamenodeStatus{{Pod: "nn-0", ID: "nn0", State: NAMENODE_ACTIVE}, {Pod: "nn-1", ID: "nn1", State: NAMENODE_STANDBY}}
+	k8sMockClient := new(K8sMockClient)
+	mockNamenodePods(k8sMockClient, ctx, "v2", "v2", "v2")
+	k8sMockClient.On("Update", ctx, c, mock.Anything).Return(nil)
+	executor := new(MockPodExecutor)
+	executor.On("Exec", ctx, testNamespace, "nn-1", "namenode", []string{"hdfs", "haadmin", "-ns", "hbase", "-failover", "nn0", "nn1"}).Return("", nil)
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := reconcileNamenodeHA(ctx, ctrl.Log.WithName("test"), c, hadoopConfig, executor, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotContains(t, c.Annotations, NAMENODE_FAILOVER_ANNOTATION)
+	assert.Equal(t, []string{REASON_NAMENODE_FAILED_OVER}, recordedReasons(recorder))
+	executor.AssertExpectations(t)
+	k8sMockClient.AssertExpectations(t)
+}
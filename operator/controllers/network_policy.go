@@ -0,0 +1,286 @@
+package controllers
+
+import (
+	context "context"
+	json "encoding/json"
+	sort "sort"
+	time "time"
+
+	logr "github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+const (
+	networkPolicyLabel    = "hbasecluster_network_policy"
+	namespaceNameLabel    = "kubernetes.io/metadata.name"
+	COMPONENT_ZOOKEEPER   = "zookeeper"
+	COMPONENT_JOURNALNODE = "journalnode"
+	COMPONENT_NAMENODE    = "namenode"
+	COMPONENT_DATANODE    = "datanode"
+	COMPONENT_HMASTER     = "hmaster"
+)
+
+// networkPolicyFlows components each component accepts connections from, besides its own pods. Regionservers of
+// tenants get no policy, so the masters reach them unrestricted
+var networkPolicyFlows = map[string][]string{
+	COMPONENT_ZOOKEEPER:   {COMPONENT_NAMENODE, COMPONENT_DATANODE, COMPONENT_HMASTER},
+	COMPONENT_JOURNALNODE: {COMPONENT_NAMENODE},
+	COMPONENT_NAMENODE:    {COMPONENT_DATANODE, COMPONENT_HMASTER},
+	COMPONENT_DATANODE:    {COMPONENT_HMASTER},
+	COMPONENT_HMASTER:     {COMPONENT_DATANODE},
+}
+
+// networkPolicyClientComponents components clients connect to. Tenants run regionservers and datanodes, which connect
+// to all of them
+var networkPolicyClientComponents = map[string]bool{
+	COMPONENT_ZOOKEEPER: true,
+	COMPONENT_NAMENODE:  true,
+	COMPONENT_DATANODE:  true,
+	COMPONENT_HMASTER:   true,
+}
+
+// networkPolicySnapshotJobComponents components the snapshot jobs of backups and restores connect to, to read and write
+// the snapshots in hdfs
+var networkPolicySnapshotJobComponents = map[string]bool{
+	COMPONENT_NAMENODE: true,
+	COMPONENT_DATANODE: true,
+}
+
+// networkPolicyComponents returns the deployments of the cluster by component, for the ones deployed
+func networkPolicyComponents(c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment) map[string]kvstorev1.HbaseClusterDeployment {
+	d := c.Spec.Deployments
+	byName := map[string]string{d.Zookeeper.Name: COMPONENT_ZOOKEEPER, d.Journalnode.Name: COMPONENT_JOURNALNODE,
+		d.Namenode.Name: COMPONENT_NAMENODE, d.Datanode.Name: COMPONENT_DATANODE, d.Hmaster.Name: COMPONENT_HMASTER}
+	components := map[string]kvstorev1.HbaseClusterDeployment{}
+	for _, deployment := range deployments {
+		if component, ok := byName[deployment.Name]; ok && len(deployment.Name) > 0 {
+			components[component] = deployment
+		}
+	}
+	return components
+}
+
+// networkPolicyName returns the name of the NetworkPolicy of the deployment
+func networkPolicyName(crName string, d kvstorev1.HbaseClusterDeployment) string {
+	return crName + "-" + d.Name
+}
+
+// networkPolicyPorts returns the ports the containers of the deployment listen on, along with the target ports of
+// their Services
+func networkPolicyPorts(d kvstorev1.HbaseClusterDeployment) []networkingv1.NetworkPolicyPort {
+	ports := []networkingv1.NetworkPolicyPort{}
+	for _, c := range d.Containers {
+		for _, p := range c.Ports {
+			protocol := p.Protocol
+			if len(protocol) == 0 {
+				protocol = corev1.ProtocolTCP
+			}
+			targets := []int32{p.Port}
+			if p.TargetPort > 0 && p.TargetPort != p.Port {
+				targets = append(targets, p.TargetPort)
+			}
+			for _, port := range targets {
+				ports = append(ports, networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &intstr.IntOrString{IntVal: port}})
+			}
+		}
+	}
+	return ports
+}
+
+// networkPolicyClients returns the peers allowed to connect to the components clients connect to: the pods of the
+// tenant namespaces, the pods of the tenants in the namespace of the cluster, the pods of the selected namespaces and
+// the selected pods of the namespace of the cluster
+func networkPolicyClients(p *kvstorev1.HbaseClusterNetworkPolicy, namespace string, tenantNamespaces []string,
+	tenants []kvstorev1.HbaseTenant) []networkingv1.NetworkPolicyPeer {
+	peers := []networkingv1.NetworkPolicyPeer{}
+	namespaces := []string{}
+	for _, ns := range tenantNamespaces {
+		if ns != namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	if len(namespaces) > 0 {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{{Key: namespaceNameLabel, Operator: metav1.LabelSelectorOpIn, Values: namespaces}},
+		}})
+	}
+	// tenants in the namespace of the cluster are selected by the labels of their StatefulSet
+	names := []string{}
+	for _, t := range tenants {
+		if t.Namespace == namespace {
+			names = append(names, t.Name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: getSharedLabelsMap(name, nil)}})
+	}
+	if p.ClientNamespaceSelector != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{NamespaceSelector: p.ClientNamespaceSelector})
+	}
+	if p.ClientPodSelector != nil {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: p.ClientPodSelector})
+	}
+	return peers
+}
+
+// buildNetworkPolicy returns the NetworkPolicy of the pods of a component. It allows the flows from the components
+// connecting to it, from clients and from snapshot jobs in the namespace of the cluster on the ports of its containers,
+// scrapes of its exporter and, for regionservers exposed outside of the kubernetes cluster, connections from anywhere to
+// their external port
+func buildNetworkPolicy(c *kvstorev1.HbaseCluster, component string, components map[string]kvstorev1.HbaseClusterDeployment,
+	tenantNamespaces []string, tenants []kvstorev1.HbaseTenant) *networkingv1.NetworkPolicy {
+	p := c.Spec.NetworkPolicy
+	d := components[component]
+	ports := networkPolicyPorts(d)
+
+	peers := []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{MatchLabels: matchLabelsForMultiStatefulSet(c.Name, d.Name)}}}
+	for _, from := range networkPolicyFlows[component] {
+		if peer, ok := components[from]; ok {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: matchLabelsForMultiStatefulSet(c.Name, peer.Name)}})
+		}
+	}
+	// a rule without ports allows all of them, so components declaring none accept no flow
+	rules := []networkingv1.NetworkPolicyIngressRule{}
+	if len(ports) > 0 {
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: ports, From: peers})
+		if clients := networkPolicyClients(p, c.Namespace, tenantNamespaces, tenants); networkPolicyClientComponents[component] && len(clients) > 0 {
+			rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: ports, From: clients})
+		}
+		if networkPolicySnapshotJobComponents[component] {
+			jobs := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{snapshotJobLabel: c.Name}}}
+			rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: ports, From: []networkingv1.NetworkPolicyPeer{jobs}})
+		}
+	}
+
+	tcp := corev1.ProtocolTCP
+	if isMonitored(c.Spec.Monitoring, d) {
+		rule := networkingv1.NetworkPolicyIngressRule{Ports: []networkingv1.NetworkPolicyPort{
+			{Protocol: &tcp, Port: &intstr.IntOrString{IntVal: jmxExporterPortOf(c.Spec.Monitoring)}},
+		}}
+		if p.MetricsNamespaceSelector != nil {
+			rule.From = []networkingv1.NetworkPolicyPeer{{NamespaceSelector: p.MetricsNamespaceSelector}}
+		}
+		rules = append(rules, rule)
+	}
+	if isExternallyAccessible(c, d) {
+		port := networkingv1.NetworkPolicyPort{Protocol: &tcp, Port: &intstr.IntOrString{IntVal: externalPort(c.Spec.ExternalAccess, 0)}}
+		if d.Size > 1 && c.Spec.ExternalAccess.Type == corev1.ServiceTypeNodePort {
+			endPort := externalPort(c.Spec.ExternalAccess, d.Size-1)
+			port.EndPort = &endPort
+		}
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: []networkingv1.NetworkPolicyPort{port}})
+	}
+
+	labels := getSharedLabelsMap(c.Name, nil)
+	labels[networkPolicyLabel] = d.Name
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      networkPolicyName(c.Name, d),
+			Namespace: c.Namespace,
+			Labels:    labels,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: matchLabelsForMultiStatefulSet(c.Name, d.Name)},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress:     rules,
+		},
+	}
+}
+
+// reconcileNetworkPolicy creates or updates the NetworkPolicy
+func reconcileNetworkPolicy(ctx context.Context, log logr.Logger, np *networkingv1.NetworkPolicy, cl client.Client) error {
+	npMarshal, _ := json.Marshal(np)
+	existing := &networkingv1.NetworkPolicy{}
+	err := cl.Get(ctx, types.NamespacedName{Name: np.Name, Namespace: np.Namespace}, existing)
+	if errors.IsNotFound(err) {
+		log.Info("Creating a new NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+		if err = cl.Create(ctx, np); err != nil {
+			log.Error(err, "Failed to create new NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+			return err
+		}
+		hashStore["netpol-"+np.Namespace+np.Name] = asSha256(npMarshal)
+		return nil
+	}
+	if err != nil {
+		log.Error(err, "Failed to get NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+		return err
+	}
+	if asSha256(npMarshal) == hashStore["netpol-"+np.Namespace+np.Name] {
+		return nil
+	}
+	log.Info("Updating NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+	np.ResourceVersion = existing.ResourceVersion
+	if err = cl.Update(ctx, np); err != nil {
+		log.Error(err, "Failed to update NetworkPolicy", "NetworkPolicy.Namespace", np.Namespace, "NetworkPolicy.Name", np.Name)
+		return err
+	}
+	hashStore["netpol-"+np.Namespace+np.Name] = asSha256(npMarshal)
+	return nil
+}
+
+// reconcileNetworkPolicies generates the NetworkPolicies of the components of the cluster, and deletes the ones it
+// generated before which are not wanted anymore, such as all of them once networkPolicy is unset. Generated policies
+// are listed in status
+func reconcileNetworkPolicies(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment,
+	tenantNamespaces []string, tenants []kvstorev1.HbaseTenant, scheme *runtime.Scheme, cl client.Client) (ctrl.Result, error) {
+	if c.Spec.NetworkPolicy == nil && len(c.Status.NetworkPolicies) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	names := []string{}
+	if c.Spec.NetworkPolicy != nil {
+		components := networkPolicyComponents(c, deployments)
+		for _, component := range []string{COMPONENT_ZOOKEEPER, COMPONENT_JOURNALNODE, COMPONENT_NAMENODE, COMPONENT_DATANODE, COMPONENT_HMASTER} {
+			if _, ok := components[component]; !ok {
+				continue
+			}
+			np := buildNetworkPolicy(c, component, components, tenantNamespaces, tenants)
+			ctrl.SetControllerReference(c, np, scheme)
+			if err := reconcileNetworkPolicy(ctx, log, np, cl); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 5}, err
+			}
+			names = append(names, np.Name)
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range names {
+		wanted[name] = true
+	}
+	for _, name := range c.Status.NetworkPolicies {
+		if wanted[name] {
+			continue
+		}
+		log.Info("Deleting NetworkPolicy", "NetworkPolicy.Namespace", c.Namespace, "NetworkPolicy.Name", name)
+		np := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: c.Namespace}}
+		if err := cl.Delete(ctx, np); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete NetworkPolicy", "NetworkPolicy.Namespace", c.Namespace, "NetworkPolicy.Name", name)
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		delete(hashStore, "netpol-"+c.Namespace+name)
+	}
+
+	sort.Strings(names)
+	if len(names) == 0 && len(c.Status.NetworkPolicies) == 0 || equality.Semantic.DeepEqual(names, c.Status.NetworkPolicies) {
+		return ctrl.Result{}, nil
+	}
+	c.Status.NetworkPolicies = names
+	if err := cl.Status().Update(ctx, c); err != nil {
+		log.Error(err, "Failed to update HbaseCluster status with the NetworkPolicies")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return ctrl.Result{}, nil
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newNetworkPolicyCluster() *kvstorev1.HbaseCluster {
+	deployment := func(name string, size int32, ports ...kvstorev1.HbaseClusterContainerPort) kvstorev1.HbaseClusterDeployment {
+		return kvstorev1.HbaseClusterDeployment{Name: name, Size: size, Containers: []kvstorev1.HbaseClusterContainer{{Name: name, Ports: ports}}}
+	}
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{
+				Zookeeper:   deployment("zk", 3, kvstorev1.HbaseClusterContainerPort{Name: "client", Port: 2181}),
+				Journalnode: deployment("jn", 3, kvstorev1.HbaseClusterContainerPort{Name: "rpc", Port: 8485}),
+				Namenode:    deployment("nn", 2, kvstorev1.HbaseClusterContainerPort{Name: "rpc", Port: 8020, TargetPort: 15020}),
+				Datanode:    deployment("dn", 3, kvstorev1.HbaseClusterContainerPort{Name: "regionserver", Port: 16020}),
+				Hmaster:     deployment("hmaster", 2),
+			},
+			NetworkPolicy: &kvstorev1.HbaseClusterNetworkPolicy{
+				ClientPodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"role": "client"}},
+			},
+		},
+	}
+}
+
+// podsOf returns the deployments selected by the pod selectors of the peers
+func podsOf(peers []networkingv1.NetworkPolicyPeer) []string {
+	names := []string{}
+	for _, peer := range peers {
+		if peer.PodSelector != nil {
+			names = append(names, peer.PodSelector.MatchLabels["statefulset.kubernetes.io/statefulset-name"])
+		}
+	}
+	return names
+}
+
+// TestBuildNetworkPolicy verifies components only accept the flows of the components connecting to them on their
+// ports, clients only reach client components, and components without ports accept no flow.
+func TestBuildNetworkPolicy(t *testing.T) {
+	c := newNetworkPolicyCluster()
+	components := networkPolicyComponents(c, clusterDeployments(c.Spec))
+	namespaces := []string{"tenant-a", testNamespace}
+
+	jn := buildNetworkPolicy(c, COMPONENT_JOURNALNODE, components, namespaces, nil)
+	assert.Equal(t, "test-jn", jn.Name)
+	assert.Equal(t, "jn", jn.Spec.PodSelector.MatchLabels["statefulset.kubernetes.io/statefulset-name"])
+	assert.Equal(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}, jn.Spec.PolicyTypes)
+	assert.Len(t, jn.Spec.Ingress, 1)
+	assert.Equal(t, []string{"jn", "nn"}, podsOf(jn.Spec.Ingress[0].From))
+
+	nn := buildNetworkPolicy(c, COMPONENT_NAMENODE, components, namespaces, nil)
+	assert.Len(t, nn.Spec.Ingress, 3)
+	assert.Equal(t, []string{"nn", "dn", "hmaster"}, podsOf(nn.Spec.Ingress[0].From))
+	assert.Equal(t, 8020, nn.Spec.Ingress[0].Ports[0].Port.IntValue())
+	assert.Equal(t, 15020, nn.Spec.Ingress[0].Ports[1].Port.IntValue())
+	assert.Equal(t, corev1.ProtocolTCP, *nn.Spec.Ingress[0].Ports[0].Protocol)
+	clients := nn.Spec.Ingress[1].From
+	assert.Equal(t, []string{"tenant-a"}, clients[0].NamespaceSelector.MatchExpressions[0].Values)
+	assert.Equal(t, c.Spec.NetworkPolicy.ClientPodSelector, clients[1].PodSelector)
+	assert.Equal(t, nn.Spec.Ingress[0].Ports, nn.Spec.Ingress[2].Ports)
+	assert.Equal(t, map[string]string{snapshotJobLabel: "test"}, nn.Spec.Ingress[2].From[0].PodSelector.MatchLabels)
+	assert.Nil(t, nn.Spec.Ingress[2].From[0].NamespaceSelector)
+
+	hmaster := buildNetworkPolicy(c, COMPONENT_HMASTER, components, namespaces, nil)
+	assert.Empty(t, hmaster.Spec.Ingress)
+}
+
+// TestBuildNetworkPolicy_TenantInClusterNamespace verifies the pods of tenants in the namespace of the cluster are
+// clients with an empty networkPolicy, while the namespace of the cluster is not opened to all of its pods.
+func TestBuildNetworkPolicy_TenantInClusterNamespace(t *testing.T) {
+	c := newNetworkPolicyCluster()
+	c.Spec.NetworkPolicy = &kvstorev1.HbaseClusterNetworkPolicy{}
+	components := networkPolicyComponents(c, clusterDeployments(c.Spec))
+	tenant := getMockHbaseTenant()
+	tenant.Namespace = testNamespace
+	other := getMockHbaseTenant()
+	other.Name = "other"
+
+	zk := buildNetworkPolicy(c, COMPONENT_ZOOKEEPER, components, tenantNamespacesOf(c, []kvstorev1.HbaseTenant{*tenant, *other}),
+		[]kvstorev1.HbaseTenant{*tenant, *other})
+	assert.Len(t, zk.Spec.Ingress, 2)
+	clients := zk.Spec.Ingress[1].From
+	assert.Len(t, clients, 2)
+	assert.Equal(t, []string{other.Namespace}, clients[0].NamespaceSelector.MatchExpressions[0].Values)
+	assert.Nil(t, clients[1].NamespaceSelector)
+	assert.Equal(t, map[string]string{"app": "hbasecluster", "hbasecluster_cr": tenant.Name}, clients[1].PodSelector.MatchLabels)
+
+	jn := buildNetworkPolicy(c, COMPONENT_JOURNALNODE, components, []string{testNamespace}, []kvstorev1.HbaseTenant{*tenant})
+	assert.Len(t, jn.Spec.Ingress, 1)
+}
+
+// TestBuildNetworkPolicy_ExporterAndExternalAccess verifies exporters can be scraped from the selected namespaces, and
+// regionservers exposed through node ports reached from anywhere.
+func TestBuildNetworkPolicy_ExporterAndExternalAccess(t *testing.T) {
+	c := newNetworkPolicyCluster()
+	c.Spec.Monitoring = &kvstorev1.HbaseClusterMonitoring{}
+	c.Spec.NetworkPolicy.MetricsNamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "monitoring"}}
+	c.Spec.ExternalAccess = &kvstorev1.HbaseClusterExternalAccess{Type: corev1.ServiceTypeNodePort, NodePortBase: 30020}
+	components := networkPolicyComponents(c, clusterDeployments(c.Spec))
+
+	dn := buildNetworkPolicy(c, COMPONENT_DATANODE, components, nil, nil)
+	assert.Len(t, dn.Spec.Ingress, 5)
+	metrics := dn.Spec.Ingress[3]
+	assert.Equal(t, int(defaultJmxExporterPort), metrics.Ports[0].Port.IntValue())
+	assert.Equal(t, c.Spec.NetworkPolicy.MetricsNamespaceSelector, metrics.From[0].NamespaceSelector)
+	external := dn.Spec.Ingress[4]
+	assert.Empty(t, external.From)
+	assert.Equal(t, 30020, external.Ports[0].Port.IntValue())
+	assert.Equal(t, int32(30022), *external.Ports[0].EndPort)
+}
+
+// TestReconcileNetworkPolicies verifies the policies of the deployed components are created and listed in status,
+// and deleted once networkPolicy is unset.
+func TestReconcileNetworkPolicies(t *testing.T) {
+	resetHashStore()
+	ctx := context.TODO()
+	c := newNetworkPolicyCluster()
+	c.Spec.ExternalZookeeper = &kvstorev1.HbaseExternalZookeeper{Quorum: "zk-0:2181"}
+	scheme := runtime.NewScheme()
+	_ = kvstorev1.AddToScheme(scheme)
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, c).Return(nil)
+	k8sMockClient.On("Get", ctx, mock.Anything, &networkingv1.NetworkPolicy{}).
+		Return(errors.NewNotFound(schema.GroupResource{}, "policy"))
+	k8sMockClient.On("Create", ctx, mock.AnythingOfType("*v1.NetworkPolicy"), mock.Anything).Return(nil)
+
+	_, err := reconcileNetworkPolicies(ctx, ctrl.Log.WithName("test"), c, clusterDeployments(c.Spec), nil, nil, scheme, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test-dn", "test-hmaster", "test-jn", "test-nn"}, c.Status.NetworkPolicies)
+	k8sMockClient.AssertNumberOfCalls(t, "Create", 4)
+
+	c.Spec.NetworkPolicy = nil
+	k8sMockClient.On("Delete", ctx, mock.AnythingOfType("*v1.NetworkPolicy")).Return(nil)
+	_, err = reconcileNetworkPolicies(ctx, ctrl.Log.WithName("test"), c, clusterDeployments(c.Spec), nil, nil, scheme, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Status.NetworkPolicies)
+	k8sMockClient.AssertNumberOfCalls(t, "Delete", 4)
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+
+	// nothing is left to do afterwards
+	_, err = reconcileNetworkPolicies(ctx, ctrl.Log.WithName("test"), c, clusterDeployments(c.Spec), nil, nil, scheme, k8sMockClient)
+	assert.NoError(t, err)
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+}
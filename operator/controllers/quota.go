@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	context "context"
+	sort "sort"
+	strconv "strconv"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+const defaultQuotaSyncInterval = time.Minute * 5
+
+// quotaKey identifies a quota by its target and type, as target/type
+func quotaKey(q QuotaSettings) string {
+	target := []string{}
+	if len(q.User) > 0 {
+		target = append(target, "user="+q.User)
+	}
+	if len(q.Namespace) > 0 {
+		target = append(target, "namespace="+q.Namespace)
+	}
+	if len(q.Table) > 0 {
+		target = append(target, "table="+q.Table)
+	}
+	quotaType := strings.ToUpper(q.ThrottleType)
+	if len(quotaType) == 0 {
+		quotaType = "SPACE"
+	}
+	return strings.Join(target, ",") + "/" + quotaType
+}
+
+// parseQuotaKey returns the target and type of the quota with the given key
+func parseQuotaKey(key string) QuotaSettings {
+	q := QuotaSettings{}
+	target, quotaType, _ := strings.Cut(key, "/")
+	if quotaType != "SPACE" {
+		q.ThrottleType = quotaType
+	}
+	for _, t := range strings.Split(target, ",") {
+		name, value, _ := strings.Cut(t, "=")
+		switch name {
+		case "user":
+			q.User = value
+		case "namespace":
+			q.Namespace = value
+		case "table":
+			q.Table = value
+		}
+	}
+	return q
+}
+
+// quotaSizeUnits are the multipliers of the size units of throttle limits
+var quotaSizeUnits = map[string]float64{"": 1, "k": 1 << 10, "m": 1 << 20, "g": 1 << 30, "t": 1 << 40, "p": 1 << 50}
+
+// quotaTimeUnits maps the time units of throttle limits to the ones rendered by the hbase shell
+var quotaTimeUnits = map[string]string{"s": "sec", "second": "sec", "seconds": "sec", "m": "min", "minute": "min",
+	"minutes": "min", "h": "hour", "hours": "hour", "d": "day", "days": "day"}
+
+// normalizeQuotaLimit returns the limit of a throttle quota in a canonical form, as the admin endpoint may render a
+// limit differently than the spec, e.g. 1M/sec for 1024K/SEC. Sizes are converted to bytes
+func normalizeQuotaLimit(limit string) string {
+	limit = strings.ToLower(strings.ReplaceAll(limit, " ", ""))
+	amount, unit, ok := strings.Cut(limit, "/")
+	if !ok {
+		return limit
+	}
+	if u, ok := quotaTimeUnits[unit]; ok {
+		unit = u
+	}
+	i := strings.IndexFunc(amount, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		return amount + "/" + unit
+	}
+	number, suffix := amount[:i], amount[i:]
+	if suffix == "req" || suffix == "cu" {
+		return number + suffix + "/" + unit
+	}
+	multiplier, ok := quotaSizeUnits[strings.TrimSuffix(suffix, "b")]
+	n, err := strconv.ParseFloat(number, 64)
+	if !ok || err != nil {
+		return limit
+	}
+	return strconv.FormatInt(int64(n*multiplier), 10) + "/" + unit
+}
+
+// isSameQuota returns true if the quota set in hbase matches the desired one
+func isSameQuota(current QuotaSettings, desired QuotaSettings) bool {
+	return quotaKey(current) == quotaKey(desired) && normalizeQuotaLimit(current.Limit) == normalizeQuotaLimit(desired.Limit) &&
+		current.SpaceLimit == desired.SpaceLimit && strings.EqualFold(current.Policy, desired.Policy)
+}
+
+// desiredQuotas returns the quotas of the spec by key
+func desiredQuotas(q *kvstorev1.HbaseTenantQuotas) map[string]QuotaSettings {
+	desired := map[string]QuotaSettings{}
+	for _, t := range q.Throttles {
+		s := QuotaSettings{User: t.User, Namespace: t.Namespace, Table: t.Table, ThrottleType: t.Type, Limit: t.Limit}
+		desired[quotaKey(s)] = s
+	}
+	for _, sq := range q.Space {
+		s := QuotaSettings{Namespace: sq.Namespace, SpaceLimit: sq.Limit.Value(), Policy: sq.Policy}
+		desired[quotaKey(s)] = s
+	}
+	return desired
+}
+
+// reconcileQuotas applies the quotas of the tenant which are missing or drifted through the admin endpoint, removes
+// the ones it applied before which are no longer in the spec, and reports space usage. Quotas are synced again after
+// the sync interval. Once quotas are unset, the ones it applied are removed and the status cleared
+func reconcileQuotas(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTenant, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	if len(t.Spec.Configuration.AdminEndpoint) == 0 {
+		log.Info("Admin endpoint not set, quotas of the tenant are not managed")
+		return ctrl.Result{}, nil
+	}
+	quotas := t.Spec.Quotas
+	if quotas == nil {
+		quotas = &kvstorev1.HbaseTenantQuotas{}
+	}
+	interval := defaultQuotaSyncInterval
+	if quotas.SyncInterval != nil && quotas.SyncInterval.Duration > 0 {
+		interval = quotas.SyncInterval.Duration
+	}
+	admin := newHbaseAdmin(t.Spec.Configuration.AdminEndpoint)
+
+	existing, err := admin.ListQuotas(ctx)
+	if err != nil {
+		return quotaFailed(log, t, err, recorder)
+	}
+	current := map[string]QuotaSettings{}
+	for _, q := range existing {
+		current[quotaKey(q)] = q
+	}
+
+	desired := desiredQuotas(quotas)
+	changed := []string{}
+	for _, key := range sortedKeys(desired) {
+		if q, ok := current[key]; ok && isSameQuota(q, desired[key]) {
+			continue
+		}
+		log.Info("Setting quota", "Quota", key)
+		if err = admin.SetQuota(ctx, desired[key]); err != nil {
+			return quotaFailed(log, t, err, recorder)
+		}
+		changed = append(changed, key)
+	}
+
+	// Only quotas the operator applied are removed, others may be managed outside of the tenant
+	if t.Status.Quotas != nil {
+		for _, key := range t.Status.Quotas.Applied {
+			if _, ok := desired[key]; ok {
+				continue
+			}
+			if _, ok := current[key]; !ok {
+				continue
+			}
+			log.Info("Removing quota", "Quota", key)
+			if err = admin.RemoveQuota(ctx, parseQuotaKey(key)); err != nil {
+				return quotaFailed(log, t, err, recorder)
+			}
+			changed = append(changed, key)
+		}
+	}
+	if len(changed) > 0 {
+		recorder.Event(t, corev1.EventTypeNormal, REASON_QUOTAS_APPLIED, "Applied quotas "+strings.Join(changed, ", "))
+	}
+
+	if t.Spec.Quotas == nil {
+		t.Status.Quotas = nil
+		if err = cl.Status().Update(ctx, t); err != nil {
+			log.Error(err, "Failed to clear quotas from HbaseTenant status")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	status := &kvstorev1.QuotaStatus{Applied: sortedKeys(desired)}
+	if len(quotas.Space) > 0 {
+		usage, err := admin.GetSpaceQuotaUsage(ctx)
+		if err != nil {
+			return quotaFailed(log, t, err, recorder)
+		}
+		spaceQuotas := map[string]bool{}
+		for _, s := range quotas.Space {
+			spaceQuotas[s.Namespace] = true
+		}
+		for _, u := range usage {
+			if !spaceQuotas[u.Namespace] {
+				continue
+			}
+			status.SpaceUsage = append(status.SpaceUsage, kvstorev1.SpaceQuotaUsage{Namespace: u.Namespace, InViolation: u.InViolation,
+				Usage: *resource.NewQuantity(u.Usage, resource.BinarySI), Limit: *resource.NewQuantity(u.Limit, resource.BinarySI)})
+		}
+		sort.Slice(status.SpaceUsage, func(i, j int) bool { return status.SpaceUsage[i].Namespace < status.SpaceUsage[j].Namespace })
+	}
+
+	// the sync time is only refreshed along with the rest of the status, so that a sync without changes does not
+	// update the tenant, which would trigger another reconcile before the sync interval
+	if t.Status.Quotas != nil {
+		status.LastSyncTime = t.Status.Quotas.LastSyncTime
+		if equality.Semantic.DeepEqual(status, t.Status.Quotas) {
+			return ctrl.Result{RequeueAfter: interval}, nil
+		}
+	}
+	status.LastSyncTime = metav1.Now()
+	t.Status.Quotas = status
+	if err = cl.Status().Update(ctx, t); err != nil {
+		log.Error(err, "Failed to update HbaseTenant status with quotas")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+func quotaFailed(log logr.Logger, t *kvstorev1.HbaseTenant, err error, recorder record.EventRecorder) (ctrl.Result, error) {
+	recordWarning(recorder, t, REASON_QUOTA_UPDATE_FAILED, err)
+	log.Error(err, "Failed to sync quotas through the admin endpoint")
+	return ctrl.Result{RequeueAfter: time.Second * 5}, err
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newQuotaTenant(endpoint string) *kvstorev1.HbaseTenant {
+	t := &kvstorev1.HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: testNamespace}}
+	t.Spec.Configuration.AdminEndpoint = endpoint
+	t.Spec.Quotas = &kvstorev1.HbaseTenantQuotas{
+		Throttles: []kvstorev1.HbaseThrottleQuota{{Namespace: "team", Type: "REQUEST_NUMBER", Limit: "1000req/sec"}},
+		Space:     []kvstorev1.HbaseSpaceQuota{{Namespace: "team", Limit: resource.MustParse("1Ti"), Policy: "NO_INSERTS"}},
+	}
+	return t
+}
+
+// TestQuotaKey verifies keys name the target and type of quotas and can be parsed back.
+func TestQuotaKey(t *testing.T) {
+	for key, q := range map[string]QuotaSettings{
+		"user=app,namespace=team/WRITE_SIZE": {User: "app", Namespace: "team", ThrottleType: "WRITE_SIZE"},
+		"table=team:events/READ_NUMBER":      {Table: "team:events", ThrottleType: "READ_NUMBER"},
+		"namespace=team/SPACE":               {Namespace: "team"},
+	} {
+		assert.Equal(t, key, quotaKey(q))
+		assert.Equal(t, q, parseQuotaKey(key))
+	}
+}
+
+// TestReconcileQuotas_Applied verifies missing and drifted quotas are set, stale ones removed and usage reported.
+func TestReconcileQuotas_Applied(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.quotas["namespace=team/REQUEST_NUMBER"] = QuotaSettings{Namespace: "team", ThrottleType: "REQUEST_NUMBER", Limit: "10req/sec"}
+	fake.quotas["namespace=team/WRITE_SIZE"] = QuotaSettings{Namespace: "team", ThrottleType: "WRITE_SIZE", Limit: "1M/sec"}
+	fake.quotas["user=other/READ_NUMBER"] = QuotaSettings{User: "other", ThrottleType: "READ_NUMBER", Limit: "5req/sec"}
+	fake.spaceUsage = []SpaceQuotaUsage{{Namespace: "team", Usage: 1 << 30, Limit: 1 << 40}, {Namespace: "other", Usage: 1}}
+
+	tenant := newQuotaTenant(server.URL)
+	tenant.Spec.Quotas.SyncInterval = &metav1.Duration{Duration: time.Minute}
+	tenant.Status.Quotas = &kvstorev1.QuotaStatus{Applied: []string{"namespace=team/REQUEST_NUMBER", "namespace=team/WRITE_SIZE"}}
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	recorder := record.NewFakeRecorder(10)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil)
+
+	result, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), tenant, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Minute}, result)
+
+	assert.Equal(t, []string{"namespace=team/REQUEST_NUMBER", "namespace=team/SPACE", "user=other/READ_NUMBER"}, sortedKeys(fake.quotas))
+	assert.Equal(t, "1000req/sec", fake.quotas["namespace=team/REQUEST_NUMBER"].Limit)
+	assert.Equal(t, int64(1<<40), fake.quotas["namespace=team/SPACE"].SpaceLimit)
+
+	assert.Equal(t, []string{"namespace=team/REQUEST_NUMBER", "namespace=team/SPACE"}, tenant.Status.Quotas.Applied)
+	assert.Len(t, tenant.Status.Quotas.SpaceUsage, 1)
+	assert.Equal(t, "team", tenant.Status.Quotas.SpaceUsage[0].Namespace)
+	assert.Equal(t, "1Gi", tenant.Status.Quotas.SpaceUsage[0].Usage.String())
+	assert.Equal(t, "1Ti", tenant.Status.Quotas.SpaceUsage[0].Limit.String())
+	assert.Equal(t, []string{REASON_QUOTAS_APPLIED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestReconcileQuotas_Unset verifies the quotas applied before are removed once quotas are unset, others being kept,
+// and the status cleared.
+func TestReconcileQuotas_Unset(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.quotas["namespace=team/REQUEST_NUMBER"] = QuotaSettings{Namespace: "team", ThrottleType: "REQUEST_NUMBER", Limit: "1000req/sec"}
+	fake.quotas["namespace=team/SPACE"] = QuotaSettings{Namespace: "team", SpaceLimit: 1 << 40, Policy: "NO_INSERTS"}
+	fake.quotas["user=other/READ_NUMBER"] = QuotaSettings{User: "other", ThrottleType: "READ_NUMBER", Limit: "5req/sec"}
+
+	tenant := newQuotaTenant(server.URL)
+	tenant.Spec.Quotas = nil
+	tenant.Status.Quotas = &kvstorev1.QuotaStatus{Applied: []string{"namespace=team/REQUEST_NUMBER", "namespace=team/SPACE"}}
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	recorder := record.NewFakeRecorder(10)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil)
+
+	result, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), tenant, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{"user=other/READ_NUMBER"}, sortedKeys(fake.quotas))
+	assert.Nil(t, tenant.Status.Quotas)
+	assert.Equal(t, []string{REASON_QUOTAS_APPLIED}, recordedReasons(recorder))
+	statusWriter.AssertExpectations(t)
+}
+
+// TestReconcileQuotas_InSync verifies quotas in sync are left alone, only usage being reported.
+func TestReconcileQuotas_InSync(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	tenant := newQuotaTenant(server.URL)
+	for key, q := range desiredQuotas(tenant.Spec.Quotas) {
+		fake.quotas[key] = q
+	}
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil)
+
+	result, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), tenant, record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: defaultQuotaSyncInterval}, result)
+	assert.Equal(t, []string{"GET /admin/quotas", "GET /admin/quotas/space_usage"}, fake.requests)
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileQuotas_NoDrift verifies a second sync without drift neither sets quotas nor updates the status.
+func TestReconcileQuotas_NoDrift(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	fake.spaceUsage = []SpaceQuotaUsage{{Namespace: "team", Usage: 1 << 30, Limit: 1 << 40}}
+	tenant := newQuotaTenant(server.URL)
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil).Once()
+
+	_, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), tenant, record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	// the admin endpoint renders limits in its own format
+	q := fake.quotas["namespace=team/REQUEST_NUMBER"]
+	q.Limit = "1000REQ/SEC"
+	fake.quotas["namespace=team/REQUEST_NUMBER"] = q
+	fake.requests = nil
+	lastSyncTime := tenant.Status.Quotas.LastSyncTime
+
+	result, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), tenant, record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{RequeueAfter: defaultQuotaSyncInterval}, result)
+	assert.Equal(t, []string{"GET /admin/quotas", "GET /admin/quotas/space_usage"}, fake.requests)
+	assert.Equal(t, lastSyncTime, tenant.Status.Quotas.LastSyncTime)
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+}
+
+// TestNormalizeQuotaLimit verifies limits rendered differently by the admin endpoint compare equal.
+func TestNormalizeQuotaLimit(t *testing.T) {
+	assert.Equal(t, normalizeQuotaLimit("1000req/sec"), normalizeQuotaLimit("1000REQ/SEC"))
+	assert.Equal(t, normalizeQuotaLimit("1M/sec"), normalizeQuotaLimit("1024K/SECONDS"))
+	assert.Equal(t, normalizeQuotaLimit("10g/min"), normalizeQuotaLimit("10 GB/min"))
+	assert.Equal(t, normalizeQuotaLimit("5CU/hour"), normalizeQuotaLimit("5cu/h"))
+	assert.NotEqual(t, normalizeQuotaLimit("1M/sec"), normalizeQuotaLimit("1M/min"))
+	assert.NotEqual(t, normalizeQuotaLimit("10req/sec"), normalizeQuotaLimit("10M/sec"))
+}
+
+// TestReconcileQuotas_AdminFailure verifies failures of the admin endpoint are reported and retried.
+func TestReconcileQuotas_AdminFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quotas disabled", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileQuotas(ctx, ctrl.Log.WithName("test"), newQuotaTenant(server.URL), recorder, k8sMockClient)
+	assert.ErrorContains(t, err, "quotas disabled")
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_QUOTA_UPDATE_FAILED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	context "context"
+	path "path"
+	strings "strings"
+
+	logr "github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+const (
+	defaultRackTopologyKey       = "topology.kubernetes.io/zone"
+	defaultRack                  = "/default-rack"
+	defaultRackTopologyMountPath = "/etc/hbase-rack-topology"
+	rackTopologyVolume           = "rack-topology"
+	rackTopologyScriptFile       = "rack_topology"
+	rackTopologyDataFile         = "rack_topology.data"
+)
+
+// rackTopologyScript prints the rack of each host given as argument. Hosts are looked up in the mapping by name or IP,
+// then by the first label of their name. DEFAULT_RACK is replaced by the default rack
+const rackTopologyScript = `#!/bin/bash
+DATA="$(dirname "$0")/` + rackTopologyDataFile + `"
+for host in "$@"; do
+  rack=$(awk -v host="$host" -v short="${host%%.*}" '$1 == host { exact = $2 } $1 == short { rack = $2 } END { print (exact ? exact : rack) }' "$DATA" 2>/dev/null)
+  echo -n "${rack:-DEFAULT_RACK} "
+done
+`
+
+// rackTopologyConfigName returns the name of the ConfigMap with the rack topology of the cluster
+func rackTopologyConfigName(crName string) string {
+	return crName + "-rack-topology"
+}
+
+// rackAwarenessSettings returns the topology key, default rack and mount path, with their defaults
+func rackAwarenessSettings(r *kvstorev1.HbaseClusterRackAwareness) (string, string, string) {
+	key, rack, mountPath := r.TopologyKey, r.DefaultRack, r.MountPath
+	if len(key) == 0 {
+		key = defaultRackTopologyKey
+	}
+	if len(rack) == 0 {
+		rack = defaultRack
+	}
+	if len(mountPath) == 0 {
+		mountPath = defaultRackTopologyMountPath
+	}
+	return key, rack, mountPath
+}
+
+// isRackAware tells whether the deployment resolves racks, which namenodes and hmasters do
+func isRackAware(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) bool {
+	return c.Spec.RackAwareness != nil && (d.Name == c.Spec.Deployments.Namenode.Name || d.Name == c.Spec.Deployments.Hmaster.Name)
+}
+
+// rackAwarenessConfig returns the hbase-site.xml and core-site.xml properties pointing to the topology script, as config
+// overlays of hbaseConfig and hadoopConfig
+func rackAwarenessConfig(r *kvstorev1.HbaseClusterRackAwareness) (map[string]string, map[string]string) {
+	_, _, mountPath := rackAwarenessSettings(r)
+	site := renderHadoopConfiguration(hadoopConfiguration{Properties: []hadoopConfigProperty{
+		{Name: "net.topology.script.file.name", Value: path.Join(mountPath, rackTopologyScriptFile)},
+	}})
+	return map[string]string{"hbase-site.xml": site}, map[string]string{"core-site.xml": site}
+}
+
+// rackOf returns the rack of a node, after its topology label
+func rackOf(node *corev1.Node, key string, defaultRack string) string {
+	if value := node.Labels[key]; len(value) > 0 {
+		return "/" + strings.Trim(value, "/")
+	}
+	return defaultRack
+}
+
+// buildRackTopology returns the mapping of the pods of the cluster, by name and IP, to the rack of their node, one
+// "host rack" line per host sorted by host. Pods not scheduled yet are left out
+func buildRackTopology(ctx context.Context, c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment, cl client.Client) (string, error) {
+	key, defaultRack, _ := rackAwarenessSettings(c.Spec.RackAwareness)
+	racks := map[string]string{}
+	nodes := map[string]string{}
+	for _, d := range deployments {
+		pods := &corev1.PodList{}
+		if err := cl.List(ctx, pods, client.InNamespace(c.Namespace), client.MatchingLabels(matchLabelsForMultiStatefulSet(c.Name, d.Name))); err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			if len(pod.Spec.NodeName) == 0 {
+				continue
+			}
+			rack, ok := nodes[pod.Spec.NodeName]
+			if !ok {
+				node := &corev1.Node{}
+				if err := cl.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+					return "", err
+				}
+				rack = rackOf(node, key, defaultRack)
+				nodes[pod.Spec.NodeName] = rack
+			}
+			racks[pod.Name] = rack
+			if len(pod.Status.PodIP) > 0 {
+				racks[pod.Status.PodIP] = rack
+			}
+		}
+	}
+
+	lines := []string{}
+	for _, host := range sortedKeys(racks) {
+		lines = append(lines, host+" "+racks[host])
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// buildRackTopologyConfigMap returns the ConfigMap with the topology script and the mapping of hosts to racks
+func buildRackTopologyConfigMap(crName string, namespace string, r *kvstorev1.HbaseClusterRackAwareness, topology string) *corev1.ConfigMap {
+	_, defaultRack, _ := rackAwarenessSettings(r)
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      rackTopologyConfigName(crName),
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			rackTopologyScriptFile: strings.ReplaceAll(rackTopologyScript, "DEFAULT_RACK", defaultRack),
+			rackTopologyDataFile:   topology,
+		},
+	}
+}
+
+// injectRackTopology mounts the rack topology ConfigMap into the containers of namenode and hmaster pods
+func injectRackTopology(ss *appsv1.StatefulSet, c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) {
+	if !isRackAware(c, d) {
+		return
+	}
+	_, _, mountPath := rackAwarenessSettings(c.Spec.RackAwareness)
+	mode := int32(0755)
+	pod := &ss.Spec.Template.Spec
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name: rackTopologyVolume,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: rackTopologyConfigName(c.Name)},
+				DefaultMode:          &mode,
+			},
+		},
+	})
+	for i := range pod.Containers {
+		pod.Containers[i].VolumeMounts = append(pod.Containers[i].VolumeMounts,
+			corev1.VolumeMount{Name: rackTopologyVolume, MountPath: mountPath, ReadOnly: true})
+	}
+}
+
+// reconcileRackTopology publishes the rack of the pods of the cluster. The mounted ConfigMap is synced by kubelet, and
+// read by the topology script on each lookup, so pods are not restarted when it changes
+func reconcileRackTopology(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, deployments []kvstorev1.HbaseClusterDeployment,
+	recorder record.EventRecorder, scheme *runtime.Scheme, cl client.Client) error {
+	if c.Spec.RackAwareness == nil {
+		return nil
+	}
+	topology, err := buildRackTopology(ctx, c, deployments, cl)
+	if err != nil {
+		log.Error(err, "Failed to map pods to racks")
+		return err
+	}
+	cfg := buildRackTopologyConfigMap(c.Name, c.Namespace, c.Spec.RackAwareness, topology)
+	ctrl.SetControllerReference(c, cfg, scheme)
+	_, err = reconcileConfigMap(ctx, log, c.Namespace, cfg, c, recorder, cl)
+	return err
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newRackAwareCluster() *kvstorev1.HbaseCluster {
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{
+				Namenode: kvstorev1.HbaseClusterDeployment{Name: "nn", Size: 2},
+				Datanode: kvstorev1.HbaseClusterDeployment{Name: "dn", Size: 3},
+				Hmaster:  kvstorev1.HbaseClusterDeployment{Name: "hmaster", Size: 2},
+			},
+			RackAwareness: &kvstorev1.HbaseClusterRackAwareness{},
+		},
+	}
+}
+
+// TestBuildRackTopology verifies pods are mapped by name and IP to the rack of their node, and unscheduled pods are
+// left out.
+func TestBuildRackTopology(t *testing.T) {
+	ctx := context.TODO()
+	c := newRackAwareCluster()
+	k8sMockClient := new(K8sMockClient)
+	k8sMockClient.On("List", ctx, &corev1.PodList{}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*corev1.PodList).Items = []corev1.Pod{
+				{ObjectMeta: metav1.ObjectMeta{Name: "dn-0"}, Spec: corev1.PodSpec{NodeName: "node-a"}, Status: corev1.PodStatus{PodIP: "10.0.0.1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "dn-1"}, Spec: corev1.PodSpec{NodeName: "node-b"}, Status: corev1.PodStatus{PodIP: "10.0.0.2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "dn-2"}, Spec: corev1.PodSpec{NodeName: "node-a"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "dn-3"}},
+			}
+		}).
+		Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "node-a"}, &corev1.Node{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*corev1.Node).Labels = map[string]string{defaultRackTopologyKey: "zone-a"}
+		}).
+		Return(nil).Once()
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: "node-b"}, &corev1.Node{}).Return(nil).Once()
+
+	topology, err := buildRackTopology(ctx, c, []kvstorev1.HbaseClusterDeployment{c.Spec.Deployments.Datanode}, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1 /zone-a\n10.0.0.2 /default-rack\ndn-0 /zone-a\ndn-1 /default-rack\ndn-2 /zone-a\n", topology)
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestBuildRackTopologyConfigMap verifies the script falls back to the default rack.
+func TestBuildRackTopologyConfigMap(t *testing.T) {
+	cfg := buildRackTopologyConfigMap("test", testNamespace, &kvstorev1.HbaseClusterRackAwareness{DefaultRack: "/rack-0"}, "dn-0 /zone-a\n")
+	assert.Equal(t, "test-rack-topology", cfg.Name)
+	assert.Equal(t, "dn-0 /zone-a\n", cfg.Data[rackTopologyDataFile])
+	assert.Contains(t, cfg.Data[rackTopologyScriptFile], `echo -n "${rack:-/rack-0} "`)
+}
+
+// TestInjectRackTopology verifies the topology is mounted into namenode and hmaster pods only, and the script set in
+// the site files.
+func TestInjectRackTopology(t *testing.T) {
+	c := newRackAwareCluster()
+	newSS := func() *appsv1.StatefulSet {
+		ss := &appsv1.StatefulSet{}
+		ss.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main"}, {Name: "sidecar"}}
+		return ss
+	}
+
+	ss := newSS()
+	injectRackTopology(ss, c, c.Spec.Deployments.Namenode)
+	assert.Equal(t, "test-rack-topology", ss.Spec.Template.Spec.Volumes[0].ConfigMap.Name)
+	assert.Equal(t, int32(0755), *ss.Spec.Template.Spec.Volumes[0].ConfigMap.DefaultMode)
+	for _, container := range ss.Spec.Template.Spec.Containers {
+		assert.Equal(t, []corev1.VolumeMount{{Name: rackTopologyVolume, MountPath: defaultRackTopologyMountPath, ReadOnly: true}}, container.VolumeMounts)
+	}
+
+	ss = newSS()
+	injectRackTopology(ss, c, c.Spec.Deployments.Datanode)
+	assert.Empty(t, ss.Spec.Template.Spec.Volumes)
+
+	hbaseConfig, hadoopConfig := rackAwarenessConfig(c.Spec.RackAwareness)
+	hbaseSite, _ := parseConfigProperties("hbase-site.xml", hbaseConfig["hbase-site.xml"])
+	coreSite, _ := parseConfigProperties("core-site.xml", hadoopConfig["core-site.xml"])
+	assert.Equal(t, "/etc/hbase-rack-topology/rack_topology", hbaseSite["net.topology.script.file.name"])
+	assert.Equal(t, "/etc/hbase-rack-topology/rack_topology", coreSite["net.topology.script.file.name"])
+}
@@ -0,0 +1,149 @@
+package controllers
+
+import (
+	context "context"
+	sort "sort"
+	strconv "strconv"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// rsGroupNameOf returns the name of the RSGroup of the tenant
+func rsGroupNameOf(t *kvstorev1.HbaseTenant) string {
+	if len(t.Spec.RSGroup.Name) > 0 {
+		return t.Spec.RSGroup.Name
+	}
+	return t.Name
+}
+
+// regionServerName returns the host:port a regionserver pod registers with in HBase. Pods of a StatefulSet are
+// reachable through the FQDN of their hostname under the governing service
+func regionServerName(pod corev1.Pod, g *kvstorev1.HbaseTenantRSGroup) string {
+	port, domain := g.Port, g.ClusterDomain
+	if port == 0 {
+		port = 16020
+	}
+	if len(domain) == 0 {
+		domain = "cluster.local"
+	}
+
+	host := pod.Name
+	if len(pod.Spec.Hostname) > 0 {
+		host = pod.Spec.Hostname
+	}
+	if len(pod.Spec.Subdomain) > 0 {
+		host = strings.Join([]string{host, pod.Spec.Subdomain, pod.Namespace, "svc", domain}, ".")
+	}
+	return host + ":" + strconv.Itoa(int(port))
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// missingFrom returns the values which are not in the existing ones, in order
+func missingFrom(existing []string, values []string) []string {
+	seen := map[string]bool{}
+	for _, v := range existing {
+		seen[v] = true
+	}
+	missing := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			missing = append(missing, v)
+		}
+	}
+	return missing
+}
+
+// reconcileRSGroup creates the RSGroup of the tenant through the admin endpoint, and moves its ready regionservers and
+// its HBase namespaces into it. Servers and namespaces which left the tenant are not moved back out of the group
+func reconcileRSGroup(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTenant, selector map[string]string, recorder record.EventRecorder,
+	cl client.Client) (ctrl.Result, error) {
+	name := rsGroupNameOf(t)
+	if len(t.Spec.Configuration.AdminEndpoint) == 0 {
+		log.Info("Admin endpoint not set, RSGroup of the tenant is not managed", "RSGroup", name)
+		return ctrl.Result{}, nil
+	}
+	admin := newHbaseAdmin(t.Spec.Configuration.AdminEndpoint)
+
+	group, err := admin.GetRSGroup(ctx, name)
+	if err != nil {
+		return rsGroupFailed(log, t, name, err, recorder)
+	}
+	if group == nil {
+		log.Info("Creating RSGroup", "RSGroup", name)
+		if err = admin.AddRSGroup(ctx, name); err != nil {
+			return rsGroupFailed(log, t, name, err, recorder)
+		}
+		recorder.Event(t, corev1.EventTypeNormal, REASON_RSGROUP_CREATED, "Created RSGroup "+name)
+		group = &RSGroupInfo{Name: name}
+	}
+
+	pods := &corev1.PodList{}
+	if err = cl.List(ctx, pods, client.InNamespace(t.Namespace), client.MatchingLabels(selector)); err != nil {
+		log.Error(err, "Failed to list regionservers of the tenant", "RSGroup", name)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	servers := []string{}
+	for _, pod := range pods.Items {
+		if isPodReady(pod) {
+			servers = append(servers, regionServerName(pod, t.Spec.RSGroup))
+		}
+	}
+
+	if missing := missingFrom(group.Servers, servers); len(missing) > 0 {
+		log.Info("Moving regionservers into RSGroup", "RSGroup", name, "Servers", missing)
+		if err = admin.MoveServersToRSGroup(ctx, name, missing); err != nil {
+			return rsGroupFailed(log, t, name, err, recorder)
+		}
+		recorder.Event(t, corev1.EventTypeNormal, REASON_RSGROUP_SERVERS_MOVED, "Moved "+strings.Join(missing, ", ")+" into RSGroup "+name)
+		group.Servers = append(group.Servers, missing...)
+	}
+
+	if missing := missingFrom(group.Namespaces, t.Spec.HbaseNamespaces); len(missing) > 0 {
+		log.Info("Moving HBase namespaces into RSGroup", "RSGroup", name, "Namespaces", missing)
+		if err = admin.MoveNamespacesToRSGroup(ctx, name, missing); err != nil {
+			return rsGroupFailed(log, t, name, err, recorder)
+		}
+		recorder.Event(t, corev1.EventTypeNormal, REASON_RSGROUP_NAMESPACES_MOVED, "Moved "+strings.Join(missing, ", ")+" into RSGroup "+name)
+		group.Namespaces = append(group.Namespaces, missing...)
+	}
+
+	status := &kvstorev1.RSGroupStatus{Name: name, Servers: group.Servers, Namespaces: group.Namespaces}
+	sort.Strings(status.Servers)
+	sort.Strings(status.Namespaces)
+	if equality.Semantic.DeepEqual(status, t.Status.RSGroup) {
+		return ctrl.Result{}, nil
+	}
+	t.Status.RSGroup = status
+	if err = cl.Status().Update(ctx, t); err != nil {
+		log.Error(err, "Failed to update HbaseTenant status with RSGroup", "RSGroup", name)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func rsGroupFailed(log logr.Logger, t *kvstorev1.HbaseTenant, name string, err error, recorder record.EventRecorder) (ctrl.Result, error) {
+	recordWarning(recorder, t, REASON_RSGROUP_UPDATE_FAILED, err)
+	log.Error(err, "Failed to update RSGroup through the admin endpoint", "RSGroup", name)
+	return ctrl.Result{RequeueAfter: time.Second * 5}, err
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newRegionServerPod(name string, ready bool) corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec:       corev1.PodSpec{Hostname: name, Subdomain: "tenant"},
+		Status:     corev1.PodStatus{Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}}},
+	}
+}
+
+func newRSGroupTenant(endpoint string) *kvstorev1.HbaseTenant {
+	t := &kvstorev1.HbaseTenant{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Namespace: testNamespace}}
+	t.Spec.Configuration.AdminEndpoint = endpoint
+	t.Spec.RSGroup = &kvstorev1.HbaseTenantRSGroup{}
+	t.Spec.HbaseNamespaces = []string{"team"}
+	return t
+}
+
+func mockRegionServerPods(k8sMockClient *K8sMockClient, ctx context.Context, selector map[string]string, pods ...corev1.Pod) {
+	k8sMockClient.On("List", ctx, &corev1.PodList{}, []client.ListOption{client.InNamespace(testNamespace), client.MatchingLabels(selector)}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*corev1.PodList).Items = pods
+		}).
+		Return(nil)
+}
+
+// TestRegionServerName verifies regionservers are named after the FQDN of their pod and the configured port.
+func TestRegionServerName(t *testing.T) {
+	pod := newRegionServerPod("rs-0", true)
+	assert.Equal(t, "rs-0.tenant."+testNamespace+".svc.cluster.local:16020", regionServerName(pod, &kvstorev1.HbaseTenantRSGroup{}))
+	assert.Equal(t, "rs-0.tenant."+testNamespace+".svc.example.org:16030", regionServerName(pod, &kvstorev1.HbaseTenantRSGroup{Port: 16030, ClusterDomain: "example.org"}))
+
+	pod.Spec = corev1.PodSpec{}
+	assert.Equal(t, "rs-0:16020", regionServerName(pod, &kvstorev1.HbaseTenantRSGroup{}))
+}
+
+// TestIsPodReady verifies only ready pods which are not being deleted are considered.
+func TestIsPodReady(t *testing.T) {
+	assert.True(t, isPodReady(newRegionServerPod("rs-0", true)))
+	assert.False(t, isPodReady(newRegionServerPod("rs-0", false)))
+	assert.False(t, isPodReady(corev1.Pod{}))
+
+	pod := newRegionServerPod("rs-0", true)
+	pod.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.False(t, isPodReady(pod))
+}
+
+// TestReconcileRSGroup_Created verifies the group is created with the ready regionservers and the hbase namespaces of the tenant.
+func TestReconcileRSGroup_Created(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	tenant := newRSGroupTenant(server.URL)
+	selector := map[string]string{"app": "hbasecluster"}
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	mockRegionServerPods(k8sMockClient, ctx, selector, newRegionServerPod("rs-1", true), newRegionServerPod("rs-0", true), newRegionServerPod("rs-2", false))
+	recorder := record.NewFakeRecorder(10)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil)
+
+	result, err := reconcileRSGroup(ctx, ctrl.Log.WithName("test"), tenant, selector, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+
+	servers := []string{"rs-0.tenant." + testNamespace + ".svc.cluster.local:16020", "rs-1.tenant." + testNamespace + ".svc.cluster.local:16020"}
+	assert.ElementsMatch(t, servers, fake.groups["tenant"].Servers)
+	assert.Equal(t, []string{"team"}, fake.groups["tenant"].Namespaces)
+	assert.Equal(t, &kvstorev1.RSGroupStatus{Name: "tenant", Servers: servers, Namespaces: []string{"team"}}, tenant.Status.RSGroup)
+	assert.ElementsMatch(t, []string{REASON_RSGROUP_CREATED, REASON_RSGROUP_SERVERS_MOVED, REASON_RSGROUP_NAMESPACES_MOVED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	statusWriter.AssertExpectations(t)
+}
+
+// TestReconcileRSGroup_UpToDate verifies nothing is moved nor updated once the group is up to date.
+func TestReconcileRSGroup_UpToDate(t *testing.T) {
+	fake, server := newFakeHbaseAdminServer(t)
+	tenant := newRSGroupTenant(server.URL)
+	tenant.Spec.RSGroup.Name = "team-group"
+	server0 := "rs-0.tenant." + testNamespace + ".svc.cluster.local:16020"
+	fake.groups["team-group"] = &RSGroupInfo{Name: "team-group", Servers: []string{server0}, Namespaces: []string{"team"}}
+	tenant.Status.RSGroup = &kvstorev1.RSGroupStatus{Name: "team-group", Servers: []string{server0}, Namespaces: []string{"team"}}
+
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	mockRegionServerPods(k8sMockClient, ctx, nil, newRegionServerPod("rs-0", true))
+
+	result, err := reconcileRSGroup(ctx, ctrl.Log.WithName("test"), tenant, nil, record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{"GET /admin/rsgroups/team-group"}, fake.requests)
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Status")
+}
+
+// TestReconcileRSGroup_AdminFailure verifies failures of the admin endpoint are reported and retried.
+func TestReconcileRSGroup_AdminFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "master not running", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := reconcileRSGroup(ctx, ctrl.Log.WithName("test"), newRSGroupTenant(server.URL), nil, recorder, k8sMockClient)
+	assert.ErrorContains(t, err, "master not running")
+	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_RSGROUP_UPDATE_FAILED}, recordedReasons(recorder))
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReconcileRSGroup_NoAdminEndpoint verifies the group is not managed without an admin endpoint.
+func TestReconcileRSGroup_NoAdminEndpoint(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+
+	result, err := reconcileRSGroup(context.TODO(), ctrl.Log.WithName("test"), newRSGroupTenant(""), nil, record.NewFakeRecorder(10), k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	k8sMockClient.AssertExpectations(t)
+}
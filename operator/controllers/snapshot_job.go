@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	context "context"
+	errs "errors"
+	fmt "fmt"
+	regexp "regexp"
+	strconv "strconv"
+	strings "strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+)
+
+// EXPORT_SNAPSHOT_CLASS tool copying snapshot files between clusters and filesystems
+const EXPORT_SNAPSHOT_CLASS = "org.apache.hadoop.hbase.snapshot.ExportSnapshot"
+
+// snapshotJobLabel is set on the pods of snapshot jobs to the name of their cluster, whose network policies allow them
+const snapshotJobLabel = "hbasecluster_snapshot_job"
+
+// snapshotJobScript runs hbase with the arguments of the container, and reports the bytes copied by ExportSnapshot as
+// termination message. Logs are the termination message of failed containers
+const snapshotJobScript = `set -o pipefail
+"$HBASE_HOME/bin/hbase" "$@" 2>&1 | tee /tmp/snapshot-job.log
+status=$?
+if [ $status -eq 0 ]; then
+  grep -o 'BYTES_COPIED=[0-9]*' /tmp/snapshot-job.log | tail -1 > /dev/termination-log
+fi
+exit $status`
+
+// bytesCopiedPattern matches the counter of ExportSnapshot in the termination message
+var bytesCopiedPattern = regexp.MustCompile(`BYTES_COPIED=([0-9]+)`)
+
+// snapshotJobResult is the outcome of a snapshot job, once finished
+type snapshotJobResult struct {
+	finished    bool
+	succeeded   bool
+	bytesCopied int64
+	message     string
+}
+
+// exportSnapshotArgs returns the arguments of ExportSnapshot copying the snapshot from one filesystem to another.
+// Generic -D options come first, as ToolRunner expects
+func exportSnapshotArgs(snapshot string, from string, to string, location kvstorev1.HbaseBackupLocation, spec kvstorev1.HbaseSnapshotJobSpec) []string {
+	args := []string{EXPORT_SNAPSHOT_CLASS}
+	for _, key := range sortedKeys(location.Properties) {
+		args = append(args, "-D"+key+"="+location.Properties[key])
+	}
+	args = append(args, "-snapshot", snapshot)
+	if len(from) > 0 {
+		args = append(args, "-copy-from", from)
+	}
+	args = append(args, "-copy-to", to)
+	if spec.Mappers != nil {
+		args = append(args, "-mappers", strconv.Itoa(int(*spec.Mappers)))
+	}
+	if spec.Bandwidth != nil {
+		args = append(args, "-bandwidth", strconv.Itoa(int(*spec.Bandwidth)))
+	}
+	return args
+}
+
+// hbaseRootDirOf returns hbase.rootdir of the cluster, where imported snapshots are copied to
+func hbaseRootDirOf(c kvstorev1.HbaseClusterConfiguration) (string, error) {
+	properties, _ := parseConfigProperties("hbase-site.xml", c.HbaseConfig["hbase-site.xml"])
+	rootDir := properties["hbase.rootdir"]
+	if len(rootDir) == 0 {
+		return "", fmt.Errorf("hbase.rootdir is not set in hbase-site.xml of the cluster")
+	}
+	return rootDir, nil
+}
+
+// buildSnapshotJob returns the job running hbase with the given arguments, on the image and configuration of the
+// cluster along with the volumes and credentials of the location
+func buildSnapshotJob(name string, namespace string, cluster referencedCluster, location kvstorev1.HbaseBackupLocation,
+	spec kvstorev1.HbaseSnapshotJobSpec, args []string) (*batchv1.Job, error) {
+	volumes, err := buildVolumes(cluster.Configuration, location.Volumes)
+	if err != nil {
+		return nil, err
+	}
+
+	container := corev1.Container{
+		Name:    "snapshot",
+		Image:   cluster.BaseImage,
+		Command: []string{"/bin/bash", "-c", snapshotJobScript, "snapshot-job"},
+		Args:    args,
+		Env: []corev1.EnvVar{
+			{Name: "HBASE_CONF_DIR", Value: cluster.Configuration.HbaseConfigMountPath},
+			{Name: "HADOOP_CONF_DIR", Value: cluster.Configuration.HadoopConfigMountPath},
+		},
+		Resources:                spec.Resources,
+		VolumeMounts:             buildVolumeMounts(location.VolumeMounts, cluster.Configuration),
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+	}
+	if len(location.CredentialsSecret) > 0 {
+		container.EnvFrom = []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: location.CredentialsSecret}},
+		}}
+	}
+
+	backoffLimit := int32(0)
+	if spec.BackoffLimit != nil {
+		backoffLimit = *spec.BackoffLimit
+	}
+	podSpec := corev1.PodSpec{
+		Containers:    []corev1.Container{container},
+		Volumes:       volumes,
+		RestartPolicy: corev1.RestartPolicyNever,
+	}
+	if cluster.FSGroup > 0 {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{FSGroup: &cluster.FSGroup}
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{snapshotJobLabel: cluster.Name}},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
+
+// snapshotJobResultOf returns the outcome of the job once it completed or failed, along with the bytes copied or the
+// cause of the failure from the termination message of its pods
+func snapshotJobResultOf(ctx context.Context, job *batchv1.Job, cl client.Client) (snapshotJobResult, error) {
+	result := snapshotJobResult{}
+	for _, c := range job.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		if c.Type == batchv1.JobComplete {
+			result.finished, result.succeeded = true, true
+		} else if c.Type == batchv1.JobFailed {
+			result.finished, result.message = true, c.Message
+		}
+	}
+	if !result.finished {
+		return result, nil
+	}
+
+	pods := &corev1.PodList{}
+	if err := cl.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return result, err
+	}
+	for _, pod := range pods.Items {
+		for _, s := range pod.Status.ContainerStatuses {
+			terminated := s.State.Terminated
+			if terminated == nil || (terminated.ExitCode == 0) != result.succeeded {
+				continue
+			}
+			if !result.succeeded && len(strings.TrimSpace(terminated.Message)) > 0 {
+				result.message = strings.TrimSpace(terminated.Message)
+			}
+			if m := bytesCopiedPattern.FindStringSubmatch(terminated.Message); result.succeeded && m != nil {
+				result.bytesCopied, _ = strconv.ParseInt(m[1], 10, 64)
+			}
+		}
+	}
+	return result, nil
+}
+
+// runSnapshotJob creates the job unless it exists already
+func runSnapshotJob(ctx context.Context, job *batchv1.Job, cl client.Client) error {
+	err := cl.Create(ctx, job)
+	if errors.IsAlreadyExists(err) {
+		return nil
+	}
+	return err
+}
+
+// isRejectedByHbase is true for errors returned by the admin endpoint, as opposed to failures reaching it
+func isRejectedByHbase(err error) bool {
+	var adminErr *hbaseAdminError
+	return errs.As(err, &adminErr)
+}
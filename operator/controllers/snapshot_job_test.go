@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newSnapshotJobCluster() referencedCluster {
+	return referencedCluster{
+		Name:      "cluster",
+		BaseImage: "hbase:2.5.8",
+		FSGroup:   1011,
+		Configuration: kvstorev1.HbaseClusterConfiguration{
+			HbaseConfigName:       "hbase-config",
+			HbaseConfigMountPath:  "/etc/hbase",
+			HadoopConfigName:      "hadoop-config",
+			HadoopConfigMountPath: "/etc/hadoop",
+			HbaseConfig: map[string]string{"hbase-site.xml": `<configuration>
+  <property><name>hbase.rootdir</name><value>hdfs://namenode:8020/hbase</value></property>
+</configuration>`},
+		},
+	}
+}
+
+func newSnapshotJobScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	_ = kvstorev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	return scheme
+}
+
+// mockSnapshotJobPods returns the pods of the job with a single terminated container
+func mockSnapshotJobPods(k8sMockClient *K8sMockClient, ctx context.Context, job string, exitCode int32, message string) {
+	k8sMockClient.On("List", ctx, &corev1.PodList{}, []client.ListOption{client.InNamespace(testNamespace), client.MatchingLabels{"job-name": job}}).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*corev1.PodList).Items = []corev1.Pod{{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Message: message}},
+			}}}}}
+		}).
+		Return(nil)
+}
+
+// TestBuildSnapshotJob_LocalFilesystem verifies a file:// target is exported to through the volumes of the location,
+// on the image and configuration of the cluster.
+func TestBuildSnapshotJob_LocalFilesystem(t *testing.T) {
+	location := kvstorev1.HbaseBackupLocation{
+		URL:          "file:///backups",
+		Volumes:      []kvstorev1.HbaseClusterVolume{{Name: "backups", VolumeSource: "HostPath", Path: "/mnt/backups"}},
+		VolumeMounts: []kvstorev1.HbaseClusterVolumeMount{{Name: "backups", MountPath: "/backups"}},
+	}
+	args := exportSnapshotArgs("orders-snapshot", "", location.URL, location, kvstorev1.HbaseSnapshotJobSpec{})
+	job, err := buildSnapshotJob("nightly-export", testNamespace, newSnapshotJobCluster(), location, kvstorev1.HbaseSnapshotJobSpec{}, args)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{EXPORT_SNAPSHOT_CLASS, "-snapshot", "orders-snapshot", "-copy-to", "file:///backups"}, args)
+	assert.Equal(t, int32(0), *job.Spec.BackoffLimit)
+	assert.Equal(t, map[string]string{snapshotJobLabel: "cluster"}, job.Spec.Template.Labels)
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, corev1.RestartPolicyNever, pod.RestartPolicy)
+	assert.Equal(t, int64(1011), *pod.SecurityContext.FSGroup)
+	assert.Equal(t, "/mnt/backups", pod.Volumes[2].HostPath.Path)
+	container := pod.Containers[0]
+	assert.Equal(t, "hbase:2.5.8", container.Image)
+	assert.Equal(t, args, container.Args)
+	assert.Equal(t, corev1.TerminationMessageFallbackToLogsOnError, container.TerminationMessagePolicy)
+	assert.Contains(t, container.Env, corev1.EnvVar{Name: "HBASE_CONF_DIR", Value: "/etc/hbase"})
+	assert.Equal(t, []string{"backups", "hbase-config", "hadoop-config"}, []string{container.VolumeMounts[0].Name, container.VolumeMounts[1].Name,
+		container.VolumeMounts[2].Name})
+	assert.Empty(t, container.EnvFrom)
+}
+
+// TestBuildSnapshotJob_ObjectStore verifies an s3a target such as MinIO gets its properties and credentials.
+func TestBuildSnapshotJob_ObjectStore(t *testing.T) {
+	mappers, bandwidth := int32(4), int32(50)
+	location := kvstorev1.HbaseBackupLocation{
+		URL:               "s3a://backups/hbase",
+		CredentialsSecret: "minio-credentials",
+		Properties:        map[string]string{"fs.s3a.path.style.access": "true", "fs.s3a.endpoint": "http://minio:9000"},
+	}
+	spec := kvstorev1.HbaseSnapshotJobSpec{Mappers: &mappers, Bandwidth: &bandwidth}
+	args := exportSnapshotArgs("orders-snapshot", "", location.URL, location, spec)
+	job, err := buildSnapshotJob("nightly-export", testNamespace, newSnapshotJobCluster(), location, spec, args)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{EXPORT_SNAPSHOT_CLASS, "-Dfs.s3a.endpoint=http://minio:9000", "-Dfs.s3a.path.style.access=true",
+		"-snapshot", "orders-snapshot", "-copy-to", "s3a://backups/hbase", "-mappers", "4", "-bandwidth", "50"}, args)
+	assert.Equal(t, "minio-credentials", job.Spec.Template.Spec.Containers[0].EnvFrom[0].SecretRef.Name)
+}
+
+// TestSnapshotJobResultOf verifies bytes copied are read from succeeded pods, and the cause from failed ones.
+func TestSnapshotJobResultOf(t *testing.T) {
+	ctx := context.TODO()
+	running := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: testNamespace}}
+	result, err := snapshotJobResultOf(ctx, running, new(K8sMockClient))
+	assert.NoError(t, err)
+	assert.False(t, result.finished)
+
+	k8sMockClient := new(K8sMockClient)
+	mockSnapshotJobPods(k8sMockClient, ctx, "complete", 0, "BYTES_COPIED=2048\n")
+	complete := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "complete", Namespace: testNamespace},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}}}
+	result, err = snapshotJobResultOf(ctx, complete, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshotJobResult{finished: true, succeeded: true, bytesCopied: 2048}, result)
+
+	mockSnapshotJobPods(k8sMockClient, ctx, "failed", 1, "java.io.FileNotFoundException: snapshot orders-snapshot\n")
+	failed := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "failed", Namespace: testNamespace},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Message: "BackoffLimitExceeded"}}}}
+	result, err = snapshotJobResultOf(ctx, failed, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, snapshotJobResult{finished: true, message: "java.io.FileNotFoundException: snapshot orders-snapshot"}, result)
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestHbaseRootDirOf verifies imports fail without hbase.rootdir.
+func TestHbaseRootDirOf(t *testing.T) {
+	rootDir, err := hbaseRootDirOf(newSnapshotJobCluster().Configuration)
+	assert.NoError(t, err)
+	assert.Equal(t, "hdfs://namenode:8020/hbase", rootDir)
+	_, err = hbaseRootDirOf(kvstorev1.HbaseClusterConfiguration{})
+	assert.Error(t, err)
+}
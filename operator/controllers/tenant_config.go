@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	context "context"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	field "k8s.io/apimachinery/pkg/util/validation/field"
+	record "k8s.io/client-go/tools/record"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// CONDITION_TENANT_CONFIG_VALID condition of objects rendering ConfigMaps, false while some of their tenant config
+// overrides are ignored, as they target namespaces the ConfigMaps are not rendered in or are malformed
+const CONDITION_TENANT_CONFIG_VALID = "TenantConfigValid"
+
+// tenantConfigFiles returns the files overridden in a namespace. When several overrides select it, later ones win
+func tenantConfigFiles(tenantConfig []kvstorev1.HbaseTenantConfigOverride, namespace string) map[string]string {
+	files := map[string]string{}
+	for _, o := range tenantConfig {
+		if o.Namespace != namespace {
+			continue
+		}
+		for k, v := range o.Files {
+			files[k] = v
+		}
+	}
+	return files
+}
+
+// resolveTenantConfig returns the configuration with tenant config overrides selecting namespaces by labels expanded
+// into an override for each of the selected namespaces. Namespaces other than the given ones are never selected
+func resolveTenantConfig(ctx context.Context, log logr.Logger, cl client.Client, c kvstorev1.HbaseClusterConfiguration, namespaces []string) (kvstorev1.HbaseClusterConfiguration, error) {
+	var err error
+	if c.HbaseTenantConfig, err = resolveTenantConfigOverrides(ctx, log, cl, c.HbaseTenantConfig, namespaces); err != nil {
+		return c, err
+	}
+	c.HadoopTenantConfig, err = resolveTenantConfigOverrides(ctx, log, cl, c.HadoopTenantConfig, namespaces)
+	return c, err
+}
+
+func resolveTenantConfigOverrides(ctx context.Context, log logr.Logger, cl client.Client, overrides []kvstorev1.HbaseTenantConfigOverride, namespaces []string) ([]kvstorev1.HbaseTenantConfigOverride, error) {
+	allowed := map[string]bool{}
+	for _, ns := range namespaces {
+		allowed[ns] = true
+	}
+
+	resolved := []kvstorev1.HbaseTenantConfigOverride{}
+	for _, o := range overrides {
+		if o.NamespaceSelector == nil {
+			resolved = append(resolved, o)
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(o.NamespaceSelector)
+		if err != nil {
+			log.Error(err, "Invalid namespace selector of tenant config override")
+			return nil, err
+		}
+		nsList := &corev1.NamespaceList{}
+		if err = cl.List(ctx, nsList, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			log.Error(err, "Failed to list namespaces selected by tenant config override", "Selector", selector.String())
+			return nil, err
+		}
+		for _, ns := range nsList.Items {
+			if allowed[ns.Name] {
+				resolved = append(resolved, kvstorev1.HbaseTenantConfigOverride{Namespace: ns.Name, Files: o.Files})
+			}
+		}
+	}
+	return resolved, nil
+}
+
+// appendTenantConfigOverrideStatus records the files of the ConfigMap replaced by tenant config overrides, if any
+func appendTenantConfigOverrideStatus(status []kvstorev1.TenantConfigOverrideStatus, cfg *corev1.ConfigMap, tenantConfig []kvstorev1.HbaseTenantConfigOverride) []kvstorev1.TenantConfigOverrideStatus {
+	files := tenantConfigFiles(tenantConfig, cfg.Namespace)
+	if len(files) == 0 {
+		return status
+	}
+	return append(status, kvstorev1.TenantConfigOverrideStatus{ConfigMap: cfg.Namespace + "/" + cfg.Name, Files: sortedKeys(files)})
+}
+
+// reportTenantConfigOverrides updates the status with the tenant config overrides applied, when they changed
+func reportTenantConfigOverrides(ctx context.Context, log logr.Logger, obj client.Object, current *[]kvstorev1.TenantConfigOverrideStatus,
+	applied []kvstorev1.TenantConfigOverrideStatus, cl client.Client) error {
+	if len(*current) == 0 && len(applied) == 0 || equality.Semantic.DeepEqual(*current, applied) {
+		return nil
+	}
+	*current = applied
+	if err := cl.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "Failed to update status with tenant config overrides")
+		return err
+	}
+	return nil
+}
+
+// reportTenantConfigValidity reports the tenant config overrides ignored when rendering the ConfigMaps in the given
+// namespaces with the TenantConfigValid condition, which is removed once they are all valid
+func reportTenantConfigValidity(ctx context.Context, log logr.Logger, obj client.Object, conditions *[]metav1.Condition,
+	c kvstorev1.HbaseClusterConfiguration, namespaces []string, recorder record.EventRecorder, cl client.Client) error {
+	errs := kvstorev1.ValidateTenantConfig(field.NewPath("spec", "configuration"), c, namespaces)
+	changed := false
+	if len(errs) == 0 {
+		changed = meta.RemoveStatusCondition(conditions, CONDITION_TENANT_CONFIG_VALID)
+	} else {
+		changed = meta.SetStatusCondition(conditions, metav1.Condition{Type: CONDITION_TENANT_CONFIG_VALID, Status: metav1.ConditionFalse,
+			ObservedGeneration: obj.GetGeneration(), Reason: REASON_TENANT_CONFIG_IGNORED, Message: errs.ToAggregate().Error()})
+		if changed {
+			recordWarning(recorder, obj, REASON_TENANT_CONFIG_IGNORED, errs.ToAggregate())
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := cl.Status().Update(ctx, obj); err != nil {
+		log.Error(err, "Failed to update status", "Condition", CONDITION_TENANT_CONFIG_VALID)
+		return err
+	}
+	return nil
+}
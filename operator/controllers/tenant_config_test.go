@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestTenantConfigFiles verifies later overrides of a namespace win.
+func TestTenantConfigFiles(t *testing.T) {
+	tenantConfig := []kvstorev1.HbaseTenantConfigOverride{
+		{Namespace: "tenant-ns", Files: map[string]string{"hbase-env.sh": "a", "log4j.properties": "b"}},
+		{Namespace: "other-ns", Files: map[string]string{"hbase-env.sh": "c"}},
+		{Namespace: "tenant-ns", Files: map[string]string{"hbase-env.sh": "d"}},
+	}
+	assert.Equal(t, map[string]string{"hbase-env.sh": "d", "log4j.properties": "b"}, tenantConfigFiles(tenantConfig, "tenant-ns"))
+	assert.Empty(t, tenantConfigFiles(tenantConfig, "unknown-ns"))
+}
+
+// TestResolveTenantConfig verifies namespace selectors are expanded to the selected tenant namespaces only.
+func TestResolveTenantConfig(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}}
+	k8sMockClient.On("List", ctx, &corev1.NamespaceList{}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(1).(*corev1.NamespaceList)
+			arg.Items = []corev1.Namespace{
+				{ObjectMeta: metav1.ObjectMeta{Name: tenantNamespace1}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "not-a-tenant-ns"}},
+			}
+		}).
+		Return(nil)
+
+	c := kvstorev1.HbaseClusterConfiguration{
+		HbaseTenantConfig: []kvstorev1.HbaseTenantConfigOverride{
+			{NamespaceSelector: selector, Files: map[string]string{"hbase-env.sh": "gold"}},
+			{Namespace: tenantNamespace2, Files: map[string]string{"hbase-env.sh": "silver"}},
+		},
+	}
+	resolved, err := resolveTenantConfig(ctx, ctrl.Log.WithName("test"), k8sMockClient, c, []string{tenantNamespace1, tenantNamespace2})
+	assert.NoError(t, err)
+	assert.Equal(t, []kvstorev1.HbaseTenantConfigOverride{
+		{Namespace: tenantNamespace1, Files: map[string]string{"hbase-env.sh": "gold"}},
+		{Namespace: tenantNamespace2, Files: map[string]string{"hbase-env.sh": "silver"}},
+	}, resolved.HbaseTenantConfig)
+	assert.Empty(t, resolved.HadoopTenantConfig)
+	assert.NotNil(t, c.HbaseTenantConfig[0].NamespaceSelector)
+	k8sMockClient.AssertNumberOfCalls(t, "List", 1)
+}
+
+// TestResolveTenantConfig_InvalidSelector verifies an invalid selector is returned as an error without listing namespaces.
+func TestResolveTenantConfig_InvalidSelector(t *testing.T) {
+	k8sMockClient := new(K8sMockClient)
+	c := kvstorev1.HbaseClusterConfiguration{
+		HadoopTenantConfig: []kvstorev1.HbaseTenantConfigOverride{{
+			NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Bogus"}}},
+			Files:             map[string]string{"hadoop-env.sh": "x"},
+		}},
+	}
+	_, err := resolveTenantConfig(context.TODO(), ctrl.Log.WithName("test"), k8sMockClient, c, []string{tenantNamespace1})
+	assert.Error(t, err)
+	k8sMockClient.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestReportTenantConfigOverrides verifies the status is only updated when the applied overrides change.
+func TestReportTenantConfigOverrides(t *testing.T) {
+	hbasetenant := getMockHbaseTenant()
+	tenantConfig := []kvstorev1.HbaseTenantConfigOverride{{Namespace: hbasetenant.Namespace, Files: map[string]string{"hbase-env.sh": "x", "hbase-site.xml": "y"}}}
+	cfg := buildConfigMap("hbase-config", hbasetenant.Name, hbasetenant.Namespace, nil, tenantConfig, ctrl.Log.WithName("test"))
+	overrides := appendTenantConfigOverrideStatus(nil, cfg, tenantConfig)
+	overrides = appendTenantConfigOverrideStatus(overrides, buildConfigMap("hadoop-config", hbasetenant.Name, hbasetenant.Namespace, nil, nil, ctrl.Log.WithName("test")), nil)
+	assert.Equal(t, []kvstorev1.TenantConfigOverrideStatus{{ConfigMap: hbasetenant.Namespace + "/hbase-config", Files: []string{"hbase-env.sh", "hbase-site.xml"}}}, overrides)
+
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	ctx := context.TODO()
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, mock.Anything).Return(nil)
+
+	log := ctrl.Log.WithName("test")
+	assert.NoError(t, reportTenantConfigOverrides(ctx, log, hbasetenant, &hbasetenant.Status.TenantConfigOverrides, nil, k8sMockClient))
+	assert.NoError(t, reportTenantConfigOverrides(ctx, log, hbasetenant, &hbasetenant.Status.TenantConfigOverrides, overrides, k8sMockClient))
+	assert.NoError(t, reportTenantConfigOverrides(ctx, log, hbasetenant, &hbasetenant.Status.TenantConfigOverrides, overrides, k8sMockClient))
+	assert.Equal(t, overrides, hbasetenant.Status.TenantConfigOverrides)
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, []client.UpdateOption(nil))
+}
+
+// TestReportTenantConfigValidity verifies overrides of namespaces which are not rendered are reported once, and the
+// condition removed once they are fixed.
+func TestReportTenantConfigValidity(t *testing.T) {
+	ctx := context.TODO()
+	cluster := &kvstorev1.HbaseCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace, Generation: 3}}
+	cluster.Spec.Configuration.HbaseTenantConfig = []kvstorev1.HbaseTenantConfigOverride{
+		{Namespace: "unknown-ns", Files: map[string]string{"hbase-env.sh": "a"}},
+	}
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, cluster).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+	namespaces := []string{"tenant-ns", testNamespace}
+
+	for i := 0; i < 2; i++ {
+		err := reportTenantConfigValidity(ctx, ctrl.Log.WithName("test"), cluster, &cluster.Status.Conditions, cluster.Spec.Configuration,
+			namespaces, recorder, k8sMockClient)
+		assert.NoError(t, err)
+	}
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, CONDITION_TENANT_CONFIG_VALID)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, int64(3), condition.ObservedGeneration)
+	assert.Contains(t, condition.Message, "unknown-ns")
+	assert.Equal(t, []string{REASON_TENANT_CONFIG_IGNORED}, recordedReasons(recorder))
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+
+	cluster.Spec.Configuration.HbaseTenantConfig[0].Namespace = "tenant-ns"
+	err := reportTenantConfigValidity(ctx, ctrl.Log.WithName("test"), cluster, &cluster.Status.Conditions, cluster.Spec.Configuration,
+		namespaces, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Empty(t, cluster.Status.Conditions)
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+}
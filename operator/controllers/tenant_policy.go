@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	context "context"
+	time "time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	errors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// CONDITION_POLICY_COMPLIANT HbaseTenant condition, false while the tenant is beyond the limits of an HbaseTenantPolicy
+const CONDITION_POLICY_COMPLIANT = "PolicyCompliant"
+
+// enforceTenantPolicies checks the tenant against the HbaseTenantPolicies applying to its namespace and reports the
+// outcome with the PolicyCompliant condition. A tenant beyond the limits is not rendered, a non empty result is
+// returned to recheck it later
+func enforceTenantPolicies(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTenant, recorder record.EventRecorder,
+	cl client.Client) (ctrl.Result, error) {
+	policies := &kvstorev1.HbaseTenantPolicyList{}
+	if err := cl.List(ctx, policies); err != nil {
+		log.Error(err, "Failed to list HbaseTenantPolicies")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	if len(policies.Items) == 0 {
+		if meta.RemoveStatusCondition(&t.Status.Conditions, CONDITION_POLICY_COMPLIANT) {
+			return ctrl.Result{}, updatePolicyCondition(ctx, log, t, cl)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// the regionserver limit is only checked when the tenant grows, so that tenants already beyond it keep running
+	grows := true
+	ss := &appsv1.StatefulSet{}
+	err := cl.Get(ctx, types.NamespacedName{Name: t.Spec.Datanode.Name, Namespace: t.Namespace}, ss)
+	if err == nil && ss.Spec.Replicas != nil {
+		grows = t.Spec.Datanode.Size > *ss.Spec.Replicas
+	} else if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get StatefulSet of HbaseTenant", "StatefulSet.Name", t.Spec.Datanode.Name)
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+	violations, err := kvstorev1.ValidateTenantPolicies(ctx, cl, policies.Items, t, t.Spec.BaseImage, grows)
+	if err != nil {
+		log.Error(err, "Failed to check HbaseTenant against HbaseTenantPolicies")
+		return ctrl.Result{RequeueAfter: time.Second * 5}, err
+	}
+
+	condition := metav1.Condition{Type: CONDITION_POLICY_COMPLIANT, Status: metav1.ConditionTrue, ObservedGeneration: t.Generation,
+		Reason: "WithinLimits", Message: "Within the limits of the HbaseTenantPolicies"}
+	if len(violations) > 0 {
+		condition.Status, condition.Reason, condition.Message = metav1.ConditionFalse, REASON_POLICY_VIOLATED, violations.ToAggregate().Error()
+	}
+	if meta.SetStatusCondition(&t.Status.Conditions, condition) {
+		if len(violations) > 0 {
+			recordWarning(recorder, t, REASON_POLICY_VIOLATED, violations.ToAggregate())
+		}
+		if err = updatePolicyCondition(ctx, log, t, cl); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 5}, err
+		}
+	}
+	if len(violations) > 0 {
+		log.Info("HbaseTenant is beyond the limits of HbaseTenantPolicies, not rendering it", "Violations", condition.Message)
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+func updatePolicyCondition(ctx context.Context, log logr.Logger, t *kvstorev1.HbaseTenant, cl client.Client) error {
+	if err := cl.Status().Update(ctx, t); err != nil {
+		log.Error(err, "Failed to update HbaseTenant status", "Condition", CONDITION_POLICY_COMPLIANT)
+		return err
+	}
+	return nil
+}
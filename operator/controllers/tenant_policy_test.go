@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TestEnforceTenantPolicies verifies a tenant growing beyond the regionservers of its policy is not rendered and
+// reported once, while a tenant which does not grow is.
+func TestEnforceTenantPolicies(t *testing.T) {
+	ctx := context.TODO()
+	tenant := getMockHbaseTenant()
+	tenant.Spec.Datanode.Size = 5
+	maxRegionServers := int32(4)
+	k8sMockClient := new(K8sMockClient)
+	k8sMockClient.On("List", ctx, &kvstorev1.HbaseTenantPolicyList{}, mock.Anything).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*kvstorev1.HbaseTenantPolicyList).Items = []kvstorev1.HbaseTenantPolicy{{
+				ObjectMeta: metav1.ObjectMeta{Name: "gold"},
+				Spec:       kvstorev1.HbaseTenantPolicySpec{Namespaces: []string{tenant.Namespace}, MaxRegionServers: &maxRegionServers},
+			}}
+		}).
+		Return(nil)
+	k8sMockClient.On("List", ctx, &corev1.NamespaceList{}, mock.Anything).Return(nil)
+	k8sMockClient.On("List", ctx, &kvstorev1.HbaseTenantList{}, mock.Anything).Return(nil)
+	replicas := int32(3)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: tenant.Spec.Datanode.Name, Namespace: tenant.Namespace}, &appsv1.StatefulSet{}).
+		Run(func(args mock.Arguments) {
+			args.Get(2).(*appsv1.StatefulSet).Spec.Replicas = &replicas
+		}).
+		Return(nil)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", ctx, tenant).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	for i := 0; i < 2; i++ {
+		result, err := enforceTenantPolicies(ctx, ctrl.Log.WithName("test"), tenant, recorder, k8sMockClient)
+		assert.NoError(t, err)
+		assert.Equal(t, ctrl.Result{RequeueAfter: time.Minute}, result)
+	}
+	condition := meta.FindStatusCondition(tenant.Status.Conditions, CONDITION_POLICY_COMPLIANT)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "exceeds the limit of 4 regionservers")
+	assert.Equal(t, []string{REASON_POLICY_VIOLATED}, recordedReasons(recorder))
+	statusWriter.AssertNumberOfCalls(t, "Update", 1)
+
+	tenant.Spec.Datanode.Size = replicas
+	result, err := enforceTenantPolicies(ctx, ctrl.Log.WithName("test"), tenant, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.True(t, meta.IsStatusConditionTrue(tenant.Status.Conditions, CONDITION_POLICY_COMPLIANT))
+	statusWriter.AssertNumberOfCalls(t, "Update", 2)
+}
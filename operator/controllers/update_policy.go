@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	context "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// getConfigUpdatePolicy returns the update policy of a CR, defaultPolicy is used when it is not set
+func getConfigUpdatePolicy(config kvstorev1.HbaseClusterConfiguration, defaultPolicy kvstorev1.ConfigUpdatePolicy) kvstorev1.ConfigUpdatePolicy {
+	if len(config.UpdatePolicy) > 0 {
+		return config.UpdatePolicy
+	}
+	return defaultPolicy
+}
+
+// updatePolicyOfLabel returns the update policy equivalent to a value of the deprecated RECONCILE_CONFIG_LABEL, or an
+// empty policy for unknown values
+func updatePolicyOfLabel(value string, config kvstorev1.HbaseClusterConfiguration) kvstorev1.ConfigUpdatePolicy {
+	switch value {
+	case "true", "yes":
+		if len(config.AdminEndpoint) > 0 {
+			return kvstorev1.ConfigUpdatePolicyHotReload
+		}
+		return kvstorev1.ConfigUpdatePolicyRollingRestart
+	case "config-only":
+		return kvstorev1.ConfigUpdatePolicyConfigOnly
+	}
+	return ""
+}
+
+// migrateConfigUpdatePolicy moves the deprecated RECONCILE_CONFIG_LABEL of a CR to spec.configuration.updatePolicy,
+// unless the policy is already set, and removes the label. Unknown values are kept along with a warning, as there is
+// no policy to migrate them to. Returns true when the CR was updated
+func migrateConfigUpdatePolicy(ctx context.Context, log logr.Logger, obj client.Object, config *kvstorev1.HbaseClusterConfiguration,
+	labels map[string]string, recorder record.EventRecorder, cl client.Client) (bool, error) {
+	value, exists := labels[RECONCILE_CONFIG_LABEL]
+	if !exists {
+		return false, nil
+	}
+	if len(config.UpdatePolicy) == 0 {
+		policy := updatePolicyOfLabel(value, *config)
+		if len(policy) == 0 {
+			log.Info("Unknown value of deprecated label, not migrated", "Label", RECONCILE_CONFIG_LABEL, "Value", value)
+			recordWarning(recorder, obj, REASON_UPDATE_POLICY_LABEL_IGNORED, fmt.Errorf(
+				"Label %s=%s has no equivalent update policy and is ignored, set spec.configuration.updatePolicy and remove it",
+				RECONCILE_CONFIG_LABEL, value))
+			return false, nil
+		}
+		config.UpdatePolicy = policy
+	}
+	delete(labels, RECONCILE_CONFIG_LABEL)
+	if err := cl.Update(ctx, obj); err != nil {
+		log.Error(err, "Failed to migrate deprecated label to update policy", "Label", RECONCILE_CONFIG_LABEL)
+		return false, err
+	}
+	recordEvent(recorder, obj, nil, corev1.EventTypeNormal, REASON_UPDATE_POLICY_MIGRATED,
+		fmt.Sprintf("Label %s=%s migrated to spec.configuration.updatePolicy %q", RECONCILE_CONFIG_LABEL, value, config.UpdatePolicy))
+	return true, nil
+}
+
+// isConfigReconciled returns true if ConfigMaps are created and updated by the operator
+func isConfigReconciled(policy kvstorev1.ConfigUpdatePolicy) bool {
+	return policy != kvstorev1.ConfigUpdatePolicyIgnore
+}
+
+// isRestartOnConfigChange returns true if StatefulSets are bound to the version of the hbase ConfigMap
+func isRestartOnConfigChange(policy kvstorev1.ConfigUpdatePolicy) bool {
+	return policy == kvstorev1.ConfigUpdatePolicyRollingRestart || policy == kvstorev1.ConfigUpdatePolicyHotReload
+}
+
+// isHotReloadEnabled returns true if changes which can be reloaded online skip the restart
+func isHotReloadEnabled(policy kvstorev1.ConfigUpdatePolicy, config kvstorev1.HbaseClusterConfiguration) bool {
+	return policy == kvstorev1.ConfigUpdatePolicyHotReload && len(config.AdminEndpoint) > 0
+}
+
+// getConfigVersion returns the config version to bind a StatefulSet to. Unless the policy restarts pods on a config
+// change, the version the existing StatefulSet is bound to is kept.
+func getConfigVersion(log logr.Logger, cl client.Client, ctx context.Context, policy kvstorev1.ConfigUpdatePolicy,
+	cfgName string, statefulSetName string, namespace string) string {
+	if isRestartOnConfigChange(policy) {
+		// Get the resource version of the configmap, if it is v2 then we will use the resource version
+		log.Info("Configmap restart enabled, new Resource Version will be used", "UpdatePolicy", policy)
+		return getCfgResourceVersionIfV2OrNil(log, cl, ctx, cfgName, namespace)
+	}
+	// if Restart of statefulSet is turned off, use existing Annotation as reference
+	log.Info("Configmap restart not enabled, getting existing resource version", "UpdatePolicy", policy)
+	return getStatefulSetAnnotation(log, cl, ctx, statefulSetName, namespace)
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// TestGetConfigUpdatePolicy verifies the spec field takes precedence over the default policy.
+func TestGetConfigUpdatePolicy(t *testing.T) {
+	assert.Equal(t, kvstorev1.ConfigUpdatePolicyIgnore, getConfigUpdatePolicy(kvstorev1.HbaseClusterConfiguration{}, kvstorev1.ConfigUpdatePolicyIgnore))
+	assert.Equal(t, kvstorev1.ConfigUpdatePolicyRollingRestart, getConfigUpdatePolicy(
+		kvstorev1.HbaseClusterConfiguration{UpdatePolicy: kvstorev1.ConfigUpdatePolicyRollingRestart}, kvstorev1.ConfigUpdatePolicyIgnore))
+}
+
+// TestMigrateConfigUpdatePolicy verifies the legacy label is migrated to the equivalent policy and removed, unless the
+// spec field is already set.
+func TestMigrateConfigUpdatePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   kvstorev1.HbaseClusterConfiguration
+		value    string
+		expected kvstorev1.ConfigUpdatePolicy
+	}{
+		{"spec field over label", kvstorev1.HbaseClusterConfiguration{UpdatePolicy: kvstorev1.ConfigUpdatePolicyIgnore}, "true", kvstorev1.ConfigUpdatePolicyIgnore},
+		{"label true", kvstorev1.HbaseClusterConfiguration{}, "true", kvstorev1.ConfigUpdatePolicyRollingRestart},
+		{"label yes", kvstorev1.HbaseClusterConfiguration{}, "yes", kvstorev1.ConfigUpdatePolicyRollingRestart},
+		{"label true with admin endpoint", kvstorev1.HbaseClusterConfiguration{AdminEndpoint: "http://admin"}, "true", kvstorev1.ConfigUpdatePolicyHotReload},
+		{"label config-only", kvstorev1.HbaseClusterConfiguration{}, "config-only", kvstorev1.ConfigUpdatePolicyConfigOnly},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.TODO()
+			tenant := &kvstorev1.HbaseTenant{Spec: kvstorev1.HbaseTenantSpec{Configuration: tt.config,
+				ServiceLabels: map[string]string{RECONCILE_CONFIG_LABEL: tt.value, "team": "kv"}}}
+			k8sMockClient := new(K8sMockClient)
+			k8sMockClient.On("Update", ctx, tenant, mock.Anything).Return(nil).Once()
+			recorder := record.NewFakeRecorder(10)
+
+			migrated, err := migrateConfigUpdatePolicy(ctx, ctrl.Log.WithName("test"), tenant, &tenant.Spec.Configuration,
+				tenant.Spec.ServiceLabels, recorder, k8sMockClient)
+			assert.NoError(t, err)
+			assert.True(t, migrated)
+			assert.Equal(t, tt.expected, tenant.Spec.Configuration.UpdatePolicy)
+			assert.Equal(t, map[string]string{"team": "kv"}, tenant.Spec.ServiceLabels)
+			assert.Equal(t, []string{REASON_UPDATE_POLICY_MIGRATED}, recordedReasons(recorder))
+
+			// nothing is left to migrate afterwards
+			migrated, err = migrateConfigUpdatePolicy(ctx, ctrl.Log.WithName("test"), tenant, &tenant.Spec.Configuration,
+				tenant.Spec.ServiceLabels, recorder, k8sMockClient)
+			assert.NoError(t, err)
+			assert.False(t, migrated)
+			k8sMockClient.AssertExpectations(t)
+		})
+	}
+}
+
+// TestMigrateConfigUpdatePolicy_UnknownLabel verifies an unknown value of the legacy label is kept and warned about
+// rather than migrated, and the label removed once the spec field is set.
+func TestMigrateConfigUpdatePolicy_UnknownLabel(t *testing.T) {
+	ctx := context.TODO()
+	tenant := &kvstorev1.HbaseTenant{Spec: kvstorev1.HbaseTenantSpec{
+		ServiceLabels: map[string]string{RECONCILE_CONFIG_LABEL: "false", "team": "kv"}}}
+	k8sMockClient := new(K8sMockClient)
+	recorder := record.NewFakeRecorder(10)
+
+	migrated, err := migrateConfigUpdatePolicy(ctx, ctrl.Log.WithName("test"), tenant, &tenant.Spec.Configuration,
+		tenant.Spec.ServiceLabels, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.False(t, migrated)
+	assert.Empty(t, tenant.Spec.Configuration.UpdatePolicy)
+	assert.Equal(t, map[string]string{RECONCILE_CONFIG_LABEL: "false", "team": "kv"}, tenant.Spec.ServiceLabels)
+	assert.Equal(t, []string{REASON_UPDATE_POLICY_LABEL_IGNORED}, recordedReasons(recorder))
+	k8sMockClient.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+
+	tenant.Spec.Configuration.UpdatePolicy = kvstorev1.ConfigUpdatePolicyConfigOnly
+	k8sMockClient.On("Update", ctx, tenant, mock.Anything).Return(nil).Once()
+	migrated, err = migrateConfigUpdatePolicy(ctx, ctrl.Log.WithName("test"), tenant, &tenant.Spec.Configuration,
+		tenant.Spec.ServiceLabels, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.True(t, migrated)
+	assert.Equal(t, map[string]string{"team": "kv"}, tenant.Spec.ServiceLabels)
+	k8sMockClient.AssertExpectations(t)
+}
+
+// TestBuildService_DeprecatedLabel verifies the deprecated label is not rendered as a label of the Service.
+func TestBuildService_DeprecatedLabel(t *testing.T) {
+	svc := buildService("dn", "test", testNamespace, map[string]string{RECONCILE_CONFIG_LABEL: "true", "team": "kv"}, nil, nil, true)
+	assert.Equal(t, map[string]string{"team": "kv"}, svc.Labels)
+}
+
+// TestConfigUpdatePolicyHelpers verifies what each policy reconciles, restarts and reloads.
+func TestConfigUpdatePolicyHelpers(t *testing.T) {
+	withEndpoint := kvstorev1.HbaseClusterConfiguration{AdminEndpoint: "http://admin"}
+
+	assert.False(t, isConfigReconciled(kvstorev1.ConfigUpdatePolicyIgnore))
+	assert.True(t, isConfigReconciled(kvstorev1.ConfigUpdatePolicyConfigOnly))
+
+	assert.False(t, isRestartOnConfigChange(kvstorev1.ConfigUpdatePolicyConfigOnly))
+	assert.True(t, isRestartOnConfigChange(kvstorev1.ConfigUpdatePolicyRollingRestart))
+	assert.True(t, isRestartOnConfigChange(kvstorev1.ConfigUpdatePolicyHotReload))
+
+	assert.True(t, isHotReloadEnabled(kvstorev1.ConfigUpdatePolicyHotReload, withEndpoint))
+	assert.False(t, isHotReloadEnabled(kvstorev1.ConfigUpdatePolicyHotReload, kvstorev1.HbaseClusterConfiguration{}))
+	assert.False(t, isHotReloadEnabled(kvstorev1.ConfigUpdatePolicyRollingRestart, withEndpoint))
+}
+
+// TestHbaseTenantReconciler_UpdatePolicyIgnore verifies ConfigMaps are left untouched with the Ignore policy.
+func TestHbaseTenantReconciler_UpdatePolicyIgnore(t *testing.T) {
+	resetHashStore()
+	hbasetenant := getMockHbaseTenant()
+	hbasetenant.Spec.Configuration.UpdatePolicy = kvstorev1.ConfigUpdatePolicyIgnore
+
+	k8sMockClient, reconciler, ctx, req := doTenantTestSetup()
+
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseTenant{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseTenant)
+			*arg = *hbasetenant
+		}).
+		Return(nil)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: hbasetenant.Spec.Datanode.Name, Namespace: hbasetenant.Namespace}, &appsv1.StatefulSet{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
+
+	mockSvc := buildService(hbasetenant.Name, hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.ServiceLabels, hbasetenant.Spec.ServiceSelectorLabels, []kvstorev1.HbaseClusterDeployment{hbasetenant.Spec.Datanode}, true)
+	ctrl.SetControllerReference(hbasetenant, mockSvc, reconciler.Scheme)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: mockSvc.Name, Namespace: hbasetenant.Namespace}, &corev1.Service{}).Return(errors.NewNotFound(schema.GroupResource{}, req.Name))
+	k8sMockClient.On("Create", ctx, mockSvc, []client.CreateOption(nil)).Return(nil)
+
+	mockSts, err := buildStatefulSet(hbasetenant.Name, hbasetenant.Namespace, hbasetenant.Spec.BaseImage, false,
+		hbasetenant.Spec.Configuration, "", hbasetenant.Spec.FSGroup, hbasetenant.Spec.Datanode, ctrl.Log.WithName("test"), false)
+	assert.NoError(t, err)
+	ctrl.SetControllerReference(hbasetenant, mockSts, reconciler.Scheme)
+	k8sMockClient.On("Create", ctx, mockSts, []client.CreateOption(nil)).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{Requeue: true, RequeueAfter: time.Second * 5}, result)
+
+	k8sMockClient.AssertExpectations(t)
+	k8sMockClient.AssertNotCalled(t, "Get", ctx, mock.Anything, &corev1.ConfigMap{})
+}
+
+// TestHbaseStandaloneReconciler_UpdatePolicyRollingRestart verifies the standalone StatefulSet is bound to the hbase ConfigMap version.
+func TestHbaseStandaloneReconciler_UpdatePolicyRollingRestart(t *testing.T) {
+	resetHashStore()
+	standalone := getMockHbaseStandalone()
+	standalone.Spec.Configuration.UpdatePolicy = kvstorev1.ConfigUpdatePolicyRollingRestart
+
+	k8sMockClient, reconciler, ctx, req := doStandaloneTestSetup()
+	populateStandaloneHashStore(standalone, reconciler)
+
+	k8sMockClient.On("Get", ctx, req.NamespacedName, &kvstorev1.HbaseStandalone{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*kvstorev1.HbaseStandalone)
+			*arg = *standalone
+		}).
+		Return(nil)
+
+	mockSvc := buildService(standalone.Name, standalone.Name, standalone.Namespace, standalone.Spec.ServiceLabels, standalone.Spec.ServiceSelectorLabels, []kvstorev1.HbaseClusterDeployment{standalone.Spec.Standalone}, true)
+	ctrl.SetControllerReference(standalone, mockSvc, reconciler.Scheme)
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: mockSvc.Name, Namespace: standalone.Namespace}, &corev1.Service{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*corev1.Service)
+			*arg = *mockSvc
+		}).
+		Return(nil)
+
+	for _, cfg := range []*corev1.ConfigMap{
+		buildConfigMap(standalone.Spec.Configuration.HbaseConfigName, standalone.Name, standalone.Namespace, standalone.Spec.Configuration.HbaseConfig, standalone.Spec.Configuration.HbaseTenantConfig, ctrl.Log.WithName("test")),
+		buildConfigMap(standalone.Spec.Configuration.HadoopConfigName, standalone.Name, standalone.Namespace, standalone.Spec.Configuration.HadoopConfig, standalone.Spec.Configuration.HadoopTenantConfig, ctrl.Log.WithName("test")),
+	} {
+		existing := cfg.DeepCopy()
+		existing.ResourceVersion = "42"
+		existing.Annotations = map[string]string{CFG_V2_ANNOTATION: "t"}
+		k8sMockClient.On("Get", ctx, types.NamespacedName{Name: cfg.Name, Namespace: cfg.Namespace}, &corev1.ConfigMap{}).
+			Run(func(args mock.Arguments) {
+				arg := args.Get(2).(*corev1.ConfigMap)
+				*arg = *existing
+			}).
+			Return(nil)
+	}
+
+	mockSts, err := buildStatefulSet(standalone.Name, standalone.Namespace, standalone.Spec.BaseImage,
+		false, standalone.Spec.Configuration, "42", standalone.Spec.FSGroup,
+		standalone.Spec.Standalone, ctrl.Log.WithName("test"), true)
+	assert.NoError(t, err)
+	ctrl.SetControllerReference(standalone, mockSts, reconciler.Scheme)
+	mockStsMarshal, _ := json.Marshal(mockSts)
+	assert.NotEqual(t, asSha256(mockStsMarshal), hashStore["ss-"+mockSts.Name])
+	k8sMockClient.On("Get", ctx, types.NamespacedName{Name: standalone.Spec.Standalone.Name, Namespace: standalone.Namespace}, &appsv1.StatefulSet{}).Return(nil)
+	k8sMockClient.On("Update", ctx, mockSts, []client.UpdateOption(nil)).Return(nil)
+
+	result, err := reconciler.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, result)
+
+	k8sMockClient.AssertExpectations(t)
+}
@@ -5,6 +5,7 @@ import (
 	json "encoding/json"
 	xml "encoding/xml"
 	errs "errors"
+	fmt "fmt"
 	time "time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -16,6 +17,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	record "k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -39,6 +41,7 @@ const CFG_V2_ANNOTATION = "hbase-operator/update-time"
 const STATEFULSET_V2_ANNOTATION = "hbase-operator/hbase-config-version"
 
 // RECONCILE_CONFIG_LABEL annotation is used to control if configMap changes needs to be bound with StatefulSet
+// Deprecated: use spec.configuration.updatePolicy, the label is only honoured when the policy is not set
 const RECONCILE_CONFIG_LABEL = "hbase-operator.cfg-statefulset-update/enable"
 
 var allowedConfigs = map[string]ConfigType{
@@ -88,6 +91,10 @@ func getCfgResourceVersionIfV2OrNil(log logr.Logger, cl client.Client, ctx conte
 	hbaseCfgMap, cfgError := getConfigMap(log, cl, ctx, configMapName, namespaceName)
 	currentConfigMapResourceVersion := ""
 	if cfgError == nil {
+		// configmap changed without a restart, statefulsets stay bound to the version before the change
+		if restartVersion, ok := hbaseCfgMap.Annotations[CFG_RESTART_VERSION_ANNOTATION]; ok {
+			return restartVersion
+		}
 		_, exists := hbaseCfgMap.Annotations[CFG_V2_ANNOTATION]
 		if exists {
 			currentConfigMapResourceVersion = hbaseCfgMap.ResourceVersion
@@ -265,8 +272,8 @@ func buildInitContainers(baseImage string, config kvstorev1.HbaseClusterConfigur
 	containers := []corev1.Container{}
 
 	for _, c := range cs {
-		//Ignore init containers whose IsBootstrap value is true if not bootstrap
-		if !c.IsBootstrap || isBootstrap {
+		//Ignore init containers whose IsBootstrap value is true or with a bootstrap phase if not bootstrap
+		if !(c.IsBootstrap || len(c.BootstrapPhase) > 0) || isBootstrap {
 			containers = append(containers, corev1.Container{
 				Image:   baseImage,
 				Name:    c.Name,
@@ -412,6 +419,7 @@ func buildPorts(ps []kvstorev1.HbaseClusterContainerPort) []corev1.ContainerPort
 		ports = append(ports, corev1.ContainerPort{
 			ContainerPort: p.Port,
 			Name:          p.Name,
+			Protocol:      p.Protocol,
 		})
 	}
 
@@ -516,6 +524,8 @@ func buildStatefulSet(name string, namespace string, baseImage string, isBootstr
 		log.Error(err, "Failed to build volumes for StatefulSet", "StatefulSet.Name", d.Name, "StatefulSet.Namespace", namespace)
 		return nil, err
 	}
+	// Components with a config overlay mount their own ConfigMaps instead of the shared ones
+	useComponentConfigMaps(volumes, configuration, d)
 
 	dep := &appsv1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
@@ -585,12 +595,7 @@ func buildService(svcName string, crName string, namespace string, labels map[st
 	for _, d := range deployments {
 		for _, c := range d.Containers {
 			for _, p := range c.Ports {
-				ports = append(ports, corev1.ServicePort{
-					Name:       p.Name,
-					Port:       p.Port,
-					TargetPort: intstr.FromInt(int(p.Port)),
-					Protocol:   corev1.ProtocolTCP,
-				})
+				ports = append(ports, buildServicePort(p))
 			}
 		}
 	}
@@ -613,45 +618,83 @@ func buildService(svcName string, crName string, namespace string, labels map[st
 		}
 	}
 
-	if labels == nil {
-		labels = map[string]string{}
+	// the deprecated RECONCILE_CONFIG_LABEL is an instruction to the operator, not a label of the Service
+	svcLabels := map[string]string{}
+	for k, v := range labels {
+		if k != RECONCILE_CONFIG_LABEL {
+			svcLabels[k] = v
+		}
 	}
 
 	dep := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      svcName,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    svcLabels,
 		},
 		Spec: spec,
 	}
 	return dep
 }
 
-func buildConfigMap(cfgName string, crName string, namespace string, config map[string]string, tenantConfig []map[string]string, log logr.Logger) *corev1.ConfigMap {
-	newConfig := map[string]string{}
-	tenantCfg := map[string]map[string]string{}
-	for _, elem := range tenantConfig {
-		ns := elem["namespace"]
-		if _, ok := tenantCfg[ns]; !ok {
-			tenantCfg[ns] = make(map[string]string)
-		}
-		for k, v := range elem {
-			if k != "namespace" {
-				tenantCfg[ns][k] = v
-				log.Info("Override config for", "Key:", k, "Namespace:", ns)
-			}
+// buildServicePort returns the Service port of a container port, targeting the port itself unless a target port is set
+func buildServicePort(p kvstorev1.HbaseClusterContainerPort) corev1.ServicePort {
+	targetPort, protocol := p.TargetPort, p.Protocol
+	if targetPort == 0 {
+		targetPort = p.Port
+	}
+	if len(protocol) == 0 {
+		protocol = corev1.ProtocolTCP
+	}
+	return corev1.ServicePort{
+		Name:        p.Name,
+		Port:        p.Port,
+		TargetPort:  intstr.FromInt(int(targetPort)),
+		Protocol:    protocol,
+		AppProtocol: p.AppProtocol,
+	}
+}
+
+// applyServiceSpec customizes the Service after the spec. Headless Services keep their type
+func applyServiceSpec(svc *corev1.Service, s *kvstorev1.HbaseClusterServiceSpec) {
+	if s == nil {
+		return
+	}
+	if len(s.Annotations) > 0 {
+		if svc.Annotations == nil {
+			svc.Annotations = map[string]string{}
+		}
+		for k, v := range s.Annotations {
+			svc.Annotations[k] = v
 		}
 	}
+	spec := &svc.Spec
+	if len(s.Type) > 0 && spec.ClusterIP != corev1.ClusterIPNone {
+		spec.Type = s.Type
+	}
+	spec.IPFamilies = s.IPFamilies
+	spec.SessionAffinity = s.SessionAffinity
+	if s.PublishNotReadyAddresses != nil {
+		spec.PublishNotReadyAddresses = *s.PublishNotReadyAddresses
+	}
+	spec.InternalTrafficPolicy = s.InternalTrafficPolicy
+	if spec.Type == corev1.ServiceTypeNodePort || spec.Type == corev1.ServiceTypeLoadBalancer {
+		spec.ExternalTrafficPolicy = s.ExternalTrafficPolicy
+	}
+}
 
+func buildConfigMap(cfgName string, crName string, namespace string, config map[string]string, tenantConfig []kvstorev1.HbaseTenantConfigOverride, log logr.Logger) *corev1.ConfigMap {
+	newConfig := map[string]string{}
 	for k, v := range config {
 		newConfig[k] = v
 	}
 
-	if _, ok := tenantCfg[namespace]; ok {
-		for k, v := range tenantCfg[namespace] {
-			newConfig[k] = v
-		}
+	overrides := tenantConfigFiles(tenantConfig, namespace)
+	if len(overrides) > 0 {
+		log.V(1).Info("Overriding config files for namespace", "ConfigMap.Name", cfgName, "Namespace", namespace, "Files", sortedKeys(overrides))
+	}
+	for k, v := range overrides {
+		newConfig[k] = v
 	}
 
 	return &corev1.ConfigMap{
@@ -663,59 +706,8 @@ func buildConfigMap(cfgName string, crName string, namespace string, config map[
 	}
 }
 
-// TODO: Take UUID reference of object under event scope
-func buildEvent(namespace string, reason string, message string, level string, kind string) *corev1.Event {
-	return &corev1.Event{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      reason,
-			Namespace: namespace,
-		},
-		InvolvedObject: corev1.ObjectReference{
-			Kind:      kind,
-			Namespace: namespace,
-		},
-		Type:    level,
-		Reason:  reason,
-		Message: message,
-		Count:   1,
-		FirstTimestamp: metav1.Time{
-			Time: time.Now(),
-		},
-		LastTimestamp: metav1.Time{
-			Time: time.Now(),
-		},
-	}
-}
-
-func publishEvent(ctx context.Context, log logr.Logger, namespace string, reason string, message string, level string, kind string, cl client.Client) {
-	evt := buildEvent(namespace, reason, message, level, kind)
-
-	event := &corev1.Event{}
-	err := cl.Get(ctx, types.NamespacedName{Name: evt.Name, Namespace: namespace}, event)
-
-	if err != nil {
-		if errors.IsNotFound(err) {
-			err = cl.Create(ctx, evt)
-			if err != nil {
-				log.Error(err, "Failed to create new event", "Event.Namespace", namespace, "Event.Name", event.Name)
-			}
-		} else {
-			log.Error(err, "Failed to create new event", "Event.Namespace", namespace, "Event.Name", event.Name)
-		}
-	} else {
-		evt.FirstTimestamp = event.FirstTimestamp
-		evt.Count = event.Count + 1
-		//TODO: is this required
-		evt.ObjectMeta.ResourceVersion = event.ObjectMeta.ResourceVersion
-		err = cl.Update(ctx, evt)
-		if err != nil {
-			log.Error(err, "Failed to update new event", "Event.Namespace", namespace, "Event.Name", event.Name)
-		}
-	}
-	return
-}
-
-func reconcileConfigMap(ctx context.Context, log logr.Logger, namespace string, cfg *corev1.ConfigMap, cl client.Client) (ctrl.Result, error) {
+func reconcileConfigMap(ctx context.Context, log logr.Logger, namespace string, cfg *corev1.ConfigMap, owner client.Object, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	kind := kindOf(owner)
 	cfgMarshal, _ := json.Marshal(cfg.Data)
 	config := &corev1.ConfigMap{}
 	err := cl.Get(ctx, types.NamespacedName{Name: cfg.Name, Namespace: namespace}, config)
@@ -727,8 +719,11 @@ func reconcileConfigMap(ctx context.Context, log logr.Logger, namespace string,
 			err = cl.Create(ctx, cfg)
 			if err != nil {
 				log.Error(err, "Failed to create new ConfigMap", "ConfigMap.Namespace", cfg.Namespace, "ConfigMap.Name", cfg.Name)
+				recordWarning(recorder, owner, REASON_CONFIGMAP_CREATE_FAILED, err)
 				return ctrl.Result{RequeueAfter: time.Second * 5}, err
 			}
+			recordConfigMapUpdate(kind, namespace, cfg.Name)
+			recordEvent(recorder, owner, cfg, corev1.EventTypeNormal, REASON_CONFIGMAP_CREATED, "Created ConfigMap "+cfg.Name)
 			log.Info("Created a new ConfigMap", "ConfigMap.Namespace", cfg.Namespace, "ConfigMap.Name", cfg.Name)
 			return ctrl.Result{}, nil
 		}
@@ -752,16 +747,20 @@ func reconcileConfigMap(ctx context.Context, log logr.Logger, namespace string,
 		err = cl.Update(ctx, cfg)
 		if err != nil {
 			log.Error(err, "Failed to update ConfigMap", "ConfigMap.Namespace", cfg.Namespace, "ConfigMap.Name", cfg.Name)
+			recordWarning(recorder, owner, REASON_CONFIGMAP_UPDATE_FAILED, err)
 			return ctrl.Result{RequeueAfter: time.Second * 5}, err
 		}
 		hashStore["cfg-"+cfg.Name+cfg.Namespace] = asSha256(cfgMarshal)
+		recordConfigMapUpdate(kind, namespace, cfg.Name)
+		recordEvent(recorder, owner, cfg, corev1.EventTypeNormal, REASON_CONFIGMAP_UPDATED, "Updated ConfigMap "+cfg.Name)
 		log.Info("Updated ConfigMap", "ConfigMap.Namespace", cfg.Namespace, "ConfigMap.Name", cfg.Name)
 		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 	}
+	recordConfigRevision(kind, namespace, config)
 	return ctrl.Result{}, nil
 }
 
-func reconcileService(ctx context.Context, log logr.Logger, namespace string, svc *corev1.Service, cl client.Client) (ctrl.Result, error) {
+func reconcileService(ctx context.Context, log logr.Logger, namespace string, svc *corev1.Service, owner client.Object, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
 	svcMarshal, _ := json.Marshal(svc.Spec)
 	service := &corev1.Service{}
 	err := cl.Get(ctx, types.NamespacedName{Name: svc.Name, Namespace: namespace}, service)
@@ -783,9 +782,10 @@ func reconcileService(ctx context.Context, log logr.Logger, namespace string, sv
 			err = cl.Create(ctx, svc)
 			if err != nil {
 				log.Error(err, "Failed to create new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
-				publishEvent(ctx, log, svc.Namespace, "CreateServiceFailed", err.Error(), "Warning", "Service/"+svc.Name, cl)
+				recordWarning(recorder, owner, REASON_SERVICE_CREATE_FAILED, err)
 				return ctrl.Result{RequeueAfter: time.Second * 5}, err
 			}
+			recordEvent(recorder, owner, svc, corev1.EventTypeNormal, REASON_SERVICE_CREATED, "Created Service "+svc.Name)
 			log.Info("Created a new Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
 			return ctrl.Result{}, nil
 		}
@@ -798,16 +798,20 @@ func reconcileService(ctx context.Context, log logr.Logger, namespace string, sv
 		err = cl.Update(ctx, svc)
 		if err != nil {
 			log.Error(err, "Failed to update Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
+			recordWarning(recorder, owner, REASON_SERVICE_UPDATE_FAILED, err)
 			return ctrl.Result{RequeueAfter: time.Second * 5}, err
 		}
 		hashStore["svc-"+svc.Name] = asSha256(svcMarshal)
+		recordEvent(recorder, owner, svc, corev1.EventTypeNormal, REASON_SERVICE_UPDATED, "Updated Service "+svc.Name)
 		log.Info("Updated Service", "Service.Namespace", svc.Namespace, "Service.Name", svc.Name)
 		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
 	}
 	return ctrl.Result{}, nil
 }
 
-func reconcileStatefulSet(ctx context.Context, log logr.Logger, namespace string, newSS *appsv1.StatefulSet, d kvstorev1.HbaseClusterDeployment, cl client.Client) (ctrl.Result, error) {
+func reconcileStatefulSet(ctx context.Context, log logr.Logger, namespace string, newSS *appsv1.StatefulSet, d kvstorev1.HbaseClusterDeployment,
+	owner client.Object, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	kind := kindOf(owner)
 	newSSMarshal, _ := json.Marshal(newSS)
 
 	existingSS := &appsv1.StatefulSet{}
@@ -824,8 +828,13 @@ func reconcileStatefulSet(ctx context.Context, log logr.Logger, namespace string
 			err = cl.Create(ctx, newSS)
 			if err != nil {
 				log.Error(err, "Failed to create new StatefulSet", "StatefulSet.Namespace", newSS.Namespace, "StatefulSet.Name", newSS.Name)
+				recordWarning(recorder, owner, REASON_STATEFULSET_CREATE_FAILED, err)
 				return ctrl.Result{RequeueAfter: time.Second * 5}, err
 			}
+			recordStatefulSetUpdate(kind, namespace, d.Name, false)
+			recordStatefulSetStatus(kind, namespace, d.Name, d.Size, 0, true)
+			recordEvent(recorder, owner, newSS, corev1.EventTypeNormal, REASON_STATEFULSET_CREATED, "Created StatefulSet "+newSS.Name)
+			hashStore[rolloutKey(namespace, newSS.Name)] = newSS.ResourceVersion
 			log.Info("Created a new StatefulSet", "StatefulSet.Namespace", newSS.Namespace, "StatefulSet.Name", newSS.Name)
 			return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 5}, nil
 		}
@@ -837,21 +846,45 @@ func reconcileStatefulSet(ctx context.Context, log logr.Logger, namespace string
 		err = cl.Update(ctx, newSS)
 		if err != nil {
 			log.Error(err, "Failed to update StatefulSet", "StatefulSet.Namespace", newSS.Namespace, "StatefulSet.Name", newSS.Name)
+			recordWarning(recorder, owner, REASON_STATEFULSET_UPDATE_FAILED, err)
 			return ctrl.Result{RequeueAfter: time.Second * 5}, err
 		}
 		hashStore["ss-"+newSS.Name] = asSha256(newSSMarshal)
+		// pods restart for the config once the config version they are bound to changes
+		previousVersion := existingSS.Spec.Template.Annotations[STATEFULSET_V2_ANNOTATION]
+		restart := len(previousVersion) > 0 && previousVersion != newSS.Spec.Template.Annotations[STATEFULSET_V2_ANNOTATION]
+		recordStatefulSetUpdate(kind, namespace, d.Name, restart)
+		recordStatefulSetStatus(kind, namespace, d.Name, d.Size, existingSS.Status.ReadyReplicas, true)
+		recordEvent(recorder, owner, newSS, corev1.EventTypeNormal, REASON_STATEFULSET_UPDATED, "Updated StatefulSet "+newSS.Name)
+		if restart {
+			recordEvent(recorder, owner, newSS, corev1.EventTypeNormal, REASON_ROLLOUT_STARTED,
+				"Rolling restart of StatefulSet "+newSS.Name+" for config version "+newSS.Spec.Template.Annotations[STATEFULSET_V2_ANNOTATION])
+		}
+		hashStore[rolloutKey(namespace, newSS.Name)] = newSS.ResourceVersion
 		log.Info("Updated StatefulSet", "StatefulSet.Namespace", newSS.Namespace, "StatefulSet.Name", newSS.Name)
 		return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
 	} else if existingSS.Status.ReadyReplicas != d.Size || existingSS.Status.CurrentRevision != existingSS.Status.UpdateRevision {
+		recordStatefulSetStatus(kind, namespace, d.Name, d.Size, existingSS.Status.ReadyReplicas, true)
 		log.Info("Waiting for StatefulSet to be ready", "NotReady", existingSS.Status, "Expected Replicas", d.Size)
 		return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, nil
 	} else {
+		recordStatefulSetStatus(kind, namespace, d.Name, d.Size, existingSS.Status.ReadyReplicas, false)
+		if _, ok := hashStore[rolloutKey(namespace, d.Name)]; ok {
+			delete(hashStore, rolloutKey(namespace, d.Name))
+			recordEvent(recorder, owner, existingSS, corev1.EventTypeNormal, REASON_ROLLOUT_COMPLETED,
+				fmt.Sprintf("StatefulSet %s rolled out, %d replicas ready", d.Name, existingSS.Status.ReadyReplicas))
+		}
 		log.Info("Reconciled for cluster", "StatefulSet", d.Name)
 	}
 
 	return ctrl.Result{}, nil
 }
 
+// rolloutKey of the hashStore entry marking a StatefulSet applied by the operator which is yet to become ready
+func rolloutKey(namespace string, name string) string {
+	return "rollout-" + namespace + "/" + name
+}
+
 func labelsForPodService(crName string, name string, labels map[string]string) map[string]string {
 	if labels == nil {
 		return map[string]string{"app": "hbasecluster", "hbasecluster_cr": crName, "statefulset.kubernetes.io/pod-name": name}
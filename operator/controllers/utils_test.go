@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -603,9 +604,9 @@ func TestBuildConfigMap_Basic(t *testing.T) {
 // TestBuildConfigMap_WithTenantOverrides verifies that tenant-specific config overrides are applied when the namespace matches the tenant entry.
 func TestBuildConfigMap_WithTenantOverrides(t *testing.T) {
 	log := ctrl.Log.WithName("test")
-	tenantConfig := []map[string]string{
-		{"namespace": "tenant-ns", "hbase-env.sh": "export OPTS=tenant"},
-		{"namespace": "other-ns", "hbase-env.sh": "export OPTS=other"},
+	tenantConfig := []kvstorev1.HbaseTenantConfigOverride{
+		{Namespace: "tenant-ns", Files: map[string]string{"hbase-env.sh": "export OPTS=tenant"}},
+		{Namespace: "other-ns", Files: map[string]string{"hbase-env.sh": "export OPTS=other"}},
 	}
 	cfg := buildConfigMap("hbase-config", "my-cluster", "tenant-ns",
 		map[string]string{"hbase-env.sh": "export OPTS=default", "hbase-site.xml": "<configuration/>"},
@@ -618,8 +619,8 @@ func TestBuildConfigMap_WithTenantOverrides(t *testing.T) {
 // TestBuildConfigMap_TenantOverrideNonMatchingNamespace verifies that tenant overrides for a different namespace are ignored, preserving the original config values.
 func TestBuildConfigMap_TenantOverrideNonMatchingNamespace(t *testing.T) {
 	log := ctrl.Log.WithName("test")
-	tenantConfig := []map[string]string{
-		{"namespace": "other-ns", "hbase-env.sh": "export OPTS=other"},
+	tenantConfig := []kvstorev1.HbaseTenantConfigOverride{
+		{Namespace: "other-ns", Files: map[string]string{"hbase-env.sh": "export OPTS=other"}},
 	}
 	cfg := buildConfigMap("hbase-config", "my-cluster", "test-ns",
 		map[string]string{"hbase-env.sh": "export OPTS=default"},
@@ -684,6 +685,66 @@ func TestBuildService_NilLabels(t *testing.T) {
 	assert.NotNil(t, svc.Labels)
 }
 
+// TestBuildService_PortSettings verifies the target port, protocol and application protocol of container ports are
+// carried to the Service ports.
+func TestBuildService_PortSettings(t *testing.T) {
+	grpc := "grpc"
+	deployments := []kvstorev1.HbaseClusterDeployment{
+		{
+			Containers: []kvstorev1.HbaseClusterContainer{
+				{Ports: []kvstorev1.HbaseClusterContainerPort{
+					{Port: 8080, Name: "http", TargetPort: 15001, AppProtocol: &grpc},
+					{Port: 8125, Name: "statsd", Protocol: corev1.ProtocolUDP},
+				}},
+			},
+		},
+	}
+	svc := buildService("my-svc", "my-cluster", "test-ns", nil, nil, deployments, true)
+
+	assert.Equal(t, 15001, svc.Spec.Ports[0].TargetPort.IntValue())
+	assert.Equal(t, corev1.ProtocolTCP, svc.Spec.Ports[0].Protocol)
+	assert.Equal(t, &grpc, svc.Spec.Ports[0].AppProtocol)
+	assert.Equal(t, 8125, svc.Spec.Ports[1].TargetPort.IntValue())
+	assert.Equal(t, corev1.ProtocolUDP, svc.Spec.Ports[1].Protocol)
+	assert.Equal(t, corev1.ProtocolUDP, buildPorts(deployments[0].Containers[0].Ports)[1].Protocol)
+}
+
+// TestApplyServiceSpec verifies the Service is customized after the spec, and headless Services stay ClusterIP.
+func TestApplyServiceSpec(t *testing.T) {
+	publish := false
+	local := corev1.ServiceInternalTrafficPolicyLocal
+	spec := &kvstorev1.HbaseClusterServiceSpec{
+		Type:                     corev1.ServiceTypeLoadBalancer,
+		Annotations:              map[string]string{"mesh.example.com/inject": "true"},
+		IPFamilies:               []corev1.IPFamily{corev1.IPv6Protocol},
+		SessionAffinity:          corev1.ServiceAffinityClientIP,
+		PublishNotReadyAddresses: &publish,
+		InternalTrafficPolicy:    &local,
+		ExternalTrafficPolicy:    corev1.ServiceExternalTrafficPolicyLocal,
+	}
+
+	svc := buildService("pod-0", "my-cluster", "test-ns", nil, nil, []kvstorev1.HbaseClusterDeployment{}, false)
+	applyServiceSpec(svc, spec)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, svc.Spec.Type)
+	assert.Equal(t, "true", svc.Annotations["mesh.example.com/inject"])
+	assert.Equal(t, []corev1.IPFamily{corev1.IPv6Protocol}, svc.Spec.IPFamilies)
+	assert.Equal(t, corev1.ServiceAffinityClientIP, svc.Spec.SessionAffinity)
+	assert.False(t, svc.Spec.PublishNotReadyAddresses)
+	assert.Equal(t, &local, svc.Spec.InternalTrafficPolicy)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, svc.Spec.ExternalTrafficPolicy)
+
+	svc = buildService("my-svc", "my-cluster", "test-ns", nil, nil, []kvstorev1.HbaseClusterDeployment{}, true)
+	applyServiceSpec(svc, spec)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, svc.Spec.Type)
+	assert.Equal(t, "None", svc.Spec.ClusterIP)
+	assert.Empty(t, svc.Spec.ExternalTrafficPolicy)
+
+	svc = buildService("my-svc", "my-cluster", "test-ns", nil, nil, []kvstorev1.HbaseClusterDeployment{}, true)
+	applyServiceSpec(svc, nil)
+	assert.True(t, svc.Spec.PublishNotReadyAddresses)
+	assert.Nil(t, svc.Annotations)
+}
+
 // ---- buildStatefulSet ----
 
 // TestBuildStatefulSet_Basic verifies core StatefulSet fields: replicas, service name, pod management policy, FSGroup, and selector labels.
@@ -836,21 +897,6 @@ func TestBuildStatefulSet_OptionalFields(t *testing.T) {
 	assert.Equal(t, corev1.DNSClusterFirst, ss.Spec.Template.Spec.DNSPolicy)
 }
 
-// ---- buildEvent ----
-
-// TestBuildEvent verifies that a Kubernetes Event is built with the correct reason, message, type, involved object kind, and initial count.
-func TestBuildEvent(t *testing.T) {
-	evt := buildEvent("test-ns", "TestReason", "test message", "Warning", "ConfigMap")
-
-	assert.Equal(t, "TestReason", evt.Name)
-	assert.Equal(t, "test-ns", evt.Namespace)
-	assert.Equal(t, "TestReason", evt.Reason)
-	assert.Equal(t, "test message", evt.Message)
-	assert.Equal(t, "Warning", evt.Type)
-	assert.Equal(t, "ConfigMap", evt.InvolvedObject.Kind)
-	assert.Equal(t, int32(1), evt.Count)
-}
-
 // ---- buildPodDisruptionBudget ----
 
 // TestBuildPodDisruptionBudget_Nil verifies that a nil PodDisruptionBudget spec in the deployment returns nil (no PDB created).
@@ -1215,9 +1261,11 @@ func TestReconcileConfigMap_NotFound_Creates(t *testing.T) {
 		Return(errors.NewNotFound(schema.GroupResource{}, "test-cfg"))
 	mockClient.On("Create", ctx, cfg, []client.CreateOption(nil)).Return(nil)
 
-	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, mockClient)
+	recorder := record.NewFakeRecorder(10)
+	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, newEventOwner(), recorder, mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_CONFIGMAP_CREATED}, recordedReasons(recorder))
 	mockClient.AssertExpectations(t)
 }
 
@@ -1234,9 +1282,11 @@ func TestReconcileConfigMap_NotFound_CreateError(t *testing.T) {
 		Return(errors.NewNotFound(schema.GroupResource{}, "test-cfg"))
 	mockClient.On("Create", ctx, cfg, []client.CreateOption(nil)).Return(assert.AnError)
 
-	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, mockClient)
+	recorder := record.NewFakeRecorder(10)
+	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, newEventOwner(), recorder, mockClient)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_CONFIGMAP_CREATE_FAILED}, recordedReasons(recorder))
 	mockClient.AssertExpectations(t)
 }
 
@@ -1252,7 +1302,7 @@ func TestReconcileConfigMap_GetError(t *testing.T) {
 	mockClient.On("Get", ctx, types.NamespacedName{Name: "test-cfg", Namespace: "test-ns"}, &corev1.ConfigMap{}).
 		Return(assert.AnError)
 
-	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, mockClient)
+	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
 	mockClient.AssertExpectations(t)
@@ -1277,7 +1327,7 @@ func TestReconcileConfigMap_Exists_HashMatches_Noop(t *testing.T) {
 		}).
 		Return(nil)
 
-	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, mockClient)
+	result, err := reconcileConfigMap(ctx, log, "test-ns", cfg, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 	mockClient.AssertExpectations(t)
@@ -1304,9 +1354,11 @@ func TestReconcileService_NotFound_Creates(t *testing.T) {
 		Return(errors.NewNotFound(schema.GroupResource{}, "test-svc"))
 	mockClient.On("Create", ctx, svc, []client.CreateOption(nil)).Return(nil)
 
-	result, err := reconcileService(ctx, log, "test-ns", svc, mockClient)
+	recorder := record.NewFakeRecorder(10)
+	result, err := reconcileService(ctx, log, "test-ns", svc, newEventOwner(), recorder, mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_SERVICE_CREATED}, recordedReasons(recorder))
 	mockClient.AssertExpectations(t)
 }
 
@@ -1322,7 +1374,7 @@ func TestReconcileService_GetError(t *testing.T) {
 	mockClient.On("Get", ctx, types.NamespacedName{Name: "test-svc", Namespace: "test-ns"}, &corev1.Service{}).
 		Return(assert.AnError)
 
-	result, err := reconcileService(ctx, log, "test-ns", svc, mockClient)
+	result, err := reconcileService(ctx, log, "test-ns", svc, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
 	mockClient.AssertExpectations(t)
@@ -1352,7 +1404,7 @@ func TestReconcileService_Exists_HashMatches_Noop(t *testing.T) {
 		}).
 		Return(nil)
 
-	result, err := reconcileService(ctx, log, "test-ns", svc, mockClient)
+	result, err := reconcileService(ctx, log, "test-ns", svc, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 	mockClient.AssertExpectations(t)
@@ -1387,9 +1439,12 @@ func TestReconcileStatefulSet_NotFound_Creates(t *testing.T) {
 		Return(errors.NewNotFound(schema.GroupResource{}, "test-dn"))
 	mockClient.On("Create", ctx, ss, []client.CreateOption(nil)).Return(nil)
 
-	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, mockClient)
+	recorder := record.NewFakeRecorder(10)
+	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), recorder, mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{Requeue: true, RequeueAfter: time.Second * 5}, result)
+	assert.Equal(t, []string{REASON_STATEFULSET_CREATED}, recordedReasons(recorder))
+	assert.Contains(t, hashStore, rolloutKey("test-ns", "test-dn"))
 	mockClient.AssertExpectations(t)
 }
 
@@ -1406,7 +1461,7 @@ func TestReconcileStatefulSet_GetError(t *testing.T) {
 	mockClient.On("Get", ctx, types.NamespacedName{Name: "test-dn", Namespace: "test-ns"}, &appsv1.StatefulSet{}).
 		Return(assert.AnError)
 
-	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, mockClient)
+	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.Error(t, err)
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
 	mockClient.AssertExpectations(t)
@@ -1449,12 +1504,64 @@ func TestReconcileStatefulSet_Exists_HashMatches_Ready(t *testing.T) {
 		}).
 		Return(nil)
 
-	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, mockClient)
+	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 	mockClient.AssertExpectations(t)
 }
 
+// TestReconcileStatefulSet_RolloutCompleted verifies that a rollout applied by the operator is reported once, when the StatefulSet becomes ready.
+func TestReconcileStatefulSet_RolloutCompleted(t *testing.T) {
+	resetHashStore()
+	mockClient := new(K8sMockClient)
+	ctx := context.TODO()
+	log := ctrl.Log.WithName("test")
+
+	config := kvstorev1.HbaseClusterConfiguration{
+		HbaseConfigName: "hbase-cfg", HbaseConfigMountPath: "/etc/hbase",
+		HadoopConfigName: "hadoop-cfg", HadoopConfigMountPath: "/etc/hadoop",
+	}
+	d := kvstorev1.HbaseClusterDeployment{
+		Name: "test-dn", Size: 3, TerminationGracePeriodSeconds: 30,
+		Containers: []kvstorev1.HbaseClusterContainer{
+			{Name: "dn", Command: []string{"/bin/start"}, CpuLimit: "1", CpuRequest: "1",
+				MemoryLimit: "1Gi", MemoryRequest: "1Gi",
+				LivenessProbe: kvstorev1.HbaseClusterProbe{Port: 9866}, SecurityContext: kvstorev1.HbaseClusterSecurity{}},
+		},
+	}
+	ss, err := buildStatefulSet("my-cluster", "test-ns", "base:1.0", false, config, "", int64(1000), d, log, false)
+	assert.NoError(t, err)
+
+	ssMarshal, _ := json.Marshal(ss)
+	hashStore["ss-"+ss.Name] = asSha256(ssMarshal)
+	hashStore[rolloutKey("test-ns", "test-dn")] = "1"
+
+	existingSS := ss.DeepCopy()
+	existingSS.Status.ReadyReplicas = 3
+	existingSS.Status.CurrentRevision = "rev1"
+	existingSS.Status.UpdateRevision = "rev1"
+
+	mockClient.On("Get", ctx, types.NamespacedName{Name: "test-dn", Namespace: "test-ns"}, &appsv1.StatefulSet{}).
+		Run(func(args mock.Arguments) {
+			arg := args.Get(2).(*appsv1.StatefulSet)
+			*arg = *existingSS
+		}).
+		Return(nil)
+
+	recorder := record.NewFakeRecorder(10)
+	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), recorder, mockClient)
+	assert.NoError(t, err)
+	assert.Equal(t, ctrl.Result{}, result)
+	assert.Equal(t, []string{REASON_ROLLOUT_COMPLETED}, recordedReasons(recorder))
+	assert.NotContains(t, hashStore, rolloutKey("test-ns", "test-dn"))
+
+	// later reconciles of the ready StatefulSet do not report the rollout again
+	_, err = reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), recorder, mockClient)
+	assert.NoError(t, err)
+	assert.Empty(t, recordedReasons(recorder))
+	mockClient.AssertExpectations(t)
+}
+
 // TestReconcileStatefulSet_Exists_HashMatches_NotReady verifies that reconciliation triggers a requeue when hash matches but not all replicas are ready yet.
 func TestReconcileStatefulSet_Exists_HashMatches_NotReady(t *testing.T) {
 	resetHashStore()
@@ -1490,7 +1597,7 @@ func TestReconcileStatefulSet_Exists_HashMatches_NotReady(t *testing.T) {
 		}).
 		Return(nil)
 
-	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, mockClient)
+	result, err := reconcileStatefulSet(ctx, log, "test-ns", ss, d, newEventOwner(), record.NewFakeRecorder(10), mockClient)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, result)
 	mockClient.AssertExpectations(t)
@@ -1584,43 +1691,3 @@ func TestReconcilePodDisruptionBudget_GetError(t *testing.T) {
 	assert.Equal(t, ctrl.Result{RequeueAfter: time.Second * 5}, result)
 	mockClient.AssertExpectations(t)
 }
-
-// ---- publishEvent ----
-
-// TestPublishEvent_NewEvent verifies that a new Kubernetes Event is created when no existing event with the same reason is found.
-func TestPublishEvent_NewEvent(t *testing.T) {
-	mockClient := new(K8sMockClient)
-	ctx := context.TODO()
-	log := ctrl.Log.WithName("test")
-
-	mockClient.On("Get", ctx, types.NamespacedName{Name: "TestReason", Namespace: "test-ns"}, &corev1.Event{}).
-		Return(errors.NewNotFound(schema.GroupResource{}, "TestReason"))
-	mockClient.On("Create", ctx, mock.Anything, []client.CreateOption(nil)).Return(nil)
-
-	publishEvent(ctx, log, "test-ns", "TestReason", "test message", "Warning", "ConfigMap", mockClient)
-	mockClient.AssertExpectations(t)
-}
-
-// TestPublishEvent_ExistingEvent_Updates verifies that an existing Event is updated (count incremented, timestamps refreshed) rather than creating a duplicate.
-func TestPublishEvent_ExistingEvent_Updates(t *testing.T) {
-	mockClient := new(K8sMockClient)
-	ctx := context.TODO()
-	log := ctrl.Log.WithName("test")
-
-	existingEvent := &corev1.Event{
-		Count:          5,
-		FirstTimestamp: metav1.Time{Time: time.Now().Add(-1 * time.Hour)},
-	}
-	existingEvent.ResourceVersion = "rv100"
-
-	mockClient.On("Get", ctx, types.NamespacedName{Name: "TestReason", Namespace: "test-ns"}, &corev1.Event{}).
-		Run(func(args mock.Arguments) {
-			arg := args.Get(2).(*corev1.Event)
-			*arg = *existingEvent
-		}).
-		Return(nil)
-	mockClient.On("Update", ctx, mock.Anything, []client.UpdateOption(nil)).Return(nil)
-
-	publishEvent(ctx, log, "test-ns", "TestReason", "test message", "Warning", "ConfigMap", mockClient)
-	mockClient.AssertExpectations(t)
-}
\ No newline at end of file
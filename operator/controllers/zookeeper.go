@@ -0,0 +1,318 @@
+package controllers
+
+import (
+	bufio "bufio"
+	context "context"
+	errs "errors"
+	fmt "fmt"
+	io "io"
+	net "net"
+	strconv "strconv"
+	strings "strings"
+	time "time"
+
+	corev1 "k8s.io/api/core/v1"
+	equality "k8s.io/apimachinery/pkg/api/equality"
+	record "k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	client "sigs.k8s.io/controller-runtime/pkg/client"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	logr "github.com/go-logr/logr"
+)
+
+// ZOOKEEPER_COMMAND_TIMEOUT timeout of a 4 letter word command sent to a zookeeper server
+const ZOOKEEPER_COMMAND_TIMEOUT = 3 * time.Second
+
+// zookeeperMember is a server of the ensemble, the pod of the zookeeper StatefulSet with ordinal ID - 1
+type zookeeperMember struct {
+	ID   int32
+	Pod  string
+	Host string
+	// host:port of the client port
+	Address string
+}
+
+// isZookeeperEnsemble tells whether the deployment is the zookeeper ensemble managed by the operator
+func isZookeeperEnsemble(c *kvstorev1.HbaseCluster, d kvstorev1.HbaseClusterDeployment) bool {
+	return c.Spec.ZookeeperEnsemble != nil && c.Spec.ExternalZookeeper == nil &&
+		c.Spec.Deployments.Zookeeper.Size > 0 && d.Name == c.Spec.Deployments.Zookeeper.Name
+}
+
+// zookeeperSettings returns the ensemble settings of the cluster with defaults filled in
+func zookeeperSettings(c *kvstorev1.HbaseCluster) kvstorev1.HbaseClusterZookeeper {
+	z := *c.Spec.ZookeeperEnsemble
+	if z.ClientPort == 0 {
+		z.ClientPort = 2181
+	}
+	if z.PeerPort == 0 {
+		z.PeerPort = 2888
+	}
+	if z.LeaderPort == 0 {
+		z.LeaderPort = 3888
+	}
+	if len(z.ClusterDomain) == 0 {
+		z.ClusterDomain = "cluster.local"
+	}
+	return z
+}
+
+// zookeeperMembers returns the first count servers of the ensemble, addressed by the DNS names of their pods
+func zookeeperMembers(c *kvstorev1.HbaseCluster, count int32) []zookeeperMember {
+	z := zookeeperSettings(c)
+	members := []zookeeperMember{}
+	for ordinal := int32(0); ordinal < count; ordinal++ {
+		pod := c.Spec.Deployments.Zookeeper.Name + "-" + strconv.Itoa(int(ordinal))
+		host := pod + "." + c.Name + "." + c.Namespace + ".svc." + z.ClusterDomain
+		members = append(members, zookeeperMember{
+			ID:      ordinal + 1,
+			Pod:     pod,
+			Host:    host,
+			Address: net.JoinHostPort(host, strconv.Itoa(int(z.ClientPort))),
+		})
+	}
+	return members
+}
+
+// zookeeperServerSpec returns the server.<id> entry of a member in the dynamic configuration of the ensemble
+func zookeeperServerSpec(z kvstorev1.HbaseClusterZookeeper, m zookeeperMember) string {
+	return fmt.Sprintf("server.%d=%s:%d:%d:participant;%d", m.ID, m.Host, z.PeerPort, z.LeaderPort, z.ClientPort)
+}
+
+// zookeeperEnsembleSize returns the number of servers the ensemble is rendered with. While growing, it includes the
+// server about to join so that its pod starts before being added, one member at a time
+func zookeeperEnsembleSize(c *kvstorev1.HbaseCluster) int32 {
+	desired := c.Spec.Deployments.Zookeeper.Size
+	if c.Status.Zookeeper == nil || c.Status.Zookeeper.Members == 0 {
+		return desired
+	}
+	members := c.Status.Zookeeper.Members
+	if desired > members {
+		return members + 1
+	}
+	return members
+}
+
+// zookeeperEnsembleConfig returns the hbase-site.xml overlay with the quorum and the server list of the ensemble,
+// from which HQuorumPeer writes zoo.cfg and derives myid of each pod
+func zookeeperEnsembleConfig(c *kvstorev1.HbaseCluster, size int32) map[string]string {
+	z := zookeeperSettings(c)
+	members := zookeeperMembers(c, size)
+	hosts := []string{}
+	hbaseSite := hadoopConfiguration{}
+	for _, m := range members {
+		hosts = append(hosts, m.Host)
+		spec := strings.SplitN(zookeeperServerSpec(z, m), "=", 2)
+		hbaseSite.Properties = append(hbaseSite.Properties, hadoopConfigProperty{Name: "hbase.zookeeper.property." + spec[0], Value: spec[1]})
+	}
+	hbaseSite.Properties = append([]hadoopConfigProperty{
+		{Name: "hbase.zookeeper.quorum", Value: strings.Join(hosts, ",")},
+		{Name: "hbase.zookeeper.property.clientPort", Value: strconv.Itoa(int(z.ClientPort))},
+		{Name: "hbase.zookeeper.property.reconfigEnabled", Value: "true"},
+		{Name: "hbase.zookeeper.property.standaloneEnabled", Value: "false"},
+	}, hbaseSite.Properties...)
+	return map[string]string{"hbase-site.xml": renderHadoopConfiguration(hbaseSite)}
+}
+
+// zookeeperCommand sends a 4 letter word to a zookeeper server and returns its answer
+func zookeeperCommand(ctx context.Context, address string, command string) (string, error) {
+	dialer := net.Dialer{Timeout: ZOOKEEPER_COMMAND_TIMEOUT}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ZOOKEEPER_COMMAND_TIMEOUT))
+	if _, err = conn.Write([]byte(command)); err != nil {
+		return "", err
+	}
+	out, err := io.ReadAll(conn)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(string(out), "is not in the whitelist") || strings.Contains(string(out), "is not executed") {
+		return "", errs.New(strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// zookeeperContainer returns the container zkcli is run in, the first container of the zookeeper deployment
+func zookeeperContainer(c *kvstorev1.HbaseCluster) string {
+	if len(c.Spec.Deployments.Zookeeper.Containers) == 0 {
+		return ""
+	}
+	return c.Spec.Deployments.Zookeeper.Containers[0].Name
+}
+
+// reconfigZookeeper applies dynamic reconfig to the ensemble with the reconfig command of zkcli, run in the pod of
+// the given member against its own server. The joining server is given as server.<id>=<spec>, the leaving one by id
+func reconfigZookeeper(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, executor PodExecutor, m zookeeperMember,
+	joining string, leaving string) error {
+	if executor == nil {
+		return errs.New("Reconfig of the zookeeper ensemble needs the operator to exec in pods")
+	}
+	command := []string{"hbase", "zkcli", "-server", m.Address, "reconfig"}
+	if len(joining) > 0 {
+		command = append(command, "-add", joining)
+	}
+	if len(leaving) > 0 {
+		command = append(command, "-remove", leaving)
+	}
+	command = withoutJmxExporterAgent(c.Spec.Monitoring, c.Spec.Deployments.Zookeeper, zookeeperContainer(c), command)
+	log.Info("Reconfiguring zookeeper ensemble", "Pod", m.Pod, "Command", strings.Join(command, " "))
+	out, err := executor.Exec(ctx, c.Namespace, m.Pod, zookeeperContainer(c), command)
+	if err != nil {
+		return err
+	}
+	// zkcli reports failures of the command on its output, while exiting successfully
+	for _, failure := range []string{"KeeperErrorCode", "Exception"} {
+		if strings.Contains(out, failure) {
+			return errs.New("zkcli reconfig failed in " + m.Pod + ": " + strings.TrimSpace(out))
+		}
+	}
+	return nil
+}
+
+// zookeeperMode returns the mode of a server, leader, follower, observer or standalone, from its stat output
+func zookeeperMode(stat string) string {
+	scanner := bufio.NewScanner(strings.NewReader(stat))
+	for scanner.Scan() {
+		if mode, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "Mode:"); ok {
+			return strings.TrimSpace(mode)
+		}
+	}
+	return ""
+}
+
+// zookeeperSyncedFollowers returns zk_synced_followers from the mntr output of the leader, -1 when not reported
+func zookeeperSyncedFollowers(mntr string) int {
+	for _, line := range strings.Split(mntr, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "zk_synced_followers" {
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				return n
+			}
+		}
+	}
+	return -1
+}
+
+// probeZookeeperEnsemble asks every member for its mode with stat, and the leader for its synced followers with mntr.
+// The quorum is healthy while a leader is followed by a majority of the members. mntr is optional, followers are
+// counted from stat when it is not allowed
+func probeZookeeperEnsemble(ctx context.Context, log logr.Logger, members []zookeeperMember) *kvstorev1.ZookeeperEnsembleStatus {
+	status := &kvstorev1.ZookeeperEnsembleStatus{Members: int32(len(members))}
+	leader := zookeeperMember{}
+	for _, m := range members {
+		stat, err := zookeeperCommand(ctx, m.Address, "stat")
+		if err != nil {
+			log.Info("Zookeeper server did not answer stat", "Pod", m.Pod, "Error", err.Error())
+			status.Unreachable = append(status.Unreachable, m.Pod)
+			continue
+		}
+		switch zookeeperMode(stat) {
+		case "leader":
+			status.Leader = m.Pod
+			leader = m
+		case "follower":
+			status.Followers = append(status.Followers, m.Pod)
+		default:
+			status.Unreachable = append(status.Unreachable, m.Pod)
+		}
+	}
+	if len(status.Leader) == 0 {
+		return status
+	}
+
+	voters := 1 + len(status.Followers)
+	if mntr, err := zookeeperCommand(ctx, leader.Address, "mntr"); err == nil {
+		if synced := zookeeperSyncedFollowers(mntr); synced >= 0 {
+			voters = 1 + synced
+		}
+	}
+	status.QuorumHealthy = voters > len(members)/2
+	return status
+}
+
+// reconcileZookeeperEnsemble reports the health of the ensemble of the cluster, and resizes it towards the replicas of
+// the zookeeper deployment once its StatefulSet is ready
+func reconcileZookeeperEnsemble(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, executor PodExecutor,
+	recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	return syncZookeeperEnsemble(ctx, log, c, zookeeperMembers(c, zookeeperEnsembleSize(c)), executor, recorder, cl)
+}
+
+// syncZookeeperEnsemble adds or removes one member of the ensemble through dynamic reconfig when its size differs from
+// the desired one, provided the quorum is healthy. members are the servers the ensemble is rendered with, reconfig is
+// run in the pod of the leader
+func syncZookeeperEnsemble(ctx context.Context, log logr.Logger, c *kvstorev1.HbaseCluster, members []zookeeperMember,
+	executor PodExecutor, recorder record.EventRecorder, cl client.Client) (ctrl.Result, error) {
+	z := zookeeperSettings(c)
+	desired := c.Spec.Deployments.Zookeeper.Size
+	configured := int32(len(members))
+	if c.Status.Zookeeper != nil && c.Status.Zookeeper.Members > 0 && c.Status.Zookeeper.Members < configured {
+		configured = c.Status.Zookeeper.Members
+	}
+
+	status := probeZookeeperEnsemble(ctx, log, members[:configured])
+	result := ctrl.Result{}
+	var err error
+	switch {
+	case !status.QuorumHealthy:
+		log.Info("Zookeeper quorum is not healthy", "Leader", status.Leader, "Followers", status.Followers, "Unreachable", status.Unreachable)
+		recordWarning(recorder, c, REASON_ZOOKEEPER_QUORUM_UNHEALTHY,
+			fmt.Errorf("Zookeeper quorum is not healthy, leader: %q, followers: %v, unreachable: %v", status.Leader, status.Followers, status.Unreachable))
+		result = ctrl.Result{RequeueAfter: time.Second * 30}
+	case desired > configured && int32(len(members)) > configured:
+		joining := members[configured]
+		if _, err = zookeeperCommand(ctx, joining.Address, "stat"); err != nil {
+			log.Info("Waiting for the joining zookeeper server to start", "Pod", joining.Pod)
+			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+		if err = reconfigZookeeper(ctx, log, c, executor, members[leaderIndex(members, status.Leader)], zookeeperServerSpec(z, joining), ""); err != nil {
+			break
+		}
+		status.Members++
+		status.Followers = append(status.Followers, joining.Pod)
+		recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_ZOOKEEPER_MEMBER_ADDED, "Added "+joining.Pod+" to the zookeeper ensemble")
+		result = ctrl.Result{RequeueAfter: time.Second * 5}
+	case desired < configured:
+		leaving := members[configured-1]
+		if err = reconfigZookeeper(ctx, log, c, executor, members[leaderIndex(members, status.Leader)], "", strconv.Itoa(int(leaving.ID))); err != nil {
+			break
+		}
+		status.Members--
+		followers := []string{}
+		for _, f := range status.Followers {
+			if f != leaving.Pod {
+				followers = append(followers, f)
+			}
+		}
+		status.Followers = followers
+		recordEvent(recorder, c, nil, corev1.EventTypeNormal, REASON_ZOOKEEPER_MEMBER_REMOVED, "Removed "+leaving.Pod+" from the zookeeper ensemble")
+		result = ctrl.Result{RequeueAfter: time.Second * 5}
+	}
+	if err != nil {
+		log.Error(err, "Failed to reconfigure zookeeper ensemble")
+		recordWarning(recorder, c, REASON_ZOOKEEPER_RECONFIG_FAILED, err)
+		result = ctrl.Result{RequeueAfter: time.Second * 30}
+	}
+
+	if !equality.Semantic.DeepEqual(status, c.Status.Zookeeper) {
+		c.Status.Zookeeper = status
+		if updateErr := cl.Status().Update(ctx, c); updateErr != nil {
+			log.Error(updateErr, "Failed to update HbaseCluster status with the zookeeper ensemble")
+			return ctrl.Result{RequeueAfter: time.Second * 5}, updateErr
+		}
+	}
+	return result, err
+}
+
+// leaderIndex returns the index of the leader among the members, the first member when not known
+func leaderIndex(members []zookeeperMember, leader string) int {
+	for i, m := range members {
+		if m.Pod == leader {
+			return i
+		}
+	}
+	return 0
+}
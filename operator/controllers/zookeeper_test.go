@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	kvstorev1 "github.com/flipkart-incubator/hbase-k8s-operator/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func newZookeeperCluster(size int32) *kvstorev1.HbaseCluster {
+	return &kvstorev1.HbaseCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: testNamespace},
+		Spec: kvstorev1.HbaseClusterSpec{
+			Deployments: kvstorev1.HbaseClusterDeployments{Zookeeper: kvstorev1.HbaseClusterDeployment{Name: "zk", Size: size,
+				Containers: []kvstorev1.HbaseClusterContainer{{Name: "zookeeper"}}}},
+			ZookeeperEnsemble: &kvstorev1.HbaseClusterZookeeper{},
+		},
+	}
+}
+
+// startZookeeperStandIn serves 4 letter words with the given answers on a local port, and returns its address
+func startZookeeperStandIn(t *testing.T, answers map[string]string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 4)
+			n, _ := conn.Read(buf)
+			answer, ok := answers[string(buf[:n])]
+			if !ok {
+				answer = string(buf[:n]) + " is not executed because it is not in the whitelist.\n"
+			}
+			conn.Write([]byte(answer))
+			conn.Close()
+		}
+	}()
+	return l.Addr().String()
+}
+
+// expectZookeeperReconfig expects zkcli reconfig with the given arguments in the pod of the leader, zk-1
+func expectZookeeperReconfig(members []zookeeperMember, out string, err error, args ...string) *MockPodExecutor {
+	executor := new(MockPodExecutor)
+	command := append([]string{"hbase", "zkcli", "-server", members[1].Address, "reconfig"}, args...)
+	executor.On("Exec", mock.Anything, testNamespace, "zk-1", "zookeeper", command).Return(out, err)
+	return executor
+}
+
+// zookeeperStandIns returns members answering stat with the given modes, and the leader answering mntr
+func zookeeperStandIns(t *testing.T, c *kvstorev1.HbaseCluster, modes ...string) []zookeeperMember {
+	members := zookeeperMembers(c, int32(len(modes)))
+	for i, mode := range modes {
+		answers := map[string]string{}
+		if len(mode) > 0 {
+			answers["stat"] = "Zookeeper version: 3.8.4\nClients:\n\nMode: " + mode + "\nNode count: 4\n"
+		}
+		if mode == "leader" {
+			answers["mntr"] = "zk_version\t3.8.4\nzk_server_state\tleader\nzk_synced_followers\t2\n"
+		}
+		members[i].Address = startZookeeperStandIn(t, answers)
+	}
+	return members
+}
+
+// TestZookeeperMembers verifies servers are addressed by the DNS names of their pods, with ids starting at 1.
+func TestZookeeperMembers(t *testing.T) {
+	members := zookeeperMembers(newZookeeperCluster(3), 2)
+	assert.Equal(t, []zookeeperMember{
+		{ID: 1, Pod: "zk-0", Host: "zk-0.test.test-namespace.svc.cluster.local", Address: "zk-0.test.test-namespace.svc.cluster.local:2181"},
+		{ID: 2, Pod: "zk-1", Host: "zk-1.test.test-namespace.svc.cluster.local", Address: "zk-1.test.test-namespace.svc.cluster.local:2181"},
+	}, members)
+	assert.Equal(t, "server.2=zk-1.test.test-namespace.svc.cluster.local:2888:3888:participant;2181",
+		zookeeperServerSpec(zookeeperSettings(newZookeeperCluster(3)), members[1]))
+}
+
+// TestZookeeperEnsembleSize verifies the ensemble grows one member at a time from the members in status.
+func TestZookeeperEnsembleSize(t *testing.T) {
+	c := newZookeeperCluster(5)
+	assert.Equal(t, int32(5), zookeeperEnsembleSize(c))
+
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 3}
+	assert.Equal(t, int32(4), zookeeperEnsembleSize(c))
+
+	c.Spec.Deployments.Zookeeper.Size = 1
+	assert.Equal(t, int32(3), zookeeperEnsembleSize(c))
+}
+
+// TestZookeeperEnsembleConfig verifies the quorum and server list are rendered in hbase-site.xml.
+func TestZookeeperEnsembleConfig(t *testing.T) {
+	c := newZookeeperCluster(3)
+	c.Spec.ZookeeperEnsemble.ClientPort = 2182
+	properties, ok := parseConfigProperties("hbase-site.xml", zookeeperEnsembleConfig(c, 3)["hbase-site.xml"])
+	assert.True(t, ok)
+	assert.Equal(t, "zk-0.test.test-namespace.svc.cluster.local,zk-1.test.test-namespace.svc.cluster.local,zk-2.test.test-namespace.svc.cluster.local",
+		properties["hbase.zookeeper.quorum"])
+	assert.Equal(t, "2182", properties["hbase.zookeeper.property.clientPort"])
+	assert.Equal(t, "true", properties["hbase.zookeeper.property.reconfigEnabled"])
+	assert.Equal(t, "zk-2.test.test-namespace.svc.cluster.local:2888:3888:participant;2182", properties["hbase.zookeeper.property.server.3"])
+	assert.NotContains(t, properties, "hbase.zookeeper.property.server.4")
+}
+
+// TestZookeeperCommand verifies answers of the 4 letter words, and errors for commands which are not allowed.
+func TestZookeeperCommand(t *testing.T) {
+	address := startZookeeperStandIn(t, map[string]string{"stat": "Mode: follower\n"})
+
+	out, err := zookeeperCommand(context.TODO(), address, "stat")
+	assert.NoError(t, err)
+	assert.Equal(t, "follower", zookeeperMode(out))
+
+	_, err = zookeeperCommand(context.TODO(), address, "mntr")
+	assert.ErrorContains(t, err, "not in the whitelist")
+}
+
+// TestProbeZookeeperEnsemble verifies the leader and followers are reported, and the quorum health.
+func TestProbeZookeeperEnsemble(t *testing.T) {
+	log := ctrl.Log.WithName("test")
+	c := newZookeeperCluster(3)
+
+	status := probeZookeeperEnsemble(context.TODO(), log, zookeeperStandIns(t, c, "follower", "leader", "follower"))
+	assert.Equal(t, &kvstorev1.ZookeeperEnsembleStatus{Members: 3, Leader: "zk-1", Followers: []string{"zk-0", "zk-2"}, QuorumHealthy: true}, status)
+
+	status = probeZookeeperEnsemble(context.TODO(), log, zookeeperStandIns(t, c, "follower", "", ""))
+	assert.Equal(t, &kvstorev1.ZookeeperEnsembleStatus{Members: 3, Followers: []string{"zk-0"}, Unreachable: []string{"zk-1", "zk-2"}}, status)
+
+	assert.Equal(t, 2, zookeeperSyncedFollowers("zk_server_state\tleader\nzk_synced_followers\t2\n"))
+	assert.Equal(t, -1, zookeeperSyncedFollowers("zk_server_state\tfollower\n"))
+}
+
+// TestSyncZookeeperEnsemble_Grow verifies the pending server is added once it answers, and the members are recorded.
+func TestSyncZookeeperEnsemble_Grow(t *testing.T) {
+	c := newZookeeperCluster(5)
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 3}
+	members := zookeeperStandIns(t, c, "follower", "leader", "follower", "follower")
+	executor := expectZookeeperReconfig(members, "Committed new configuration", nil, "-add", zookeeperServerSpec(zookeeperSettings(c), members[3]))
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", context.TODO(), c).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := syncZookeeperEnsemble(context.TODO(), ctrl.Log.WithName("test"), c, members, executor, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	executor.AssertExpectations(t)
+	assert.Equal(t, int32(4), c.Status.Zookeeper.Members)
+	assert.Equal(t, "zk-1", c.Status.Zookeeper.Leader)
+	assert.Equal(t, []string{REASON_ZOOKEEPER_MEMBER_ADDED}, recordedReasons(recorder))
+	assert.Equal(t, int32(5), zookeeperEnsembleSize(c))
+	statusWriter.AssertExpectations(t)
+}
+
+// TestSyncZookeeperEnsemble_Shrink verifies the last server is removed by id.
+func TestSyncZookeeperEnsemble_Shrink(t *testing.T) {
+	c := newZookeeperCluster(3)
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 5}
+	members := zookeeperStandIns(t, c, "follower", "leader", "follower", "follower", "follower")
+	executor := expectZookeeperReconfig(members, "Committed new configuration", nil, "-remove", "5")
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", context.TODO(), c).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := syncZookeeperEnsemble(context.TODO(), ctrl.Log.WithName("test"), c, members, executor, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	executor.AssertExpectations(t)
+	assert.Equal(t, int32(4), c.Status.Zookeeper.Members)
+	assert.NotContains(t, c.Status.Zookeeper.Followers, "zk-4")
+	assert.Equal(t, []string{REASON_ZOOKEEPER_MEMBER_REMOVED}, recordedReasons(recorder))
+	// the StatefulSet is scaled down once the member left
+	assert.Equal(t, int32(4), zookeeperEnsembleSize(c))
+}
+
+// TestSyncZookeeperEnsemble_Unhealthy verifies the ensemble is not resized while the quorum is not healthy.
+func TestSyncZookeeperEnsemble_Unhealthy(t *testing.T) {
+	c := newZookeeperCluster(5)
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 3}
+	members := zookeeperStandIns(t, c, "follower", "", "", "follower")
+	executor := new(MockPodExecutor)
+	k8sMockClient := new(K8sMockClient)
+	statusWriter := new(K8sMockStatusWriter)
+	k8sMockClient.On("Status").Return(statusWriter)
+	statusWriter.On("Update", context.TODO(), c).Return(nil)
+	recorder := record.NewFakeRecorder(10)
+
+	result, err := syncZookeeperEnsemble(context.TODO(), ctrl.Log.WithName("test"), c, members, executor, recorder, k8sMockClient)
+	assert.NoError(t, err)
+	assert.NotZero(t, result.RequeueAfter)
+	executor.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, int32(3), c.Status.Zookeeper.Members)
+	assert.False(t, c.Status.Zookeeper.QuorumHealthy)
+	assert.Equal(t, []string{REASON_ZOOKEEPER_QUORUM_UNHEALTHY}, recordedReasons(recorder))
+}
+
+// TestSyncZookeeperEnsemble_ReconfigFailed verifies failures reported by zkcli are reported and the size is kept.
+func TestSyncZookeeperEnsemble_ReconfigFailed(t *testing.T) {
+	c := newZookeeperCluster(3)
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 5, Leader: "zk-1", Followers: []string{"zk-0", "zk-2", "zk-3", "zk-4"}, QuorumHealthy: true}
+	members := zookeeperStandIns(t, c, "follower", "leader", "follower", "follower", "follower")
+	executor := expectZookeeperReconfig(members, "KeeperErrorCode = NoAuth for /zookeeper/config", nil, "-remove", "5")
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := syncZookeeperEnsemble(context.TODO(), ctrl.Log.WithName("test"), c, members, executor, recorder, new(K8sMockClient))
+	assert.Error(t, err)
+	assert.Equal(t, int32(5), c.Status.Zookeeper.Members)
+	assert.Equal(t, []string{REASON_ZOOKEEPER_RECONFIG_FAILED}, recordedReasons(recorder))
+}
+
+// TestSyncZookeeperEnsemble_ExecFailed verifies the ensemble is not resized when zkcli can not be run.
+func TestSyncZookeeperEnsemble_ExecFailed(t *testing.T) {
+	c := newZookeeperCluster(5)
+	c.Status.Zookeeper = &kvstorev1.ZookeeperEnsembleStatus{Members: 3, Leader: "zk-1", Followers: []string{"zk-0", "zk-2"}, QuorumHealthy: true}
+	members := zookeeperStandIns(t, c, "follower", "leader", "follower", "follower")
+	executor := expectZookeeperReconfig(members, "", errors.New("command terminated with exit code 1"), "-add", zookeeperServerSpec(zookeeperSettings(c), members[3]))
+	recorder := record.NewFakeRecorder(10)
+
+	_, err := syncZookeeperEnsemble(context.TODO(), ctrl.Log.WithName("test"), c, members, executor, recorder, new(K8sMockClient))
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), c.Status.Zookeeper.Members)
+	assert.Equal(t, []string{REASON_ZOOKEEPER_RECONFIG_FAILED}, recordedReasons(recorder))
+}
@@ -92,6 +92,7 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var maxReconcilersTenant int
+	var enableWebhooks bool
 
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -100,6 +101,9 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.IntVar(&maxReconcilersTenant, "max-reconcilers-tenant", 3, "Max concurrent reconcilers for hbase tenant controller. Default is 3.")
 
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable validating webhooks. Requires serving certificates, see config/certmanager.")
+
 	opts := zap.Options{
 		Development: true,
 	}
@@ -131,28 +135,119 @@ func main() {
 		os.Exit(1)
 	}
 
+	podExecutor, err := controllers.NewPodExecutor(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create pod executor")
+		os.Exit(1)
+	}
 	if err = (&controllers.HbaseClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		Recorder:    mgr.GetEventRecorderFor("hbasecluster-controller"),
+		PodExecutor: podExecutor,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HbaseCluster")
 		os.Exit(1)
 	}
 	if err = (&controllers.HbaseTenantReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasetenant-controller"),
 	}).SetupWithManager(mgr, controllers.Options{MaxConcurrentReconciles: maxReconcilersTenant}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HbaseTenant")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.HbaseStandaloneReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasestandalone-controller"),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HbaseStandalone")
 		os.Exit(1)
 	}
+	if err = (&controllers.HbaseTenantPolicyReconciler{
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		AdmissionEnforced: enableWebhooks,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseTenantPolicy")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseNamespaceReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasenamespace-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseNamespace")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseTableReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasetable-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseTable")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseSnapshotScheduleReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasesnapshotschedule-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseSnapshotSchedule")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseBackupReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasebackup-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseBackup")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseRestoreReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbaserestore-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseRestore")
+		os.Exit(1)
+	}
+	if err = (&controllers.HbaseReplicationPeerReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("hbasereplicationpeer-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "HbaseReplicationPeer")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		if err = (&kvstorev1.HbaseCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseCluster")
+			os.Exit(1)
+		}
+		if err = (&kvstorev1.HbaseTenant{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseTenant")
+			os.Exit(1)
+		}
+		if err = (&kvstorev1.HbaseStandalone{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseStandalone")
+			os.Exit(1)
+		}
+		if err = (&kvstorev1.HbaseTenantPolicy{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseTenantPolicy")
+			os.Exit(1)
+		}
+		if err = (&kvstorev1.HbaseSnapshotSchedule{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseSnapshotSchedule")
+			os.Exit(1)
+		}
+		if err = (&kvstorev1.HbaseReplicationPeer{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "HbaseReplicationPeer")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
@@ -0,0 +1,647 @@
+package com.flipkart.hbase;
+
+import com.fasterxml.jackson.core.JsonProcessingException;
+import com.fasterxml.jackson.core.type.TypeReference;
+import com.fasterxml.jackson.databind.ObjectMapper;
+import com.sun.net.httpserver.HttpExchange;
+import com.sun.net.httpserver.HttpServer;
+import org.apache.hadoop.conf.Configuration;
+import org.apache.hadoop.fs.Path;
+import org.apache.hadoop.hbase.ClusterMetrics;
+import org.apache.hadoop.hbase.HBaseConfiguration;
+import org.apache.hadoop.hbase.NamespaceDescriptor;
+import org.apache.hadoop.hbase.NamespaceExistException;
+import org.apache.hadoop.hbase.NamespaceNotFoundException;
+import org.apache.hadoop.hbase.ReplicationPeerNotFoundException;
+import org.apache.hadoop.hbase.ServerMetrics;
+import org.apache.hadoop.hbase.ServerName;
+import org.apache.hadoop.hbase.TableExistsException;
+import org.apache.hadoop.hbase.TableName;
+import org.apache.hadoop.hbase.TableNotFoundException;
+import org.apache.hadoop.hbase.client.Admin;
+import org.apache.hadoop.hbase.client.ColumnFamilyDescriptorBuilder;
+import org.apache.hadoop.hbase.client.Connection;
+import org.apache.hadoop.hbase.client.ConnectionFactory;
+import org.apache.hadoop.hbase.client.SnapshotDescription;
+import org.apache.hadoop.hbase.client.TableDescriptorBuilder;
+import org.apache.hadoop.hbase.net.Address;
+import org.apache.hadoop.hbase.quotas.QuotaFilter;
+import org.apache.hadoop.hbase.quotas.QuotaSettings;
+import org.apache.hadoop.hbase.quotas.QuotaSettingsFactory;
+import org.apache.hadoop.hbase.quotas.QuotaTableUtil;
+import org.apache.hadoop.hbase.quotas.QuotaType;
+import org.apache.hadoop.hbase.quotas.SpaceQuotaSnapshotView;
+import org.apache.hadoop.hbase.quotas.SpaceViolationPolicy;
+import org.apache.hadoop.hbase.quotas.ThrottleSettings;
+import org.apache.hadoop.hbase.quotas.ThrottleType;
+import org.apache.hadoop.hbase.replication.ReplicationLoadSource;
+import org.apache.hadoop.hbase.replication.ReplicationPeerConfig;
+import org.apache.hadoop.hbase.replication.ReplicationPeerConfigBuilder;
+import org.apache.hadoop.hbase.replication.ReplicationPeerDescription;
+import org.apache.hadoop.hbase.rsgroup.RSGroupAdminClient;
+import org.apache.hadoop.hbase.rsgroup.RSGroupInfo;
+import org.apache.hadoop.hbase.shaded.protobuf.ProtobufUtil;
+import org.apache.hadoop.hbase.shaded.protobuf.generated.QuotaProtos;
+import org.apache.hadoop.hbase.snapshot.SnapshotDoesNotExistException;
+import org.apache.hadoop.hbase.util.Bytes;
+import org.slf4j.Logger;
+import org.slf4j.LoggerFactory;
+
+import java.io.IOException;
+import java.io.InputStream;
+import java.io.OutputStream;
+import java.net.InetSocketAddress;
+import java.net.URLDecoder;
+import java.nio.charset.StandardCharsets;
+import java.util.ArrayList;
+import java.util.Collections;
+import java.util.EnumSet;
+import java.util.HashMap;
+import java.util.HashSet;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+import java.util.concurrent.Executors;
+import java.util.concurrent.TimeUnit;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Reference implementation of the admin endpoint of the operator, see docs/admin-endpoint.md. Every request is served
+ * with the HBase Java Admin API through a connection shared by all the requests.
+ */
+public class HbaseAdminServer {
+    private static final Logger LOG = LoggerFactory.getLogger(HbaseAdminServer.class);
+    private static final ObjectMapper MAPPER = new ObjectMapper();
+    private static final Pattern LIMIT = Pattern.compile("^(\\d+)(req|cu|[bkmgtp])/(sec|min|hour|day)$", Pattern.CASE_INSENSITIVE);
+    private static final String SPACE = "SPACE";
+
+    private final Connection connection;
+
+    public HbaseAdminServer(Connection connection) {
+        this.connection = connection;
+    }
+
+    public static void main(String[] args) throws Exception {
+        if (args.length < 2) {
+            LOG.error("Usage: java -cp adminserver.jar com.flipkart.hbase.HbaseAdminServer hbaseConfPath port");
+            LOG.error("Example: java -cp adminserver.jar com.flipkart.hbase.HbaseAdminServer /etc/hbase 16080");
+            System.exit(1);
+        }
+
+        Configuration configuration = HBaseConfiguration.create();
+        configuration.addResource(new Path(args[0] + "/hbase-site.xml"));
+
+        HbaseAdminServer adminServer = new HbaseAdminServer(ConnectionFactory.createConnection(configuration));
+        HttpServer server = HttpServer.create(new InetSocketAddress(Integer.parseInt(args[1])), 0);
+        server.createContext("/admin/", adminServer::handle);
+        server.setExecutor(Executors.newFixedThreadPool(4));
+        server.start();
+        LOG.info("Serving the admin endpoint on port " + args[1]);
+        Thread.currentThread().join();
+    }
+
+    private void handle(HttpExchange exchange) throws IOException {
+        String method = exchange.getRequestMethod();
+        List<String> path = new ArrayList<>();
+        for (String segment : exchange.getRequestURI().getRawPath().substring("/admin/".length()).split("/")) {
+            if (!segment.isEmpty()) {
+                path.add(URLDecoder.decode(segment, StandardCharsets.UTF_8.name()));
+            }
+        }
+
+        try (Admin admin = connection.getAdmin()) {
+            Object response = route(admin, method, path, exchange.getRequestBody());
+            if (response == null) {
+                reply(exchange, 200, "application/json", "{}");
+            } else {
+                reply(exchange, 200, "application/json", MAPPER.writeValueAsString(response));
+            }
+        } catch (NotFoundException ex) {
+            reply(exchange, 404, "text/plain", ex.getMessage());
+        } catch (Exception ex) {
+            int status = statusOf(ex);
+            if (status == 500) {
+                LOG.error("{} {} failed with error: {}", method, exchange.getRequestURI(), ex.getMessage(), ex);
+            }
+            reply(exchange, status, "text/plain", String.valueOf(ex.getMessage()));
+        }
+    }
+
+    private Object route(Admin admin, String method, List<String> path, InputStream body) throws Exception {
+        String resource = path.isEmpty() ? "" : path.get(0);
+        int size = path.size();
+        switch (method + " " + resource + "/" + size) {
+            case "POST update_all_config/1":
+                admin.updateConfiguration();
+                return null;
+            case "GET rsgroups/2":
+                return getRSGroup(admin, path.get(1));
+            case "POST rsgroups/1":
+                new RSGroupAdminClient(connection).addRSGroup(string(read(body), "name"));
+                return null;
+            case "POST rsgroups/3":
+                if ("servers".equals(path.get(2))) {
+                    moveServers(path.get(1), strings(read(body), "servers"));
+                    return null;
+                } else if ("namespaces".equals(path.get(2))) {
+                    moveNamespaces(admin, path.get(1), strings(read(body), "namespaces"));
+                    return null;
+                }
+                break;
+            case "GET quotas/1":
+                return listQuotas(admin);
+            case "PUT quotas/1":
+                admin.setQuota(quotaSettings(read(body), false));
+                return null;
+            case "DELETE quotas/1":
+                admin.setQuota(quotaSettings(read(body), true));
+                return null;
+            case "GET quotas/2":
+                if ("space_usage".equals(path.get(1))) {
+                    return spaceUsage(admin);
+                }
+                break;
+            case "POST tables/1":
+                createTable(admin, read(body));
+                return null;
+            case "GET tables/1":
+                List<String> tables = new ArrayList<>();
+                for (TableName table : admin.listTableNames()) {
+                    tables.add(table.getNamespaceAsString() + ":" + table.getQualifierAsString());
+                }
+                return tables;
+            case "POST snapshots/1":
+                Map<String, Object> snapshot = read(body);
+                admin.snapshot(string(snapshot, "name"), TableName.valueOf(string(snapshot, "table")));
+                return null;
+            case "GET snapshots/1":
+                List<Map<String, Object>> snapshots = new ArrayList<>();
+                for (SnapshotDescription s : admin.listSnapshots()) {
+                    Map<String, Object> info = new LinkedHashMap<>();
+                    info.put("name", s.getName());
+                    info.put("table", s.getTableNameAsString());
+                    info.put("creationTime", s.getCreationTime());
+                    snapshots.add(info);
+                }
+                return snapshots;
+            case "DELETE snapshots/2":
+                requireSnapshot(admin, path.get(1));
+                admin.deleteSnapshot(path.get(1));
+                return null;
+            case "POST snapshots/3":
+                requireSnapshot(admin, path.get(1));
+                if ("restore".equals(path.get(2))) {
+                    admin.restoreSnapshot(path.get(1));
+                    return null;
+                } else if ("clone".equals(path.get(2))) {
+                    admin.cloneSnapshot(path.get(1), TableName.valueOf(string(read(body), "table")));
+                    return null;
+                }
+                break;
+            case "GET replication/2":
+                if ("peers".equals(path.get(1))) {
+                    return listReplicationPeers(admin);
+                } else if ("load".equals(path.get(1))) {
+                    return replicationLoad(admin);
+                }
+                break;
+            case "POST replication/2":
+                if ("peers".equals(path.get(1))) {
+                    Map<String, Object> peer = read(body);
+                    admin.addReplicationPeer(string(peer, "id"), peerConfig(ReplicationPeerConfig.newBuilder()
+                            .setClusterKey(string(peer, "clusterKey"))
+                            .setSerial(Boolean.TRUE.equals(peer.get("serial"))), peer), Boolean.TRUE.equals(peer.get("enabled")));
+                    return null;
+                }
+                break;
+            case "PUT replication/3":
+                if ("peers".equals(path.get(1))) {
+                    String id = requirePeer(admin, path.get(2));
+                    admin.updateReplicationPeerConfig(id,
+                            peerConfig(ReplicationPeerConfig.newBuilder(admin.getReplicationPeerConfig(id)), read(body)));
+                    return null;
+                }
+                break;
+            case "DELETE replication/3":
+                if ("peers".equals(path.get(1))) {
+                    admin.removeReplicationPeer(requirePeer(admin, path.get(2)));
+                    return null;
+                }
+                break;
+            case "POST replication/4":
+                if ("peers".equals(path.get(1)) && "enable".equals(path.get(3))) {
+                    admin.enableReplicationPeer(requirePeer(admin, path.get(2)));
+                    return null;
+                } else if ("peers".equals(path.get(1)) && "disable".equals(path.get(3))) {
+                    admin.disableReplicationPeer(requirePeer(admin, path.get(2)));
+                    return null;
+                }
+                break;
+            default:
+                break;
+        }
+        throw new NotFoundException("No such operation: " + method + " /admin/" + String.join("/", path));
+    }
+
+    private Map<String, Object> getRSGroup(Admin admin, String name) throws IOException {
+        RSGroupInfo group = new RSGroupAdminClient(connection).getRSGroupInfo(name);
+        if (group == null) {
+            throw new NotFoundException("RSGroup " + name + " does not exist");
+        }
+        List<String> servers = new ArrayList<>();
+        for (Address server : group.getServers()) {
+            servers.add(server.toString());
+        }
+        List<String> namespaces = new ArrayList<>();
+        for (NamespaceDescriptor ns : admin.listNamespaceDescriptors()) {
+            if (name.equals(ns.getConfigurationValue(RSGroupInfo.NAMESPACE_DESC_PROP_GROUP))) {
+                namespaces.add(ns.getName());
+            }
+        }
+        Map<String, Object> info = new LinkedHashMap<>();
+        info.put("name", group.getName());
+        info.put("servers", servers);
+        info.put("namespaces", namespaces);
+        return info;
+    }
+
+    // moving servers already in the group is refused by the master, they are skipped
+    private void moveServers(String name, List<String> servers) throws IOException {
+        RSGroupAdminClient rsGroupAdmin = new RSGroupAdminClient(connection);
+        RSGroupInfo group = rsGroupAdmin.getRSGroupInfo(name);
+        if (group == null) {
+            throw new NotFoundException("RSGroup " + name + " does not exist");
+        }
+        Set<Address> moved = new HashSet<>();
+        for (String server : servers) {
+            Address address = Address.fromString(server);
+            if (!group.containsServer(address)) {
+                moved.add(address);
+            }
+        }
+        if (!moved.isEmpty()) {
+            rsGroupAdmin.moveServers(moved, name);
+        }
+    }
+
+    // the group of a namespace is set in its descriptor for new tables, existing tables are moved along
+    private void moveNamespaces(Admin admin, String name, List<String> namespaces) throws IOException {
+        RSGroupAdminClient rsGroupAdmin = new RSGroupAdminClient(connection);
+        if (rsGroupAdmin.getRSGroupInfo(name) == null) {
+            throw new NotFoundException("RSGroup " + name + " does not exist");
+        }
+        for (String namespace : namespaces) {
+            NamespaceDescriptor ns = admin.getNamespaceDescriptor(namespace);
+            if (!name.equals(ns.getConfigurationValue(RSGroupInfo.NAMESPACE_DESC_PROP_GROUP))) {
+                admin.modifyNamespace(NamespaceDescriptor.create(ns).addConfiguration(RSGroupInfo.NAMESPACE_DESC_PROP_GROUP, name).build());
+            }
+            Set<TableName> moved = new HashSet<>();
+            for (TableName table : admin.listTableNamesByNamespace(namespace)) {
+                RSGroupInfo current = rsGroupAdmin.getRSGroupInfoOfTable(table);
+                if (current == null || !name.equals(current.getName())) {
+                    moved.add(table);
+                }
+            }
+            if (!moved.isEmpty()) {
+                rsGroupAdmin.moveTables(moved, name);
+            }
+        }
+    }
+
+    private List<Map<String, Object>> listQuotas(Admin admin) throws IOException {
+        List<Map<String, Object>> quotas = new ArrayList<>();
+        for (QuotaSettings settings : admin.getQuota(new QuotaFilter())) {
+            Map<String, Object> quota = new LinkedHashMap<>();
+            putIfSet(quota, "user", settings.getUserName());
+            putIfSet(quota, "namespace", settings.getNamespace());
+            if (settings.getTableName() != null) {
+                quota.put("table", settings.getTableName().getNameAsString());
+            }
+            if (settings instanceof ThrottleSettings) {
+                ThrottleSettings throttle = (ThrottleSettings) settings;
+                quota.put("throttleType", throttle.getThrottleType().name());
+                quota.put("limit", formatLimit(throttle.getThrottleType(), throttle.getSoftLimit(), throttle.getTimeUnit()));
+            } else if (settings.getQuotaType() == QuotaType.SPACE && settings.getNamespace() != null) {
+                // space limits are not exposed by the client API, they are read from the quota table instead
+                QuotaProtos.Quotas proto = QuotaTableUtil.getNamespaceQuota(connection, settings.getNamespace());
+                if (proto == null || !proto.hasSpace() || proto.getSpace().getRemove()) {
+                    continue;
+                }
+                quota.put("spaceLimit", proto.getSpace().getSoftLimit());
+                quota.put("policy", ProtobufUtil.toViolationPolicy(proto.getSpace().getViolationPolicy()).name());
+            } else {
+                // space quotas of tables and other quota types are not managed by the operator
+                continue;
+            }
+            quotas.add(quota);
+        }
+        return quotas;
+    }
+
+    private List<Map<String, Object>> spaceUsage(Admin admin) throws IOException {
+        List<Map<String, Object>> usage = new ArrayList<>();
+        for (QuotaSettings settings : admin.getQuota(new QuotaFilter())) {
+            if (settings.getQuotaType() != QuotaType.SPACE || settings.getNamespace() == null) {
+                continue;
+            }
+            SpaceQuotaSnapshotView snapshot = admin.getCurrentSpaceQuotaSnapshot(settings.getNamespace());
+            if (snapshot == null) {
+                continue;
+            }
+            Map<String, Object> namespace = new LinkedHashMap<>();
+            namespace.put("namespace", settings.getNamespace());
+            namespace.put("usage", snapshot.getUsage());
+            namespace.put("limit", snapshot.getLimit());
+            namespace.put("inViolation", snapshot.getQuotaStatus().isInViolation());
+            usage.add(namespace);
+        }
+        return usage;
+    }
+
+    // quotaSettings builds the settings setting the quota of the request, or removing it
+    private static QuotaSettings quotaSettings(Map<String, Object> quota, boolean remove) {
+        String user = optionalString(quota, "user");
+        String namespace = optionalString(quota, "namespace");
+        String table = optionalString(quota, "table");
+        String throttleType = optionalString(quota, "throttleType");
+
+        if (throttleType == null) {
+            if (namespace == null) {
+                throw new IllegalArgumentException("Space quotas are only supported on namespaces");
+            }
+            if (remove) {
+                return QuotaSettingsFactory.removeNamespaceSpaceLimit(namespace);
+            }
+            Object spaceLimit = quota.get("spaceLimit");
+            if (!(spaceLimit instanceof Number)) {
+                throw new IllegalArgumentException("spaceLimit is required");
+            }
+            return QuotaSettingsFactory.limitNamespaceSpace(namespace, ((Number) spaceLimit).longValue(),
+                    SpaceViolationPolicy.valueOf(string(quota, "policy").toUpperCase()));
+        }
+
+        ThrottleType type = ThrottleType.valueOf(throttleType.toUpperCase());
+        if (remove) {
+            if (user != null && table != null) {
+                return QuotaSettingsFactory.unthrottleUserByThrottleType(user, TableName.valueOf(table), type);
+            } else if (user != null && namespace != null) {
+                return QuotaSettingsFactory.unthrottleUserByThrottleType(user, namespace, type);
+            } else if (user != null) {
+                return QuotaSettingsFactory.unthrottleUserByThrottleType(user, type);
+            } else if (table != null) {
+                return QuotaSettingsFactory.unthrottleTableByThrottleType(TableName.valueOf(table), type);
+            } else if (namespace != null) {
+                return QuotaSettingsFactory.unthrottleNamespaceByThrottleType(namespace, type);
+            }
+            throw new IllegalArgumentException("One of user, namespace or table is required");
+        }
+
+        Matcher limit = LIMIT.matcher(string(quota, "limit").replace(" ", ""));
+        if (!limit.matches()) {
+            throw new IllegalArgumentException("Invalid limit " + quota.get("limit") + ", expected as in 1000req/sec or 10M/sec");
+        }
+        long amount = Long.parseLong(limit.group(1)) * sizeMultiplier(limit.group(2));
+        TimeUnit unit = timeUnit(limit.group(3));
+        if (user != null && table != null) {
+            return QuotaSettingsFactory.throttleUser(user, TableName.valueOf(table), type, amount, unit);
+        } else if (user != null && namespace != null) {
+            return QuotaSettingsFactory.throttleUser(user, namespace, type, amount, unit);
+        } else if (user != null) {
+            return QuotaSettingsFactory.throttleUser(user, type, amount, unit);
+        } else if (table != null) {
+            return QuotaSettingsFactory.throttleTable(TableName.valueOf(table), type, amount, unit);
+        } else if (namespace != null) {
+            return QuotaSettingsFactory.throttleNamespace(namespace, type, amount, unit);
+        }
+        throw new IllegalArgumentException("One of user, namespace or table is required");
+    }
+
+    private static String formatLimit(ThrottleType type, long limit, TimeUnit unit) {
+        String suffix = "B";
+        switch (type) {
+            case REQUEST_NUMBER:
+            case READ_NUMBER:
+            case WRITE_NUMBER:
+                suffix = "req";
+                break;
+            case REQUEST_CAPACITY_UNIT:
+            case READ_CAPACITY_UNIT:
+            case WRITE_CAPACITY_UNIT:
+                suffix = "CU";
+                break;
+            default:
+                break;
+        }
+        switch (unit) {
+            case MINUTES:
+                return limit + suffix + "/min";
+            case HOURS:
+                return limit + suffix + "/hour";
+            case DAYS:
+                return limit + suffix + "/day";
+            default:
+                return limit + suffix + "/sec";
+        }
+    }
+
+    private static long sizeMultiplier(String unit) {
+        switch (unit.toLowerCase()) {
+            case "k":
+                return 1L << 10;
+            case "m":
+                return 1L << 20;
+            case "g":
+                return 1L << 30;
+            case "t":
+                return 1L << 40;
+            case "p":
+                return 1L << 50;
+            default:
+                return 1;
+        }
+    }
+
+    private static TimeUnit timeUnit(String unit) {
+        switch (unit.toLowerCase()) {
+            case "min":
+                return TimeUnit.MINUTES;
+            case "hour":
+                return TimeUnit.HOURS;
+            case "day":
+                return TimeUnit.DAYS;
+            default:
+                return TimeUnit.SECONDS;
+        }
+    }
+
+    // createTable creates the table of a schema in the format of the REST gateway, pre-split at the given keys
+    @SuppressWarnings("unchecked")
+    private static void createTable(Admin admin, Map<String, Object> request) throws IOException {
+        Object schema = request.get("schema");
+        if (!(schema instanceof Map)) {
+            throw new IllegalArgumentException("schema is required");
+        }
+        Map<String, Object> table = (Map<String, Object>) schema;
+        TableDescriptorBuilder builder = TableDescriptorBuilder.newBuilder(TableName.valueOf(string(table, "name")));
+        for (Map.Entry<String, Object> attribute : table.entrySet()) {
+            if ("name".equals(attribute.getKey()) || "ColumnSchema".equals(attribute.getKey())) {
+                continue;
+            }
+            builder.setValue(attribute.getKey(), String.valueOf(attribute.getValue()));
+        }
+        Object families = table.get("ColumnSchema");
+        if (families instanceof List) {
+            for (Object family : (List<Object>) families) {
+                Map<String, Object> attributes = (Map<String, Object>) family;
+                ColumnFamilyDescriptorBuilder cf = ColumnFamilyDescriptorBuilder.newBuilder(Bytes.toBytes(string(attributes, "name")));
+                for (Map.Entry<String, Object> attribute : attributes.entrySet()) {
+                    if (!"name".equals(attribute.getKey())) {
+                        cf.setValue(attribute.getKey(), String.valueOf(attribute.getValue()));
+                    }
+                }
+                builder.setColumnFamily(cf.build());
+            }
+        }
+
+        List<String> splitKeys = strings(request, "splitKeys");
+        if (splitKeys.isEmpty()) {
+            admin.createTable(builder.build());
+            return;
+        }
+        byte[][] splits = new byte[splitKeys.size()][];
+        for (int i = 0; i < splitKeys.size(); i++) {
+            splits[i] = Bytes.toBytesBinary(splitKeys.get(i));
+        }
+        admin.createTable(builder.build(), splits);
+    }
+
+    private static void requireSnapshot(Admin admin, String name) throws IOException {
+        for (SnapshotDescription s : admin.listSnapshots()) {
+            if (s.getName().equals(name)) {
+                return;
+            }
+        }
+        throw new NotFoundException("Snapshot " + name + " does not exist");
+    }
+
+    private static List<Map<String, Object>> listReplicationPeers(Admin admin) throws IOException {
+        List<Map<String, Object>> peers = new ArrayList<>();
+        for (ReplicationPeerDescription description : admin.listReplicationPeers()) {
+            ReplicationPeerConfig config = description.getPeerConfig();
+            Map<String, Object> peer = new LinkedHashMap<>();
+            peer.put("id", description.getPeerId());
+            peer.put("clusterKey", config.getClusterKey());
+            peer.put("enabled", description.isEnabled());
+            peer.put("serial", config.isSerial());
+            if (!config.replicateAllUserTables() && config.getTableCFsMap() != null) {
+                Map<String, List<String>> tableCFs = new HashMap<>();
+                for (Map.Entry<TableName, List<String>> table : config.getTableCFsMap().entrySet()) {
+                    tableCFs.put(table.getKey().getNameAsString(), table.getValue() == null ? Collections.<String>emptyList() : table.getValue());
+                }
+                peer.put("tableCFs", tableCFs);
+            }
+            peers.add(peer);
+        }
+        return peers;
+    }
+
+    // peerConfig sets the tables replicated to the peer, all the tables with replication scope set without tableCFs
+    @SuppressWarnings("unchecked")
+    private static ReplicationPeerConfig peerConfig(ReplicationPeerConfigBuilder builder, Map<String, Object> peer) {
+        Object tableCFs = peer.get("tableCFs");
+        if (!(tableCFs instanceof Map) || ((Map<String, Object>) tableCFs).isEmpty()) {
+            return builder.setReplicateAllUserTables(true).setTableCFsMap(null).build();
+        }
+        Map<TableName, List<String>> tables = new HashMap<>();
+        for (Map.Entry<String, Object> table : ((Map<String, Object>) tableCFs).entrySet()) {
+            List<String> families = table.getValue() instanceof List ? (List<String>) table.getValue() : null;
+            tables.put(TableName.valueOf(table.getKey()), families == null || families.isEmpty() ? null : families);
+        }
+        return builder.setReplicateAllUserTables(false).setExcludeTableCFsMap(null).setTableCFsMap(tables).build();
+    }
+
+    private static String requirePeer(Admin admin, String id) throws IOException {
+        if (admin.listReplicationPeers(Pattern.compile(Pattern.quote(id))).isEmpty()) {
+            throw new NotFoundException("Replication peer " + id + " does not exist");
+        }
+        return id;
+    }
+
+    private static List<Map<String, Object>> replicationLoad(Admin admin) throws IOException {
+        List<Map<String, Object>> load = new ArrayList<>();
+        ClusterMetrics metrics = admin.getClusterMetrics(EnumSet.of(ClusterMetrics.Option.LIVE_SERVERS));
+        for (Map.Entry<ServerName, ServerMetrics> server : metrics.getLiveServerMetrics().entrySet()) {
+            for (ReplicationLoadSource source : server.getValue().getReplicationLoadSourceList()) {
+                Map<String, Object> progress = new LinkedHashMap<>();
+                progress.put("peerId", source.getPeerID());
+                progress.put("server", server.getKey().getAddress().toString());
+                progress.put("replicationLag", source.getReplicationLag());
+                progress.put("sizeOfLogQueue", source.getSizeOfLogQueue());
+                load.add(progress);
+            }
+        }
+        return load;
+    }
+
+    private static Map<String, Object> read(InputStream body) throws IOException {
+        Map<String, Object> request = MAPPER.readValue(body, new TypeReference<Map<String, Object>>() {});
+        return request == null ? Collections.<String, Object>emptyMap() : request;
+    }
+
+    private static String string(Map<String, Object> request, String key) {
+        String value = optionalString(request, key);
+        if (value == null) {
+            throw new IllegalArgumentException(key + " is required");
+        }
+        return value;
+    }
+
+    private static String optionalString(Map<String, Object> request, String key) {
+        Object value = request.get(key);
+        return value == null || value.toString().isEmpty() ? null : value.toString();
+    }
+
+    @SuppressWarnings("unchecked")
+    private static List<String> strings(Map<String, Object> request, String key) {
+        Object value = request.get(key);
+        return value instanceof List ? (List<String>) value : Collections.<String>emptyList();
+    }
+
+    private static void putIfSet(Map<String, Object> map, String key, String value) {
+        if (value != null && !value.isEmpty()) {
+            map.put(key, value);
+        }
+    }
+
+    // statusOf maps failures of HBase to the status of the response, looking through their causes
+    private static int statusOf(Throwable ex) {
+        for (Throwable cause = ex; cause != null; cause = cause.getCause()) {
+            if (cause instanceof TableNotFoundException || cause instanceof NamespaceNotFoundException
+                    || cause instanceof SnapshotDoesNotExistException || cause instanceof ReplicationPeerNotFoundException) {
+                return 404;
+            } else if (cause instanceof TableExistsException || cause instanceof NamespaceExistException) {
+                return 409;
+            } else if (cause instanceof IllegalArgumentException || cause instanceof JsonProcessingException) {
+                return 400;
+            }
+        }
+        return 500;
+    }
+
+    private static void reply(HttpExchange exchange, int status, String contentType, String body) throws IOException {
+        byte[] payload = body.getBytes(StandardCharsets.UTF_8);
+        exchange.getResponseHeaders().set("Content-Type", contentType);
+        exchange.sendResponseHeaders(status, payload.length);
+        try (OutputStream out = exchange.getResponseBody()) {
+            out.write(payload);
+        }
+    }
+
+    private static class NotFoundException extends IOException {
+        NotFoundException(String message) {
+            super(message);
+        }
+    }
+}